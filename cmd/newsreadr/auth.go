@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/internal/keyring"
+	"golang.org/x/term"
+)
+
+// runAuthCommand stores and removes secrets in the system keyring, where
+// config settings reference them as keyring:NAME
+func runAuthCommand(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: newsreadr auth set|delete NAME")
+	}
+	name := args[1]
+
+	switch args[0] {
+	case "set":
+		secret, err := readSecret(name)
+		if err != nil {
+			return err
+		}
+		if secret == "" {
+			return errors.New("no secret given")
+		}
+		if err := keyring.Set(name, secret); err != nil {
+			return err
+		}
+		fmt.Printf("Stored %s in the keyring. Use it in the config as keyring:%s\n", name, name)
+		return nil
+
+	case "delete":
+		if err := keyring.Delete(name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Printf("Removed %s from the keyring\n", name)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown auth command %q", args[0])
+	}
+}
+
+// readSecret prompts for a secret without echoing it, or reads the first
+// line of stdin when it isn't a terminal so scripts can pipe it in
+func readSecret(name string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading secret: %w", err)
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	fmt.Printf("Enter %s: ", name)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("reading secret: %w", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/internal/metrics"
+	"github.com/thomaskoefod/newsreadr/internal/notify"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// runDaemonCommand fetches and scores articles on a schedule without the
+// reader until interrupted, serving metrics when metrics.listen is set
+func runDaemonCommand(cfg *config.Config, db *database.DB, args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	interval := fs.Duration("interval", 0, "time between fetches (default ui.refresh_interval)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		d, err := cfg.UI.GetRefreshInterval()
+		if err != nil {
+			return err
+		}
+		*interval = d
+	}
+
+	m := metrics.New(db)
+	if cfg.Metrics.Listen != "" {
+		srv, err := m.Serve(cfg.Metrics.Listen)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+		log.Printf("Serving metrics on http://%s/metrics", cfg.Metrics.Listen)
+	}
+
+	fetcher := feed.NewFetcher(db, cfg)
+	aiClient, err := newAIClient(cfg, db)
+	if err != nil {
+		return err
+	}
+	aiClient.SetMetrics(m)
+	notifier, err := notify.NewDispatcher(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	// A signal mid-cycle aborts the fetch and the scoring; the scores
+	// computed so far are written before the cycle returns
+	stopped := make(chan struct{})
+	go func() {
+		<-stop
+		log.Print("Stopping")
+		fetcher.Shutdown()
+		aiClient.Shutdown()
+		close(stopped)
+	}()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	log.Printf("Fetching every %s", *interval)
+	synced := false
+	for {
+		// Cycles are skipped while the reader or a rescore holds the lock;
+		// the reader fetches on its own. The feeds and interests are synced
+		// with the config in the first cycle holding it.
+		if lock, err := database.AcquireLock(cfg.Database.Path); err != nil {
+			log.Printf("Skipping fetch: %v", err)
+		} else {
+			if !synced {
+				if err := seedFromConfig(cfg, db); err != nil {
+					lock.Release()
+					return err
+				}
+				synced = true
+			}
+			if err := fetchCycle(cfg, db, fetcher, aiClient, notifier, m); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("Fetch failed: %v", err)
+			}
+			lock.Release()
+		}
+		select {
+		case <-stopped:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// logScoring logs what went wrong while scoring carried on
+func logScoring(_ map[int64]float64, _ int, err error) {
+	if err != nil {
+		log.Printf("Scoring: %v", err)
+	}
+}
+
+// fetchCycle fetches all feeds, scores the new articles, cleans up and sends
+// notifications, like a refresh in the reader. Scoring is skipped while
+// Ollama is unavailable.
+func fetchCycle(cfg *config.Config, db *database.DB, fetcher *feed.Fetcher, aiClient *ai.Client, notifier *notify.Dispatcher, m *metrics.Metrics) error {
+	started := time.Now()
+	maxAge := time.Duration(cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
+
+	scorer, err := aiClient.NewScorer(logScoring)
+	if err != nil {
+		log.Printf("Not scoring: %v", err)
+	}
+	onNew := func(article *models.Article) {
+		if scorer != nil {
+			scorer.Submit(article)
+		}
+	}
+	onFeedDone := func(result feed.FeedResult, _, _ int) {
+		m.FeedFetched(result.New, result.Err)
+		if result.Err != nil {
+			log.Printf("%s: %v", result.FeedName, result.Err)
+		}
+	}
+
+	summary, err := fetcher.FetchAllFeeds(onNew, onFeedDone)
+	if scorer != nil {
+		if closeErr := scorer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := summary.HookErr(); err != nil {
+		log.Printf("Hook failed: %v", err)
+	}
+	if err := summary.ExtractErr(); err != nil {
+		log.Printf("Extraction failed: %v", err)
+	}
+
+	// Catch up on articles left unscored by earlier cycles
+	if scorer != nil {
+		if err := aiClient.ScoreAllUnscored(cfg.UI.ArticleMaxAgeDays, logScoring); err != nil {
+			return err
+		}
+	}
+
+	if err := db.DeleteOldArticles(maxAge); err != nil {
+		return err
+	}
+	if _, err := db.PruneEmbeddings(cfg.Database.EmbeddingCacheMaxAge()); err != nil {
+		return err
+	}
+	if _, err := db.VacuumIfNeeded(cfg.Database.AutoVacuumThreshold()); err != nil {
+		return err
+	}
+	if _, err := fetcher.CacheOffline(); err != nil {
+		log.Printf("Offline caching failed: %v", err)
+	}
+	if scorer != nil && cfg.Ollama.ExtractEntities {
+		if _, err := aiClient.ExtractAllEntities(nil); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Entity extraction failed: %v", err)
+		}
+	}
+	m.FetchDone()
+	log.Printf("Fetched %d new articles from %d feeds (%d failed)", summary.TotalNew(), len(summary.Results), summary.Failed())
+
+	if notifier == nil {
+		return nil
+	}
+	unread, err := db.GetUnreadArticleList(maxAge)
+	if err != nil {
+		return err
+	}
+	return notifier.FetchDone(summary.TotalNew(), summary.Failed(), unread, started)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+)
+
+// runDBCommand handles the "db" maintenance subcommands
+func runDBCommand(cfg *config.Config, db *database.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: newsreadr db stats|vacuum|encrypt")
+	}
+
+	switch args[0] {
+	case "stats":
+		stats, err := db.GetStats()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Database:      %s\n", cfg.Database.Path)
+		fmt.Printf("Feeds:         %d\n", stats.Feeds)
+		fmt.Printf("Articles:      %d\n", stats.Articles)
+		fmt.Printf("Read articles: %d\n", stats.ReadArticles)
+		fmt.Printf("Read history:  %d reads\n", stats.ReadHistory)
+		fmt.Printf("Starred:       %d\n", stats.Starred)
+		fmt.Printf("In trash:      %d\n", stats.Trashed)
+		fmt.Printf("Size:          %s\n", formatBytes(stats.SizeBytes))
+		fmt.Printf("Free space:    %s\n", formatBytes(stats.FreeBytes))
+		fmt.Printf("Cache:         %s\n", cfg.Database.CachePath)
+		fmt.Printf("Embeddings:    %d cached\n", stats.Embeddings)
+		fmt.Printf("Cache size:    %s\n", formatBytes(stats.CacheBytes))
+		return nil
+
+	case "vacuum":
+		lock, err := database.AcquireLock(cfg.Database.Path)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+
+		before, err := db.GetStats()
+		if err != nil {
+			return err
+		}
+		if err := db.Vacuum(); err != nil {
+			return err
+		}
+		after, err := db.GetStats()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Vacuumed database: %s -> %s\n", formatBytes(before.SizeBytes), formatBytes(after.SizeBytes))
+		fmt.Printf("Vacuumed cache:    %s -> %s\n", formatBytes(before.CacheBytes), formatBytes(after.CacheBytes))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown db command %q", args[0])
+	}
+}
+
+// runEncryptCommand encrypts the stored articles with the configured key.
+// Every article is rewritten, so it needs the lock like rescore.
+func runEncryptCommand(cfg *config.Config) error {
+	lock, err := database.AcquireLock(cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	db, err := database.New(cfg.Database.Path, cfg.Database.CachePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.Encrypt(cfg.Database.EncryptionKey); err != nil {
+		return err
+	}
+	fmt.Printf("Encrypted articles in %s\n", cfg.Database.Path)
+	return nil
+}
+
+// formatBytes renders a byte count in human readable units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
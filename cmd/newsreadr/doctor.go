@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+)
+
+// runDoctorCommand checks the config file and the Ollama connection,
+// listing every problem found
+func runDoctorCommand(configPath string) error {
+	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no configuration at %s (run newsreadr to create one)", configPath)
+	}
+
+	cfg, err := config.Load(configPath)
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		noun := "problems"
+		if len(invalid.Problems) == 1 {
+			noun = "problem"
+		}
+		fmt.Printf("%s has %d %s:\n", configPath, len(invalid.Problems), noun)
+		for _, p := range invalid.Problems {
+			fmt.Printf("  %s\n", p)
+		}
+		return errors.New("configuration is invalid")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Configuration OK: %s\n", configPath)
+
+	if err := ai.NewClient(cfg.Ollama.Host, cfg.Ollama.Model, nil).Ping(5 * time.Second); err != nil {
+		return fmt.Errorf("Ollama at %s: %w", cfg.Ollama.Host, err)
+	}
+	fmt.Printf("Ollama reachable at %s\n", cfg.Ollama.Host)
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+)
+
+// runEntitiesCommand handles the "entities" subcommands
+func runEntitiesCommand(cfg *config.Config, db *database.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: newsreadr entities extract|list")
+	}
+
+	switch args[0] {
+	case "extract":
+		// Entities saved by a concurrent fetch would be written twice
+		lock, err := database.AcquireLock(cfg.Database.Path)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+
+		aiClient := ai.NewClient(cfg.Ollama.Host, cfg.Ollama.Model, db)
+		n, err := aiClient.ExtractAllEntities(func(done, total int) {
+			fmt.Printf("Extracted entities from %d/%d articles\r", done, total)
+		})
+		if n > 0 {
+			fmt.Println()
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Extracted entities from %d articles using %s\n", n, cfg.Ollama.Model)
+		return nil
+
+	case "list":
+		entities, err := db.GetEntities()
+		if err != nil {
+			return err
+		}
+		if len(entities) == 0 {
+			fmt.Println("No entities extracted from unread articles")
+			return nil
+		}
+		for _, e := range entities {
+			fmt.Printf("%4d  %-8s %s\n", e.Articles, e.Kind, e.Name)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown entities command %q", args[0])
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/export"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// runExportCommand exports read or starred history to stdout or a file
+func runExportCommand(db *database.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: newsreadr export read|starred [-format json|csv] [-o file]")
+	}
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "output format: json or csv")
+	output := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var entries []models.HistoryEntry
+	var err error
+	switch args[0] {
+	case "read":
+		entries, err = db.GetReadHistory()
+	case "starred":
+		entries, err = db.GetStarredArticles()
+	default:
+		return fmt.Errorf("unknown export source %q", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return export.Write(w, *format, entries)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/internal/raindrop"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// healthTimeout bounds the Ollama checks and each sampled feed
+const healthTimeout = 30 * time.Second
+
+// Exit codes of the health command. Failing to run at all exits with 1,
+// like any other command.
+const (
+	exitUnhealthy = 2 // the database or Ollama can't be used
+	exitDegraded  = 3 // Raindrop or a sampled feed failed
+)
+
+// healthCheck is the outcome of one check. Critical checks make the result
+// unhealthy when they fail, the others only degraded.
+type healthCheck struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"` // "ok", "fail" or "skip"
+	Detail   string `json:"detail,omitempty"`
+	Critical bool   `json:"critical"`
+}
+
+type healthReport struct {
+	Status string        `json:"status"` // "healthy", "degraded" or "unhealthy"
+	Checks []healthCheck `json:"checks"`
+}
+
+// runHealthCommand checks that everything newsreadr depends on works and
+// exits with a code scripts can act on. It opens the database itself so an
+// unusable one is reported as a check.
+func runHealthCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	sample := fs.Int("feeds", 3, "number of random enabled feeds to fetch")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var report healthReport
+	add := func(name string, critical bool, detail string, err error) {
+		check := healthCheck{Name: name, Status: "ok", Detail: detail, Critical: critical}
+		if err != nil {
+			check.Status, check.Detail = "fail", err.Error()
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	skip := func(name string, critical bool, reason string) {
+		report.Checks = append(report.Checks, healthCheck{Name: name, Status: "skip", Detail: reason, Critical: critical})
+	}
+
+	db, err := openDatabase(cfg)
+	if err == nil {
+		defer db.Close()
+		var stats *database.Stats
+		if stats, err = db.GetStats(); err == nil {
+			add("database", true, fmt.Sprintf("%d articles in %d feeds, %s", stats.Articles, stats.Feeds, formatBytes(stats.SizeBytes)), nil)
+		}
+	}
+	if err != nil {
+		add("database", true, "", err)
+		db = nil
+	}
+
+	aiClient := ai.NewClient(cfg.Ollama.Host, cfg.Ollama.Model, nil)
+	if err := aiClient.Ping(healthTimeout); err != nil {
+		add("ollama", true, "", fmt.Errorf("%s: %w", cfg.Ollama.Host, err))
+		skip("model", true, "Ollama is unreachable")
+	} else {
+		add("ollama", true, "reachable at "+cfg.Ollama.Host, nil)
+		found, err := aiClient.HasModel(healthTimeout)
+		if err == nil && !found {
+			err = fmt.Errorf("%s is not installed (run ollama pull %s)", cfg.Ollama.Model, cfg.Ollama.Model)
+		}
+		add("model", true, cfg.Ollama.Model, err)
+	}
+
+	if cfg.Raindrop.APIToken == "" {
+		skip("raindrop", false, "no raindrop.api_token")
+	} else {
+		add("raindrop", false, "token accepted", raindrop.NewClient(cfg.Raindrop.APIToken).TestConnection())
+	}
+
+	switch feeds, err := enabledFeeds(db); {
+	case err != nil:
+		add("feeds", false, "", err)
+	case len(feeds) == 0:
+		skip("feeds", false, "no enabled feeds")
+	default:
+		rand.Shuffle(len(feeds), func(i, j int) { feeds[i], feeds[j] = feeds[j], feeds[i] })
+		report.Checks = append(report.Checks, checkFeeds(cfg, db, feeds[:min(*sample, len(feeds))])...)
+	}
+
+	report.Status = "healthy"
+	for _, check := range report.Checks {
+		if check.Status != "fail" {
+			continue
+		}
+		if check.Critical {
+			report.Status = "unhealthy"
+			break
+		}
+		report.Status = "degraded"
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, check := range report.Checks {
+			status := check.Status
+			if status == "fail" {
+				status = "FAIL"
+			}
+			fmt.Printf("%-5s %-24s %s\n", status, check.Name, check.Detail)
+		}
+		fmt.Printf("Status: %s\n", report.Status)
+	}
+
+	switch report.Status {
+	case "unhealthy":
+		return exitCodeError{exitUnhealthy}
+	case "degraded":
+		return exitCodeError{exitDegraded}
+	}
+	return nil
+}
+
+// enabledFeeds lists the web feeds to sample, none when the database is
+// unusable
+func enabledFeeds(db *database.DB) ([]models.Feed, error) {
+	if db == nil {
+		return nil, nil
+	}
+	feeds, err := db.GetEnabledFeeds()
+	if err != nil {
+		return nil, err
+	}
+	var web []models.Feed
+	for _, f := range feeds {
+		if !feed.IsNewsletter(f.URL) {
+			web = append(web, f)
+		}
+	}
+	return web, nil
+}
+
+// checkFeeds fetches feeds concurrently without storing anything, giving up
+// on each after healthTimeout
+func checkFeeds(cfg *config.Config, db *database.DB, feeds []models.Feed) []healthCheck {
+	fetcher := feed.NewFetcher(db, cfg)
+	checks := make([]healthCheck, len(feeds))
+	var wg sync.WaitGroup
+	for i, f := range feeds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks[i] = healthCheck{Name: "feed " + f.Name, Status: "ok"}
+			type outcome struct {
+				items int
+				err   error
+			}
+			done := make(chan outcome, 1)
+			go func() {
+				parsed, _, err := fetcher.FetchFeed(f.URL, cfg.FeedSettings(f.URL, f.Name))
+				if err != nil {
+					done <- outcome{err: err}
+					return
+				}
+				done <- outcome{items: len(parsed.Items)}
+			}()
+			select {
+			case o := <-done:
+				if o.err != nil {
+					checks[i].Status, checks[i].Detail = "fail", o.err.Error()
+				} else {
+					checks[i].Detail = fmt.Sprintf("%d items", o.items)
+				}
+			case <-time.After(healthTimeout):
+				checks[i].Status, checks[i].Detail = "fail", fmt.Sprintf("no response within %s", healthTimeout)
+			}
+		}()
+	}
+	wg.Wait()
+	return checks
+}
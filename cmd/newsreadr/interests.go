@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+)
+
+// runInterestsCommand handles the "interests" subcommands
+func runInterestsCommand(cfg *config.Config, db *database.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: newsreadr interests list|refresh")
+	}
+
+	switch args[0] {
+	case "list":
+		interests, err := db.GetInterests()
+		if err != nil {
+			return err
+		}
+		if len(interests) == 0 {
+			fmt.Println("No interests configured")
+			return nil
+		}
+		for _, i := range interests {
+			section := i.Group
+			if i.Avoid {
+				section = "avoid"
+			}
+			cached := " "
+			if len(i.Embedding) > 0 {
+				cached = "*"
+			}
+			fmt.Printf("%s %4.1f  %-12s %s\n", cached, i.Weight, section, i.Description)
+		}
+		fmt.Println("\n* embedding cached")
+		return nil
+
+	case "refresh":
+		// The reader or daemon holding the lock writes the embeddings too
+		lock, err := database.AcquireLock(cfg.Database.Path)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+
+		aiClient := ai.NewClient(cfg.Ollama.Host, cfg.Ollama.Model, db)
+		n, err := aiClient.RefreshInterestEmbeddings()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Refreshed embeddings for %d interests using %s\n", n, cfg.Ollama.Model)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown interests command %q", args[0])
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/export"
+	"github.com/thomaskoefod/newsreadr/internal/hooks"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// runListCommand prints the unread articles for scripts, or marks the
+// articles picked from them read
+func runListCommand(cfg *config.Config, db *database.DB, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	format := fs.String("format", "tsv", "output format: tsv or json")
+	minScore := fs.Float64("min-score", 0, "only list articles scoring at least this")
+	markRead := fs.String("mark-read", "", "mark the articles with these comma-separated IDs read instead of listing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("usage: newsreadr list [-format tsv|json] [-min-score 0.6] [-mark-read ID,...]")
+	}
+
+	if *markRead != "" {
+		return markArticlesRead(cfg, db, *markRead)
+	}
+
+	maxAge := time.Duration(cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
+	articles, err := db.GetUnreadArticleList(maxAge)
+	if err != nil {
+		return err
+	}
+	if *minScore > 0 {
+		var kept []models.Article
+		for _, a := range articles {
+			if a.Scored && a.RelevanceScore >= *minScore {
+				kept = append(kept, a)
+			}
+		}
+		articles = kept
+	}
+	return export.WriteArticles(os.Stdout, *format, articles)
+}
+
+// markArticlesRead marks unread articles read as the reader does, moving
+// them to the trash and running the on_article_read hooks
+func markArticlesRead(cfg *config.Config, db *database.DB, ids string) error {
+	// The reader holding the lock marks and trashes articles too
+	lock, err := database.AcquireLock(cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	maxAge := time.Duration(cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
+	unread, err := db.GetUnreadArticles(maxAge)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]models.Article, len(unread))
+	for _, a := range unread {
+		byID[a.ID] = a
+	}
+
+	runner := hooks.New(cfg.Hooks)
+	var errs []error
+	for _, field := range strings.FieldsFunc(ids, func(r rune) bool { return r == ',' || r == ' ' }) {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid article ID %q", field)
+		}
+		article, ok := byID[id]
+		if !ok {
+			errs = append(errs, fmt.Errorf("no unread article %d", id))
+			continue
+		}
+		if err := db.MarkArticleRead(id); err != nil {
+			return err
+		}
+		delete(byID, id)
+		if err := runner.Fire(hooks.ArticleRead, &article); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := db.DeleteReadArticles(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
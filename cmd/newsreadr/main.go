@@ -0,0 +1,492 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/bookmark"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/demo"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/internal/notify"
+	"github.com/thomaskoefod/newsreadr/internal/raindrop"
+	"github.com/thomaskoefod/newsreadr/internal/scoring"
+	"github.com/thomaskoefod/newsreadr/internal/tui"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// shutdownTimeout bounds how long quitting waits for background jobs
+const shutdownTimeout = 5 * time.Second
+
+func main() {
+	configPath := flag.String("config", config.DefaultConfigPath(), "path to config file")
+	demoMode := flag.Bool("demo", false, "start the reader on a temporary database with sample articles")
+	plain := flag.Bool("plain", false, "start the reader in plain text, for screen readers: no colors, box drawing or full-screen display")
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := run(*configPath, *demoMode, *plain, flag.Args()); err != nil {
+		var exit exitCodeError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// exitCodeError ends the program with a specific exit code once a command
+// has already reported why
+type exitCodeError struct {
+	code int
+}
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: newsreadr [flags] [command]
+
+Commands:
+  (none)       Start the interactive reader
+  auth set|delete NAME
+               Store a token or password in the system keyring (read from
+               the terminal or stdin), to reference as keyring:NAME
+  daemon [-interval 15m]
+               Fetch and score in the background without the reader,
+               serving metrics on metrics.listen
+  db stats     Show article, feed and read counts and database size
+  db vacuum    Reclaim free space and refresh query statistics
+  db encrypt   Encrypt the stored articles with database.encryption_key
+  doctor       Check the configuration and the Ollama connection
+  entities extract
+               Tag stored articles not looked at yet with the people,
+               companies and projects they name, using Ollama
+  entities list
+               List the entities named by unread articles, most named first
+  health [-json] [-feeds 3]
+               Check the database, Ollama and its model, the Raindrop token
+               and a sample of feeds; exits 2 when the database or Ollama
+               fails, 3 when only Raindrop or feeds fail
+  export read|starred [-format json|csv] [-o file]
+               Export read history or starred articles
+  interests list
+               List interests and whether their embeddings are cached
+  interests refresh
+               Regenerate all interest embeddings (e.g. after changing model)
+  list [-format tsv|json] [-min-score 0.6] [-mark-read ID,...]
+               Print the unread articles for scripts, or mark the ones
+               with these IDs read
+  raindrop export starred|read [-tag name] [-collection id] [-all] [-dry-run]
+               Bookmark starred or read articles in Raindrop, tagged with
+               their feed, skipping ones exported before
+  rescore [-all]
+               Score unread articles again after changing interests
+               (-all: every stored article, including read ones)
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func run(configPath string, demoMode, plain bool, args []string) error {
+	if (demoMode || plain) && len(args) > 0 {
+		return errors.New("-demo and -plain only apply to the reader")
+	}
+	if demoMode {
+		return runReader(demo.Config(), true, plain)
+	}
+
+	moved, err := config.MigrateLegacyLayout(configPath)
+	for _, m := range moved {
+		fmt.Printf("Moved %s\n", m)
+	}
+	if err != nil {
+		return fmt.Errorf("migrating to XDG directories: %w", err)
+	}
+
+	// doctor reports on the config file itself, so it must run before
+	// loading it fails
+	if len(args) > 0 && args[0] == "doctor" {
+		return runDoctorCommand(configPath)
+	}
+
+	// auth stores secrets the config may not load without
+	if len(args) > 0 && args[0] == "auth" {
+		return runAuthCommand(args[1:])
+	}
+
+	// The setup wizard is full screen, so -plain starts from the defaults
+	cfg, err := loadOrCreateConfig(configPath, len(args) == 0 && !plain)
+	if err != nil {
+		return err
+	}
+
+	// health reports on the database too, so it opens it itself
+	if len(args) > 0 && args[0] == "health" {
+		return runHealthCommand(cfg, args[1:])
+	}
+
+	// db encrypt opens the database before it can be unlocked, so it opens
+	// it itself
+	if len(args) > 1 && args[0] == "db" && args[1] == "encrypt" {
+		return runEncryptCommand(cfg)
+	}
+
+	if len(args) > 0 {
+		db, err := openDatabase(cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return runCommand(cfg, db, args)
+	}
+	return runReader(cfg, false, plain)
+}
+
+// openDatabase opens the configured database, unlocking its encrypted
+// articles with database.encryption_key
+func openDatabase(cfg *config.Config) (*database.DB, error) {
+	db, err := database.New(cfg.Database.Path, cfg.Database.CachePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Unlock(cfg.Database.EncryptionKey); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// runReader starts the interactive reader, on the demo articles when
+// demoMode is set, and as plain text for screen readers when plain is set
+func runReader(cfg *config.Config, demoMode, plain bool) error {
+	// Without the lock another instance is writing, so this one only reads
+	// and leaves the config sync, demo data and session to it
+	lock, lockErr := database.AcquireLock(cfg.Database.Path)
+	if lockErr != nil && !errors.Is(lockErr, database.ErrLocked) {
+		return lockErr
+	}
+	defer lock.Release()
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if lockErr == nil {
+		if err := seedFromConfig(cfg, db); err != nil {
+			return err
+		}
+		if demoMode {
+			if err := demo.Seed(db); err != nil {
+				return err
+			}
+		}
+	}
+
+	fetcher := feed.NewFetcher(db, cfg)
+	aiClient, err := newAIClient(cfg, db)
+	if err != nil {
+		return err
+	}
+	saveTargets, err := bookmark.Targets(cfg, raindrop.NewClient(cfg.Raindrop.APIToken))
+	if err != nil {
+		return err
+	}
+	// Escape sequences would be read out in plain mode
+	terminal := io.Writer(os.Stdout)
+	if plain {
+		terminal = nil
+	}
+	notifier, err := notify.NewDispatcher(cfg, terminal)
+	if err != nil {
+		return err
+	}
+
+	if plain {
+		reader := tui.NewPlain(cfg, db, fetcher, aiClient, notifier)
+		if lockErr != nil {
+			reader.ReadOnly(lockErr)
+		}
+		return reader.Run(os.Stdin, os.Stdout)
+	}
+
+	model := tui.New(cfg, db, fetcher, aiClient, saveTargets, notifier)
+	if lockErr != nil {
+		model = model.ReadOnly(lockErr)
+	}
+
+	p := tea.NewProgram(model)
+	model.Attach(p)
+	_, err = p.Run()
+
+	// Abort the fetches and scoring still running and let them write what
+	// they got before the database is closed
+	fetcher.Shutdown()
+	aiClient.Shutdown()
+	if !model.Wait(shutdownTimeout) {
+		fmt.Fprintln(os.Stderr, "Warning: quit before background jobs finished")
+	}
+	// The next session tells what arrived since this one
+	if lockErr == nil {
+		if recordErr := db.RecordSession(); recordErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", recordErr)
+		}
+	}
+	if errors.Is(err, tea.ErrProgramPanic) || model.Crashed() {
+		// Bubble Tea restored the terminal and printed the panic, or it
+		// happened after quitting
+		if path, reportErr := model.WriteCrashReport(); reportErr == nil {
+			fmt.Fprintf(os.Stderr, "newsreadr crashed. A report was written to %s\n", path)
+		} else {
+			fmt.Fprintf(os.Stderr, "newsreadr crashed and the report could not be written: %v\n", reportErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("running TUI: %w", err)
+	}
+	return nil
+}
+
+// newAIClient creates the Ollama client with the configured scoring setup
+func newAIClient(cfg *config.Config, db *database.DB) (*ai.Client, error) {
+	aiClient := ai.NewClient(cfg.Ollama.Host, cfg.Ollama.Model, db)
+	aiClient.SetScoring(cfg.Scoring)
+	aiClient.SetWorkers(cfg.Ollama.Workers)
+	if cfg.Scoring.Backend == "script" {
+		script, err := scoring.LoadScript(cfg)
+		if err != nil {
+			return nil, err
+		}
+		aiClient.SetAdjuster(script)
+	}
+	return aiClient, nil
+}
+
+// runCommand dispatches a non-interactive subcommand
+func runCommand(cfg *config.Config, db *database.DB, args []string) error {
+	switch args[0] {
+	case "daemon":
+		return runDaemonCommand(cfg, db, args[1:])
+	case "db":
+		return runDBCommand(cfg, db, args[1:])
+	case "entities":
+		return runEntitiesCommand(cfg, db, args[1:])
+	case "export":
+		return runExportCommand(db, args[1:])
+	case "list":
+		return runListCommand(cfg, db, args[1:])
+	case "interests":
+		return runInterestsCommand(cfg, db, args[1:])
+	case "raindrop":
+		return runRaindropCommand(cfg, db, args[1:])
+	case "rescore":
+		return runRescoreCommand(cfg, db, args[1:])
+	default:
+		usage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// loadOrCreateConfig loads the config file. On first run it is created by
+// the setup wizard, or from the defaults when running a subcommand.
+func loadOrCreateConfig(path string, interactive bool) (*config.Config, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		cfg := config.Default()
+		if interactive {
+			done, err := tui.RunSetup(cfg)
+			if err != nil {
+				return nil, err
+			}
+			if !done {
+				return nil, errors.New("setup cancelled, no configuration written")
+			}
+		}
+		if err := config.Save(cfg, path); err != nil {
+			return nil, err
+		}
+		if interactive {
+			fmt.Printf("Created configuration at %s\n", path)
+		} else {
+			fmt.Printf("Created default configuration at %s\n", path)
+		}
+	}
+	return config.Load(path)
+}
+
+// seedFromConfig syncs feeds and interests with the config
+func seedFromConfig(cfg *config.Config, db *database.DB) error {
+	if err := syncFeeds(cfg, db); err != nil {
+		return err
+	}
+	return syncInterests(cfg, db)
+}
+
+// syncFeeds reconciles the feeds in the database with the config according
+// to feeds_manage_via: "both" adds config feeds missing from the database,
+// "config" also removes database feeds no longer in the config and takes
+// names from it, and "db" leaves the database alone once it has feeds.
+// Feeds are matched by URL, or by name when the URL was changed in the
+// database after a redirect.
+func syncFeeds(cfg *config.Config, db *database.DB) error {
+	feeds, err := db.GetFeeds()
+	if err != nil {
+		return err
+	}
+	if cfg.FeedsManageVia == "db" && len(feeds) > 0 {
+		return nil
+	}
+
+	knownURLs := make(map[string]bool, len(feeds))
+	knownNames := make(map[string]bool, len(feeds))
+	for _, f := range feeds {
+		knownURLs[f.URL] = true
+		knownNames[f.Name] = true
+	}
+	for _, fc := range cfg.Feeds {
+		if knownURLs[fc.URL] || (fc.Name != "" && knownNames[fc.Name]) {
+			continue
+		}
+		if err := db.AddFeed(&models.Feed{URL: fc.URL, Name: fc.Name, Enabled: true}); err != nil {
+			return err
+		}
+	}
+
+	if cfg.FeedsManageVia != "config" {
+		return nil
+	}
+	removed := 0
+	for _, f := range feeds {
+		// Newsletter senders come from the mailbox, not the config
+		if feed.IsNewsletter(f.URL) {
+			continue
+		}
+		fc := cfg.FeedSettings(f.URL, f.Name)
+		if fc == nil {
+			if err := db.DeleteFeed(f.ID); err != nil {
+				return err
+			}
+			removed++
+			continue
+		}
+		if fc.Name != "" && fc.Name != f.Name {
+			f.Name = fc.Name
+			if err := db.UpdateFeed(&f); err != nil {
+				return err
+			}
+		}
+	}
+	if removed > 0 {
+		fmt.Printf("Removed %d feeds that are no longer in the config\n", removed)
+	}
+	return nil
+}
+
+// configInterests lists the interests of the config as the database stores them
+func configInterests(cfg *config.Config) []models.UserInterest {
+	var interests []models.UserInterest
+	add := func(ic config.InterestConfig, group string, avoid bool) {
+		weight := ic.Weight
+		if weight == 0 {
+			weight = 1.0
+		}
+		interests = append(interests, models.UserInterest{Description: ic.Description, Weight: weight, Group: group, Avoid: avoid})
+	}
+	for _, ic := range cfg.Interests {
+		add(ic, "", false)
+	}
+	for _, ic := range cfg.AvoidInterests {
+		add(ic, "", true)
+	}
+	for group, ics := range cfg.InterestGroups {
+		for _, ic := range ics {
+			add(ic, group, false)
+		}
+	}
+	return interests
+}
+
+// syncInterests reconciles the interests in the database with the config
+// according to interests_manage_via, like syncFeeds. Interests removed from
+// the config are archived rather than deleted, keeping their cached
+// embedding, and restored when they are added back.
+func syncInterests(cfg *config.Config, db *database.DB) error {
+	active, err := db.GetInterests()
+	if err != nil {
+		return err
+	}
+	if cfg.InterestsManageVia == "db" && len(active) > 0 {
+		return nil
+	}
+	archived, err := db.GetArchivedInterests()
+	if err != nil {
+		return err
+	}
+
+	byDescription := make(map[string]models.UserInterest, len(active))
+	for _, i := range active {
+		byDescription[i.Description] = i
+	}
+	archivedByDescription := make(map[string]models.UserInterest, len(archived))
+	for _, i := range archived {
+		archivedByDescription[i.Description] = i
+	}
+
+	manageViaConfig := cfg.InterestsManageVia == "config"
+	inConfig := make(map[string]bool)
+	for _, want := range configInterests(cfg) {
+		inConfig[want.Description] = true
+		if have, ok := byDescription[want.Description]; ok {
+			if manageViaConfig && (have.Weight != want.Weight || have.Group != want.Group || have.Avoid != want.Avoid) {
+				want.ID = have.ID
+				if err := db.UpdateInterest(&want); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if old, ok := archivedByDescription[want.Description]; ok {
+			want.ID = old.ID
+			if err := db.RestoreInterest(old.ID); err != nil {
+				return err
+			}
+			if err := db.UpdateInterest(&want); err != nil {
+				return err
+			}
+			byDescription[want.Description] = want
+			continue
+		}
+		if err := db.AddInterest(&want); err != nil {
+			return err
+		}
+		byDescription[want.Description] = want
+	}
+
+	if !manageViaConfig {
+		return nil
+	}
+	removed := 0
+	for _, i := range active {
+		if inConfig[i.Description] {
+			continue
+		}
+		if err := db.ArchiveInterest(i.ID); err != nil {
+			return err
+		}
+		removed++
+	}
+	if removed > 0 {
+		fmt.Printf("Archived %d interests that are no longer in the config\n", removed)
+	}
+	return nil
+}
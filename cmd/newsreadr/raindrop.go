@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/raindrop"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// runRaindropCommand handles the "raindrop" subcommands
+func runRaindropCommand(cfg *config.Config, db *database.DB, args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		return fmt.Errorf("usage: newsreadr raindrop export starred|read [-tag name] [-collection id] [-all] [-dry-run]")
+	}
+	return runRaindropExport(cfg, db, args[1:])
+}
+
+// runRaindropExport bookmarks starred or read articles in Raindrop, tagged
+// with their feed. Articles exported before are skipped unless -all is set,
+// so it can run periodically to archive new ones.
+func runRaindropExport(cfg *config.Config, db *database.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: newsreadr raindrop export starred|read [-tag name] [-collection id] [-all] [-dry-run]")
+	}
+
+	fs := flag.NewFlagSet("raindrop export", flag.ContinueOnError)
+	var tags stringList
+	fs.Var(&tags, "tag", "extra tag for every bookmark (repeatable)")
+	collection := fs.Int64("collection", 0, "Raindrop collection ID (default: Unsorted)")
+	all := fs.Bool("all", false, "also export articles exported before")
+	dryRun := fs.Bool("dry-run", false, "list what would be exported without saving")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var entries []models.HistoryEntry
+	var err error
+	switch args[0] {
+	case "read":
+		entries, err = db.GetReadHistory()
+	case "starred":
+		entries, err = db.GetStarredArticles()
+	default:
+		return fmt.Errorf("unknown export source %q", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	exported := map[string]bool{}
+	if !*all {
+		if exported, err = db.GetRaindropExports(); err != nil {
+			return err
+		}
+	}
+
+	var items []raindrop.RaindropItem
+	for _, e := range entries {
+		// The read history has an entry per read, so skip repeats too
+		if exported[e.URL] {
+			continue
+		}
+		exported[e.URL] = true
+
+		item := raindrop.RaindropItem{Link: e.URL, Title: e.Title, Tags: append([]string(nil), tags...)}
+		if e.FeedName != "" {
+			item.Tags = append(item.Tags, e.FeedName)
+		}
+		if *collection != 0 {
+			item.Collection = &raindrop.Collection{ID: *collection}
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("Nothing new to export")
+		return nil
+	}
+	if *dryRun {
+		for _, item := range items {
+			fmt.Printf("%s [%s]\n", item.Link, strings.Join(item.Tags, ", "))
+		}
+		fmt.Printf("Would export %d articles\n", len(items))
+		return nil
+	}
+	if cfg.Raindrop.APIToken == "" {
+		return fmt.Errorf("raindrop.api_token is not set")
+	}
+
+	// The exports are recorded in the database, which only the lock
+	// holder writes
+	lock, err := database.AcquireLock(cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	client := raindrop.NewClient(cfg.Raindrop.APIToken)
+	n, err := client.SaveItems(items, func(saved []raindrop.RaindropItem) error {
+		urls := make([]string, len(saved))
+		for i, item := range saved {
+			urls[i] = item.Link
+		}
+		return db.MarkRaindropExported(urls)
+	})
+	fmt.Printf("Exported %d/%d articles to Raindrop\n", n, len(items))
+	return err
+}
+
+// stringList collects the values of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+)
+
+// runRescoreCommand scores stored articles again against the current interests
+func runRescoreCommand(cfg *config.Config, db *database.DB, args []string) error {
+	fs := flag.NewFlagSet("rescore", flag.ContinueOnError)
+	all := fs.Bool("all", false, "rescore every stored article, not just unread ones")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Scores written by a concurrent fetch would be overwritten
+	lock, err := database.AcquireLock(cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	aiClient, err := newAIClient(cfg, db)
+	if err != nil {
+		return err
+	}
+	n, err := aiClient.Rescore(*all, cfg.UI.ArticleMaxAgeDays, func(_ map[int64]float64, scored, total int, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: %v\n", err)
+		}
+		fmt.Printf("Rescored %d/%d articles\r", scored, total)
+	})
+	if n > 0 {
+		fmt.Println()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Rescored %d articles using %s\n", n, cfg.Ollama.Model)
+	return nil
+}
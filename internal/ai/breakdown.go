@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// InterestMatch is how similar an article is to one interest
+type InterestMatch struct {
+	Interest   models.UserInterest
+	Similarity float64
+}
+
+// ScoreBreakdown explains an article's AI score: the weighted average
+// similarity of each group, the best of which is reduced by the strongest
+// match among interests to avoid
+type ScoreBreakdown struct {
+	Matches []InterestMatch // most similar first
+	Groups  map[string]float64
+	Penalty float64
+	Score   float64
+	// Skipped says which interests were left out because they could not
+	// be embedded, nil when none were
+	Skipped error
+}
+
+// ExplainScore scores an article against the current interests and
+// returns how the score came about. Embeddings come from the cache when
+// the article was scored before, so this rarely calls Ollama.
+func (c *Client) ExplainScore(article *models.Article) (*ScoreBreakdown, error) {
+	interests, err := c.db.GetInterests()
+	if err != nil {
+		return nil, fmt.Errorf("getting interests: %w", err)
+	}
+	if len(interests) == 0 {
+		return nil, ErrNoInterests
+	}
+	return c.scoreBreakdown(article, interests)
+}
+
+func (c *Client) scoreBreakdown(article *models.Article, interests []models.UserInterest) (*ScoreBreakdown, error) {
+	articleEmbs, err := c.articleEmbeddings(article)
+	if err != nil {
+		return nil, err
+	}
+
+	// Weighted average similarity within each group; the best group wins.
+	// The strongest match among interests to avoid is then subtracted.
+	type groupScore struct{ score, weight float64 }
+	groups := make(map[string]*groupScore)
+	b := &ScoreBreakdown{Groups: make(map[string]float64)}
+
+	for i := range interests {
+		// Index into the slice so generated embeddings stay cached on it
+		interest := &interests[i]
+		interestEmb, err := c.interestEmbedding(interest)
+		if err != nil {
+			b.Skipped = errors.Join(b.Skipped, fmt.Errorf("embedding interest %q: %w", interest.Description, err))
+			continue
+		}
+
+		similarity := c.similarity(articleEmbs, interestEmb)
+		b.Matches = append(b.Matches, InterestMatch{Interest: *interest, Similarity: similarity})
+		if interest.Avoid {
+			b.Penalty = math.Max(b.Penalty, similarity*interest.Weight)
+			continue
+		}
+
+		g := groups[interest.Group]
+		if g == nil {
+			g = &groupScore{}
+			groups[interest.Group] = g
+		}
+		g.score += similarity * interest.Weight
+		g.weight += interest.Weight
+	}
+
+	best := 0.0
+	for name, g := range groups {
+		if g.weight == 0 {
+			continue
+		}
+		avg := g.score / g.weight
+		b.Groups[name] = avg
+		if avg > best {
+			best = avg
+		}
+	}
+	sort.SliceStable(b.Matches, func(i, j int) bool {
+		return b.Matches[i].Similarity > b.Matches[j].Similarity
+	})
+	b.Score = math.Min(math.Max(best-b.Penalty, 0), 1)
+	return b, nil
+}
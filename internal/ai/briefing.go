@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// briefingWords caps how much of each article's summary is sent for a
+// briefing, keeping the prompt short with many articles
+const briefingWords = 120
+
+// Briefing asks the model to narrate the articles' summaries as a single
+// morning briefing, streaming it token by token. The briefing refers to the
+// articles by their number in square brackets, [1] being the first.
+func (c *Client) Briefing(articles []models.Article, onToken func(string)) (string, error) {
+	var b strings.Builder
+	b.WriteString("Write a short morning briefing from the news articles below, as a narrator would read it aloud. " +
+		"Start with \"Here's what's happening in your topics\", group related stories and keep to a few paragraphs. " +
+		"After each story, cite the articles it comes from by their number in square brackets, e.g. [2]. " +
+		"Reply with the briefing only.\n")
+	for i, article := range articles {
+		text := article.Description
+		if text == "" {
+			text = article.Content
+		}
+		words := strings.Fields(plainText(text))
+		if len(words) > briefingWords {
+			words = words[:briefingWords]
+		}
+		fmt.Fprintf(&b, "\n[%d] %s (%s)\n%s\n", i+1, article.Title, article.FeedName, strings.Join(words, " "))
+	}
+
+	briefing, err := c.GenerateStream(b.String(), onToken)
+	if err != nil {
+		return "", fmt.Errorf("writing briefing: %w", err)
+	}
+	return strings.TrimSpace(briefing), nil
+}
@@ -0,0 +1,31 @@
+package ai
+
+// ChatMessage is one turn of a conversation with the model
+type ChatMessage struct {
+	Role    string `json:"role"` // "system", "user" or "assistant"
+	Content string `json:"content"`
+}
+
+type ChatRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+type ChatResponse struct {
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Chat sends a conversation to Ollama's chat endpoint, streaming the reply.
+// onToken is called with each piece of the reply as it arrives; the full
+// reply is returned once the model is done.
+func (c *Client) Chat(messages []ChatMessage, onToken func(string)) (string, error) {
+	reqBody := ChatRequest{
+		Model:    c.model,
+		Messages: messages,
+		Stream:   true,
+	}
+	return c.stream("/api/chat", reqBody, func() streamChunk { return &ChatResponse{} }, onToken)
+}
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+	"golang.org/x/net/html"
+)
+
+// articleEmbeddings embeds the title and description, storing that
+// embedding for similarity search, and with full content scoring enabled
+// each chunk of the article body
+func (c *Client) articleEmbeddings(article *models.Article) ([][]float64, error) {
+	summaryEmb, err := c.GetEmbedding(summaryText(article))
+	if err != nil {
+		return nil, fmt.Errorf("getting article embedding: %w", err)
+	}
+	if err := c.saveArticleEmbedding(article.ID, summaryEmb); err != nil {
+		return nil, err
+	}
+	embs := [][]float64{summaryEmb}
+
+	if !c.scoring.FullContent {
+		return embs, nil
+	}
+
+	for _, chunk := range chunkWords(plainText(article.Content), c.scoring.ChunkWords, c.scoring.MaxChunks) {
+		emb, err := c.GetEmbedding(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("getting content chunk embedding: %w", err)
+		}
+		embs = append(embs, emb)
+	}
+	return embs, nil
+}
+
+// similarity combines the similarity of each article embedding to an
+// interest using the configured aggregate, max by default
+func (c *Client) similarity(articleEmbs [][]float64, interestEmb []float64) float64 {
+	var best, total float64
+	for i, emb := range articleEmbs {
+		sim := CosineSimilarity(emb, interestEmb)
+		if i == 0 || sim > best {
+			best = sim
+		}
+		total += sim
+	}
+	if c.scoring.Aggregate == "mean" && len(articleEmbs) > 0 {
+		return total / float64(len(articleEmbs))
+	}
+	return best
+}
+
+// plainText strips HTML markup from article content, keeping text nodes
+// separated so words from adjacent elements don't run together
+func plainText(content string) string {
+	var s strings.Builder
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return s.String()
+		case html.TextToken:
+			s.Write(z.Text())
+			s.WriteByte(' ')
+		}
+	}
+}
+
+// chunkWords splits text into at most maxChunks chunks of size words each
+func chunkWords(text string, size, maxChunks int) []string {
+	words := strings.Fields(text)
+	var chunks []string
+	for start := 0; start < len(words) && len(chunks) < maxChunks; start += size {
+		end := min(start+size, len(words))
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+	}
+	return chunks
+}
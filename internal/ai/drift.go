@@ -0,0 +1,192 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// driftClusterThreshold is the similarity to a cluster centroid needed to join it
+	driftClusterThreshold = 0.8
+	// driftMinTopicSize is the number of read articles needed to report a topic
+	driftMinTopicSize = 3
+	// driftLabelTitles caps how many headlines are sent when labelling a topic
+	driftLabelTitles = 10
+)
+
+// DriftTopic is a cluster of read articles poorly covered by declared interests
+type DriftTopic struct {
+	Label      string
+	Titles     []string
+	Similarity float64 // best similarity of the cluster to any declared interest
+}
+
+// DriftReport compares what was actually read against the declared interests
+type DriftReport struct {
+	Sampled            int
+	ClosestInterest    string
+	CentroidSimilarity float64
+	Topics             []DriftTopic
+}
+
+type driftCluster struct {
+	centroid []float64
+	members  [][]float64
+	titles   []string
+}
+
+// AnalyzeDrift embeds the last limit read articles, compares their centroid
+// with the declared interests and groups poorly covered reads into topics
+func (c *Client) AnalyzeDrift(limit int) (*DriftReport, error) {
+	history, err := c.db.GetRecentReadHistory(limit)
+	if err != nil {
+		return nil, fmt.Errorf("getting read history: %w", err)
+	}
+	interests, err := c.db.GetInterests()
+	if err != nil {
+		return nil, fmt.Errorf("getting interests: %w", err)
+	}
+
+	report := &DriftReport{}
+	if len(history) == 0 {
+		return report, nil
+	}
+
+	var interestEmbs [][]float64
+	var interestNames []string
+	for i := range interests {
+		emb, err := c.interestEmbedding(&interests[i])
+		if err != nil {
+			return nil, fmt.Errorf("getting interest embedding: %w", err)
+		}
+		interestEmbs = append(interestEmbs, emb)
+		interestNames = append(interestNames, interests[i].Description)
+	}
+
+	// Articles opened in the browser were clicked through rather than
+	// skimmed, so they count double towards what is actually read
+	var embs, weighted [][]float64
+	var titles []string
+	for _, entry := range history {
+		emb, err := c.GetEmbedding(entry.Title)
+		if err != nil {
+			return nil, fmt.Errorf("getting article embedding: %w", err)
+		}
+		embs = append(embs, emb)
+		titles = append(titles, entry.Title)
+		weighted = append(weighted, emb)
+		if entry.OpenedAt != nil {
+			weighted = append(weighted, emb)
+		}
+	}
+	report.Sampled = len(embs)
+
+	idx, sim := bestMatch(centroid(weighted), interestEmbs)
+	if idx >= 0 {
+		report.ClosestInterest = interestNames[idx]
+		report.CentroidSimilarity = sim
+	}
+
+	// Reads whose best interest match is below average are considered
+	// uncovered and grouped greedily into clusters
+	coverage := make([]float64, len(embs))
+	var mean float64
+	for i, emb := range embs {
+		_, coverage[i] = bestMatch(emb, interestEmbs)
+		mean += coverage[i]
+	}
+	mean /= float64(len(embs))
+
+	var clusters []*driftCluster
+	for i, emb := range embs {
+		if len(interestEmbs) > 0 && coverage[i] >= mean {
+			continue
+		}
+
+		var target *driftCluster
+		for _, cl := range clusters {
+			if CosineSimilarity(emb, cl.centroid) >= driftClusterThreshold {
+				target = cl
+				break
+			}
+		}
+		if target == nil {
+			target = &driftCluster{}
+			clusters = append(clusters, target)
+		}
+		target.members = append(target.members, emb)
+		target.titles = append(target.titles, titles[i])
+		target.centroid = centroid(target.members)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].members) > len(clusters[j].members)
+	})
+
+	for _, cl := range clusters {
+		if len(cl.members) < driftMinTopicSize {
+			continue
+		}
+		label, err := c.labelTopic(cl.titles)
+		if err != nil {
+			return nil, fmt.Errorf("labelling topic: %w", err)
+		}
+		_, sim := bestMatch(cl.centroid, interestEmbs)
+		report.Topics = append(report.Topics, DriftTopic{
+			Label:      label,
+			Titles:     cl.titles,
+			Similarity: sim,
+		})
+	}
+
+	return report, nil
+}
+
+// labelTopic asks the model for a short interest description covering the headlines
+func (c *Client) labelTopic(titles []string) (string, error) {
+	if len(titles) > driftLabelTitles {
+		titles = titles[:driftLabelTitles]
+	}
+
+	prompt := "Describe the common topic of these news headlines as a short interest " +
+		"description of at most eight words. Reply with the description only.\n\n- " +
+		strings.Join(titles, "\n- ")
+
+	label, err := c.Generate(prompt)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(strings.TrimSpace(label), "\"'."), nil
+}
+
+// centroid returns the element-wise mean of the vectors
+func centroid(vectors [][]float64) []float64 {
+	if len(vectors) == 0 {
+		return nil
+	}
+	sum := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		for i := range sum {
+			if i < len(v) {
+				sum[i] += v[i]
+			}
+		}
+	}
+	for i := range sum {
+		sum[i] /= float64(len(vectors))
+	}
+	return sum
+}
+
+// bestMatch returns the index and similarity of the candidate closest to v,
+// or -1 when there are no candidates
+func bestMatch(v []float64, candidates [][]float64) (int, float64) {
+	best, bestSim := -1, 0.0
+	for i, cand := range candidates {
+		if sim := CosineSimilarity(v, cand); best < 0 || sim > bestSim {
+			best, bestSim = i, sim
+		}
+	}
+	return best, bestSim
+}
@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// entityWords caps how much of the article is sent when extracting entities
+const entityWords = 600
+
+// entityKinds maps the kinds the model may answer with to the stored ones
+var entityKinds = map[string]string{
+	"person":       models.EntityPerson,
+	"people":       models.EntityPerson,
+	"company":      models.EntityCompany,
+	"companies":    models.EntityCompany,
+	"organization": models.EntityCompany,
+	"project":      models.EntityProject,
+	"projects":     models.EntityProject,
+	"product":      models.EntityProject,
+}
+
+// ExtractEntities asks the model for the people, companies and projects an
+// article names
+func (c *Client) ExtractEntities(article *models.Article) ([]models.Entity, error) {
+	text := article.Content
+	if text == "" {
+		text = article.Description
+	}
+	words := strings.Fields(plainText(text))
+	if len(words) > entityWords {
+		words = words[:entityWords]
+	}
+
+	prompt := "List the people, companies and projects (software, products, initiatives) this news article names. " +
+		"Write one per line as \"person: Name\", \"company: Name\" or \"project: Name\", with the name as the article spells it. " +
+		"Reply with the list only, or nothing when it names none.\n\nTitle: " + article.Title + "\n\n" + strings.Join(words, " ")
+
+	reply, err := c.Generate(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("extracting entities: %w", err)
+	}
+	return parseEntities(reply), nil
+}
+
+// parseEntities reads "kind: Name" lines, skipping any other lines and
+// repeated entities
+func parseEntities(reply string) []models.Entity {
+	var entities []models.Entity
+	seen := map[models.Entity]bool{}
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimLeft(strings.TrimSpace(line), "-*•0123456789. ")
+		kind, name, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		kind, ok = entityKinds[strings.ToLower(strings.Trim(kind, " *"))]
+		name = strings.Trim(name, " *\"'.")
+		if !ok || name == "" || len(name) > 80 {
+			continue
+		}
+		e := models.Entity{Kind: kind, Name: name}
+		if !seen[e] {
+			seen[e] = true
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+// ExtractAllEntities tags the stored articles not looked at yet with the
+// entities they name, newest first. onProgress is called after each article
+// with the running count. It stops early on Shutdown and returns how many
+// articles were looked at.
+func (c *Client) ExtractAllEntities(onProgress func(done, total int)) (int, error) {
+	articles, err := c.db.GetArticlesWithoutEntities()
+	if err != nil {
+		return 0, err
+	}
+	for i := range articles {
+		if err := c.ctx.Err(); err != nil {
+			return i, err
+		}
+		entities, err := c.ExtractEntities(&articles[i])
+		if err != nil {
+			return i, err
+		}
+		if err := c.db.SaveArticleEntities(articles[i].ID, entities); err != nil {
+			return i, err
+		}
+		if onProgress != nil {
+			onProgress(i+1, len(articles))
+		}
+	}
+	return len(articles), nil
+}
@@ -2,22 +2,42 @@ package ai
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"time"
 
+	"github.com/thomaskoefod/newsreadr/internal/config"
 	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/metrics"
 	"github.com/thomaskoefod/newsreadr/pkg/models"
 )
 
 type Client struct {
 	host   string
 	model  string
-	db     *database.DB
+	db     database.Store
 	client *http.Client
+	// ctx is cancelled by Shutdown, aborting the requests in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// adjuster, when set, turns the AI score into the stored score
+	adjuster ScoreAdjuster
+	scoring  config.ScoringConfig
+	workers  int
+	metrics  *metrics.Metrics
+}
+
+// ScoreAdjuster computes an article's final score from its AI score
+type ScoreAdjuster interface {
+	Score(article *models.Article, aiScore float64) (float64, error)
 }
 
 type EmbeddingRequest struct {
@@ -29,17 +49,146 @@ type EmbeddingResponse struct {
 	Embedding []float64 `json:"embedding"`
 }
 
-func NewClient(host, model string, db *database.DB) *Client {
+type GenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+type GenerateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+func NewClient(host, model string, db database.Store) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Client{
 		host:   host,
 		model:  model,
 		db:     db,
 		client: &http.Client{},
+		ctx:    ctx,
+		cancel: cancel,
 	}
 }
 
-// GetEmbedding generates an embedding for the given text
+// Shutdown aborts the requests to Ollama in flight and fails the ones made
+// after it, so quitting doesn't leave the model busy. Scores already
+// computed are still written.
+func (c *Client) Shutdown() {
+	c.cancel()
+}
+
+// post sends a JSON request to Ollama, aborted by Shutdown
+func (c *Client) post(url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(c.ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.client.Do(req)
+}
+
+// SetScoring configures how article text is embedded for scoring
+func (c *Client) SetScoring(cfg config.ScoringConfig) {
+	c.scoring = cfg
+}
+
+// SetWorkers sets how many articles are scored concurrently
+func (c *Client) SetWorkers(n int) {
+	c.workers = n
+}
+
+// SetAdjuster sets an adjuster applied to AI scores before they are stored
+func (c *Client) SetAdjuster(a ScoreAdjuster) {
+	c.adjuster = a
+}
+
+// SetMetrics sets where scoring times and Ollama errors are counted
+func (c *Client) SetMetrics(m *metrics.Metrics) {
+	c.metrics = m
+}
+
+// Ping checks that the Ollama server is reachable
+func (c *Client) Ping(timeout time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(fmt.Sprintf("%s/api/tags", c.host))
+	if err != nil {
+		return fmt.Errorf("connecting to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama API error (status %d)", resp.StatusCode)
+	}
+	return nil
+}
+
+// HasModel reports whether the configured model is installed on the Ollama
+// server. A model without a tag matches its "latest" tag.
+func (c *Client) HasModel(timeout time.Duration) (bool, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(fmt.Sprintf("%s/api/tags", c.host))
+	if err != nil {
+		return false, fmt.Errorf("connecting to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("Ollama API error (status %d)", resp.StatusCode)
+	}
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return false, fmt.Errorf("decoding response: %w", err)
+	}
+	for _, m := range tags.Models {
+		if m.Name == c.model || m.Name == c.model+":latest" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetEmbedding generates an embedding for the given text, reusing the
+// cached one when the same text was embedded with the same model before
 func (c *Client) GetEmbedding(text string) ([]float64, error) {
+	sum := sha256.Sum256([]byte(text))
+	hash := hex.EncodeToString(sum[:])
+
+	cached, err := c.db.GetCachedEmbedding(hash, c.model)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		var emb []float64
+		if err := json.Unmarshal(cached, &emb); err == nil {
+			return emb, nil
+		}
+	}
+
+	emb, err := c.requestEmbedding(text)
+	if err != nil {
+		c.metrics.OllamaError()
+		return nil, err
+	}
+
+	embData, err := json.Marshal(emb)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling embedding: %w", err)
+	}
+	if err := c.db.CacheEmbedding(hash, c.model, embData); err != nil {
+		return nil, err
+	}
+	return emb, nil
+}
+
+// requestEmbedding asks Ollama for the embedding of text
+func (c *Client) requestEmbedding(text string) ([]float64, error) {
 	reqBody := EmbeddingRequest{
 		Model:  c.model,
 		Prompt: text,
@@ -51,7 +200,7 @@ func (c *Client) GetEmbedding(text string) ([]float64, error) {
 	}
 
 	url := fmt.Sprintf("%s/api/embeddings", c.host)
-	resp, err := c.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := c.post(url, jsonData)
 	if err != nil {
 		return nil, fmt.Errorf("sending request to Ollama: %w", err)
 	}
@@ -70,6 +219,22 @@ func (c *Client) GetEmbedding(text string) ([]float64, error) {
 	return embResp.Embedding, nil
 }
 
+// Generate asks the model to complete the given prompt and returns the full response
+func (c *Client) Generate(prompt string) (string, error) {
+	return c.GenerateStream(prompt, nil)
+}
+
+// GenerateStream asks the model to complete the given prompt, calling
+// onToken with each piece of the response as it is generated
+func (c *Client) GenerateStream(prompt string, onToken func(string)) (string, error) {
+	reqBody := GenerateRequest{
+		Model:  c.model,
+		Prompt: prompt,
+		Stream: true,
+	}
+	return c.stream("/api/generate", reqBody, func() streamChunk { return &GenerateResponse{} }, onToken)
+}
+
 // CosineSimilarity calculates cosine similarity between two vectors
 func CosineSimilarity(a, b []float64) float64 {
 	if len(a) != len(b) {
@@ -90,90 +255,116 @@ func CosineSimilarity(a, b []float64) float64 {
 	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
 }
 
-// ScoreArticle calculates relevance score for an article based on user interests
-func (c *Client) ScoreArticle(article *models.Article, interests []models.UserInterest) (float64, error) {
-	// Create text representation of article for embedding
-	articleText := fmt.Sprintf("%s. %s", article.Title, article.Description)
-
-	// Get article embedding
-	articleEmb, err := c.GetEmbedding(articleText)
-	if err != nil {
-		return 0, fmt.Errorf("getting article embedding: %w", err)
-	}
-
-	// Calculate weighted average similarity with interests
-	var totalScore float64
-	var totalWeight float64
-
-	for _, interest := range interests {
-		// Get or generate interest embedding
-		var interestEmb []float64
-		if len(interest.Embedding) > 0 {
-			if err := json.Unmarshal(interest.Embedding, &interestEmb); err != nil {
-				return 0, fmt.Errorf("unmarshaling interest embedding: %w", err)
-			}
-		} else {
-			// Generate and cache embedding
-			interestEmb, err = c.GetEmbedding(interest.Description)
-			if err != nil {
-				fmt.Printf("Warning: failed to get embedding for interest '%s': %v\n", interest.Description, err)
-				continue
-			}
-
-			// Cache embedding
-			embData, _ := json.Marshal(interestEmb)
-			interest.Embedding = embData
+// interestEmbedding returns the cached embedding of an interest, generating
+// it and storing it on the interest and in the database when missing
+func (c *Client) interestEmbedding(interest *models.UserInterest) ([]float64, error) {
+	var emb []float64
+	if len(interest.Embedding) > 0 {
+		if err := json.Unmarshal(interest.Embedding, &emb); err != nil {
+			return nil, fmt.Errorf("unmarshaling interest embedding: %w", err)
 		}
-
-		similarity := CosineSimilarity(articleEmb, interestEmb)
-		totalScore += similarity * interest.Weight
-		totalWeight += interest.Weight
+		return emb, nil
 	}
 
-	if totalWeight == 0 {
-		return 0, nil
+	return c.embedInterest(interest)
+}
+
+// embedInterest generates an interest's embedding and persists it
+func (c *Client) embedInterest(interest *models.UserInterest) ([]float64, error) {
+	emb, err := c.GetEmbedding(interest.Description)
+	if err != nil {
+		return nil, err
 	}
 
-	return totalScore / totalWeight, nil
+	embData, err := json.Marshal(emb)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling interest embedding: %w", err)
+	}
+	if err := c.db.UpdateInterestEmbedding(interest.ID, embData); err != nil {
+		return nil, err
+	}
+	interest.Embedding = embData
+	return emb, nil
 }
 
-// ScoreAllUnscored scores all articles that have a relevance score of 0
-func (c *Client) ScoreAllUnscored(maxAgeDays int) error {
+// RefreshInterestEmbeddings regenerates and stores the embeddings of all
+// interests, e.g. after switching models. It returns how many were updated.
+func (c *Client) RefreshInterestEmbeddings() (int, error) {
 	interests, err := c.db.GetInterests()
 	if err != nil {
-		return fmt.Errorf("getting interests: %w", err)
+		return 0, fmt.Errorf("getting interests: %w", err)
 	}
 
-	if len(interests) == 0 {
-		fmt.Println("No interests configured, skipping scoring")
-		return nil
+	for i := range interests {
+		if _, err := c.embedInterest(&interests[i]); err != nil {
+			return i, fmt.Errorf("embedding interest %q: %w", interests[i].Description, err)
+		}
+	}
+	return len(interests), nil
+}
+
+// ScoreArticle calculates relevance score for an article based on user interests
+func (c *Client) ScoreArticle(article *models.Article, interests []models.UserInterest) (float64, error) {
+	b, err := c.scoreBreakdown(article, interests)
+	if err != nil {
+		return 0, err
 	}
+	return b.Score, nil
+}
 
+// ScoreAllUnscored scores all articles that have not been scored yet or
+// were marked for re-scoring, several at a time, calling onFlush as
+// batches of scores are written. Having no interests is not an error.
+func (c *Client) ScoreAllUnscored(maxAgeDays int, onFlush FlushFunc) error {
 	// Get unread articles
-	articles, err := c.db.GetUnreadArticles(24 * time.Duration(maxAgeDays))
+	articles, err := c.db.GetUnreadArticles(time.Duration(maxAgeDays) * 24 * time.Hour)
 	if err != nil {
 		return fmt.Errorf("getting articles: %w", err)
 	}
 
-	for i, article := range articles {
-		// Skip already scored articles
-		if article.RelevanceScore > 0 {
-			continue
-		}
-
-		score, err := c.ScoreArticle(&article, interests)
-		if err != nil {
-			fmt.Printf("Warning: failed to score article '%s': %v\n", article.Title, err)
-			continue
+	// Skip already scored articles
+	var unscored []*models.Article
+	for i := range articles {
+		if !articles[i].Scored {
+			unscored = append(unscored, &articles[i])
 		}
+	}
+	if len(unscored) == 0 {
+		return nil
+	}
 
-		if err := c.db.UpdateArticleRelevance(article.ID, score); err != nil {
-			fmt.Printf("Warning: failed to update article relevance: %v\n", err)
-		}
+	err = c.ScoreArticles(unscored, onFlush)
+	if errors.Is(err, ErrNoInterests) {
+		return nil
+	}
+	return err
+}
 
-		fmt.Printf("Scored %d/%d articles\r", i+1, len(articles))
+// Rescore scores articles again whether or not they were scored before, so
+// changed interests apply to them: the unread ones, or every stored article
+// when all is set. onProgress is called with each batch of scores written,
+// the running total and what went wrong, as for FlushFunc. It returns how
+// many articles were rescored.
+func (c *Client) Rescore(all bool, maxAgeDays int, onProgress func(batch map[int64]float64, scored, total int, err error)) (int, error) {
+	var articles []models.Article
+	var err error
+	if all {
+		articles, err = c.db.GetAllArticles()
+	} else {
+		articles, err = c.db.GetUnreadArticles(time.Duration(maxAgeDays) * 24 * time.Hour)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getting articles: %w", err)
 	}
-	fmt.Println()
 
-	return nil
+	pending := make([]*models.Article, len(articles))
+	for i := range articles {
+		pending[i] = &articles[i]
+	}
+	err = c.ScoreArticles(pending, func(batch map[int64]float64, scored int, err error) {
+		if onProgress != nil {
+			onProgress(batch, scored, len(pending), err)
+		}
+	})
+	return len(pending), err
 }
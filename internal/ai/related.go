@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/thomaskoefod/newsreadr/internal/vector"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// summaryText is the text an article is embedded by for scoring and
+// similarity search
+func summaryText(article *models.Article) string {
+	return fmt.Sprintf("%s. %s", article.Title, article.Description)
+}
+
+// saveArticleEmbedding stores an article's summary embedding so it can be
+// indexed for similarity search
+func (c *Client) saveArticleEmbedding(articleID int64, emb []float64) error {
+	data, err := json.Marshal(emb)
+	if err != nil {
+		return fmt.Errorf("marshaling article embedding: %w", err)
+	}
+	return c.db.SaveArticleEmbedding(articleID, c.model, data)
+}
+
+// ArticleEmbedding returns an article's summary embedding, generating and
+// storing it when the article was scored before embeddings were kept
+func (c *Client) ArticleEmbedding(article *models.Article) ([]float64, error) {
+	data, err := c.db.GetArticleEmbedding(article.ID, c.model)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		var emb []float64
+		if err := json.Unmarshal(data, &emb); err == nil {
+			return emb, nil
+		}
+	}
+
+	emb, err := c.GetEmbedding(summaryText(article))
+	if err != nil {
+		return nil, fmt.Errorf("getting article embedding: %w", err)
+	}
+	if err := c.saveArticleEmbedding(article.ID, emb); err != nil {
+		return nil, err
+	}
+	return emb, nil
+}
+
+// BuildIndex builds a nearest neighbor index over the stored embeddings
+// of all unread articles
+func (c *Client) BuildIndex() (*vector.Index, error) {
+	stored, err := c.db.GetUnreadArticleEmbeddings(c.model)
+	if err != nil {
+		return nil, err
+	}
+
+	index := vector.New()
+	for _, e := range stored {
+		var emb []float64
+		if err := json.Unmarshal(e.Embedding, &emb); err != nil {
+			continue
+		}
+		index.Add(e.ArticleID, emb)
+	}
+	return index, nil
+}
+
+// StoredMatch is a stored article similar to another one
+type StoredMatch struct {
+	ArticleID  int64
+	Similarity float64
+}
+
+// SimilarStored compares an article with every stored article, read or
+// not, and returns those at least minSimilarity similar to it, most similar
+// first. Unlike the index, which only holds unread articles, it reaches
+// back as far as articles are kept.
+func (c *Client) SimilarStored(article *models.Article, minSimilarity float64) ([]StoredMatch, error) {
+	emb, err := c.ArticleEmbedding(article)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := c.db.GetStoredArticleEmbeddings(c.model)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []StoredMatch
+	for _, e := range stored {
+		if e.ArticleID == article.ID {
+			continue
+		}
+		var other []float64
+		if err := json.Unmarshal(e.Embedding, &other); err != nil {
+			continue
+		}
+		if sim := CosineSimilarity(emb, other); sim >= minSimilarity {
+			matches = append(matches, StoredMatch{ArticleID: e.ArticleID, Similarity: sim})
+		}
+	}
+	slices.SortFunc(matches, func(a, b StoredMatch) int {
+		return cmp.Compare(b.Similarity, a.Similarity)
+	})
+	return matches, nil
+}
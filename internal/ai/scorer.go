@@ -0,0 +1,241 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// scoreBatchSize is how many scores are written per transaction
+const scoreBatchSize = 50
+
+// scoreFlushInterval bounds how long a score waits to be written while
+// articles trickle in
+const scoreFlushInterval = time.Second
+
+// ErrNoInterests is returned when there are no interests to score against
+var ErrNoInterests = errors.New("no interests configured")
+
+// FlushFunc is called each time a batch of scores has been written, with
+// the batch (article ID to score) and the running total. err joins what
+// went wrong since the last call without stopping scoring: interests or
+// articles that could not be embedded, and batches that could not be
+// written, in which case batch is nil.
+type FlushFunc func(batch map[int64]float64, scored int, err error)
+
+// Scorer scores articles in the background as they are submitted, using
+// the client's workers, and writes the scores in batches
+type Scorer struct {
+	c         *Client
+	interests []models.UserInterest
+	onFlush   FlushFunc
+
+	jobs    chan *models.Article
+	results chan scoredArticle
+	stop    chan struct{}
+	done    chan struct{}
+	err     error
+	// warnings are reported with the next flush
+	warnings []error
+}
+
+// scoredArticle is an article's score, or why it could not be scored when
+// failed is set. err may also say which interests were left out.
+type scoredArticle struct {
+	article *models.Article
+	score   float64
+	err     error
+	failed  bool
+}
+
+// NewScorer starts a scorer for the current interests. onFlush, when set,
+// is called after each batch is written. It returns nil when there are no
+// interests to score against.
+func (c *Client) NewScorer(onFlush FlushFunc) (*Scorer, error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	interests, err := c.db.GetInterests()
+	if err != nil {
+		return nil, fmt.Errorf("getting interests: %w", err)
+	}
+	if len(interests) == 0 {
+		return nil, nil
+	}
+
+	// Embed the interests up front so the workers only read them
+	var ready []models.UserInterest
+	var warnings []error
+	for i := range interests {
+		if _, err := c.interestEmbedding(&interests[i]); err != nil {
+			warnings = append(warnings, fmt.Errorf("embedding interest %q: %w", interests[i].Description, err))
+			continue
+		}
+		ready = append(ready, interests[i])
+	}
+	if len(ready) == 0 {
+		return nil, fmt.Errorf("no interest could be embedded: %w", errors.Join(warnings...))
+	}
+
+	s := &Scorer{
+		c:         c,
+		interests: ready,
+		onFlush:   onFlush,
+		warnings:  warnings,
+		jobs:      make(chan *models.Article, scoreBatchSize),
+		results:   make(chan scoredArticle),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	for range max(c.workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(s.results)
+	}()
+	go s.collect()
+
+	return s, nil
+}
+
+// ScoreArticles scores articles against the current interests, calling
+// onFlush each time a batch of scores is written
+func (c *Client) ScoreArticles(articles []*models.Article, onFlush FlushFunc) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	scorer, err := c.NewScorer(onFlush)
+	if err != nil {
+		return err
+	}
+	if scorer == nil {
+		return ErrNoInterests
+	}
+	for _, article := range articles {
+		scorer.Submit(article)
+	}
+	return scorer.Close()
+}
+
+// Submit queues an article for scoring, waiting while the queue is full.
+// Articles submitted after scoring failed are dropped.
+func (s *Scorer) Submit(article *models.Article) {
+	select {
+	case s.jobs <- article:
+	case <-s.stop:
+	}
+}
+
+// Close waits for the submitted articles to be scored and written. After
+// Shutdown it returns once the scores computed so far are written.
+func (s *Scorer) Close() error {
+	close(s.jobs)
+	<-s.done
+	if s.err == nil {
+		return s.c.ctx.Err()
+	}
+	return s.err
+}
+
+// work scores the submitted articles. After Shutdown the rest are skipped,
+// leaving them unscored for the next run.
+func (s *Scorer) work() {
+	for article := range s.jobs {
+		if s.c.ctx.Err() != nil {
+			continue
+		}
+		started := time.Now()
+		b, err := s.c.scoreBreakdown(article, s.interests)
+		if s.c.ctx.Err() != nil {
+			continue
+		}
+		if err != nil {
+			s.results <- scoredArticle{article: article, err: fmt.Errorf("scoring %q: %w", article.Title, err), failed: true}
+			continue
+		}
+		if b.Skipped != nil {
+			err = fmt.Errorf("scoring %q: %w", article.Title, b.Skipped)
+		}
+		s.c.metrics.ArticleScored(time.Since(started))
+		s.results <- scoredArticle{article: article, score: b.Score, err: err}
+	}
+}
+
+// collect adjusts and writes scores one at a time as the workers produce them
+func (s *Scorer) collect() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(scoreFlushInterval)
+	defer ticker.Stop()
+
+	scored := 0
+	batch := make(map[int64]float64, scoreBatchSize)
+	flush := func() {
+		if len(batch) == 0 && len(s.warnings) == 0 {
+			return
+		}
+		var written map[int64]float64
+		if len(batch) > 0 {
+			if err := s.c.db.UpdateArticleRelevances(batch); err != nil {
+				s.warnings = append(s.warnings, fmt.Errorf("writing scores: %w", err))
+			} else {
+				scored += len(batch)
+				written = maps.Clone(batch)
+			}
+		}
+		clear(batch)
+		warnings := errors.Join(s.warnings...)
+		s.warnings = nil
+		if s.onFlush != nil {
+			s.onFlush(written, scored, warnings)
+		}
+	}
+
+	for {
+		select {
+		case r, ok := <-s.results:
+			if !ok {
+				flush()
+				return
+			}
+			if s.err != nil {
+				continue // drain until the workers finish
+			}
+			if r.err != nil {
+				s.warnings = append(s.warnings, r.err)
+			}
+			if r.failed {
+				continue
+			}
+
+			score := r.score
+			if s.c.adjuster != nil {
+				var err error
+				score, err = s.c.adjuster.Score(r.article, score)
+				if err != nil {
+					s.err = fmt.Errorf("adjusting score: %w", err)
+					close(s.stop)
+					continue
+				}
+			}
+
+			batch[r.article.ID] = score
+			if len(batch) >= scoreBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// streamChunk is one object of a streamed Ollama response
+type streamChunk interface {
+	token() string
+	done() bool
+	errMsg() string
+}
+
+func (r *GenerateResponse) token() string  { return r.Response }
+func (r *GenerateResponse) done() bool     { return r.Done }
+func (r *GenerateResponse) errMsg() string { return r.Error }
+
+func (r *ChatResponse) token() string  { return r.Message.Content }
+func (r *ChatResponse) done() bool     { return r.Done }
+func (r *ChatResponse) errMsg() string { return r.Error }
+
+// stream posts a request with streaming enabled and reads the response, a
+// sequence of JSON objects each holding the next piece of text. onToken, when
+// set, receives every piece as it arrives; the full text is returned.
+func (c *Client) stream(endpoint string, reqBody any, newChunk func() streamChunk, onToken func(string)) (string, error) {
+	text, err := c.readStream(endpoint, reqBody, newChunk, onToken)
+	if err != nil {
+		c.metrics.OllamaError()
+	}
+	return text, err
+}
+
+func (c *Client) readStream(endpoint string, reqBody any, newChunk func() streamChunk, onToken func(string)) (string, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("marshaling request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s%s", c.host, endpoint)
+	resp, err := c.post(url, jsonData)
+	if err != nil {
+		return "", fmt.Errorf("sending request to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var text bytes.Buffer
+	dec := json.NewDecoder(resp.Body)
+	for {
+		chunk := newChunk()
+		if err := dec.Decode(chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				return text.String(), nil
+			}
+			return "", fmt.Errorf("decoding response: %w", err)
+		}
+		if msg := chunk.errMsg(); msg != "" {
+			return "", fmt.Errorf("Ollama error: %s", msg)
+		}
+		if token := chunk.token(); token != "" {
+			text.WriteString(token)
+			if onToken != nil {
+				onToken(token)
+			}
+		}
+		if chunk.done() {
+			return text.String(), nil
+		}
+	}
+}
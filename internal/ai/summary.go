@@ -0,0 +1,32 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// summaryWords caps how much of the article is sent when summarizing it
+const summaryWords = 1500
+
+// Summarize asks the model for a short summary of an article
+func (c *Client) Summarize(article *models.Article) (string, error) {
+	text := article.Content
+	if text == "" {
+		text = article.Description
+	}
+	words := strings.Fields(plainText(text))
+	if len(words) > summaryWords {
+		words = words[:summaryWords]
+	}
+
+	prompt := "Summarize this news article in two or three sentences. " +
+		"Reply with the summary only.\n\nTitle: " + article.Title + "\n\n" + strings.Join(words, " ")
+
+	summary, err := c.Generate(prompt)
+	if err != nil {
+		return "", fmt.Errorf("summarizing article: %w", err)
+	}
+	return strings.TrimSpace(summary), nil
+}
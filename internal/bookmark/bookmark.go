@@ -0,0 +1,87 @@
+package bookmark
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/raindrop"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+	"golang.org/x/net/html"
+)
+
+// Saver stores an article in a bookmarking service
+type Saver interface {
+	// Name is shown when picking where to save
+	Name() string
+	// Save bookmarks the article with an optional note and excerpt; an
+	// empty excerpt means the article's description
+	Save(article *models.Article, note, excerpt string) error
+}
+
+// Target is a configured place to save articles
+type Target struct {
+	Saver
+	// Summarize asks for an AI summary as the excerpt
+	Summarize bool
+}
+
+// raindropSaver saves to Raindrop.io with the shared client, so its rate
+// limiting and circuit breaker apply to every save
+type raindropSaver struct {
+	client *raindrop.Client
+}
+
+func (r raindropSaver) Name() string { return "Raindrop.io" }
+
+func (r raindropSaver) Save(article *models.Article, note, excerpt string) error {
+	return r.client.SaveArticle(article, note, excerpt)
+}
+
+// Targets returns the configured save targets: Raindrop.io first when it
+// has an API token, then save.targets in order
+func Targets(cfg *config.Config, rd *raindrop.Client) ([]Target, error) {
+	var targets []Target
+	if cfg.Raindrop.APIToken != "" && rd != nil {
+		targets = append(targets, Target{Saver: raindropSaver{client: rd}, Summarize: cfg.Raindrop.Summarize})
+	}
+	for _, t := range cfg.Save.Targets {
+		var saver Saver
+		switch t.Type {
+		case "command":
+			saver = Command{name: t.Name, command: t.Command}
+		case "linkding":
+			saver = NewLinkding(t.Name, t.URL, t.Token)
+		case "shiori":
+			saver = NewShiori(t.Name, t.URL, t.Username, t.Password)
+		default:
+			return nil, fmt.Errorf("save target %q has unknown type %q", t.Name, t.Type)
+		}
+		targets = append(targets, Target{Saver: saver, Summarize: t.Summarize})
+	}
+	return targets, nil
+}
+
+// tagNames are the tags given to a saved article: its feed name, like the
+// bulk Raindrop export
+func tagNames(article *models.Article) []string {
+	if article.FeedName == "" {
+		return nil
+	}
+	return []string{article.FeedName}
+}
+
+// snippet returns the text of an HTML description on a single line
+func snippet(description string) string {
+	var s strings.Builder
+	z := html.NewTokenizer(strings.NewReader(description))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(s.String()), " ")
+		case html.TextToken:
+			s.Write(z.Text())
+			s.WriteByte(' ')
+		}
+	}
+}
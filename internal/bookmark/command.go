@@ -0,0 +1,67 @@
+package bookmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// commandTimeout bounds how long a save command may run
+const commandTimeout = 30 * time.Second
+
+// Command saves by running a shell command with the article as JSON on
+// stdin, for bookmark managers without a built-in client (buku, a CLI for
+// a self-hosted service, a custom script)
+type Command struct {
+	name    string
+	command string
+}
+
+// commandPayload is the article with the note and excerpt added
+type commandPayload struct {
+	*models.Article
+	Note    string `json:"note,omitempty"`
+	Excerpt string `json:"excerpt,omitempty"`
+}
+
+func (c Command) Name() string { return c.name }
+
+func (c Command) Save(article *models.Article, note, excerpt string) error {
+	if excerpt == "" {
+		excerpt = article.Description
+	}
+	payload, err := json.Marshal(commandPayload{Article: article, Note: note, Excerpt: excerpt})
+	if err != nil {
+		return fmt.Errorf("marshaling article for %s: %w", c.name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.command)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"NEWSREADR_TITLE="+article.Title,
+		"NEWSREADR_URL="+article.URL,
+		"NEWSREADR_FEED="+article.FeedName,
+		"NEWSREADR_NOTE="+note,
+	)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("saving to %s: %w: %s", c.name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
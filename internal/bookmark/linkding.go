@@ -0,0 +1,79 @@
+package bookmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// requestTimeout bounds requests to self-hosted bookmark managers
+const requestTimeout = 30 * time.Second
+
+// Linkding saves to a Linkding instance through its REST API, using an API
+// token from the instance's settings page
+type Linkding struct {
+	name   string
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewLinkding(name, url, token string) *Linkding {
+	return &Linkding{
+		name:   name,
+		url:    strings.TrimRight(url, "/"),
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type linkdingBookmark struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Notes       string   `json:"notes,omitempty"`
+	TagNames    []string `json:"tag_names,omitempty"`
+}
+
+func (l *Linkding) Name() string { return l.name }
+
+func (l *Linkding) Save(article *models.Article, note, excerpt string) error {
+	if excerpt == "" {
+		excerpt = snippet(article.Description)
+	}
+	body, err := json.Marshal(linkdingBookmark{
+		URL:         article.URL,
+		Title:       article.Title,
+		Description: excerpt,
+		Notes:       note,
+		TagNames:    tagNames(article),
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling bookmark: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", l.url+"/api/bookmarks/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Token "+l.token)
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request to %s: %w", l.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s API error (status %d): %s", l.name, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package bookmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Shiori saves to a Shiori instance. It logs in with a username and
+// password for a token, logging in again when the token is rejected.
+type Shiori struct {
+	name     string
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+func NewShiori(name, url, username, password string) *Shiori {
+	return &Shiori{
+		name:     name,
+		url:      strings.TrimRight(url, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type shioriTag struct {
+	Name string `json:"name"`
+}
+
+type shioriBookmark struct {
+	URL           string      `json:"url"`
+	Title         string      `json:"title"`
+	Excerpt       string      `json:"excerpt,omitempty"`
+	Tags          []shioriTag `json:"tags,omitempty"`
+	CreateArchive bool        `json:"createArchive"`
+}
+
+type shioriLogin struct {
+	OK      bool `json:"ok"`
+	Message struct {
+		Token string `json:"token"`
+	} `json:"message"`
+}
+
+func (s *Shiori) Name() string { return s.name }
+
+// Save bookmarks the article. Shiori has no notes, so the note is put in
+// front of the excerpt.
+func (s *Shiori) Save(article *models.Article, note, excerpt string) error {
+	if excerpt == "" {
+		excerpt = snippet(article.Description)
+	}
+	if note != "" {
+		excerpt = strings.TrimSpace(note + "\n\n" + excerpt)
+	}
+	bookmark := shioriBookmark{URL: article.URL, Title: article.Title, Excerpt: excerpt}
+	for _, tag := range tagNames(article) {
+		bookmark.Tags = append(bookmark.Tags, shioriTag{Name: tag})
+	}
+	body, err := json.Marshal(bookmark)
+	if err != nil {
+		return fmt.Errorf("marshaling bookmark: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, data, err := s.post(body)
+	if err == nil && status == http.StatusUnauthorized && s.token != "" {
+		// The token expired, log in again once
+		s.token = ""
+		status, data, err = s.post(body)
+	}
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusCreated {
+		return fmt.Errorf("%s API error (status %d): %s", s.name, status, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// post sends a bookmark, logging in first when there is no token
+func (s *Shiori) post(body []byte) (int, []byte, error) {
+	if s.token == "" {
+		if err := s.login(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	req, err := http.NewRequest("POST", s.url+"/api/bookmarks", bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("sending request to %s: %w", s.name, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, data, nil
+}
+
+// login exchanges the username and password for a token
+func (s *Shiori) login() error {
+	body, err := json.Marshal(map[string]any{"username": s.username, "password": s.password, "remember_me": true})
+	if err != nil {
+		return fmt.Errorf("marshaling login: %w", err)
+	}
+	resp, err := s.client.Post(s.url+"/api/v1/auth/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("logging in to %s: %w", s.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("logging in to %s (status %d): %s", s.name, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	var result shioriLogin
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding %s login: %w", s.name, err)
+	}
+	if result.Message.Token == "" {
+		return fmt.Errorf("logging in to %s: no token in response", s.name)
+	}
+	s.token = result.Message.Token
+	return nil
+}
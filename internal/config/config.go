@@ -1,44 +1,522 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/thomaskoefod/newsreadr/internal/github"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Database DatabaseConfig `yaml:"database"`
 	Feeds    []FeedConfig   `yaml:"feeds"`
-	Interests []string      `yaml:"interests"`
+	// FeedsManageVia says where feeds are managed: "config", "db" or "both"
+	FeedsManageVia string `yaml:"feeds_manage_via,omitempty"`
+	Interests []InterestConfig `yaml:"interests"`
+	// InterestGroups maps a group name to its interests. Articles are
+	// scored against each group separately and keep the best group score.
+	InterestGroups map[string][]InterestConfig `yaml:"interest_groups,omitempty"`
+	// AvoidInterests are topics whose similarity lowers an article's score
+	AvoidInterests []InterestConfig `yaml:"avoid_interests,omitempty"`
+	// InterestsManageVia says where interests are managed, like FeedsManageVia
+	InterestsManageVia string `yaml:"interests_manage_via,omitempty"`
 	Ollama   OllamaConfig   `yaml:"ollama"`
 	Raindrop RaindropConfig `yaml:"raindrop"`
+	Nextcloud NextcloudConfig `yaml:"nextcloud"`
+	Newsletters NewslettersConfig `yaml:"newsletters"`
 	UI       UIConfig       `yaml:"ui"`
+	Mute     MuteConfig     `yaml:"mute"`
+	Fetch    FetchConfig    `yaml:"fetch"`
+	HTTP     HTTPConfig     `yaml:"http"`
+	Share    ShareConfig    `yaml:"share"`
+	Save     SaveConfig     `yaml:"save"`
+	Webhook  WebhookConfig  `yaml:"webhook"`
+	Notify   NotifyConfig   `yaml:"notify"`
+	Hooks    HooksConfig    `yaml:"hooks"`
+	Scoring  ScoringConfig  `yaml:"scoring"`
+	Metrics  MetricsConfig  `yaml:"metrics"`
+	Offline  OfflineConfig  `yaml:"offline"`
+	Papers   PapersConfig   `yaml:"papers"`
+	Open     OpenConfig     `yaml:"open"`
+	// SecretsFile holds the values of secret:NAME references (default
+	// secrets.yaml next to this file)
+	SecretsFile string `yaml:"secrets_file,omitempty"`
+
+	// lines maps field paths to their line in the loaded file, for
+	// validation messages
+	lines map[string]int
+	// secretProblems are references that could not be resolved
+	secretProblems []Problem
 }
 
+// MemoryDatabase as database.path keeps the database in a temporary file
+// deleted on exit, along with the embedding cache unless cache_path is set
+const MemoryDatabase = ":memory:"
+
 type DatabaseConfig struct {
 	Path string `yaml:"path"`
+	// CachePath is a separate database for cached embeddings, which can be
+	// deleted at any time (default under $XDG_CACHE_HOME)
+	CachePath string `yaml:"cache_path,omitempty"`
+	// AutoVacuumMB vacuums the database after cleanups once this many
+	// megabytes are held by free pages. Zero disables automatic vacuuming.
+	AutoVacuumMB int `yaml:"auto_vacuum_mb"`
+	// EmbeddingCacheDays prunes cached embeddings unused for this many
+	// days. Defaults to 30; a negative value keeps them forever.
+	EmbeddingCacheDays int `yaml:"embedding_cache_days"`
+	// EncryptionKey encrypts article content and descriptions in the
+	// database. Once set it can't be removed or changed.
+	EncryptionKey string `yaml:"encryption_key,omitempty"`
+}
+
+// InterestConfig is an interest written either as its description alone or
+// as a mapping with a description and a weight (default 1)
+type InterestConfig struct {
+	Description string  `yaml:"description"`
+	Weight      float64 `yaml:"weight,omitempty"`
+}
+
+func (i *InterestConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&i.Description)
+	}
+	type plain InterestConfig
+	return node.Decode((*plain)(i))
+}
+
+// MarshalYAML writes interests without a weight as plain descriptions
+func (i InterestConfig) MarshalYAML() (any, error) {
+	if i.Weight == 0 {
+		return i.Description, nil
+	}
+	type plain InterestConfig
+	return plain(i), nil
 }
 
 type FeedConfig struct {
 	URL  string `yaml:"url"`
 	Name string `yaml:"name"`
+	// Optional credentials and extra request headers for private feeds
+	Username string            `yaml:"username,omitempty"`
+	Password string            `yaml:"password,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty"`
+	// Weight is exposed to scoring scripts as feed_weight (default 1)
+	Weight float64 `yaml:"weight,omitempty"`
+	// Selector is the part of a watched page (watch+https://...) to compare,
+	// instead of the domain's rule or the article or main element
+	Selector string `yaml:"selector,omitempty"`
+	// Timeout replaces fetch.timeout for this feed, e.g. 2m for a slow one
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// FeedSettings returns the configured entry for a feed, matched by URL or,
+// for feeds whose URL changed after a redirect, by name
+func (c *Config) FeedSettings(url, name string) *FeedConfig {
+	for i := range c.Feeds {
+		if c.Feeds[i].URL == url {
+			return &c.Feeds[i]
+		}
+	}
+	for i := range c.Feeds {
+		if name != "" && c.Feeds[i].Name == name {
+			return &c.Feeds[i]
+		}
+	}
+	return nil
 }
 
 type OllamaConfig struct {
 	Host  string `yaml:"host"`
 	Model string `yaml:"model"`
+	// Workers is how many articles are scored concurrently; keep it at or
+	// below the server's OLLAMA_NUM_PARALLEL
+	Workers int `yaml:"workers"`
+	// ExtractEntities has Ollama tag new articles with the people, companies
+	// and projects they name after each fetch, for the entity browser
+	ExtractEntities bool `yaml:"extract_entities,omitempty"`
 }
 
 type RaindropConfig struct {
 	APIToken string `yaml:"api_token"`
+	// Summarize has Ollama write a summary of saved articles for the
+	// bookmark excerpt instead of using the feed's description
+	Summarize bool `yaml:"summarize"`
+}
+
+// MetricsConfig exposes Prometheus metrics while running as a daemon
+type MetricsConfig struct {
+	// Listen is the address serving /metrics, e.g. 127.0.0.1:9464 (empty = off)
+	Listen string `yaml:"listen,omitempty"`
+}
+
+// OfflineConfig keeps queued and starred articles readable offline by
+// fetching their full text and downloading their images after each fetch
+type OfflineConfig struct {
+	// MaxMB bounds the downloaded images, evicting those of articles no
+	// longer queued or starred first and then the oldest. Defaults to 200;
+	// a negative value turns offline caching off.
+	MaxMB int `yaml:"max_mb"`
+	// Dir holds the downloaded images (default under $XDG_CACHE_HOME)
+	Dir string `yaml:"dir,omitempty"`
+}
+
+// MaxBytes returns the image cache limit in bytes
+func (o *OfflineConfig) MaxBytes() int64 {
+	return int64(o.MaxMB) * 1024 * 1024
+}
+
+// PapersConfig is for articles from arXiv and journal feeds
+type PapersConfig struct {
+	// PDFDir is where p in the article view saves a paper's PDF (default
+	// ~/Downloads/papers)
+	PDFDir string `yaml:"pdf_dir,omitempty"`
+}
+
+// OpenConfig changes the URL articles are opened at in the browser
+type OpenConfig struct {
+	// Rewrites maps a domain, which covers its subdomains, or "*" for any
+	// other domain to the URL to open instead: a Go template over the
+	// article, like https://r.jina.ai/{{.URL}} or
+	// https://example.com/read?url={{urlquery .URL}}
+	Rewrites map[string]string `yaml:"rewrites,omitempty"`
+	// StripParams are query parameters removed from article URLs before
+	// they are opened, like utm_* (a trailing * matches any suffix)
+	StripParams []string `yaml:"strip_params,omitempty"`
+	// Browser is the command articles are opened with instead of the
+	// system default, e.g. "firefox -P reading". {url} stands for the URL;
+	// without it the URL is the last argument.
+	Browser string `yaml:"browser,omitempty"`
+	// Terminal runs Browser in this terminal, suspending the reader until
+	// it exits. w3m, lynx, links and elinks are known to need it.
+	Terminal bool `yaml:"terminal"`
+}
+
+// terminalBrowsers run in the terminal they are started from
+var terminalBrowsers = map[string]bool{"w3m": true, "lynx": true, "links": true, "elinks": true}
+
+// BrowserArgs returns the browser command for a URL, split into words
+// like a shell would, quotes included
+func (o *OpenConfig) BrowserArgs(url string) ([]string, error) {
+	args, err := splitWords(o.Browser)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("no command")
+	}
+	placeholder := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{url}") {
+			args[i] = strings.ReplaceAll(arg, "{url}", url)
+			placeholder = true
+		}
+	}
+	if !placeholder {
+		args = append(args, url)
+	}
+	return args, nil
+}
+
+// TerminalBrowser reports whether the browser runs in the terminal
+func (o *OpenConfig) TerminalBrowser() bool {
+	args, err := splitWords(o.Browser)
+	if err != nil || len(args) == 0 {
+		return false
+	}
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	return o.Terminal || terminalBrowsers[name]
+}
+
+// splitWords splits a command line into words at spaces outside single or
+// double quotes, dropping the quotes
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Rewrite returns the URL template for an article's domain, also trying
+// its parent domains and then "*"
+func (o *OpenConfig) Rewrite(domain string) (string, bool) {
+	for domain != "" {
+		for d, tmpl := range o.Rewrites {
+			if strings.TrimPrefix(strings.ToLower(d), "www.") == domain {
+				return tmpl, true
+			}
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			break
+		}
+		domain = parent
+	}
+	tmpl, ok := o.Rewrites["*"]
+	return tmpl, ok
+}
+
+// NextcloudConfig syncs with the News app of a Nextcloud instance. When URL
+// is set, articles come from Nextcloud instead of fetching the feeds
+// directly, and read status is kept in sync both ways.
+type NextcloudConfig struct {
+	URL      string `yaml:"url,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"` // an app password works too
+}
+
+// NewslettersConfig reads email newsletters from a folder of an IMAP
+// mailbox as articles, with a feed per sender. When Host is empty no
+// mailbox is read.
+type NewslettersConfig struct {
+	// Host is the IMAP server, reached over TLS on port 993 unless given as
+	// host:port
+	Host     string `yaml:"host,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// Folder holds the newsletters (default INBOX). Messages are marked
+	// seen once stored.
+	Folder string `yaml:"folder,omitempty"`
+}
+
+// WebhookConfig posts a report after every fetch/score cycle. Without a
+// Template the report is sent as JSON.
+type WebhookConfig struct {
+	URL         string            `yaml:"url"`
+	Template    string            `yaml:"template,omitempty"`
+	ContentType string            `yaml:"content_type,omitempty"`
+	Headers     map[string]string `yaml:"headers,omitempty"`
+	// TopArticles is how many of the best new articles to include
+	TopArticles int `yaml:"top_articles"`
+}
+
+// NotifyConfig posts new articles scoring at least Threshold to chat
+// services, sending at most MaxPerHour headlines
+type NotifyConfig struct {
+	Threshold  float64        `yaml:"threshold"`
+	MaxPerHour int            `yaml:"max_per_hour"`
+	Matrix     MatrixConfig   `yaml:"matrix"`
+	Telegram   TelegramConfig `yaml:"telegram"`
+	// Terminal has the terminal the reader runs in show desktop
+	// notifications (OSC 9); the daemon has no terminal to do so
+	Terminal bool `yaml:"terminal"`
+}
+
+type MatrixConfig struct {
+	Homeserver  string `yaml:"homeserver"`
+	AccessToken string `yaml:"access_token"`
+	RoomID      string `yaml:"room_id"`
+}
+
+type TelegramConfig struct {
+	BotToken string `yaml:"bot_token"`
+	ChatID   string `yaml:"chat_id"`
+}
+
+// ScoringConfig selects how relevance scores are computed: "ai" stores the
+// embedding similarity as is, "script" passes it through a Starlark script
+type ScoringConfig struct {
+	Backend string `yaml:"backend"`
+	Script  string `yaml:"script,omitempty"`
+
+	// FullContent also embeds the article body, split into chunks of
+	// ChunkWords words (at most MaxChunks). Aggregate combines the chunk
+	// similarities: "max" or "mean".
+	FullContent bool   `yaml:"full_content"`
+	ChunkWords  int    `yaml:"chunk_words"`
+	MaxChunks   int    `yaml:"max_chunks"`
+	Aggregate   string `yaml:"aggregate"`
+
+	// Display shows scores in the reader as "bands" of stars or as "raw"
+	// similarities. For bands the scores are first spread over [0, 1] by
+	// Calibration, "minmax" or "zscore", over the unread articles, and
+	// Bands are the calibrated scores earning each further star.
+	Display     string    `yaml:"display"`
+	Calibration string    `yaml:"calibration"`
+	Bands       []float64 `yaml:"bands,omitempty"`
+}
+
+// HooksConfig lists shell commands run on article lifecycle events. Each
+// command receives the article as JSON on stdin.
+type HooksConfig struct {
+	OnArticleFetched []string `yaml:"on_article_fetched"`
+	OnArticleRead    []string `yaml:"on_article_read"`
+	OnArticleSaved   []string `yaml:"on_article_saved"`
+}
+
+type ShareConfig struct {
+	Targets []ShareTarget `yaml:"targets"`
+}
+
+// ShareTarget is an entry in the share menu. Clipboard targets copy the
+// rendered Template, a Go template over the article ({{.Title}}, {{.URL}},
+// ...); command targets run Command in a shell with the article in
+// NEWSREADR_* environment variables.
+type ShareTarget struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"` // "clipboard" or "command"
+	Template string `yaml:"template,omitempty"`
+	Command  string `yaml:"command,omitempty"`
+}
+
+// SaveConfig lists bookmarking targets besides Raindrop.io, offered when
+// saving an article
+type SaveConfig struct {
+	Targets []SaveTarget `yaml:"targets"`
+}
+
+// SaveTarget is a bookmarking service. Command targets run Command in a
+// shell with the article, note and excerpt as JSON on stdin. Linkding
+// targets need the instance URL and an API token, Shiori targets the URL,
+// a username and a password.
+type SaveTarget struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"` // "command", "linkding" or "shiori"
+	Command  string `yaml:"command,omitempty"`
+	URL      string `yaml:"url,omitempty"`
+	Token    string `yaml:"token,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// Summarize has Ollama write the excerpt, like raindrop.summarize
+	Summarize bool `yaml:"summarize,omitempty"`
+}
+
+type HTTPConfig struct {
+	// Proxy is an http://, https:// or socks5:// URL used for fetching feeds
+	// and web content. Empty falls back to HTTP_PROXY/HTTPS_PROXY.
+	Proxy     string `yaml:"proxy"`
+	UserAgent string `yaml:"user_agent"`
+	// RequestsPerMinute limits requests to any single host (0 = unlimited)
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// MaxRetries is how often a request is retried after a 429 response
+	MaxRetries int `yaml:"max_retries"`
+}
+
+type FetchConfig struct {
+	// SilentDays flags a feed in the health view when its newest item is
+	// older than this many days
+	SilentDays int `yaml:"silent_days"`
+	// RulesFile holds per-domain rules for extracting the full article
+	// from its page (default rules.yaml next to this file)
+	RulesFile string `yaml:"rules_file,omitempty"`
+	// Archive is where articles that turn out to be paywalled are loaded
+	// from instead: "wayback" (the Wayback Machine), "archive.today" or
+	// "none" to turn this off
+	Archive string `yaml:"archive"`
+	// Timeout bounds downloading and parsing one feed, so a slow feed is
+	// given up on and the others fetched (e.g. 30s)
+	Timeout string `yaml:"timeout"`
+
+	// Rules are the rules loaded from RulesFile, by domain
+	Rules map[string]ExtractRule `yaml:"-"`
+}
+
+type MuteConfig struct {
+	Keywords []string `yaml:"keywords"`
+	Patterns []string `yaml:"patterns"`
+	Domains  []string `yaml:"domains"`
+	// Action is "drop" to skip muted articles at fetch time or "read" to
+	// store them already marked as read
+	Action string `yaml:"action"`
+}
+
+// Mutes returns the configured mute rules
+func (m *MuteConfig) Mutes() []models.Mute {
+	var mutes []models.Mute
+	for _, k := range m.Keywords {
+		mutes = append(mutes, models.Mute{Kind: models.MuteKeyword, Value: k})
+	}
+	for _, p := range m.Patterns {
+		mutes = append(mutes, models.Mute{Kind: models.MuteRegex, Value: p})
+	}
+	for _, d := range m.Domains {
+		mutes = append(mutes, models.Mute{Kind: models.MuteDomain, Value: d})
+	}
+	return mutes
 }
 
 type UIConfig struct {
 	RefreshInterval  string `yaml:"refresh_interval"`
 	ArticleMaxAgeDays int   `yaml:"article_max_age_days"`
+	// ListMode is "detailed" (title, details and a snippet) or "compact"
+	// (one line per article)
+	ListMode string `yaml:"list_mode"`
+	// GroupBy is "none" (a single list ordered by relevance), "feed"
+	// (collapsible sections per feed) or "date" (Today, Yesterday, This
+	// Week and Older sections)
+	GroupBy string `yaml:"group_by"`
+	// CodeTheme colors code blocks in articles: "auto" (the dark or light
+	// article style, whichever suits the terminal), "none" (no
+	// highlighting) or the name of a chroma style such as "monokai"
+	CodeTheme string `yaml:"code_theme"`
+	// TerminalTitle shows the unread count, and how many are above
+	// notify.threshold, in the title of the terminal or tmux pane
+	TerminalTitle bool `yaml:"terminal_title"`
+	// StatusBar is a text/template replacing the key hints at the bottom of
+	// the article list and article, e.g. "{{.Unread}} unread • fetched
+	// {{.LastFetch}}"; empty shows the key hints
+	StatusBar string `yaml:"status_bar"`
+	// BriefingArticles is how many of the most relevant unread articles the
+	// briefing covers
+	BriefingArticles int `yaml:"briefing_articles"`
+}
+
+// AutoVacuumThreshold returns the automatic vacuum threshold in bytes
+func (d *DatabaseConfig) AutoVacuumThreshold() int64 {
+	return int64(d.AutoVacuumMB) * 1024 * 1024
+}
+
+// EmbeddingCacheMaxAge returns how long unused cached embeddings are kept,
+// or zero to keep them forever
+func (d *DatabaseConfig) EmbeddingCacheMaxAge() time.Duration {
+	if d.EmbeddingCacheDays < 0 {
+		return 0
+	}
+	return time.Duration(d.EmbeddingCacheDays) * 24 * time.Hour
+}
+
+// FeedTimeout returns how long fetching a feed may take: its own timeout
+// when settings has one, else fetch.timeout. Zero means no limit.
+func (c *Config) FeedTimeout(settings *FeedConfig) time.Duration {
+	timeout := c.Fetch.Timeout
+	if settings != nil && settings.Timeout != "" {
+		timeout = settings.Timeout
+	}
+	d, _ := time.ParseDuration(timeout)
+	return d
 }
 
 // GetRefreshInterval parses the refresh interval string
@@ -53,31 +531,157 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
 
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := doc.Decode(&cfg); err != nil {
 		return nil, fmt.Errorf("parsing config file: %w", err)
 	}
+	cfg.lines = nodeLines(&doc)
+	cfg.resolveSecrets(path)
+	if err := cfg.loadRules(path); err != nil {
+		return nil, err
+	}
 
 	// Expand home directory in database path
-	if cfg.Database.Path != "" {
-		cfg.Database.Path = expandPath(cfg.Database.Path)
+	if cfg.Database.Path == "" {
+		cfg.Database.Path = DefaultDatabasePath()
+	}
+	cfg.Database.Path = expandPath(cfg.Database.Path)
+	// Configs written by older versions name the old default location,
+	// which MigrateLegacyLayout moved to the data directory
+	if cfg.Database.Path == legacyDatabasePath() {
+		if _, err := os.Stat(cfg.Database.Path); errors.Is(err, os.ErrNotExist) {
+			cfg.Database.Path = DefaultDatabasePath()
+		}
+	}
+	if cfg.Database.CachePath == "" {
+		cfg.Database.CachePath = DefaultCachePath()
+		// A throwaway database shouldn't fill the real cache either
+		if cfg.Database.Path == MemoryDatabase {
+			cfg.Database.CachePath = MemoryDatabase
+		}
+	}
+	cfg.Database.CachePath = expandPath(cfg.Database.CachePath)
+	cfg.Scoring.Script = expandPath(cfg.Scoring.Script)
+	cfg.Offline.Dir = expandPath(cfg.Offline.Dir)
+	cfg.Papers.PDFDir = expandPath(cfg.Papers.PDFDir)
+	// github.com/ORG/REPO subscribes to the repository's releases
+	for i := range cfg.Feeds {
+		if feedURL, name, ok := github.FeedURL(cfg.Feeds[i].URL); ok {
+			cfg.Feeds[i].URL = feedURL
+			if cfg.Feeds[i].Name == "" {
+				cfg.Feeds[i].Name = name
+			}
+		}
+	}
+	cfg.ApplyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// ApplyDefaults fills in the options left unset with their default values
+func (c *Config) ApplyDefaults() {
+	if c.Database.EmbeddingCacheDays == 0 {
+		c.Database.EmbeddingCacheDays = 30
+	}
+	if c.Ollama.Host == "" {
+		c.Ollama.Host = "http://localhost:11434"
+	}
+	if c.Ollama.Model == "" {
+		c.Ollama.Model = "llama2"
+	}
+	if c.Ollama.Workers <= 0 {
+		c.Ollama.Workers = 4
+	}
+	if c.UI.RefreshInterval == "" {
+		c.UI.RefreshInterval = "15m"
+	}
+	if c.UI.ArticleMaxAgeDays == 0 {
+		c.UI.ArticleMaxAgeDays = 14
+	}
+	if c.HTTP.MaxRetries == 0 {
+		c.HTTP.MaxRetries = 2
+	}
+	if c.UI.ListMode == "" {
+		c.UI.ListMode = "detailed"
+	}
+	if c.UI.GroupBy == "" {
+		c.UI.GroupBy = "none"
+	}
+	if c.UI.CodeTheme == "" {
+		c.UI.CodeTheme = "auto"
+	}
+	if c.UI.BriefingArticles == 0 {
+		c.UI.BriefingArticles = 10
+	}
+	if c.Scoring.Backend == "" {
+		c.Scoring.Backend = "ai"
 	}
 
-	// Set defaults
-	if cfg.Ollama.Host == "" {
-		cfg.Ollama.Host = "http://localhost:11434"
+	if c.Scoring.ChunkWords == 0 {
+		c.Scoring.ChunkWords = 256
 	}
-	if cfg.Ollama.Model == "" {
-		cfg.Ollama.Model = "llama2"
+	if c.Scoring.MaxChunks == 0 {
+		c.Scoring.MaxChunks = 8
 	}
-	if cfg.UI.RefreshInterval == "" {
-		cfg.UI.RefreshInterval = "15m"
+	if c.Scoring.Aggregate == "" {
+		c.Scoring.Aggregate = "max"
 	}
-	if cfg.UI.ArticleMaxAgeDays == 0 {
-		cfg.UI.ArticleMaxAgeDays = 14
+	if c.Scoring.Display == "" {
+		c.Scoring.Display = "bands"
+	}
+	if c.Scoring.Calibration == "" {
+		c.Scoring.Calibration = "minmax"
+	}
+	if c.Scoring.Bands == nil {
+		c.Scoring.Bands = []float64{0.2, 0.4, 0.6, 0.8}
 	}
 
-	return &cfg, nil
+	if c.Notify.Threshold == 0 {
+		c.Notify.Threshold = 0.7
+	}
+	if c.Notify.MaxPerHour == 0 {
+		c.Notify.MaxPerHour = 10
+	}
+	if c.Webhook.TopArticles == 0 {
+		c.Webhook.TopArticles = 5
+	}
+	if c.Fetch.SilentDays == 0 {
+		c.Fetch.SilentDays = 30
+	}
+	if c.Offline.MaxMB == 0 {
+		c.Offline.MaxMB = 200
+	}
+	if c.Offline.Dir == "" {
+		c.Offline.Dir = DefaultOfflineDir()
+	}
+	if c.Papers.PDFDir == "" {
+		c.Papers.PDFDir = DefaultPDFDir()
+	}
+	if c.Fetch.Archive == "" {
+		c.Fetch.Archive = "wayback"
+	}
+	if c.Fetch.Timeout == "" {
+		c.Fetch.Timeout = "30s"
+	}
+	if c.Newsletters.Folder == "" {
+		c.Newsletters.Folder = "INBOX"
+	}
+	if c.Mute.Action == "" {
+		c.Mute.Action = "drop"
+	}
+	if c.FeedsManageVia == "" {
+		c.FeedsManageVia = "both"
+	}
+	if c.InterestsManageVia == "" {
+		c.InterestsManageVia = "both"
+	}
 }
 
 // Save writes configuration to file
@@ -98,22 +702,64 @@ func Save(cfg *Config, path string) error {
 	return nil
 }
 
-// expandPath expands ~ to home directory
+// expandPath expands a leading ~ to the home directory. Both / and the
+// platform separator may follow it, so ~/x and ~\x work on Windows.
 func expandPath(path string) string {
-	if len(path) > 0 && path[0] == '~' {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			return filepath.Join(home, path[1:])
-		}
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
 	}
-	return path
-}
-
-// DefaultConfigPath returns the default configuration file path
-func DefaultConfigPath() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return "config.yaml"
+		return path
+	}
+	return filepath.Join(home, filepath.FromSlash(path[1:]))
+}
+
+// Default returns a starter configuration written on first run
+func Default() *Config {
+	return &Config{
+		Database: DatabaseConfig{Path: DefaultDatabasePath()},
+		Feeds: []FeedConfig{
+			{URL: "https://hnrss.org/frontpage", Name: "Hacker News"},
+			{URL: "https://blog.golang.org/feed.atom", Name: "Go Blog"},
+		},
+		Interests: []InterestConfig{
+			{Description: "golang programming and software development"},
+		},
+		Ollama: OllamaConfig{
+			Host:    "http://localhost:11434",
+			Model:   "llama2",
+			Workers: 4,
+		},
+		UI: UIConfig{
+			RefreshInterval:   "15m",
+			ArticleMaxAgeDays: 14,
+			ListMode:          "detailed",
+			GroupBy:           "none",
+			CodeTheme:         "auto",
+			BriefingArticles:  10,
+		},
+		Fetch: FetchConfig{
+			SilentDays: 30,
+			Archive:    "wayback",
+			Timeout:    "30s",
+		},
+		Offline: OfflineConfig{
+			MaxMB: 200,
+			Dir:   DefaultOfflineDir(),
+		},
+		Papers: PapersConfig{
+			PDFDir: DefaultPDFDir(),
+		},
+		Newsletters: NewslettersConfig{
+			Folder: "INBOX",
+		},
+		HTTP: HTTPConfig{
+			RequestsPerMinute: 30,
+			MaxRetries:        2,
+		},
+		Mute: MuteConfig{
+			Action: "drop",
+		},
 	}
-	return filepath.Join(home, ".config", "newsreader", "config.yaml")
 }
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const appDir = "newsreader"
+
+// ConfigDir holds config.yaml and secrets.yaml ($XDG_CONFIG_HOME, or
+// %APPDATA% on Windows)
+func ConfigDir() string {
+	return filepath.Join(configHome(), appDir)
+}
+
+// DataDir holds the database ($XDG_DATA_HOME, or %LOCALAPPDATA% on Windows)
+func DataDir() string {
+	return filepath.Join(dataHome(), appDir)
+}
+
+// CacheDir holds data that can be regenerated, like cached embeddings
+// ($XDG_CACHE_HOME, or %LOCALAPPDATA% on Windows)
+func CacheDir() string {
+	return filepath.Join(cacheHome(), appDir)
+}
+
+// DefaultConfigPath returns the default configuration file path
+func DefaultConfigPath() string {
+	return filepath.Join(ConfigDir(), "config.yaml")
+}
+
+// DefaultDatabasePath returns the default database file path
+func DefaultDatabasePath() string {
+	return filepath.Join(DataDir(), "data.db")
+}
+
+// DefaultCachePath returns the default embedding cache file path
+func DefaultCachePath() string {
+	return filepath.Join(CacheDir(), "cache.db")
+}
+
+// DefaultOfflineDir returns the default directory of images downloaded
+// for offline reading
+func DefaultOfflineDir() string {
+	return filepath.Join(CacheDir(), "offline")
+}
+
+// DefaultPDFDir returns the default directory of downloaded papers
+func DefaultPDFDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(DataDir(), "papers")
+	}
+	return filepath.Join(home, "Downloads", "papers")
+}
+
+// legacyDir is where older versions kept both the config and the database
+func legacyDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", appDir)
+}
+
+// legacyDatabasePath is the database location used by older versions
+func legacyDatabasePath() string {
+	if dir := legacyDir(); dir != "" {
+		return filepath.Join(dir, "data.db")
+	}
+	return ""
+}
+
+// MigrateLegacyLayout moves the config file and database written by older
+// versions to their XDG locations. The config is only moved when it is
+// expected at its default path, the database only when the config uses
+// the default location. Files already present at the new location are
+// never overwritten. It returns a description of each move.
+func MigrateLegacyLayout(configPath string) ([]string, error) {
+	var moved []string
+	dir := legacyDir()
+	if dir == "" {
+		return nil, nil
+	}
+
+	if configPath == DefaultConfigPath() {
+		for _, name := range []string{"config.yaml", "secrets.yaml"} {
+			ok, err := moveIfMissing(filepath.Join(dir, name), filepath.Join(ConfigDir(), name))
+			if err != nil {
+				return moved, err
+			}
+			if ok {
+				moved = append(moved, fmt.Sprintf("%s -> %s", filepath.Join(dir, name), filepath.Join(ConfigDir(), name)))
+			}
+		}
+	}
+
+	oldDB, newDB := legacyDatabasePath(), DefaultDatabasePath()
+	if oldDB == newDB || !usesDefaultDatabase(configPath) {
+		return moved, nil
+	}
+	if _, err := os.Stat(newDB); err == nil {
+		return moved, nil
+	}
+	// SQLite keeps uncommitted pages in -wal and -shm files next to the
+	// database, so they have to move along with it
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		ok, err := moveIfMissing(oldDB+suffix, newDB+suffix)
+		if err != nil {
+			return moved, err
+		}
+		if ok && suffix == "" {
+			moved = append(moved, fmt.Sprintf("%s -> %s", oldDB, newDB))
+		}
+	}
+	return moved, nil
+}
+
+// usesDefaultDatabase reports whether the config at path leaves the
+// database at its default location, either by not setting database.path or
+// by naming the location used by older versions
+func usesDefaultDatabase(path string) bool {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		Database struct {
+			Path string `yaml:"path"`
+		} `yaml:"database"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return false
+	}
+	p := expandPath(cfg.Database.Path)
+	return p == "" || p == legacyDatabasePath()
+}
+
+// moveIfMissing moves src to dst unless src is missing or dst exists. It
+// falls back to copying when src and dst are on different filesystems.
+func moveIfMissing(src, dst string) (bool, error) {
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, fmt.Errorf("creating %s: %w", filepath.Dir(dst), err)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return true, nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		os.Remove(dst)
+		return false, fmt.Errorf("moving %s to %s: %w", src, dst, err)
+	}
+	if err := os.Remove(src); err != nil {
+		return false, fmt.Errorf("removing %s: %w", src, err)
+	}
+	return true, nil
+}
+
+// copyFile copies src to dst, keeping its permissions
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// setHome points os.UserHomeDir at dir on every platform
+func setHome(t *testing.T, dir string) {
+	t.Helper()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+}
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"empty", "", ""},
+		{"home", "~", home},
+		{"under home", "~/news/data.db", filepath.Join(home, "news", "data.db")},
+		{"absolute", filepath.Join(home, "data.db"), filepath.Join(home, "data.db")},
+		{"relative", filepath.Join("news", "data.db"), filepath.Join("news", "data.db")},
+		{"other user", "~bob/data.db", "~bob/data.db"},
+		{"tilde inside", "news/~/data.db", "news/~/data.db"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandPath(tt.path); got != tt.want {
+				t.Errorf("expandPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func configHome() string { return xdgHome("XDG_CONFIG_HOME", ".config") }
+func dataHome() string   { return xdgHome("XDG_DATA_HOME", filepath.Join(".local", "share")) }
+func cacheHome() string  { return xdgHome("XDG_CACHE_HOME", ".cache") }
+
+// xdgHome returns $env, falling back to ~/fallback when the variable is
+// unset or not an absolute path as the XDG base directory spec requires
+func xdgHome(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, fallback)
+}
@@ -0,0 +1,66 @@
+//go:build !windows
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPaths(t *testing.T) {
+	home := t.TempDir()
+	xdg := t.TempDir()
+
+	tests := []struct {
+		name                    string
+		env                     map[string]string
+		config, database, cache string
+	}{
+		{
+			name:     "unset",
+			config:   filepath.Join(home, ".config", appDir, "config.yaml"),
+			database: filepath.Join(home, ".local", "share", appDir, "data.db"),
+			cache:    filepath.Join(home, ".cache", appDir, "cache.db"),
+		},
+		{
+			name: "xdg",
+			env: map[string]string{
+				"XDG_CONFIG_HOME": filepath.Join(xdg, "config"),
+				"XDG_DATA_HOME":   filepath.Join(xdg, "data"),
+				"XDG_CACHE_HOME":  filepath.Join(xdg, "cache"),
+			},
+			config:   filepath.Join(xdg, "config", appDir, "config.yaml"),
+			database: filepath.Join(xdg, "data", appDir, "data.db"),
+			cache:    filepath.Join(xdg, "cache", appDir, "cache.db"),
+		},
+		{
+			// The spec says to ignore relative paths
+			name: "relative xdg",
+			env: map[string]string{
+				"XDG_CONFIG_HOME": "config",
+				"XDG_DATA_HOME":   "data",
+				"XDG_CACHE_HOME":  "cache",
+			},
+			config:   filepath.Join(home, ".config", appDir, "config.yaml"),
+			database: filepath.Join(home, ".local", "share", appDir, "data.db"),
+			cache:    filepath.Join(home, ".cache", appDir, "cache.db"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setHome(t, home)
+			for _, name := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := DefaultConfigPath(); got != tt.config {
+				t.Errorf("DefaultConfigPath() = %q, want %q", got, tt.config)
+			}
+			if got := DefaultDatabasePath(); got != tt.database {
+				t.Errorf("DefaultDatabasePath() = %q, want %q", got, tt.database)
+			}
+			if got := DefaultCachePath(); got != tt.cache {
+				t.Errorf("DefaultCachePath() = %q, want %q", got, tt.cache)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Windows has no XDG directories: the config roams with the profile in
+// %APPDATA%, while the database and cache stay on the machine in
+// %LOCALAPPDATA% (where os.UserCacheDir puts caches too). XDG variables
+// still win when set, e.g. under MSYS2.
+
+func configHome() string {
+	return knownFolder("XDG_CONFIG_HOME", "APPDATA", filepath.Join("AppData", "Roaming"))
+}
+
+func dataHome() string {
+	return knownFolder("XDG_DATA_HOME", "LOCALAPPDATA", filepath.Join("AppData", "Local"))
+}
+
+func cacheHome() string {
+	return knownFolder("XDG_CACHE_HOME", "LOCALAPPDATA", filepath.Join("AppData", "Local"))
+}
+
+// knownFolder returns $xdg or $env when set to an absolute path, falling
+// back to fallback under the user's profile
+func knownFolder(xdg, env, fallback string) string {
+	for _, name := range []string{xdg, env} {
+		if dir := os.Getenv(name); filepath.IsAbs(dir) {
+			return dir
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, fallback)
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExtractRule says where a site keeps an article's text, for sites whose
+// feeds carry only a teaser. Each field is a CSS selector.
+type ExtractRule struct {
+	// Content selects the article body; every match is kept, in order
+	Content string `yaml:"content"`
+	Author  string `yaml:"author,omitempty"`
+	// Date selects the publication date, read from a datetime or content
+	// attribute or else the element's text
+	Date string `yaml:"date,omitempty"`
+	// Remove lists elements dropped from the content, like share buttons
+	Remove []string `yaml:"remove,omitempty"`
+}
+
+// Rule returns the extraction rule for an article's domain, also trying
+// its parent domains so a rule for example.com covers blog.example.com
+func (f *FetchConfig) Rule(domain string) (ExtractRule, bool) {
+	for domain != "" {
+		if rule, ok := f.Rules[domain]; ok {
+			return rule, true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			break
+		}
+		domain = parent
+	}
+	return ExtractRule{}, false
+}
+
+// rulesPath returns the rules file, by default rules.yaml next to the
+// config file. A relative path is relative to the config file's directory.
+func (c *Config) rulesPath(configPath string) string {
+	path := expandPath(c.Fetch.RulesFile)
+	if path == "" {
+		path = "rules.yaml"
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(configPath), path)
+	}
+	return path
+}
+
+// loadRules reads the extraction rules, a mapping of domains to rules. The
+// default rules file is optional; one named in the config must exist.
+func (c *Config) loadRules(configPath string) error {
+	path := c.rulesPath(configPath)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && c.Fetch.RulesFile == "" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading rules file: %w", err)
+	}
+
+	var rules map[string]ExtractRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("parsing rules file %s: %w", path, err)
+	}
+	c.Fetch.Rules = make(map[string]ExtractRule, len(rules))
+	for domain, rule := range rules {
+		c.Fetch.Rules[strings.TrimPrefix(strings.ToLower(domain), "www.")] = rule
+	}
+	return nil
+}
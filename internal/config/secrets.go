@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/internal/keyring"
+	"gopkg.in/yaml.v3"
+)
+
+// secretPrefix marks a value looked up in the secrets file
+const secretPrefix = "secret:"
+
+// keyringPrefix marks a value looked up in the system keyring
+const keyringPrefix = "keyring:"
+
+// envRef matches a value that is a whole environment variable reference,
+// $NAME or ${NAME}
+var envRef = regexp.MustCompile(`^\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))$`)
+
+// secretField is a setting that may hold a reference instead of a value
+type secretField struct {
+	path  string
+	value *string
+}
+
+// secretFields lists the settings holding tokens and passwords. New API keys
+// belong here so they can be kept out of the main config file too.
+func (c *Config) secretFields() []secretField {
+	fields := []secretField{
+		{"database.encryption_key", &c.Database.EncryptionKey},
+		{"raindrop.api_token", &c.Raindrop.APIToken},
+		{"nextcloud.password", &c.Nextcloud.Password},
+		{"newsletters.password", &c.Newsletters.Password},
+		{"notify.matrix.access_token", &c.Notify.Matrix.AccessToken},
+		{"notify.telegram.bot_token", &c.Notify.Telegram.BotToken},
+	}
+	for i := range c.Feeds {
+		fields = append(fields, secretField{fmt.Sprintf("feeds[%d].password", i), &c.Feeds[i].Password})
+	}
+	for i := range c.Save.Targets {
+		fields = append(fields,
+			secretField{fmt.Sprintf("save.targets[%d].token", i), &c.Save.Targets[i].Token},
+			secretField{fmt.Sprintf("save.targets[%d].password", i), &c.Save.Targets[i].Password},
+		)
+	}
+	return fields
+}
+
+// secretHeaders lists the header maps, whose values often carry credentials
+func (c *Config) secretHeaders() map[string]map[string]string {
+	headers := map[string]map[string]string{"webhook.headers": c.Webhook.Headers}
+	for i := range c.Feeds {
+		headers[fmt.Sprintf("feeds[%d].headers", i)] = c.Feeds[i].Headers
+	}
+	return headers
+}
+
+// resolveSecrets replaces references in secret settings with their values:
+// $NAME or ${NAME} reads an environment variable, secret:NAME an entry of
+// the secrets file, keyring:NAME a secret stored with newsreadr auth set.
+// References that can't be resolved are recorded for Validate to report.
+func (c *Config) resolveSecrets(configPath string) {
+	v := &validator{lines: c.lines}
+	var secrets map[string]string
+	secretsLoaded := false
+
+	resolve := func(field, value string) string {
+		if m := envRef.FindStringSubmatch(value); m != nil {
+			name := m[1] + m[2]
+			env, ok := os.LookupEnv(name)
+			if !ok {
+				v.add(field, "environment variable %s is not set", name)
+			}
+			return env
+		}
+		if name, ok := strings.CutPrefix(value, keyringPrefix); ok {
+			secret, err := keyring.Get(name)
+			if errors.Is(err, keyring.ErrNotFound) {
+				v.add(field, "%q is not in the keyring (run newsreadr auth set %s)", name, name)
+			} else if err != nil {
+				v.add(field, "%v", err)
+			}
+			return secret
+		}
+		if !strings.HasPrefix(value, secretPrefix) {
+			return value
+		}
+		if !secretsLoaded {
+			secretsLoaded = true
+			var err error
+			if secrets, err = loadSecrets(c.secretsPath(configPath)); err != nil {
+				v.add("secrets_file", "%v", err)
+			}
+		}
+		name := strings.TrimPrefix(value, secretPrefix)
+		secret, ok := secrets[name]
+		if !ok && secrets != nil {
+			v.add(field, "%q is not in %s", name, c.secretsPath(configPath))
+		}
+		return secret
+	}
+
+	for _, f := range c.secretFields() {
+		*f.value = resolve(f.path, *f.value)
+	}
+	for path, headers := range c.secretHeaders() {
+		for name, value := range headers {
+			headers[name] = resolve(path+"."+name, value)
+		}
+	}
+	c.secretProblems = v.problems
+}
+
+// secretsPath returns the secrets file, by default secrets.yaml next to the
+// config file. A relative path is relative to the config file's directory.
+func (c *Config) secretsPath(configPath string) string {
+	path := expandPath(c.SecretsFile)
+	if path == "" {
+		path = "secrets.yaml"
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(configPath), path)
+	}
+	return path
+}
+
+// loadSecrets reads a secrets file of name: value pairs. It refuses files
+// other users can read, as ssh does for private keys.
+func loadSecrets(path string) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets file: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("%s is accessible by other users (run chmod 600 %s)", path, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets file: %w", err)
+	}
+	secrets := map[string]string{}
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("parsing secrets file %s: %w", path, err)
+	}
+	return secrets, nil
+}
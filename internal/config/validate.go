@@ -0,0 +1,393 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/andybalholm/cascadia"
+	"gopkg.in/yaml.v3"
+)
+
+// maxFeedWeight bounds feed weights; beyond it one feed drowns out the rest
+const maxFeedWeight = 10
+
+// Problem is one invalid setting. Line is the line in the config file it
+// was found on, or 0 when unknown.
+type Problem struct {
+	Field   string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", p.Line, p.Field, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Field, p.Message)
+}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid configuration: " + e.Problems[0].String()
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "invalid configuration (%d problems):", len(e.Problems))
+	for _, p := range e.Problems {
+		s.WriteString("\n  ")
+		s.WriteString(p.String())
+	}
+	return s.String()
+}
+
+// validator collects problems, looking up the line of each field
+type validator struct {
+	lines    map[string]int
+	problems []Problem
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Field: field, Line: v.line(field), Message: fmt.Sprintf(format, args...)})
+}
+
+// line returns the line of a field, or of its closest parent when the field
+// itself isn't in the file
+func (v *validator) line(field string) int {
+	for field != "" {
+		if line, ok := v.lines[field]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(field, ".[")
+		if i < 0 {
+			break
+		}
+		field = field[:i]
+	}
+	return 0
+}
+
+// checkURL reports a URL that doesn't parse or lacks one of the schemes
+func (v *validator) checkURL(field, value string, schemes ...string) {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		v.add(field, "%q is not a valid URL (include the scheme, e.g. https://)", value)
+		return
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			return
+		}
+	}
+	v.add(field, "unsupported scheme %q (want %s)", u.Scheme, strings.Join(schemes, ", "))
+}
+
+func (v *validator) checkOneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, "unknown value %q (want %s)", value, strings.Join(allowed, " or "))
+}
+
+func (v *validator) checkNotNegative(field string, value int) {
+	if value < 0 {
+		v.add(field, "must not be negative, got %d", value)
+	}
+}
+
+// checkTimeout checks a duration limiting how long something may take
+func (v *validator) checkTimeout(field, value string) {
+	if d, err := time.ParseDuration(value); err != nil {
+		v.add(field, "%q is not a duration (e.g. 30s, 2m)", value)
+	} else if d <= 0 {
+		v.add(field, "must be positive")
+	}
+}
+
+// maxBands bounds how many stars a score can show
+const maxBands = 9
+
+// checkBands checks score bands rise within (0, 1]
+func (v *validator) checkBands(field string, bands []float64) {
+	if len(bands) == 0 || len(bands) > maxBands {
+		v.add(field, "must list 1 to %d bands, got %d", maxBands, len(bands))
+		return
+	}
+	for i, b := range bands {
+		switch {
+		case b <= 0 || b > 1:
+			v.add(fmt.Sprintf("%s[%d]", field, i), "must be above 0 and at most 1, got %g", b)
+		case i > 0 && b <= bands[i-1]:
+			v.add(fmt.Sprintf("%s[%d]", field, i), "must be above the band before, got %g after %g", b, bands[i-1])
+		}
+	}
+}
+
+// maxInterestWeight bounds interest weights; beyond it one interest decides every score
+const maxInterestWeight = 5
+
+func (v *validator) checkInterests(field string, interests []InterestConfig) {
+	for i, interest := range interests {
+		item := fmt.Sprintf("%s[%d]", field, i)
+		if strings.TrimSpace(interest.Description) == "" {
+			v.add(item, "empty interest")
+		}
+		if interest.Weight < 0 || interest.Weight > maxInterestWeight {
+			v.add(item+".weight", "must be between 0 and %d, got %g", maxInterestWeight, interest.Weight)
+		}
+	}
+}
+
+// checkRules reports extraction rules without content or with selectors
+// that don't parse, under fetch.rules_file as they live in their own file
+func (v *validator) checkRules(rules map[string]ExtractRule) {
+	domains := make([]string, 0, len(rules))
+	for domain := range rules {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	for _, domain := range domains {
+		rule := rules[domain]
+		if rule.Content == "" {
+			v.add("fetch.rules_file", "%s: content selector required", domain)
+		}
+		selectors := [][2]string{{"content", rule.Content}, {"author", rule.Author}, {"date", rule.Date}}
+		for i, r := range rule.Remove {
+			selectors = append(selectors, [2]string{fmt.Sprintf("remove[%d]", i), r})
+		}
+		for _, s := range selectors {
+			if s[1] == "" {
+				continue
+			}
+			if _, err := cascadia.ParseGroup(s[1]); err != nil {
+				v.add("fetch.rules_file", "%s: invalid %s selector %q: %v", domain, s[0], s[1], err)
+			}
+		}
+	}
+}
+
+// Validate checks the configuration and reports every problem at once
+func (c *Config) Validate() error {
+	v := &validator{lines: c.lines, problems: append([]Problem(nil), c.secretProblems...)}
+
+	seen := make(map[string]bool, len(c.Feeds))
+	for i, f := range c.Feeds {
+		field := fmt.Sprintf("feeds[%d]", i)
+		if f.URL == "" {
+			v.add(field+".url", "missing")
+		} else {
+			v.checkURL(field+".url", f.URL, "http", "https", "mastodon", "bluesky", "github", "watch+http", "watch+https")
+			if seen[f.URL] {
+				v.add(field+".url", "%s is listed more than once", f.URL)
+			}
+			seen[f.URL] = true
+		}
+		if f.Selector != "" {
+			if _, err := cascadia.ParseGroup(f.Selector); err != nil {
+				v.add(field+".selector", "%q is not a CSS selector: %v", f.Selector, err)
+			}
+		}
+		if f.Weight < 0 || f.Weight > maxFeedWeight {
+			v.add(field+".weight", "must be between 0 and %d, got %g", maxFeedWeight, f.Weight)
+		}
+		if f.Timeout != "" {
+			v.checkTimeout(field+".timeout", f.Timeout)
+		}
+	}
+
+	v.checkOneOf("feeds_manage_via", c.FeedsManageVia, "config", "db", "both")
+
+	v.checkOneOf("interests_manage_via", c.InterestsManageVia, "config", "db", "both")
+	v.checkInterests("interests", c.Interests)
+	v.checkInterests("avoid_interests", c.AvoidInterests)
+	for group, interests := range c.InterestGroups {
+		v.checkInterests("interest_groups."+group, interests)
+	}
+
+	v.checkURL("ollama.host", c.Ollama.Host, "http", "https")
+	if c.Ollama.Workers > 64 {
+		v.add("ollama.workers", "%d concurrent requests is more than any Ollama server handles", c.Ollama.Workers)
+	}
+
+	if d, err := time.ParseDuration(c.UI.RefreshInterval); err != nil {
+		v.add("ui.refresh_interval", "%q is not a duration (e.g. 15m, 1h)", c.UI.RefreshInterval)
+	} else if d <= 0 {
+		v.add("ui.refresh_interval", "must be positive")
+	}
+	v.checkNotNegative("ui.article_max_age_days", c.UI.ArticleMaxAgeDays)
+	v.checkNotNegative("ui.briefing_articles", c.UI.BriefingArticles)
+	v.checkOneOf("ui.list_mode", c.UI.ListMode, "detailed", "compact")
+	v.checkOneOf("ui.group_by", c.UI.GroupBy, "none", "feed", "date")
+	if _, ok := styles.Registry[c.UI.CodeTheme]; !ok && c.UI.CodeTheme != "auto" && c.UI.CodeTheme != "none" {
+		v.add("ui.code_theme", "%q is not auto, none or a chroma style (e.g. monokai, github, dracula)", c.UI.CodeTheme)
+	}
+	if _, err := template.New("status_bar").Parse(c.UI.StatusBar); err != nil {
+		v.add("ui.status_bar", "invalid template: %v", err)
+	}
+
+	if c.HTTP.Proxy != "" {
+		v.checkURL("http.proxy", c.HTTP.Proxy, "http", "https", "socks5", "socks5h")
+	}
+	v.checkNotNegative("http.requests_per_minute", c.HTTP.RequestsPerMinute)
+	v.checkNotNegative("http.max_retries", c.HTTP.MaxRetries)
+	v.checkNotNegative("database.auto_vacuum_mb", c.Database.AutoVacuumMB)
+	v.checkNotNegative("fetch.silent_days", c.Fetch.SilentDays)
+	v.checkRules(c.Fetch.Rules)
+	v.checkOneOf("fetch.archive", c.Fetch.Archive, "wayback", "archive.today", "none")
+	v.checkTimeout("fetch.timeout", c.Fetch.Timeout)
+
+	v.checkOneOf("scoring.backend", c.Scoring.Backend, "ai", "script")
+	if c.Scoring.Backend == "script" && c.Scoring.Script == "" {
+		v.add("scoring.script", "required when scoring.backend is script")
+	}
+	v.checkOneOf("scoring.aggregate", c.Scoring.Aggregate, "max", "mean")
+	if c.Scoring.ChunkWords < 0 {
+		v.add("scoring.chunk_words", "must be positive, got %d", c.Scoring.ChunkWords)
+	}
+	if c.Scoring.MaxChunks < 0 {
+		v.add("scoring.max_chunks", "must be positive, got %d", c.Scoring.MaxChunks)
+	}
+	v.checkOneOf("scoring.display", c.Scoring.Display, "bands", "raw")
+	v.checkOneOf("scoring.calibration", c.Scoring.Calibration, "minmax", "zscore")
+	v.checkBands("scoring.bands", c.Scoring.Bands)
+
+	if c.Notify.Threshold < 0 || c.Notify.Threshold > 1 {
+		v.add("notify.threshold", "must be between 0 and 1, got %g", c.Notify.Threshold)
+	}
+	v.checkNotNegative("notify.max_per_hour", c.Notify.MaxPerHour)
+	if c.Notify.Matrix.Homeserver != "" {
+		v.checkURL("notify.matrix.homeserver", c.Notify.Matrix.Homeserver, "http", "https")
+	}
+	if c.Metrics.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Listen); err != nil {
+			v.add("metrics.listen", "%q is not a host:port address (e.g. 127.0.0.1:9464)", c.Metrics.Listen)
+		}
+	}
+	if c.Nextcloud.URL != "" {
+		v.checkURL("nextcloud.url", c.Nextcloud.URL, "http", "https")
+		if c.Nextcloud.Username == "" || c.Nextcloud.Password == "" {
+			v.add("nextcloud.username", "username and password required to sync with Nextcloud")
+		}
+	}
+	if c.Newsletters.Host != "" && (c.Newsletters.Username == "" || c.Newsletters.Password == "") {
+		v.add("newsletters.username", "username and password required to read newsletters")
+	}
+	if c.Webhook.URL != "" {
+		v.checkURL("webhook.url", c.Webhook.URL, "http", "https")
+	}
+
+	v.checkOneOf("mute.action", c.Mute.Action, "drop", "read")
+	for i, p := range c.Mute.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			v.add(fmt.Sprintf("mute.patterns[%d]", i), "invalid regular expression: %v", err)
+		}
+	}
+
+	for i, t := range c.Save.Targets {
+		field := fmt.Sprintf("save.targets[%d]", i)
+		if t.Name == "" {
+			v.add(field+".name", "required")
+		}
+		switch t.Type {
+		case "command":
+			if t.Command == "" {
+				v.add(field+".command", "required for command targets")
+			}
+		case "linkding", "shiori":
+			if t.URL == "" {
+				v.add(field+".url", "required for %s targets", t.Type)
+			} else {
+				v.checkURL(field+".url", t.URL, "http", "https")
+			}
+			if t.Type == "linkding" && t.Token == "" {
+				v.add(field+".token", "required for linkding targets")
+			}
+			if t.Type == "shiori" && (t.Username == "" || t.Password == "") {
+				v.add(field+".username", "username and password required for shiori targets")
+			}
+		default:
+			v.add(field+".type", "unknown value %q (want command, linkding or shiori)", t.Type)
+		}
+	}
+
+	if c.Open.Browser != "" {
+		if _, err := c.Open.BrowserArgs(""); err != nil {
+			v.add("open.browser", "%q is not a command: %v", c.Open.Browser, err)
+		}
+	}
+	for domain, tmpl := range c.Open.Rewrites {
+		if _, err := template.New(domain).Parse(tmpl); err != nil {
+			v.add("open.rewrites."+domain, "invalid template: %v", err)
+		}
+	}
+
+	for i, t := range c.Share.Targets {
+		field := fmt.Sprintf("share.targets[%d]", i)
+		switch t.Type {
+		case "clipboard":
+			if t.Template == "" {
+				v.add(field+".template", "required for clipboard targets")
+			}
+		case "command":
+			if t.Command == "" {
+				v.add(field+".command", "required for command targets")
+			} else if strings.Contains(t.Command, "{{") {
+				v.add(field+".command", "commands are not templates: use $NEWSREADR_TITLE, $NEWSREADR_URL, ...")
+			}
+		default:
+			v.add(field+".type", "unknown value %q (want clipboard or command)", t.Type)
+		}
+	}
+
+	if len(v.problems) > 0 {
+		sort.SliceStable(v.problems, func(i, j int) bool { return v.problems[i].Line < v.problems[j].Line })
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// nodeLines maps each field path in a parsed YAML document, such as
+// "feeds[2].url", to the line it is on
+func nodeLines(doc *yaml.Node) map[string]int {
+	lines := make(map[string]int)
+	var walk func(n *yaml.Node, path string)
+	walk = func(n *yaml.Node, path string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, value := n.Content[i], n.Content[i+1]
+				field := key.Value
+				if path != "" {
+					field = path + "." + key.Value
+				}
+				lines[field] = key.Line
+				walk(value, field)
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				field := fmt.Sprintf("%s[%d]", path, i)
+				lines[field] = c.Line
+				walk(c, field)
+			}
+		}
+	}
+	walk(doc, "")
+	return lines
+}
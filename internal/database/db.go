@@ -2,43 +2,116 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
 
+// MemoryPath as a database or cache path keeps it in a temporary file that
+// is deleted on Close, so tests and demos leave the user's data alone
+const MemoryPath = ":memory:"
+
 type DB struct {
 	*sql.DB
+	// cache holds the embedding cache, kept in its own file so it can be
+	// deleted without losing anything but time
+	cache *sql.DB
+	// tempDir holds the files of MemoryPath databases
+	tempDir string
+	// crypter encrypts article text once Unlock was given a passphrase
+	crypter *crypter
 }
 
-// New creates a new database connection and initializes schema
-func New(dbPath string) (*DB, error) {
+// New creates a new database connection and initializes schema. Cached
+// embeddings are stored in a separate database at cachePath. Either path
+// may be MemoryPath.
+func New(dbPath, cachePath string) (*DB, error) {
+	var tempDir string
+	if dbPath == MemoryPath || cachePath == MemoryPath {
+		// A real :memory: database would be separate for each pooled
+		// connection, so a temporary file stands in for it
+		dir, err := os.MkdirTemp("", "newsreadr-")
+		if err != nil {
+			return nil, fmt.Errorf("creating temporary database: %w", err)
+		}
+		tempDir = dir
+		if dbPath == MemoryPath {
+			dbPath = filepath.Join(dir, "data.db")
+		}
+		if cachePath == MemoryPath {
+			cachePath = filepath.Join(dir, "cache.db")
+		}
+	}
+
+	db, err := open(dbPath)
+	if err != nil {
+		removeTemp(tempDir)
+		return nil, err
+	}
+	cache, err := open(cachePath)
+	if err != nil {
+		db.Close()
+		removeTemp(tempDir)
+		return nil, err
+	}
+
+	d := &DB{DB: db, cache: cache, tempDir: tempDir}
+	if err := d.initSchema(); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("initializing schema: %w", err)
+	}
+
+	return d, nil
+}
+
+// open opens a SQLite file, creating its directory if needed. The path
+// must not be empty: SQLite would take the pragmas after it for the file
+// name and open a stray file without them.
+func open(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, errors.New("opening database: no path given")
+	}
+
 	// Ensure directory exists
-	dir := filepath.Dir(dbPath)
+	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("creating database directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	// Pragmas in the DSN apply to every pooled connection: foreign keys are
+	// enforced, and concurrent writers wait for each other instead of failing
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
-
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+	if err := db.Ping(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("enabling foreign keys: %w", err)
+		return nil, fmt.Errorf("opening database %s: %w", path, err)
 	}
+	return db, nil
+}
 
-	d := &DB{db}
-	if err := d.initSchema(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("initializing schema: %w", err)
+// Close closes the database and the embedding cache, deleting them when
+// they were temporary
+func (db *DB) Close() error {
+	cacheErr := db.cache.Close()
+	err := db.DB.Close()
+	removeTemp(db.tempDir)
+	if err != nil {
+		return err
 	}
+	return cacheErr
+}
 
-	return d, nil
+// removeTemp deletes the directory of temporary databases, if any
+func removeTemp(dir string) {
+	if dir != "" {
+		os.RemoveAll(dir)
+	}
 }
 
 // initSchema creates database tables if they don't exist
@@ -49,7 +122,12 @@ func (db *DB) initSchema() error {
 			url TEXT NOT NULL UNIQUE,
 			name TEXT NOT NULL,
 			enabled INTEGER NOT NULL DEFAULT 1,
-			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_fetched_at TIMESTAMP,
+			last_success_at TIMESTAMP,
+			last_item_at TIMESTAMP,
+			last_status INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT ''
 		);
 
 		CREATE TABLE IF NOT EXISTS articles (
@@ -62,14 +140,34 @@ func (db *DB) initSchema() error {
 			published_at TIMESTAMP NOT NULL,
 			fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			relevance_score REAL DEFAULT 0,
+			snoozed_until TIMESTAMP,
+			queued_at TIMESTAMP,
+			tags TEXT NOT NULL DEFAULT '',
+			scored_at TIMESTAMP,
+			author TEXT NOT NULL DEFAULT '',
+			guid TEXT NOT NULL DEFAULT '',
+			comments_url TEXT NOT NULL DEFAULT '',
+			remote_id INTEGER,
+			deleted_at TIMESTAMP,
+			offline_at TIMESTAMP,
 			FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
 		);
 
+		CREATE TABLE IF NOT EXISTS article_embeddings (
+			article_id INTEGER PRIMARY KEY,
+			model TEXT NOT NULL,
+			embedding BLOB NOT NULL,
+			FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+		);
+
 		CREATE TABLE IF NOT EXISTS user_interests (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			description TEXT NOT NULL,
 			weight REAL NOT NULL DEFAULT 1.0,
-			embedding BLOB
+			embedding BLOB,
+			group_name TEXT NOT NULL DEFAULT '',
+			avoid INTEGER NOT NULL DEFAULT 0,
+			archived_at TIMESTAMP
 		);
 
 		CREATE TABLE IF NOT EXISTS read_articles (
@@ -78,7 +176,116 @@ func (db *DB) initSchema() error {
 			FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
 		);
 
-		CREATE INDEX IF NOT EXISTS idx_articles_published_at ON articles(published_at);
+		-- Snapshots of read and starred articles, kept after the article
+		-- itself is deleted so history can be exported and analyzed
+		CREATE TABLE IF NOT EXISTS read_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			article_id INTEGER NOT NULL,
+			feed_id INTEGER NOT NULL,
+			feed_name TEXT NOT NULL DEFAULT '',
+			title TEXT NOT NULL,
+			url TEXT NOT NULL,
+			relevance_score REAL DEFAULT 0,
+			published_at TIMESTAMP NOT NULL,
+			read_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS starred_articles (
+			url TEXT PRIMARY KEY,
+			article_id INTEGER NOT NULL,
+			feed_id INTEGER NOT NULL,
+			feed_name TEXT NOT NULL DEFAULT '',
+			title TEXT NOT NULL,
+			relevance_score REAL DEFAULT 0,
+			published_at TIMESTAMP NOT NULL,
+			starred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Articles opened in the browser, as a stronger signal than reading
+		-- them in the terminal. Like read_history it outlives the article.
+		CREATE TABLE IF NOT EXISTS article_opens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			article_id INTEGER NOT NULL,
+			feed_name TEXT NOT NULL DEFAULT '',
+			title TEXT NOT NULL,
+			url TEXT NOT NULL,
+			opened_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Links already exported to Raindrop, so periodic exports skip them
+		CREATE TABLE IF NOT EXISTS raindrop_exports (
+			url TEXT PRIMARY KEY,
+			exported_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Remote IDs of synced articles read here but not yet marked read
+		-- in Nextcloud News
+		CREATE TABLE IF NOT EXISTS remote_pending_reads (
+			remote_id INTEGER PRIMARY KEY
+		);
+
+		CREATE TABLE IF NOT EXISTS mutes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			value TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (kind, value)
+		);
+
+		-- Images of queued and starred articles downloaded for reading
+		-- offline. Rows outlive their article until evicted, so the files
+		-- are deleted along with them.
+		CREATE TABLE IF NOT EXISTS offline_images (
+			url TEXT PRIMARY KEY,
+			article_id INTEGER NOT NULL,
+			path TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			cached_at TIMESTAMP NOT NULL
+		);
+
+		-- The text of watched pages when last checked, to tell what changed
+		CREATE TABLE IF NOT EXISTS page_snapshots (
+			feed_id INTEGER PRIMARY KEY,
+			content TEXT NOT NULL,
+			checked_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+		);
+
+		-- Where the last session of the reader left off
+		CREATE TABLE IF NOT EXISTS session_state (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			last_article_id INTEGER NOT NULL,
+			ended_at TIMESTAMP NOT NULL
+		);
+
+		-- People, companies and projects articles name, and the articles
+		-- looked at for them, named or not
+		CREATE TABLE IF NOT EXISTS article_entities (
+			article_id INTEGER NOT NULL,
+			kind TEXT NOT NULL,
+			name TEXT NOT NULL,
+			PRIMARY KEY (article_id, kind, name),
+			FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS entity_extractions (
+			article_id INTEGER PRIMARY KEY,
+			extracted_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_read_history_read_at ON read_history(read_at);
+		CREATE INDEX IF NOT EXISTS idx_article_entities_name ON article_entities(name);
+		CREATE TABLE IF NOT EXISTS encryption (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			salt BLOB NOT NULL,
+			key_check TEXT NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_article_opens_url ON article_opens(url);
+		-- Covers the unread list's date range and read check without reading
+		-- article rows; it replaces an index on published_at alone
+		CREATE INDEX IF NOT EXISTS idx_articles_unread ON articles(published_at, relevance_score, id);
+		DROP INDEX IF EXISTS idx_articles_published_at;
 		CREATE INDEX IF NOT EXISTS idx_articles_relevance_score ON articles(relevance_score);
 		CREATE INDEX IF NOT EXISTS idx_articles_feed_id ON articles(feed_id);
 	`
@@ -87,5 +294,157 @@ func (db *DB) initSchema() error {
 		return fmt.Errorf("creating schema: %w", err)
 	}
 
+	cacheSchema := `
+		CREATE TABLE IF NOT EXISTS embeddings (
+			hash TEXT NOT NULL,
+			model TEXT NOT NULL,
+			embedding BLOB NOT NULL,
+			used_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (hash, model)
+		);
+	`
+	if _, err := db.cache.Exec(cacheSchema); err != nil {
+		return fmt.Errorf("creating cache schema: %w", err)
+	}
+
+	if err := db.migrate(); err != nil {
+		return err
+	}
+	return db.moveEmbeddingCache()
+}
+
+// moveEmbeddingCache moves cached embeddings out of the main database,
+// where older versions kept them, into the cache database
+func (db *DB) moveEmbeddingCache() error {
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'embeddings'").Scan(&exists); err != nil {
+		return fmt.Errorf("checking for embedding cache: %w", err)
+	}
+	if exists == 0 {
+		return nil
+	}
+
+	rows, err := db.Query("SELECT hash, model, embedding, used_at FROM embeddings")
+	if err != nil {
+		return fmt.Errorf("reading embedding cache: %w", err)
+	}
+	defer rows.Close()
+
+	tx, err := db.cache.Begin()
+	if err != nil {
+		return fmt.Errorf("moving embedding cache: %w", err)
+	}
+	defer tx.Rollback()
+	for rows.Next() {
+		var (
+			hash, model string
+			embedding   []byte
+			usedAt      time.Time
+		)
+		if err := rows.Scan(&hash, &model, &embedding, &usedAt); err != nil {
+			return fmt.Errorf("scanning cached embedding: %w", err)
+		}
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO embeddings (hash, model, embedding, used_at) VALUES (?, ?, ?, ?)",
+			hash, model, embedding, usedAt,
+		); err != nil {
+			return fmt.Errorf("moving cached embedding: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading embedding cache: %w", err)
+	}
+	rows.Close()
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("moving embedding cache: %w", err)
+	}
+
+	if _, err := db.Exec("DROP TABLE embeddings"); err != nil {
+		return fmt.Errorf("dropping old embedding cache: %w", err)
+	}
+	return nil
+}
+
+// columnMigrations lists columns added after a table was first released.
+// Fresh databases get them from the schema above; older ones are altered.
+var columnMigrations = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"articles", "snoozed_until", "TIMESTAMP"},
+	{"articles", "queued_at", "TIMESTAMP"},
+	{"articles", "tags", "TEXT NOT NULL DEFAULT ''"},
+	{"feeds", "last_fetched_at", "TIMESTAMP"},
+	{"feeds", "last_success_at", "TIMESTAMP"},
+	{"feeds", "last_item_at", "TIMESTAMP"},
+	{"feeds", "last_status", "INTEGER NOT NULL DEFAULT 0"},
+	{"feeds", "last_error", "TEXT NOT NULL DEFAULT ''"},
+	{"user_interests", "group_name", "TEXT NOT NULL DEFAULT ''"},
+	{"user_interests", "avoid", "INTEGER NOT NULL DEFAULT 0"},
+	{"articles", "scored_at", "TIMESTAMP"},
+	{"user_interests", "archived_at", "TIMESTAMP"},
+	{"articles", "author", "TEXT NOT NULL DEFAULT ''"},
+	{"articles", "guid", "TEXT NOT NULL DEFAULT ''"},
+	{"articles", "comments_url", "TEXT NOT NULL DEFAULT ''"},
+	{"articles", "remote_id", "INTEGER"},
+	{"articles", "deleted_at", "TIMESTAMP"},
+	{"articles", "offline_at", "TIMESTAMP"},
+}
+
+// columnBackfills fills a column from existing data right after it is added,
+// keyed by "table.column"
+var columnBackfills = map[string]string{
+	// Before scored_at, a positive score was the only sign of scoring
+	"articles.scored_at": "UPDATE articles SET scored_at = fetched_at WHERE relevance_score > 0",
+}
+
+// migrate adds any missing columns to tables created by older versions
+func (db *DB) migrate() error {
+	for _, cm := range columnMigrations {
+		exists, err := db.columnExists(cm.table, cm.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", cm.table, cm.column, cm.definition)
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("adding column %s.%s: %w", cm.table, cm.column, err)
+		}
+		if backfill, ok := columnBackfills[cm.table+"."+cm.column]; ok {
+			if _, err := db.Exec(backfill); err != nil {
+				return fmt.Errorf("filling column %s.%s: %w", cm.table, cm.column, err)
+			}
+		}
+	}
 	return nil
 }
+
+// columnExists reports whether a table has the named column
+func (db *DB) columnExists(table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("reading table info for %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("scanning table info: %w", err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetCachedEmbedding returns the embedding stored for a text hash and
+// model, or nil when there is none, and marks it as used
+func (db *DB) GetCachedEmbedding(hash, model string) ([]byte, error) {
+	var embedding []byte
+	err := db.cache.QueryRow("SELECT embedding FROM embeddings WHERE hash = ? AND model = ?", hash, model).Scan(&embedding)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying embedding cache: %w", err)
+	}
+
+	if _, err := db.cache.Exec("UPDATE embeddings SET used_at = ? WHERE hash = ? AND model = ?", time.Now(), hash, model); err != nil {
+		return nil, fmt.Errorf("touching cached embedding: %w", err)
+	}
+	return embedding, nil
+}
+
+// CacheEmbedding stores an embedding for a text hash and model
+func (db *DB) CacheEmbedding(hash, model string, embedding []byte) error {
+	_, err := db.cache.Exec(
+		"INSERT OR REPLACE INTO embeddings (hash, model, embedding, used_at) VALUES (?, ?, ?, ?)",
+		hash, model, embedding, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("caching embedding: %w", err)
+	}
+	return nil
+}
+
+// PruneEmbeddings deletes cached embeddings not used within maxAge and
+// returns how many were removed. A maxAge of zero or less keeps them all.
+func (db *DB) PruneEmbeddings(maxAge time.Duration) (int64, error) {
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	result, err := db.cache.Exec("DELETE FROM embeddings WHERE used_at < ?", time.Now().Add(-maxAge))
+	if err != nil {
+		return 0, fmt.Errorf("pruning embedding cache: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ArticleEmbedding is the stored embedding of an article
+type ArticleEmbedding struct {
+	ArticleID int64
+	Embedding []byte
+}
+
+// SaveArticleEmbedding stores the embedding an article was scored with
+func (db *DB) SaveArticleEmbedding(articleID int64, model string, embedding []byte) error {
+	_, err := db.Exec(
+		"INSERT OR REPLACE INTO article_embeddings (article_id, model, embedding) VALUES (?, ?, ?)",
+		articleID, model, embedding,
+	)
+	if err != nil {
+		return fmt.Errorf("saving article embedding: %w", err)
+	}
+	return nil
+}
+
+// GetArticleEmbedding returns an article's stored embedding for a model, or
+// nil when there is none
+func (db *DB) GetArticleEmbedding(articleID int64, model string) ([]byte, error) {
+	var embedding []byte
+	err := db.QueryRow(
+		"SELECT embedding FROM article_embeddings WHERE article_id = ? AND model = ?",
+		articleID, model,
+	).Scan(&embedding)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying article embedding: %w", err)
+	}
+	return embedding, nil
+}
+
+// GetUnreadArticleEmbeddings returns the stored embeddings of all unread
+// articles for a model
+func (db *DB) GetUnreadArticleEmbeddings(model string) ([]ArticleEmbedding, error) {
+	rows, err := db.Query(`
+		SELECT e.article_id, e.embedding
+		FROM article_embeddings e
+		JOIN articles a ON a.id = e.article_id
+		LEFT JOIN read_articles r ON r.article_id = e.article_id
+		WHERE e.model = ? AND r.article_id IS NULL AND a.deleted_at IS NULL
+	`, model)
+	if err != nil {
+		return nil, fmt.Errorf("querying article embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var embeddings []ArticleEmbedding
+	for rows.Next() {
+		var e ArticleEmbedding
+		if err := rows.Scan(&e.ArticleID, &e.Embedding); err != nil {
+			return nil, fmt.Errorf("scanning article embedding: %w", err)
+		}
+		embeddings = append(embeddings, e)
+	}
+	return embeddings, rows.Err()
+}
+
+// GetStoredArticleEmbeddings returns the stored embeddings of all articles
+// for a model, read or not, leaving out those in the trash
+func (db *DB) GetStoredArticleEmbeddings(model string) ([]ArticleEmbedding, error) {
+	rows, err := db.Query(`
+		SELECT e.article_id, e.embedding
+		FROM article_embeddings e
+		JOIN articles a ON a.id = e.article_id
+		WHERE e.model = ? AND a.deleted_at IS NULL
+	`, model)
+	if err != nil {
+		return nil, fmt.Errorf("querying article embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var embeddings []ArticleEmbedding
+	for rows.Next() {
+		var e ArticleEmbedding
+		if err := rows.Scan(&e.ArticleID, &e.Embedding); err != nil {
+			return nil, fmt.Errorf("scanning article embedding: %w", err)
+		}
+		embeddings = append(embeddings, e)
+	}
+	return embeddings, rows.Err()
+}
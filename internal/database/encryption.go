@@ -0,0 +1,208 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks a column value encrypted with the database key
+const encryptedPrefix = "enc1:"
+
+// keyIterations is the PBKDF2 cost of deriving the key from the passphrase
+const keyIterations = 600_000
+
+// keyCheck is encrypted with a new key, so a wrong passphrase is detected
+// before anything is written with it
+const keyCheck = "newsreadr"
+
+// ErrWrongKey is returned when the passphrase doesn't match the database
+var ErrWrongKey = errors.New("wrong database encryption key")
+
+// ErrEncrypted is returned when the database holds encrypted articles but
+// no key was given
+var ErrEncrypted = errors.New("database is encrypted: set database.encryption_key")
+
+// ErrNotEncrypted is returned when a key is set for a database that was
+// never encrypted
+var ErrNotEncrypted = errors.New("database is not encrypted yet: run newsreadr db encrypt")
+
+// crypter encrypts article text. A nil *crypter leaves text unencrypted.
+type crypter struct {
+	aead cipher.AEAD
+}
+
+// newCrypter derives an AES-256-GCM key from passphrase and salt
+func newCrypter(passphrase string, salt []byte) (*crypter, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, keyIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("deriving encryption key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &crypter{aead: aead}, nil
+}
+
+// seal encrypts s with a random nonce. Empty text stays empty.
+func (c *crypter) seal(s string) string {
+	if c == nil || s == "" {
+		return s
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	rand.Read(nonce)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(s), nil))
+}
+
+// open decrypts a value written by seal. Values without the prefix were
+// stored before encryption was enabled and are returned as they are.
+func (c *crypter) open(s string) (string, error) {
+	encoded, ok := strings.CutPrefix(s, encryptedPrefix)
+	if !ok {
+		return s, nil
+	}
+	if c == nil {
+		return "", ErrEncrypted
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return "", fmt.Errorf("decrypting article: malformed value")
+	}
+	nonce, sealed := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting article: %w", err)
+	}
+	return string(plain), nil
+}
+
+// Unlock sets the passphrase decrypting article content and descriptions.
+// The passphrase must be the one the database was encrypted with: a
+// different one returns ErrWrongKey, none at all ErrEncrypted. A passphrase
+// for a database not encrypted yet returns ErrNotEncrypted, since
+// encrypting it rewrites every article and must wait for the lock.
+func (db *DB) Unlock(passphrase string) error {
+	salt, check, encrypted, err := db.encryptionSettings()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case passphrase == "" && encrypted:
+		return ErrEncrypted
+	case passphrase == "":
+		return nil
+	case !encrypted:
+		return ErrNotEncrypted
+	}
+
+	c, err := newCrypter(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	if plain, err := c.open(check); err != nil || plain != keyCheck {
+		return ErrWrongKey
+	}
+	db.crypter = c
+	return nil
+}
+
+// Encrypt encrypts the stored articles with passphrase and vacuums the
+// database, so their plain text doesn't linger in free pages. It is done
+// once, holding the lock, after which Unlock must be given the same
+// passphrase.
+func (db *DB) Encrypt(passphrase string) error {
+	if passphrase == "" {
+		return errors.New("no encryption key: set database.encryption_key")
+	}
+	_, _, encrypted, err := db.encryptionSettings()
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return errors.New("database is already encrypted")
+	}
+
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	c, err := newCrypter(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	if err := db.encryptArticles(c, salt); err != nil {
+		return err
+	}
+	db.crypter = c
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("vacuuming database: %w", err)
+	}
+	return nil
+}
+
+// encryptionSettings reads the salt and key check of an encrypted database.
+// encrypted is false when it was never encrypted.
+func (db *DB) encryptionSettings() (salt []byte, check string, encrypted bool, err error) {
+	err = db.QueryRow("SELECT salt, key_check FROM encryption WHERE id = 1").Scan(&salt, &check)
+	if err == sql.ErrNoRows {
+		return nil, "", false, nil
+	}
+	if err != nil {
+		return nil, "", false, fmt.Errorf("reading encryption settings: %w", err)
+	}
+	return salt, check, true, nil
+}
+
+// encryptArticles encrypts every stored article with c and records its
+// salt, all in one transaction
+func (db *DB) encryptArticles(c *crypter, salt []byte) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, COALESCE(content, ''), COALESCE(description, '') FROM articles")
+	if err != nil {
+		return fmt.Errorf("reading articles: %w", err)
+	}
+	type text struct {
+		id                   int64
+		content, description string
+	}
+	var articles []text
+	for rows.Next() {
+		var t text
+		if err := rows.Scan(&t.id, &t.content, &t.description); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning article: %w", err)
+		}
+		articles = append(articles, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, t := range articles {
+		if _, err := tx.Exec("UPDATE articles SET content = ?, description = ? WHERE id = ?",
+			c.seal(t.content), c.seal(t.description), t.id); err != nil {
+			return fmt.Errorf("encrypting article: %w", err)
+		}
+	}
+	if _, err := tx.Exec("INSERT INTO encryption (id, salt, key_check) VALUES (1, ?, ?)", salt, c.seal(keyCheck)); err != nil {
+		return fmt.Errorf("saving encryption settings: %w", err)
+	}
+	return tx.Commit()
+}
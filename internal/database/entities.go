@@ -0,0 +1,127 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// EntityCount is an entity and how many unread articles name it
+type EntityCount struct {
+	models.Entity
+	Articles int
+}
+
+// GetArticlesWithoutEntities returns the stored articles not looked at for
+// entities yet, newest first
+func (db *DB) GetArticlesWithoutEntities() ([]models.Article, error) {
+	rows, err := db.Query(`
+		SELECT ` + articleColumns + `
+		FROM articles a
+		WHERE a.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM entity_extractions x WHERE x.article_id = a.id)
+		ORDER BY a.published_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying articles without entities: %w", err)
+	}
+	defer rows.Close()
+
+	return db.scanArticles(rows)
+}
+
+// SaveArticleEntities replaces the entities an article names and records
+// that it was looked at, even when it names none
+func (db *DB) SaveArticleEntities(articleID int64, entities []models.Entity) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM article_entities WHERE article_id = ?", articleID); err != nil {
+		return fmt.Errorf("clearing article entities: %w", err)
+	}
+	for _, e := range entities {
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO article_entities (article_id, kind, name) VALUES (?, ?, ?)",
+			articleID, e.Kind, e.Name,
+		); err != nil {
+			return fmt.Errorf("saving article entity: %w", err)
+		}
+	}
+	if _, err := tx.Exec(
+		"INSERT OR REPLACE INTO entity_extractions (article_id, extracted_at) VALUES (?, ?)",
+		articleID, time.Now(),
+	); err != nil {
+		return fmt.Errorf("recording entity extraction: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetArticleEntities returns the entities an article names
+func (db *DB) GetArticleEntities(articleID int64) ([]models.Entity, error) {
+	rows, err := db.Query("SELECT kind, name FROM article_entities WHERE article_id = ? ORDER BY kind, name", articleID)
+	if err != nil {
+		return nil, fmt.Errorf("querying article entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []models.Entity
+	for rows.Next() {
+		var e models.Entity
+		if err := rows.Scan(&e.Kind, &e.Name); err != nil {
+			return nil, fmt.Errorf("scanning article entity: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, rows.Err()
+}
+
+// GetEntities returns the entities named by unread articles, the most
+// named first
+func (db *DB) GetEntities() ([]EntityCount, error) {
+	rows, err := db.Query(`
+		SELECT e.kind, e.name, COUNT(*) AS n
+		FROM article_entities e
+		JOIN articles a ON a.id = e.article_id
+		WHERE a.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM read_articles r WHERE r.article_id = a.id)
+		GROUP BY e.kind, e.name
+		ORDER BY n DESC, e.name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []EntityCount
+	for rows.Next() {
+		var e EntityCount
+		if err := rows.Scan(&e.Kind, &e.Name, &e.Articles); err != nil {
+			return nil, fmt.Errorf("scanning entity: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, rows.Err()
+}
+
+// GetEntityArticles returns the unread articles naming an entity, snoozed
+// ones included, newest first and without their content
+func (db *DB) GetEntityArticles(entity models.Entity) ([]models.Article, error) {
+	rows, err := db.Query(`
+		SELECT `+articleListColumns+`
+		FROM article_entities e
+		JOIN articles a ON a.id = e.article_id
+		WHERE e.kind = ? AND e.name = ? AND a.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM read_articles r WHERE r.article_id = a.id)
+		ORDER BY a.published_at DESC
+	`, entity.Kind, entity.Name)
+	if err != nil {
+		return nil, fmt.Errorf("querying entity articles: %w", err)
+	}
+	defer rows.Close()
+
+	return db.scanArticles(rows)
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetRaindropExports returns the URLs already exported to Raindrop
+func (db *DB) GetRaindropExports() (map[string]bool, error) {
+	rows, err := db.Query("SELECT url FROM raindrop_exports")
+	if err != nil {
+		return nil, fmt.Errorf("querying Raindrop exports: %w", err)
+	}
+	defer rows.Close()
+
+	exported := map[string]bool{}
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, fmt.Errorf("scanning Raindrop export: %w", err)
+		}
+		exported[url] = true
+	}
+	return exported, rows.Err()
+}
+
+// MarkRaindropExported records that the URLs were exported to Raindrop
+func (db *DB) MarkRaindropExported(urls []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, url := range urls {
+		if _, err := tx.Exec(
+			"INSERT OR REPLACE INTO raindrop_exports (url, exported_at) VALUES (?, ?)",
+			url, now,
+		); err != nil {
+			return fmt.Errorf("recording Raindrop export: %w", err)
+		}
+	}
+	return tx.Commit()
+}
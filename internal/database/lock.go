@@ -0,0 +1,75 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrLocked is returned by AcquireLock while another process holds the lock
+var ErrLocked = errors.New("database is in use by another newsreadr")
+
+// Lock is an advisory lock on a database, held by the process that fetches,
+// scores and cleans up so two instances never do so at once. The operating
+// system releases it when the process exits, even after a crash.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock takes the lock on the database at dbPath without waiting.
+// When another process holds it, the error wraps ErrLocked and names the
+// holder's PID. Temporary databases are never shared, so they get a nil
+// lock.
+func AcquireLock(dbPath string) (*Lock, error) {
+	if dbPath == MemoryPath {
+		return nil, nil
+	}
+	path := dbPath + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating database directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+
+	locked, err := tryLock(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	if !locked {
+		f.Close()
+		if pid := lockHolder(path); pid != "" {
+			return nil, fmt.Errorf("%w (pid %s)", ErrLocked, pid)
+		}
+		return nil, ErrLocked
+	}
+
+	// Record who holds the lock for the error above
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release gives up the lock. Releasing a nil lock does nothing.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	unlock(l.file)
+	return l.file.Close()
+}
+
+// lockHolder returns the PID recorded in a lock file, if any
+func lockHolder(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockRange is the locked byte range. It lies past the PID written to the
+// file, as Windows locks keep other processes from reading locked bytes.
+var lockRange = windows.Overlapped{OffsetHigh: 1}
+
+// tryLock takes an exclusive lock on f, reporting false when another
+// process holds it
+func tryLock(f *os.File) (bool, error) {
+	ol := lockRange
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func unlock(f *os.File) {
+	ol := lockRange
+	windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &ol)
+}
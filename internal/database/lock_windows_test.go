@@ -0,0 +1,64 @@
+//go:build windows
+
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAcquireLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	lock, err := AcquireLock(path)
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+
+	_, err = AcquireLock(path)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("second AcquireLock returned %v, want ErrLocked", err)
+	}
+	if pid := "(pid " + strconv.Itoa(os.Getpid()) + ")"; !strings.Contains(err.Error(), pid) {
+		t.Errorf("second AcquireLock returned %q, want it to name %s", err, pid)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	again, err := AcquireLock(path)
+	if err != nil {
+		t.Fatalf("AcquireLock after Release: %v", err)
+	}
+	if err := again.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+}
+
+func TestAcquireLockMemory(t *testing.T) {
+	lock, err := AcquireLock(MemoryPath)
+	if lock != nil || err != nil {
+		t.Fatalf("AcquireLock(MemoryPath) = %v, %v, want nil, nil", lock, err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Errorf("releasing a nil lock: %v", err)
+	}
+}
+
+// The PID is read from the lock file while it is locked, so the locked
+// range must not cover it
+func TestLockHolderReadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	lock, err := AcquireLock(path)
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+	defer lock.Release()
+
+	if got, want := lockHolder(path+".lock"), strconv.Itoa(os.Getpid()); got != want {
+		t.Errorf("lockHolder = %q, want %q", got, want)
+	}
+}
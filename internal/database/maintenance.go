@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Stats summarizes the contents and on-disk size of the database
+type Stats struct {
+	Feeds        int
+	Articles     int
+	ReadArticles int
+	ReadHistory  int
+	Starred      int
+	Trashed      int
+	Embeddings   int
+	SizeBytes    int64
+	FreeBytes    int64
+	// CacheBytes is the size of the embedding cache database
+	CacheBytes int64
+}
+
+// GetStats collects row counts and page usage for the database
+func (db *DB) GetStats() (*Stats, error) {
+	var stats Stats
+
+	counts := []struct {
+		query string
+		dest  *int
+	}{
+		{"SELECT COUNT(*) FROM feeds", &stats.Feeds},
+		{"SELECT COUNT(*) FROM articles WHERE deleted_at IS NULL", &stats.Articles},
+		{"SELECT COUNT(*) FROM articles WHERE deleted_at IS NOT NULL", &stats.Trashed},
+		{"SELECT COUNT(*) FROM read_articles", &stats.ReadArticles},
+		{"SELECT COUNT(*) FROM read_history", &stats.ReadHistory},
+		{"SELECT COUNT(*) FROM starred_articles", &stats.Starred},
+	}
+	for _, c := range counts {
+		if err := db.QueryRow(c.query).Scan(c.dest); err != nil {
+			return nil, fmt.Errorf("counting rows: %w", err)
+		}
+	}
+	if err := db.cache.QueryRow("SELECT COUNT(*) FROM embeddings").Scan(&stats.Embeddings); err != nil {
+		return nil, fmt.Errorf("counting rows: %w", err)
+	}
+
+	pageSize, pageCount, freePages, err := pageUsage(db.DB)
+	if err != nil {
+		return nil, err
+	}
+	stats.SizeBytes = pageSize * pageCount
+	stats.FreeBytes = pageSize * freePages
+
+	pageSize, pageCount, _, err = pageUsage(db.cache)
+	if err != nil {
+		return nil, err
+	}
+	stats.CacheBytes = pageSize * pageCount
+
+	return &stats, nil
+}
+
+// CleanupCount is what a cleanup would delete from one feed
+type CleanupCount struct {
+	FeedName string
+	Old      int // published before the cutoff and not queued
+	Read     int // read, but not old
+}
+
+// PreviewCleanup counts per feed the articles DeleteOldArticles(maxAge) and
+// DeleteReadArticles would move to the trash, feeds losing the most first
+func (db *DB) PreviewCleanup(maxAge time.Duration) ([]CleanupCount, error) {
+	cutoff := time.Now().Add(-maxAge)
+	rows, err := db.Query(`
+		SELECT COALESCE(f.name, ''),
+			SUM(a.published_at < ? AND a.queued_at IS NULL) AS old,
+			SUM(NOT (a.published_at < ? AND a.queued_at IS NULL) AND r.article_id IS NOT NULL) AS read
+		FROM articles a
+		LEFT JOIN feeds f ON f.id = a.feed_id
+		LEFT JOIN read_articles r ON r.article_id = a.id
+		WHERE a.deleted_at IS NULL AND ((a.published_at < ? AND a.queued_at IS NULL) OR r.article_id IS NOT NULL)
+		GROUP BY a.feed_id
+		ORDER BY old + read DESC, f.name
+	`, cutoff, cutoff, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("previewing cleanup: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []CleanupCount
+	for rows.Next() {
+		var c CleanupCount
+		if err := rows.Scan(&c.FeedName, &c.Old, &c.Read); err != nil {
+			return nil, fmt.Errorf("scanning cleanup count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// Vacuum rebuilds the database and cache files to reclaim free pages and
+// refreshes the query planner statistics
+func (db *DB) Vacuum() error {
+	for _, d := range []*sql.DB{db.DB, db.cache} {
+		if _, err := d.Exec("VACUUM"); err != nil {
+			return fmt.Errorf("vacuuming database: %w", err)
+		}
+		if _, err := d.Exec("ANALYZE"); err != nil {
+			return fmt.Errorf("analyzing database: %w", err)
+		}
+	}
+	return nil
+}
+
+// VacuumIfNeeded vacuums the database when the space held by free pages
+// exceeds threshold bytes. A threshold of zero or less disables it.
+func (db *DB) VacuumIfNeeded(threshold int64) (bool, error) {
+	if threshold <= 0 {
+		return false, nil
+	}
+
+	pageSize, _, freePages, err := pageUsage(db.DB)
+	if err != nil {
+		return false, err
+	}
+	if pageSize*freePages < threshold {
+		return false, nil
+	}
+
+	if err := db.Vacuum(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// pageUsage returns the page size, total page count and free page count
+func pageUsage(db *sql.DB) (pageSize, pageCount, freePages int64, err error) {
+	if err = db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, 0, 0, fmt.Errorf("reading page size: %w", err)
+	}
+	if err = db.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, 0, 0, fmt.Errorf("reading page count: %w", err)
+	}
+	if err = db.QueryRow("PRAGMA freelist_count").Scan(&freePages); err != nil {
+		return 0, 0, 0, fmt.Errorf("reading freelist count: %w", err)
+	}
+	return pageSize, pageCount, freePages, nil
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// OfflineImage is an article image downloaded for reading offline
+type OfflineImage struct {
+	URL       string
+	ArticleID int64
+	Path      string
+	Size      int64
+}
+
+// keptOffline matches articles kept for offline reading: queued or starred
+// and not in the trash
+const keptOffline = `a.deleted_at IS NULL AND (a.queued_at IS NOT NULL OR EXISTS (SELECT 1 FROM starred_articles s WHERE s.url = a.url))`
+
+// GetArticlesToCache retrieves the queued and starred articles not yet
+// cached for offline reading
+func (db *DB) GetArticlesToCache() ([]models.Article, error) {
+	rows, err := db.Query(`
+		SELECT ` + articleColumns + `
+		FROM articles a
+		WHERE a.offline_at IS NULL AND ` + keptOffline + `
+		ORDER BY a.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying articles to cache: %w", err)
+	}
+	defer rows.Close()
+	return db.scanArticles(rows)
+}
+
+// MarkArticleCached records that an article is readable offline
+func (db *DB) MarkArticleCached(articleID int64) error {
+	if _, err := db.Exec("UPDATE articles SET offline_at = ? WHERE id = ?", time.Now(), articleID); err != nil {
+		return fmt.Errorf("marking article cached: %w", err)
+	}
+	return nil
+}
+
+// AddOfflineImage records a downloaded image
+func (db *DB) AddOfflineImage(img OfflineImage) error {
+	_, err := db.Exec(
+		"INSERT OR REPLACE INTO offline_images (url, article_id, path, size, cached_at) VALUES (?, ?, ?, ?, ?)",
+		img.URL, img.ArticleID, img.Path, img.Size, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("recording offline image: %w", err)
+	}
+	return nil
+}
+
+// GetOfflineImages maps the image URLs of an article to their downloaded
+// files
+func (db *DB) GetOfflineImages(articleID int64) (map[string]string, error) {
+	rows, err := db.Query("SELECT url, path FROM offline_images WHERE article_id = ?", articleID)
+	if err != nil {
+		return nil, fmt.Errorf("querying offline images: %w", err)
+	}
+	defer rows.Close()
+
+	images := map[string]string{}
+	for rows.Next() {
+		var url, path string
+		if err := rows.Scan(&url, &path); err != nil {
+			return nil, fmt.Errorf("scanning offline image: %w", err)
+		}
+		images[url] = path
+	}
+	return images, rows.Err()
+}
+
+// EvictOfflineImages forgets downloaded images until they take at most
+// maxBytes, returning the files to delete. Images of articles no longer
+// queued or starred go first, then the oldest.
+func (db *DB) EvictOfflineImages(maxBytes int64) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT i.url, i.path, i.size
+		FROM offline_images i
+		LEFT JOIN articles a ON a.id = i.article_id AND ` + keptOffline + `
+		ORDER BY a.id IS NOT NULL, i.cached_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying offline images: %w", err)
+	}
+	var images []OfflineImage
+	var total int64
+	for rows.Next() {
+		var img OfflineImage
+		if err := rows.Scan(&img.URL, &img.Path, &img.Size); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning offline image: %w", err)
+		}
+		images = append(images, img)
+		total += img.Size
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var evicted []string
+	for _, img := range images {
+		if total <= maxBytes {
+			break
+		}
+		if _, err := db.Exec("DELETE FROM offline_images WHERE url = ?", img.URL); err != nil {
+			return evicted, fmt.Errorf("evicting offline image: %w", err)
+		}
+		evicted = append(evicted, img.Path)
+		total -= img.Size
+	}
+	return evicted, nil
+}
@@ -2,12 +2,97 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/thomaskoefod/newsreadr/pkg/models"
 )
 
+// articleColumns is the column list matching articleFields, for queries
+// selecting from articles aliased as a
+const articleColumns = `a.id, a.feed_id, a.title, a.url, a.content, a.description, a.published_at, a.fetched_at, a.relevance_score,
+	a.tags, a.author, a.guid, a.comments_url, COALESCE((SELECT f.name FROM feeds f WHERE f.id = a.feed_id), ''),
+	COALESCE((SELECT f.url FROM feeds f WHERE f.id = a.feed_id), ''),
+	EXISTS (SELECT 1 FROM starred_articles s WHERE s.url = a.url), a.queued_at IS NOT NULL, a.scored_at IS NOT NULL`
+
+// articleListColumns is articleColumns with an empty content, for lists that
+// fetch it per article with GetArticleContent when it is shown
+var articleListColumns = strings.Replace(articleColumns, "a.content", "''", 1)
+
+// articleRow holds scan destinations for columns that need decoding
+type articleRow struct {
+	article *models.Article
+	crypter *crypter
+	tags    string
+}
+
+// fields returns scan destinations for the columns in articleColumns
+func (r *articleRow) fields() []interface{} {
+	a := r.article
+	return []interface{}{
+		&a.ID, &a.FeedID, &a.Title, &a.URL, &a.Content, &a.Description,
+		&a.PublishedAt, &a.FetchedAt, &a.RelevanceScore,
+		&r.tags, &a.Author, &a.GUID, &a.CommentsURL, &a.FeedName, &a.FeedURL, &a.Starred, &a.Queued, &a.Scored,
+	}
+}
+
+// finish decodes the scanned columns into the article, decrypting its text
+func (r *articleRow) finish() error {
+	a := r.article
+	a.Tags = splitTags(r.tags)
+	var err error
+	if a.Content, err = r.crypter.open(a.Content); err != nil {
+		return err
+	}
+	a.Description, err = r.crypter.open(a.Description)
+	return err
+}
+
+// scanArticles scans all rows selected with articleColumns
+func (db *DB) scanArticles(rows *sql.Rows) ([]models.Article, error) {
+	var articles []models.Article
+	for rows.Next() {
+		var article models.Article
+		row := articleRow{article: &article, crypter: db.crypter}
+		if err := rows.Scan(row.fields()...); err != nil {
+			return nil, fmt.Errorf("scanning article: %w", err)
+		}
+		if err := row.finish(); err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+
+	return articles, rows.Err()
+}
+
+// joinTags stores tags as a comma separated list with surrounding commas,
+// so a single tag can be matched with LIKE '%,tag,%'
+func joinTags(tags []string) string {
+	var clean []string
+	for _, t := range tags {
+		t = strings.TrimSpace(strings.ReplaceAll(t, ",", " "))
+		if t != "" {
+			clean = append(clean, t)
+		}
+	}
+	if len(clean) == 0 {
+		return ""
+	}
+	return "," + strings.Join(clean, ",") + ","
+}
+
+// splitTags decodes a list stored by joinTags
+func splitTags(s string) []string {
+	s = strings.Trim(s, ",")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
 // AddFeed inserts a new feed
 func (db *DB) AddFeed(feed *models.Feed) error {
 	result, err := db.Exec(
@@ -27,46 +112,58 @@ func (db *DB) AddFeed(feed *models.Feed) error {
 	return nil
 }
 
+// feedColumns is the column list scanned by scanFeeds
+const feedColumns = "id, url, name, enabled, created_at, last_fetched_at, last_success_at, last_item_at, last_status, last_error"
+
 // GetFeeds retrieves all feeds
 func (db *DB) GetFeeds() ([]models.Feed, error) {
-	rows, err := db.Query("SELECT id, url, name, enabled, created_at FROM feeds ORDER BY created_at DESC")
+	rows, err := db.Query("SELECT " + feedColumns + " FROM feeds ORDER BY created_at DESC")
 	if err != nil {
 		return nil, fmt.Errorf("querying feeds: %w", err)
 	}
 	defer rows.Close()
 
-	var feeds []models.Feed
-	for rows.Next() {
-		var feed models.Feed
-		if err := rows.Scan(&feed.ID, &feed.URL, &feed.Name, &feed.Enabled, &feed.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scanning feed: %w", err)
-		}
-		feeds = append(feeds, feed)
-	}
-
-	return feeds, rows.Err()
+	return scanFeeds(rows)
 }
 
 // GetEnabledFeeds retrieves only enabled feeds
 func (db *DB) GetEnabledFeeds() ([]models.Feed, error) {
-	rows, err := db.Query("SELECT id, url, name, enabled, created_at FROM feeds WHERE enabled = 1 ORDER BY created_at DESC")
+	rows, err := db.Query("SELECT " + feedColumns + " FROM feeds WHERE enabled = 1 ORDER BY created_at DESC")
 	if err != nil {
 		return nil, fmt.Errorf("querying enabled feeds: %w", err)
 	}
 	defer rows.Close()
 
+	return scanFeeds(rows)
+}
+
+// scanFeeds scans all rows selected with feedColumns
+func scanFeeds(rows *sql.Rows) ([]models.Feed, error) {
 	var feeds []models.Feed
 	for rows.Next() {
 		var feed models.Feed
-		if err := rows.Scan(&feed.ID, &feed.URL, &feed.Name, &feed.Enabled, &feed.CreatedAt); err != nil {
+		var fetchedAt, successAt, itemAt sql.NullTime
+		if err := rows.Scan(&feed.ID, &feed.URL, &feed.Name, &feed.Enabled, &feed.CreatedAt,
+			&fetchedAt, &successAt, &itemAt, &feed.LastStatus, &feed.LastError); err != nil {
 			return nil, fmt.Errorf("scanning feed: %w", err)
 		}
+		feed.LastFetchedAt = nullTime(fetchedAt)
+		feed.LastSuccessAt = nullTime(successAt)
+		feed.LastItemAt = nullTime(itemAt)
 		feeds = append(feeds, feed)
 	}
 
 	return feeds, rows.Err()
 }
 
+// nullTime converts a nullable time into an optional one
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
 // UpdateFeed updates an existing feed
 func (db *DB) UpdateFeed(feed *models.Feed) error {
 	_, err := db.Exec(
@@ -79,6 +176,39 @@ func (db *DB) UpdateFeed(feed *models.Feed) error {
 	return nil
 }
 
+// RecordFeedFetch stores the outcome of fetching a feed. On success
+// newestItem is the publish time of the newest item in the feed, if any.
+func (db *DB) RecordFeedFetch(feedID int64, status int, fetchErr error, newestItem *time.Time) error {
+	now := time.Now()
+	var err error
+	if fetchErr != nil {
+		_, err = db.Exec(
+			"UPDATE feeds SET last_fetched_at = ?, last_status = ?, last_error = ? WHERE id = ?",
+			now, status, fetchErr.Error(), feedID,
+		)
+	} else {
+		_, err = db.Exec(
+			`UPDATE feeds SET last_fetched_at = ?, last_success_at = ?, last_status = ?, last_error = '',
+				last_item_at = COALESCE(?, last_item_at)
+			WHERE id = ?`,
+			now, now, status, newestItem, feedID,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("recording feed fetch: %w", err)
+	}
+	return nil
+}
+
+// UpdateFeedURL changes the URL of a feed, e.g. after a permanent redirect
+func (db *DB) UpdateFeedURL(feedID int64, url string) error {
+	_, err := db.Exec("UPDATE feeds SET url = ? WHERE id = ?", url, feedID)
+	if err != nil {
+		return fmt.Errorf("updating feed url: %w", err)
+	}
+	return nil
+}
+
 // DeleteFeed removes a feed and its articles
 func (db *DB) DeleteFeed(id int64) error {
 	_, err := db.Exec("DELETE FROM feeds WHERE id = ?", id)
@@ -88,13 +218,55 @@ func (db *DB) DeleteFeed(id int64) error {
 	return nil
 }
 
+// GetPageSnapshot retrieves the text of a watched page when last checked.
+// It returns false when the page hasn't been checked yet.
+func (db *DB) GetPageSnapshot(feedID int64) (string, bool, error) {
+	var content string
+	err := db.QueryRow("SELECT content FROM page_snapshots WHERE feed_id = ?", feedID).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("querying page snapshot: %w", err)
+	}
+	if content, err = db.crypter.open(content); err != nil {
+		return "", false, err
+	}
+	return content, true, nil
+}
+
+// SavePageSnapshot stores the text of a watched page as last checked
+func (db *DB) SavePageSnapshot(feedID int64, content string) error {
+	_, err := db.Exec(
+		"INSERT OR REPLACE INTO page_snapshots (feed_id, content, checked_at) VALUES (?, ?, ?)",
+		feedID, db.crypter.seal(content), time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving page snapshot: %w", err)
+	}
+	return nil
+}
+
+// ErrDuplicate is returned when inserting an article whose URL is already stored
+var ErrDuplicate = errors.New("article already exists")
+
+// isUniqueViolation reports whether err is a sqlite unique constraint failure
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
 // AddArticle inserts a new article
 func (db *DB) AddArticle(article *models.Article) error {
+	fetchedAt := time.Now()
 	result, err := db.Exec(
-		"INSERT INTO articles (feed_id, title, url, content, description, published_at, fetched_at, relevance_score) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
-		article.FeedID, article.Title, article.URL, article.Content, article.Description, article.PublishedAt, time.Now(), article.RelevanceScore,
+		"INSERT INTO articles (feed_id, title, url, content, description, published_at, fetched_at, relevance_score, tags, author, guid, comments_url, remote_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		article.FeedID, article.Title, article.URL, db.crypter.seal(article.Content), db.crypter.seal(article.Description), article.PublishedAt, fetchedAt, article.RelevanceScore, joinTags(article.Tags),
+		article.Author, article.GUID, article.CommentsURL, remoteID(article.RemoteID),
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
 		return fmt.Errorf("inserting article: %w", err)
 	}
 
@@ -104,45 +276,123 @@ func (db *DB) AddArticle(article *models.Article) error {
 	}
 
 	article.ID = id
+	article.FetchedAt = fetchedAt
 	return nil
 }
 
-// GetUnreadArticles retrieves articles not marked as read, newer than maxAge, ordered by relevance
+// UpdateArticleContent replaces an article's content, author and
+// publication date, as extracted from its page
+func (db *DB) UpdateArticleContent(article *models.Article) error {
+	_, err := db.Exec("UPDATE articles SET content = ?, author = ?, published_at = ? WHERE id = ?",
+		db.crypter.seal(article.Content), article.Author, article.PublishedAt, article.ID)
+	if err != nil {
+		return fmt.Errorf("updating article content: %w", err)
+	}
+	return nil
+}
+
+// GetUnreadArticles retrieves articles not marked as read or snoozed, newer than maxAge, ordered by relevance
 func (db *DB) GetUnreadArticles(maxAge time.Duration) ([]models.Article, error) {
+	return db.unreadArticles(articleColumns, maxAge)
+}
+
+// GetUnreadArticleList is GetUnreadArticles without the content of the
+// articles, which is most of their size, for listing them
+func (db *DB) GetUnreadArticleList(maxAge time.Duration) ([]models.Article, error) {
+	return db.unreadArticles(articleListColumns, maxAge)
+}
+
+// unreadArticles selects the given columns of the unread articles
+func (db *DB) unreadArticles(columns string, maxAge time.Duration) ([]models.Article, error) {
 	cutoff := time.Now().Add(-maxAge)
 	query := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.content, a.description, a.published_at, a.fetched_at, a.relevance_score
-		FROM articles a
-		LEFT JOIN read_articles r ON a.id = r.article_id
-		WHERE r.article_id IS NULL AND a.published_at >= ?
+		SELECT ` + columns + `
+		FROM (
+			-- Unread IDs from idx_articles_unread alone, so read articles
+			-- are skipped without loading their rows
+			SELECT u.id FROM articles u
+			WHERE u.published_at >= ?
+				AND NOT EXISTS (SELECT 1 FROM read_articles r WHERE r.article_id = u.id)
+		) unread
+		JOIN articles a ON a.id = unread.id
+		WHERE a.deleted_at IS NULL AND (a.snoozed_until IS NULL OR a.snoozed_until <= ?)
 		ORDER BY a.relevance_score DESC, a.published_at DESC
 	`
 
-	rows, err := db.Query(query, cutoff)
+	rows, err := db.Query(query, cutoff, time.Now())
 	if err != nil {
 		return nil, fmt.Errorf("querying unread articles: %w", err)
 	}
 	defer rows.Close()
 
-	var articles []models.Article
-	for rows.Next() {
-		var article models.Article
-		if err := rows.Scan(&article.ID, &article.FeedID, &article.Title, &article.URL, &article.Content, &article.Description, &article.PublishedAt, &article.FetchedAt, &article.RelevanceScore); err != nil {
-			return nil, fmt.Errorf("scanning article: %w", err)
-		}
-		articles = append(articles, article)
+	return db.scanArticles(rows)
+}
+
+// GetArticleContent retrieves the content of an article listed without it.
+// It is empty for articles that are gone.
+func (db *DB) GetArticleContent(id int64) (string, error) {
+	var content sql.NullString
+	err := db.QueryRow("SELECT content FROM articles WHERE id = ?", id).Scan(&content)
+	if err == sql.ErrNoRows {
+		return "", nil
 	}
+	if err != nil {
+		return "", fmt.Errorf("querying article content: %w", err)
+	}
+	return db.crypter.open(content.String)
+}
 
-	return articles, rows.Err()
+// GetAllArticles retrieves every stored article not in the trash, read or not
+func (db *DB) GetAllArticles() ([]models.Article, error) {
+	rows, err := db.Query(`SELECT ` + articleColumns + ` FROM articles a WHERE a.deleted_at IS NULL ORDER BY a.id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying articles: %w", err)
+	}
+	defer rows.Close()
+
+	return db.scanArticles(rows)
+}
+
+// GetQueuedArticles retrieves unread articles in the read-it-later queue, oldest queued first
+func (db *DB) GetQueuedArticles() ([]models.Article, error) {
+	query := `
+		SELECT ` + articleColumns + `
+		FROM articles a
+		LEFT JOIN read_articles r ON a.id = r.article_id
+		WHERE r.article_id IS NULL AND a.deleted_at IS NULL AND a.queued_at IS NOT NULL
+		ORDER BY a.queued_at ASC
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("querying queued articles: %w", err)
+	}
+	defer rows.Close()
+
+	return db.scanArticles(rows)
+}
+
+// SetArticleQueued adds an article to or removes it from the read-it-later queue
+func (db *DB) SetArticleQueued(articleID int64, queued bool) error {
+	var queuedAt interface{}
+	if queued {
+		queuedAt = time.Now()
+	}
+	_, err := db.Exec("UPDATE articles SET queued_at = ? WHERE id = ?", queuedAt, articleID)
+	if err != nil {
+		return fmt.Errorf("updating article queue state: %w", err)
+	}
+	return nil
 }
 
 // GetArticleByID retrieves a single article
 func (db *DB) GetArticleByID(id int64) (*models.Article, error) {
 	var article models.Article
+	row := articleRow{article: &article, crypter: db.crypter}
 	err := db.QueryRow(
-		"SELECT id, feed_id, title, url, content, description, published_at, fetched_at, relevance_score FROM articles WHERE id = ?",
+		"SELECT "+articleColumns+" FROM articles a WHERE a.id = ?",
 		id,
-	).Scan(&article.ID, &article.FeedID, &article.Title, &article.URL, &article.Content, &article.Description, &article.PublishedAt, &article.FetchedAt, &article.RelevanceScore)
+	).Scan(row.fields()...)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -150,46 +400,229 @@ func (db *DB) GetArticleByID(id int64) (*models.Article, error) {
 	if err != nil {
 		return nil, fmt.Errorf("querying article: %w", err)
 	}
+	if err := row.finish(); err != nil {
+		return nil, err
+	}
 
 	return &article, nil
 }
 
-// MarkArticleRead marks an article as read
+// MarkArticleRead marks an article as read and records it in the read
+// history. Synced articles are queued to be marked read remotely too.
 func (db *DB) MarkArticleRead(articleID int64) error {
-	_, err := db.Exec(
+	return db.markRead(articleID, true)
+}
+
+func (db *DB) markRead(articleID int64, queueRemote bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec(
 		"INSERT INTO read_articles (article_id, read_at) VALUES (?, ?)",
+		articleID, now,
+	); err != nil {
+		return fmt.Errorf("marking article as read: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO read_history (article_id, feed_id, feed_name, title, url, relevance_score, published_at, read_at)
+		SELECT a.id, a.feed_id, COALESCE(f.name, ''), a.title, a.url, a.relevance_score, a.published_at, ?
+		FROM articles a
+		LEFT JOIN feeds f ON a.feed_id = f.id
+		WHERE a.id = ?`,
+		now, articleID,
+	); err != nil {
+		return fmt.Errorf("recording read history: %w", err)
+	}
+
+	if queueRemote {
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO remote_pending_reads (remote_id)
+			SELECT remote_id FROM articles WHERE id = ? AND remote_id IS NOT NULL`,
+			articleID,
+		); err != nil {
+			return fmt.Errorf("queueing remote read: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RecordArticleOpen records that an article was opened in the browser
+func (db *DB) RecordArticleOpen(articleID int64) error {
+	_, err := db.Exec(`
+		INSERT INTO article_opens (article_id, feed_name, title, url, opened_at)
+		SELECT a.id, COALESCE(f.name, ''), a.title, a.url, ?
+		FROM articles a
+		LEFT JOIN feeds f ON a.feed_id = f.id
+		WHERE a.id = ?`,
+		time.Now(), articleID,
+	)
+	if err != nil {
+		return fmt.Errorf("recording article open: %w", err)
+	}
+	return nil
+}
+
+// MarkArticleMuted marks an article as read without recording read history
+func (db *DB) MarkArticleMuted(articleID int64) error {
+	_, err := db.Exec(
+		"INSERT OR IGNORE INTO read_articles (article_id, read_at) VALUES (?, ?)",
 		articleID, time.Now(),
 	)
 	if err != nil {
-		return fmt.Errorf("marking article as read: %w", err)
+		return fmt.Errorf("marking article as muted: %w", err)
+	}
+	return nil
+}
+
+// StarArticle stars an article, keeping a snapshot that survives deletion
+func (db *DB) StarArticle(articleID int64) error {
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO starred_articles (url, article_id, feed_id, feed_name, title, relevance_score, published_at, starred_at)
+		SELECT a.url, a.id, a.feed_id, COALESCE(f.name, ''), a.title, a.relevance_score, a.published_at, ?
+		FROM articles a
+		LEFT JOIN feeds f ON a.feed_id = f.id
+		WHERE a.id = ?`,
+		time.Now(), articleID,
+	)
+	if err != nil {
+		return fmt.Errorf("starring article: %w", err)
+	}
+	return nil
+}
+
+// UnstarArticle removes the star from the article with the given URL
+func (db *DB) UnstarArticle(url string) error {
+	_, err := db.Exec("DELETE FROM starred_articles WHERE url = ?", url)
+	if err != nil {
+		return fmt.Errorf("unstarring article: %w", err)
+	}
+	return nil
+}
+
+// GetReadHistory retrieves the read history, most recently read first
+func (db *DB) GetReadHistory() ([]models.HistoryEntry, error) {
+	rows, err := db.Query(`
+		SELECT h.article_id, h.feed_name, h.title, h.url, h.relevance_score, h.published_at, h.read_at, s.starred_at, o.opened_at
+		FROM read_history h
+		LEFT JOIN starred_articles s ON h.url = s.url
+		LEFT JOIN article_opens o ON o.id = (SELECT MAX(id) FROM article_opens WHERE url = h.url)
+		ORDER BY h.read_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying read history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHistory(rows)
+}
+
+// GetRecentReadHistory retrieves the most recently read limit entries
+func (db *DB) GetRecentReadHistory(limit int) ([]models.HistoryEntry, error) {
+	rows, err := db.Query(`
+		SELECT h.article_id, h.feed_name, h.title, h.url, h.relevance_score, h.published_at, h.read_at, s.starred_at, o.opened_at
+		FROM read_history h
+		LEFT JOIN starred_articles s ON h.url = s.url
+		LEFT JOIN article_opens o ON o.id = (SELECT MAX(id) FROM article_opens WHERE url = h.url)
+		ORDER BY h.read_at DESC
+		LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying recent read history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHistory(rows)
+}
+
+// GetStarredArticles retrieves starred articles, most recently starred first
+func (db *DB) GetStarredArticles() ([]models.HistoryEntry, error) {
+	rows, err := db.Query(`
+		SELECT s.article_id, s.feed_name, s.title, s.url, s.relevance_score, s.published_at, h.read_at, s.starred_at, o.opened_at
+		FROM starred_articles s
+		LEFT JOIN read_history h ON h.id = (SELECT MAX(id) FROM read_history WHERE url = s.url)
+		LEFT JOIN article_opens o ON o.id = (SELECT MAX(id) FROM article_opens WHERE url = s.url)
+		ORDER BY s.starred_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying starred articles: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHistory(rows)
+}
+
+// scanHistory scans rows of history entries with nullable read, starred and
+// opened times
+func scanHistory(rows *sql.Rows) ([]models.HistoryEntry, error) {
+	var entries []models.HistoryEntry
+	for rows.Next() {
+		var entry models.HistoryEntry
+		var readAt, starredAt, openedAt sql.NullTime
+		if err := rows.Scan(&entry.ArticleID, &entry.FeedName, &entry.Title, &entry.URL, &entry.RelevanceScore, &entry.PublishedAt, &readAt, &starredAt, &openedAt); err != nil {
+			return nil, fmt.Errorf("scanning history entry: %w", err)
+		}
+		if readAt.Valid {
+			entry.ReadAt = &readAt.Time
+		}
+		if starredAt.Valid {
+			entry.StarredAt = &starredAt.Time
+		}
+		if openedAt.Valid {
+			entry.OpenedAt = &openedAt.Time
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// SnoozeArticle hides an article from the unread list until the given time
+func (db *DB) SnoozeArticle(articleID int64, until time.Time) error {
+	_, err := db.Exec("UPDATE articles SET snoozed_until = ? WHERE id = ?", until, articleID)
+	if err != nil {
+		return fmt.Errorf("snoozing article: %w", err)
 	}
 	return nil
 }
 
-// DeleteReadArticles removes read articles from database
+// DeleteReadArticles moves read articles to the trash
 func (db *DB) DeleteReadArticles() error {
-	_, err := db.Exec("DELETE FROM articles WHERE id IN (SELECT article_id FROM read_articles)")
+	_, err := db.Exec(
+		"UPDATE articles SET deleted_at = ? WHERE deleted_at IS NULL AND id IN (SELECT article_id FROM read_articles)",
+		time.Now(),
+	)
 	if err != nil {
 		return fmt.Errorf("deleting read articles: %w", err)
 	}
 	return nil
 }
 
-// DeleteOldArticles removes articles older than maxAge
+// DeleteOldArticles moves articles older than maxAge to the trash, keeping
+// queued ones, and purges articles trashed more than TrashRetention ago
 func (db *DB) DeleteOldArticles(maxAge time.Duration) error {
-	cutoff := time.Now().Add(-maxAge)
-	_, err := db.Exec("DELETE FROM articles WHERE published_at < ?", cutoff)
+	now := time.Now()
+	_, err := db.Exec(
+		"UPDATE articles SET deleted_at = ? WHERE deleted_at IS NULL AND published_at < ? AND queued_at IS NULL",
+		now, now.Add(-maxAge),
+	)
 	if err != nil {
 		return fmt.Errorf("deleting old articles: %w", err)
 	}
-	return nil
+	return db.purgeTrash(now.Add(-TrashRetention))
 }
 
 // AddInterest inserts a new user interest
 func (db *DB) AddInterest(interest *models.UserInterest) error {
 	result, err := db.Exec(
-		"INSERT INTO user_interests (description, weight, embedding) VALUES (?, ?, ?)",
-		interest.Description, interest.Weight, interest.Embedding,
+		"INSERT INTO user_interests (description, weight, embedding, group_name, avoid) VALUES (?, ?, ?, ?, ?)",
+		interest.Description, interest.Weight, interest.Embedding, interest.Group, interest.Avoid,
 	)
 	if err != nil {
 		return fmt.Errorf("inserting interest: %w", err)
@@ -204,9 +637,19 @@ func (db *DB) AddInterest(interest *models.UserInterest) error {
 	return nil
 }
 
-// GetInterests retrieves all user interests
+// GetInterests retrieves all user interests that are not archived, ordered
+// by group with interests to avoid last
 func (db *DB) GetInterests() ([]models.UserInterest, error) {
-	rows, err := db.Query("SELECT id, description, weight, embedding FROM user_interests")
+	return db.queryInterests("WHERE archived_at IS NULL")
+}
+
+// GetArchivedInterests retrieves the interests removed from the config
+func (db *DB) GetArchivedInterests() ([]models.UserInterest, error) {
+	return db.queryInterests("WHERE archived_at IS NOT NULL")
+}
+
+func (db *DB) queryInterests(where string) ([]models.UserInterest, error) {
+	rows, err := db.Query("SELECT id, description, weight, embedding, group_name, avoid FROM user_interests " + where + " ORDER BY avoid, group_name, id")
 	if err != nil {
 		return nil, fmt.Errorf("querying interests: %w", err)
 	}
@@ -216,7 +659,7 @@ func (db *DB) GetInterests() ([]models.UserInterest, error) {
 	for rows.Next() {
 		var interest models.UserInterest
 		var embedding sql.NullString
-		if err := rows.Scan(&interest.ID, &interest.Description, &interest.Weight, &embedding); err != nil {
+		if err := rows.Scan(&interest.ID, &interest.Description, &interest.Weight, &embedding, &interest.Group, &interest.Avoid); err != nil {
 			return nil, fmt.Errorf("scanning interest: %w", err)
 		}
 		if embedding.Valid {
@@ -228,11 +671,137 @@ func (db *DB) GetInterests() ([]models.UserInterest, error) {
 	return interests, rows.Err()
 }
 
+// UpdateInterest updates the weight, group and avoid flag of an interest
+func (db *DB) UpdateInterest(interest *models.UserInterest) error {
+	_, err := db.Exec(
+		"UPDATE user_interests SET weight = ?, group_name = ?, avoid = ? WHERE id = ?",
+		interest.Weight, interest.Group, interest.Avoid, interest.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating interest: %w", err)
+	}
+	return nil
+}
+
+// UpdateInterestEmbedding stores the cached embedding of an interest
+func (db *DB) UpdateInterestEmbedding(id int64, embedding []byte) error {
+	if _, err := db.Exec("UPDATE user_interests SET embedding = ? WHERE id = ?", embedding, id); err != nil {
+		return fmt.Errorf("updating interest embedding: %w", err)
+	}
+	return nil
+}
+
+// ArchiveInterest sets an interest aside: it no longer counts for scoring
+// but keeps its weight and cached embedding in case it is restored
+func (db *DB) ArchiveInterest(id int64) error {
+	if _, err := db.Exec("UPDATE user_interests SET archived_at = ? WHERE id = ?", time.Now(), id); err != nil {
+		return fmt.Errorf("archiving interest: %w", err)
+	}
+	return nil
+}
+
+// RestoreInterest brings back an archived interest
+func (db *DB) RestoreInterest(id int64) error {
+	if _, err := db.Exec("UPDATE user_interests SET archived_at = NULL WHERE id = ?", id); err != nil {
+		return fmt.Errorf("restoring interest: %w", err)
+	}
+	return nil
+}
+
+// DeleteInterest removes an interest
+func (db *DB) DeleteInterest(id int64) error {
+	if _, err := db.Exec("DELETE FROM user_interests WHERE id = ?", id); err != nil {
+		return fmt.Errorf("deleting interest: %w", err)
+	}
+	return nil
+}
+
 // UpdateArticleRelevance updates the relevance score of an article
 func (db *DB) UpdateArticleRelevance(articleID int64, score float64) error {
-	_, err := db.Exec("UPDATE articles SET relevance_score = ? WHERE id = ?", score, articleID)
+	_, err := db.Exec("UPDATE articles SET relevance_score = ?, scored_at = ? WHERE id = ?", score, time.Now(), articleID)
 	if err != nil {
 		return fmt.Errorf("updating article relevance: %w", err)
 	}
 	return nil
 }
+
+// UpdateArticleRelevances stores many relevance scores in one transaction
+func (db *DB) UpdateArticleRelevances(scores map[int64]float64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("UPDATE articles SET relevance_score = ?, scored_at = ? WHERE id = ?")
+	if err != nil {
+		return fmt.Errorf("preparing relevance update: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for id, score := range scores {
+		if _, err := stmt.Exec(score, now, id); err != nil {
+			return fmt.Errorf("updating article relevance: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// MarkArticleUnscored makes the next scoring pass score an article again.
+// Its current score is kept until then.
+func (db *DB) MarkArticleUnscored(articleID int64) error {
+	if _, err := db.Exec("UPDATE articles SET scored_at = NULL WHERE id = ?", articleID); err != nil {
+		return fmt.Errorf("marking article unscored: %w", err)
+	}
+	return nil
+}
+
+// MarkAllUnscored makes the next scoring pass score every article again,
+// e.g. after the interests changed. It returns how many were marked.
+func (db *DB) MarkAllUnscored() (int64, error) {
+	result, err := db.Exec("UPDATE articles SET scored_at = NULL WHERE scored_at IS NOT NULL")
+	if err != nil {
+		return 0, fmt.Errorf("marking articles unscored: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// AddMute inserts a mute rule, ignoring duplicates
+func (db *DB) AddMute(mute *models.Mute) error {
+	result, err := db.Exec(
+		"INSERT OR IGNORE INTO mutes (kind, value, created_at) VALUES (?, ?, ?)",
+		mute.Kind, mute.Value, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("inserting mute: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+
+	mute.ID = id
+	return nil
+}
+
+// GetMutes retrieves all mute rules
+func (db *DB) GetMutes() ([]models.Mute, error) {
+	rows, err := db.Query("SELECT id, kind, value FROM mutes ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("querying mutes: %w", err)
+	}
+	defer rows.Close()
+
+	var mutes []models.Mute
+	for rows.Next() {
+		var mute models.Mute
+		if err := rows.Scan(&mute.ID, &mute.Kind, &mute.Value); err != nil {
+			return nil, fmt.Errorf("scanning mute: %w", err)
+		}
+		mutes = append(mutes, mute)
+	}
+
+	return mutes, rows.Err()
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// remoteID stores a missing remote ID as NULL
+func remoteID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// SetArticleRemoteID links a stored article to its item in a synced service
+func (db *DB) SetArticleRemoteID(url string, remoteID int64) error {
+	_, err := db.Exec("UPDATE articles SET remote_id = ? WHERE url = ?", remoteID, url)
+	if err != nil {
+		return fmt.Errorf("setting remote ID: %w", err)
+	}
+	return nil
+}
+
+// GetUnreadRemoteIDs maps the remote IDs of unread synced articles to their
+// local IDs
+func (db *DB) GetUnreadRemoteIDs() (map[int64]int64, error) {
+	rows, err := db.Query(`
+		SELECT a.remote_id, a.id
+		FROM articles a
+		LEFT JOIN read_articles r ON a.id = r.article_id
+		WHERE r.article_id IS NULL AND a.remote_id IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("querying remote IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := map[int64]int64{}
+	for rows.Next() {
+		var remote, local int64
+		if err := rows.Scan(&remote, &local); err != nil {
+			return nil, fmt.Errorf("scanning remote ID: %w", err)
+		}
+		ids[remote] = local
+	}
+	return ids, rows.Err()
+}
+
+// MarkArticleReadRemotely marks an article read because it was read in the
+// synced service, so it isn't queued to be marked read there again
+func (db *DB) MarkArticleReadRemotely(articleID int64) error {
+	return db.markRead(articleID, false)
+}
+
+// GetPendingRemoteReads returns the remote IDs of articles read here that
+// still have to be marked read in the synced service
+func (db *DB) GetPendingRemoteReads() ([]int64, error) {
+	rows, err := db.Query("SELECT remote_id FROM remote_pending_reads ORDER BY remote_id")
+	if err != nil {
+		return nil, fmt.Errorf("querying pending remote reads: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning pending remote read: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ClearPendingRemoteReads forgets remote reads once they were sent
+func (db *DB) ClearPendingRemoteReads(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	_, err := db.Exec("DELETE FROM remote_pending_reads WHERE remote_id IN ("+placeholders+")", args...)
+	if err != nil {
+		return fmt.Errorf("clearing pending remote reads: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Session records where the last session of the reader left off, to tell
+// which articles arrived since
+type Session struct {
+	// LastArticleID is the newest article stored when the session ended;
+	// article IDs only grow, so newer articles have greater IDs
+	LastArticleID int64
+	EndedAt       time.Time
+}
+
+// GetLastSession returns the last session of the reader, or nil before the
+// first one ended
+func (db *DB) GetLastSession() (*Session, error) {
+	var s Session
+	err := db.QueryRow("SELECT last_article_id, ended_at FROM session_state WHERE id = 1").Scan(&s.LastArticleID, &s.EndedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying last session: %w", err)
+	}
+	return &s, nil
+}
+
+// RecordSession records that a session of the reader ended now, having
+// seen every article stored so far
+func (db *DB) RecordSession() error {
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO session_state (id, last_article_id, ended_at)
+		SELECT 1, COALESCE(MAX(id), 0), ? FROM articles`, time.Now())
+	if err != nil {
+		return fmt.Errorf("recording session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// PeriodCount is the number of articles read in a day or week
+type PeriodCount struct {
+	Period string
+	Count  int
+}
+
+// FeedCount is the number of articles read from a feed
+type FeedCount struct {
+	FeedName string
+	Count    int
+}
+
+// ReadingStats aggregates the read history for the stats view
+type ReadingStats struct {
+	PerDay     []PeriodCount
+	PerWeek    []PeriodCount
+	TopFeeds   []FeedCount
+	TotalRead  int
+	Opened     int // articles opened in the browser
+	ReadOpened int // read articles that were also opened in the browser
+	AvgRead    float64
+	AvgSkipped float64
+}
+
+// GetReadingStats aggregates read history over the last days days
+func (db *DB) GetReadingStats(days, topFeeds int) (*ReadingStats, error) {
+	var stats ReadingStats
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	// Timestamps are stored as text starting with the local date and time,
+	// so the leading characters can be used directly for bucketing
+	perDay, err := db.countReadsBy("substr(read_at, 1, 10)", cutoff)
+	if err != nil {
+		return nil, err
+	}
+	stats.PerDay = perDay
+
+	perWeek, err := db.countReadsBy("strftime('%Y-W%W', substr(read_at, 1, 19))", cutoff)
+	if err != nil {
+		return nil, err
+	}
+	stats.PerWeek = perWeek
+
+	rows, err := db.Query(`
+		SELECT feed_name, COUNT(*) AS reads
+		FROM read_history
+		GROUP BY feed_name
+		ORDER BY reads DESC, feed_name
+		LIMIT ?`,
+		topFeeds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying top feeds: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fc FeedCount
+		if err := rows.Scan(&fc.FeedName, &fc.Count); err != nil {
+			return nil, fmt.Errorf("scanning feed count: %w", err)
+		}
+		stats.TopFeeds = append(stats.TopFeeds, fc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := db.QueryRow(
+		"SELECT COUNT(*), COALESCE(AVG(relevance_score), 0) FROM read_history",
+	).Scan(&stats.TotalRead, &stats.AvgRead); err != nil {
+		return nil, fmt.Errorf("averaging read relevance: %w", err)
+	}
+
+	if err := db.QueryRow(`
+		SELECT
+			(SELECT COUNT(DISTINCT url) FROM article_opens),
+			(SELECT COUNT(*) FROM read_history h WHERE EXISTS (SELECT 1 FROM article_opens o WHERE o.url = h.url))`,
+	).Scan(&stats.Opened, &stats.ReadOpened); err != nil {
+		return nil, fmt.Errorf("counting opened articles: %w", err)
+	}
+
+	// Skipped articles are those still sitting unread in the database
+	if err := db.QueryRow(`
+		SELECT COALESCE(AVG(a.relevance_score), 0)
+		FROM articles a
+		LEFT JOIN read_articles r ON a.id = r.article_id
+		WHERE r.article_id IS NULL AND a.deleted_at IS NULL`,
+	).Scan(&stats.AvgSkipped); err != nil {
+		return nil, fmt.Errorf("averaging skipped relevance: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// countReadsBy counts reads since cutoff grouped by the given SQL expression
+func (db *DB) countReadsBy(expr string, cutoff time.Time) ([]PeriodCount, error) {
+	query := fmt.Sprintf(`
+		SELECT %s AS period, COUNT(*)
+		FROM read_history
+		WHERE read_at >= ?
+		GROUP BY period
+		ORDER BY period`, expr)
+
+	rows, err := db.Query(query, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("counting reads: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []PeriodCount
+	for rows.Next() {
+		var pc PeriodCount
+		if err := rows.Scan(&pc.Period, &pc.Count); err != nil {
+			return nil, fmt.Errorf("scanning read count: %w", err)
+		}
+		counts = append(counts, pc)
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Store is everything the reader, fetcher and scorer need from storage. DB
+// implements it with SQLite; other backends or test doubles can stand in
+// for it.
+type Store interface {
+	ArticleStore
+	FeedStore
+	InterestStore
+	ReadStateStore
+	EmbeddingStore
+	OfflineStore
+	EntityStore
+	MaintenanceStore
+}
+
+// ArticleStore keeps fetched articles and their scores
+type ArticleStore interface {
+	// AddArticle stores a new article, setting its ID and fetch time. It
+	// returns ErrDuplicate when an article with the same URL exists.
+	AddArticle(article *models.Article) error
+	UpdateArticleContent(article *models.Article) error
+	GetArticleByID(id int64) (*models.Article, error)
+	GetUnreadArticles(maxAge time.Duration) ([]models.Article, error)
+	// GetUnreadArticleList leaves out the content, for GetArticleContent
+	// to fetch when an article is shown
+	GetUnreadArticleList(maxAge time.Duration) ([]models.Article, error)
+	GetArticleContent(id int64) (string, error)
+	GetAllArticles() ([]models.Article, error)
+	GetQueuedArticles() ([]models.Article, error)
+	SetArticleQueued(articleID int64, queued bool) error
+	SnoozeArticle(articleID int64, until time.Time) error
+	MarkArticleMuted(articleID int64) error
+	UpdateArticleRelevance(articleID int64, score float64) error
+	UpdateArticleRelevances(scores map[int64]float64) error
+	MarkArticleUnscored(articleID int64) error
+	MarkAllUnscored() (int64, error)
+	// DeleteOldArticles and DeleteReadArticles move articles to the trash
+	DeleteOldArticles(maxAge time.Duration) error
+	DeleteReadArticles() error
+	GetTrashedArticles() ([]TrashedArticle, error)
+	RestoreArticle(articleID int64, maxAge time.Duration) (queued bool, err error)
+	SetArticleRemoteID(url string, remoteID int64) error
+	GetUnreadRemoteIDs() (map[int64]int64, error)
+}
+
+// FeedStore keeps subscriptions, their fetch health and mute rules
+type FeedStore interface {
+	AddFeed(feed *models.Feed) error
+	GetFeeds() ([]models.Feed, error)
+	GetEnabledFeeds() ([]models.Feed, error)
+	UpdateFeed(feed *models.Feed) error
+	UpdateFeedURL(feedID int64, url string) error
+	DeleteFeed(id int64) error
+	RecordFeedFetch(feedID int64, status int, fetchErr error, newestItem *time.Time) error
+	GetMutes() ([]models.Mute, error)
+	AddMute(mute *models.Mute) error
+	GetPageSnapshot(feedID int64) (string, bool, error)
+	SavePageSnapshot(feedID int64, content string) error
+}
+
+// InterestStore keeps the interests articles are scored against
+type InterestStore interface {
+	AddInterest(interest *models.UserInterest) error
+	GetInterests() ([]models.UserInterest, error)
+	GetArchivedInterests() ([]models.UserInterest, error)
+	UpdateInterest(interest *models.UserInterest) error
+	UpdateInterestEmbedding(id int64, embedding []byte) error
+	DeleteInterest(id int64) error
+	ArchiveInterest(id int64) error
+	RestoreInterest(id int64) error
+}
+
+// ReadStateStore keeps what was read, opened, starred and exported, reads
+// still to be synced and where the last session left off
+type ReadStateStore interface {
+	MarkArticleRead(articleID int64) error
+	MarkArticleReadRemotely(articleID int64) error
+	RecordArticleOpen(articleID int64) error
+	StarArticle(articleID int64) error
+	UnstarArticle(url string) error
+	GetStarredArticles() ([]models.HistoryEntry, error)
+	GetReadHistory() ([]models.HistoryEntry, error)
+	GetRecentReadHistory(limit int) ([]models.HistoryEntry, error)
+	GetReadingStats(days, topFeeds int) (*ReadingStats, error)
+	GetPendingRemoteReads() ([]int64, error)
+	ClearPendingRemoteReads(ids []int64) error
+	GetRaindropExports() (map[string]bool, error)
+	MarkRaindropExported(urls []string) error
+	GetLastSession() (*Session, error)
+	RecordSession() error
+}
+
+// EmbeddingStore caches embeddings by text hash and by article
+type EmbeddingStore interface {
+	GetCachedEmbedding(hash, model string) ([]byte, error)
+	CacheEmbedding(hash, model string, embedding []byte) error
+	PruneEmbeddings(maxAge time.Duration) (int64, error)
+	GetArticleEmbedding(articleID int64, model string) ([]byte, error)
+	SaveArticleEmbedding(articleID int64, model string, embedding []byte) error
+	GetUnreadArticleEmbeddings(model string) ([]ArticleEmbedding, error)
+	GetStoredArticleEmbeddings(model string) ([]ArticleEmbedding, error)
+}
+
+// OfflineStore keeps track of articles and images cached for reading
+// offline
+type OfflineStore interface {
+	GetArticlesToCache() ([]models.Article, error)
+	MarkArticleCached(articleID int64) error
+	AddOfflineImage(img OfflineImage) error
+	GetOfflineImages(articleID int64) (map[string]string, error)
+	EvictOfflineImages(maxBytes int64) ([]string, error)
+}
+
+// EntityStore keeps the people, companies and projects articles name
+type EntityStore interface {
+	GetArticlesWithoutEntities() ([]models.Article, error)
+	SaveArticleEntities(articleID int64, entities []models.Entity) error
+	GetArticleEntities(articleID int64) ([]models.Entity, error)
+	GetEntities() ([]EntityCount, error)
+	GetEntityArticles(entity models.Entity) ([]models.Article, error)
+}
+
+// MaintenanceStore reports on, cleans up and compacts the storage
+type MaintenanceStore interface {
+	GetStats() (*Stats, error)
+	PreviewCleanup(maxAge time.Duration) ([]CleanupCount, error)
+	Vacuum() error
+	VacuumIfNeeded(threshold int64) (bool, error)
+}
+
+var _ Store = (*DB)(nil)
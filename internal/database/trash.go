@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// TrashRetention is how long deleted articles can be restored before they
+// are purged for good
+const TrashRetention = 7 * 24 * time.Hour
+
+// TrashedArticle is a deleted article that can still be restored
+type TrashedArticle struct {
+	Article   models.Article
+	DeletedAt time.Time
+}
+
+// GetTrashedArticles retrieves the articles in the trash, most recently
+// deleted first
+func (db *DB) GetTrashedArticles() ([]TrashedArticle, error) {
+	rows, err := db.Query(`
+		SELECT ` + articleColumns + `, a.deleted_at
+		FROM articles a
+		WHERE a.deleted_at IS NOT NULL
+		ORDER BY a.deleted_at DESC, a.id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying trash: %w", err)
+	}
+	defer rows.Close()
+
+	var trashed []TrashedArticle
+	for rows.Next() {
+		var t TrashedArticle
+		row := articleRow{article: &t.Article, crypter: db.crypter}
+		if err := rows.Scan(append(row.fields(), &t.DeletedAt)...); err != nil {
+			return nil, fmt.Errorf("scanning trashed article: %w", err)
+		}
+		if err := row.finish(); err != nil {
+			return nil, err
+		}
+		trashed = append(trashed, t)
+	}
+	return trashed, rows.Err()
+}
+
+// RestoreArticle takes an article out of the trash and marks it unread
+// again. Its read history is kept. Articles older than maxAge go to the
+// read-later queue, which DeleteOldArticles leaves alone, as they would be
+// trashed again by the next cleanup otherwise; queued reports whether it
+// was one.
+func (db *DB) RestoreArticle(articleID int64, maxAge time.Duration) (queued bool, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE articles SET deleted_at = NULL WHERE id = ?", articleID); err != nil {
+		return false, fmt.Errorf("restoring article: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM read_articles WHERE article_id = ?", articleID); err != nil {
+		return false, fmt.Errorf("marking article unread: %w", err)
+	}
+	now := time.Now()
+	result, err := tx.Exec(
+		"UPDATE articles SET queued_at = COALESCE(queued_at, ?) WHERE id = ? AND published_at < ?",
+		now, articleID, now.Add(-maxAge),
+	)
+	if err != nil {
+		return false, fmt.Errorf("queueing restored article: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, tx.Commit()
+}
+
+// purgeTrash deletes articles trashed before cutoff
+func (db *DB) purgeTrash(cutoff time.Time) error {
+	if _, err := db.Exec("DELETE FROM articles WHERE deleted_at < ?", cutoff); err != nil {
+		return fmt.Errorf("emptying trash: %w", err)
+	}
+	return nil
+}
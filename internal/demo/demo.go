@@ -0,0 +1,124 @@
+// Package demo fills a throwaway database with sample feeds and articles, so
+// the reader can be shown or tried out without touching real data
+package demo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// feeds are real, so fetching adds live articles when online
+var feeds = []config.FeedConfig{
+	{URL: "https://hnrss.org/frontpage", Name: "Hacker News"},
+	{URL: "https://go.dev/blog/feed.atom", Name: "Go Blog"},
+	{URL: "https://feeds.arstechnica.com/arstechnica/index", Name: "Ars Technica"},
+}
+
+var interests = []config.InterestConfig{
+	{Description: "golang programming and software development", Weight: 1},
+	{Description: "self-hosting and home servers", Weight: 1},
+	{Description: "battery and energy storage research", Weight: 0.5},
+}
+
+// article is a sample article, published age ago in feeds[feed] and scored
+// as if Ollama had ranked it
+type article struct {
+	feed        int
+	title       string
+	slug        string
+	author      string
+	description string
+	tags        []string
+	age         time.Duration
+	score       float64
+}
+
+var articles = []article{
+	{0, "Show HN: A terminal RSS reader that ranks articles with a local LLM", "terminal-rss-reader", "",
+		"Embeddings from a local model score each article against a list of interests, so the best ones rise to the top without sending anything to the cloud.",
+		[]string{"show-hn"}, 2 * time.Hour, 0.86},
+	{1, "Range over function types", "range-functions", "Go Team",
+		"Iterators arrive in the language: how range-over-func works, when to reach for iter.Seq, and what it means for existing collection types.",
+		[]string{"go", "iterators"}, 5 * time.Hour, 0.82},
+	{0, "Ask HN: What do you self-host in 2026?", "ask-self-host", "",
+		"Readers share their home server setups, from media servers and password managers to full mail stacks, and what they gave up on.",
+		nil, 9 * time.Hour, 0.74},
+	{2, "Sodium-ion cells reach grid-scale storage pilots", "sodium-ion-storage", "Staff",
+		"Cheaper chemistry without lithium or cobalt is moving from the lab into container-sized installations next to solar farms.",
+		[]string{"science", "energy"}, 20 * time.Hour, 0.63},
+	{1, "Structured logging with slog, two years in", "slog-two-years", "Go Team",
+		"Lessons from migrating large codebases to log/slog: handlers, performance, and keeping logs useful.",
+		[]string{"go"}, 30 * time.Hour, 0.71},
+	{0, "SQLite as an application file format, revisited", "sqlite-file-format", "",
+		"Why a single SQLite file beats a directory of JSON for desktop apps, with notes on WAL mode and backups.",
+		nil, 40 * time.Hour, 0.58},
+	{2, "The best budget mini PCs for a home lab", "mini-pc-home-lab", "Staff",
+		"Low-power x86 boxes that idle under 10 watts and still run a dozen containers.",
+		[]string{"gear"}, 3 * 24 * time.Hour, 0.55},
+	{2, "A new space telescope maps the early universe", "space-telescope", "Staff",
+		"First light images reveal galaxies far earlier than models predicted.",
+		[]string{"science", "space"}, 4 * 24 * time.Hour, 0.31},
+	{0, "The history of the spreadsheet", "spreadsheet-history", "",
+		"From VisiCalc to today, how the spreadsheet became the most widely used programming environment.",
+		nil, 6 * 24 * time.Hour, 0.27},
+	{2, "Review: the latest flagship phone is fast, expensive and familiar", "phone-review", "Staff",
+		"A faster chip and a better camera, but little that changes how you use it.",
+		[]string{"gear", "phones"}, 8 * 24 * time.Hour, 0.12},
+}
+
+// Config returns the default configuration with the demo feeds and
+// interests and a temporary database. Nothing else of the user's config is
+// used, so the demo never syncs, notifies or runs hooks.
+func Config() *config.Config {
+	cfg := config.Default()
+	cfg.Database.Path = config.MemoryDatabase
+	cfg.Database.CachePath = config.MemoryDatabase
+	cfg.Feeds = feeds
+	cfg.Interests = interests
+	// Keep the demo out of the real offline cache
+	cfg.Offline.MaxMB = -1
+	cfg.ApplyDefaults()
+	return cfg
+}
+
+// Seed adds the sample articles to db, whose feeds must already hold the
+// demo feeds. The articles link to example.com.
+func Seed(db database.Store) error {
+	stored, err := db.GetFeeds()
+	if err != nil {
+		return err
+	}
+	feedIDs := make(map[string]int64, len(stored))
+	for _, f := range stored {
+		feedIDs[f.URL] = f.ID
+	}
+
+	now := time.Now()
+	for _, a := range articles {
+		feedID, ok := feedIDs[feeds[a.feed].URL]
+		if !ok {
+			return fmt.Errorf("demo feed %s is missing", feeds[a.feed].Name)
+		}
+		article := &models.Article{
+			FeedID:      feedID,
+			Title:       a.title,
+			URL:         "https://example.com/demo/" + a.slug,
+			Content:     "<p>" + a.description + "</p><p>This is a sample article of the newsreadr demo.</p>",
+			Description: a.description,
+			PublishedAt: now.Add(-a.age),
+			Tags:        a.tags,
+			Author:      a.author,
+		}
+		if err := db.AddArticle(article); err != nil {
+			return fmt.Errorf("adding demo article: %w", err)
+		}
+		if err := db.UpdateArticleRelevance(article.ID, a.score); err != nil {
+			return err
+		}
+	}
+	return nil
+}
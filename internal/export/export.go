@@ -0,0 +1,159 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Write encodes history entries to w in the given format ("json" or "csv")
+func Write(w io.Writer, format string, entries []models.HistoryEntry) error {
+	switch format {
+	case "json":
+		return WriteJSON(w, entries)
+	case "csv":
+		return WriteCSV(w, entries)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// WriteJSON writes history entries as an indented JSON array
+func WriteJSON(w io.Writer, entries []models.HistoryEntry) error {
+	if entries == nil {
+		entries = []models.HistoryEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV writes history entries as CSV with a header row
+func WriteCSV(w io.Writer, entries []models.HistoryEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"title", "url", "feed", "published_at", "read_at", "starred_at", "score", "opened_at"}); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+
+	for _, e := range entries {
+		record := []string{
+			e.Title,
+			e.URL,
+			e.FeedName,
+			e.PublishedAt.Format(time.RFC3339),
+			formatTime(e.ReadAt),
+			formatTime(e.StarredAt),
+			strconv.FormatFloat(e.RelevanceScore, 'f', 4, 64),
+			formatTime(e.OpenedAt),
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("writing CSV record: %w", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatTime formats an optional timestamp, returning "" when unset
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// listedArticle is an unread article as listed by WriteArticlesJSON
+type listedArticle struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Feed        string    `json:"feed"`
+	Author      string    `json:"author,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	Score       *float64  `json:"score"`
+	Starred     bool      `json:"starred"`
+	Queued      bool      `json:"queued"`
+	Tags        []string  `json:"tags,omitempty"`
+}
+
+// WriteArticles lists articles to w in the given format ("json" or "tsv")
+func WriteArticles(w io.Writer, format string, articles []models.Article) error {
+	switch format {
+	case "json":
+		return WriteArticlesJSON(w, articles)
+	case "tsv":
+		return WriteArticlesTSV(w, articles)
+	default:
+		return fmt.Errorf("unsupported list format %q", format)
+	}
+}
+
+// WriteArticlesJSON writes articles as an indented JSON array, without
+// their content. Articles awaiting scoring have a null score.
+func WriteArticlesJSON(w io.Writer, articles []models.Article) error {
+	listed := make([]listedArticle, len(articles))
+	for i, a := range articles {
+		listed[i] = listedArticle{
+			ID:          a.ID,
+			Title:       a.Title,
+			URL:         a.URL,
+			Feed:        a.FeedName,
+			Author:      a.Author,
+			PublishedAt: a.PublishedAt,
+			Starred:     a.Starred,
+			Queued:      a.Queued,
+			Tags:        a.Tags,
+		}
+		if a.Scored {
+			score := a.RelevanceScore
+			listed[i].Score = &score
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(listed); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteArticlesTSV writes an article per line, without a header, as the
+// tab separated ID, score, feed, publication date, title and URL. Articles
+// awaiting scoring have an empty score; tabs and line breaks in the fields
+// become spaces.
+func WriteArticlesTSV(w io.Writer, articles []models.Article) error {
+	for _, a := range articles {
+		score := ""
+		if a.Scored {
+			score = strconv.FormatFloat(a.RelevanceScore, 'f', 2, 64)
+		}
+		fields := []string{
+			strconv.FormatInt(a.ID, 10),
+			score,
+			tsvField(a.FeedName),
+			a.PublishedAt.Format(time.RFC3339),
+			tsvField(a.Title),
+			tsvField(a.URL),
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return fmt.Errorf("writing TSV: %w", err)
+		}
+	}
+	return nil
+}
+
+// tsvField collapses the whitespace of a field, tabs and line breaks
+// included
+func tsvField(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,147 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Archives that keep snapshots of articles
+const (
+	ArchiveWayback = "wayback"
+	ArchiveToday   = "archive.today"
+)
+
+// ErrNoSnapshot is returned when an archive has no copy of an article
+var ErrNoSnapshot = errors.New("no archived copy found")
+
+// paywallMarkers are phrases of paywall and cookie-wall notices
+var paywallMarkers = []string{
+	"subscribe to continue", "subscribe to read", "subscribers only",
+	"subscriber-only", "already a subscriber", "for subscribers",
+	"sign in to continue", "sign in to read", "log in to continue",
+	"create a free account", "become a member to", "members only",
+	"to continue reading", "you have reached your limit", "free articles left",
+	"we use cookies", "accept cookies", "accept all cookies",
+	"cookie consent", "consent to the use of cookies", "manage your cookie",
+}
+
+// paywallMaxWords is the length beyond which content is taken to be the
+// article, whatever notices it carries
+const paywallMaxWords = 300
+
+// IsPaywalled reports whether article content looks like a paywall or
+// cookie-wall notice rather than the article itself
+func IsPaywalled(content string) bool {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return false
+	}
+	text := strings.ToLower(strings.Join(strings.Fields(doc.Text()), " "))
+	if len(strings.Fields(text)) > paywallMaxWords {
+		return false
+	}
+	for _, marker := range paywallMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchArchived replaces the article's content with a snapshot from the
+// Wayback Machine or archive.today. The snapshot is extracted with the
+// domain's rule, or else from its article or main element. It returns
+// ErrNoSnapshot when the archive has no copy.
+func (f *Fetcher) FetchArchived(article *models.Article, archive string) error {
+	var snapshot string
+	var err error
+	switch archive {
+	case ArchiveWayback:
+		snapshot, err = f.waybackSnapshot(article.URL)
+	case ArchiveToday:
+		snapshot = "https://archive.ph/newest/" + article.URL
+	default:
+		return fmt.Errorf("unknown archive %q", archive)
+	}
+	if err != nil {
+		return err
+	}
+
+	doc, err := f.fetchPage(f.ctx, snapshot)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return ErrNoSnapshot
+	}
+	if err != nil {
+		return err
+	}
+
+	// Links resolve against the original page, as the Wayback Machine serves
+	// the page unchanged
+	base, err := url.Parse(article.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url %s: %w", article.URL, err)
+	}
+	content := ""
+	if rule, ok := f.cfg.Fetch.Rule(ArticleDomain(article.URL)); ok {
+		if content, err = extractContent(doc, rule, base); err != nil {
+			return err
+		}
+	}
+	if content == "" {
+		if content, err = extractGeneric(doc, base); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(content) == "" {
+		return ErrNoSnapshot
+	}
+	if IsPaywalled(content) {
+		return fmt.Errorf("the archived copy is paywalled too")
+	}
+
+	article.Content = content
+	return nil
+}
+
+// waybackSnapshot returns the URL of the Wayback Machine's closest snapshot
+// of a page, in its original form without the archive's toolbar
+func (f *Fetcher) waybackSnapshot(pageURL string) (string, error) {
+	req, err := f.newRequest(f.ctx, "https://archive.org/wayback/available?url="+url.QueryEscape(pageURL), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("querying the Wayback Machine: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("querying the Wayback Machine: %w", &HTTPError{resp.StatusCode})
+	}
+
+	var result struct {
+		ArchivedSnapshots struct {
+			Closest struct {
+				Available bool   `json:"available"`
+				Timestamp string `json:"timestamp"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decoding Wayback Machine response: %w", err)
+	}
+	closest := result.ArchivedSnapshots.Closest
+	if !closest.Available || closest.Timestamp == "" {
+		return "", ErrNoSnapshot
+	}
+	return "https://web.archive.org/web/" + closest.Timestamp + "id_/" + pageURL, nil
+}
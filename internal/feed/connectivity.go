@@ -0,0 +1,49 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// connectivityProbes is how many feeds are tried before declaring the network down
+const connectivityProbes = 3
+
+// CheckConnectivity reports whether any of the first few enabled feeds can
+// be reached within timeout. Without feeds the network is assumed up.
+func (f *Fetcher) CheckConnectivity(timeout time.Duration) error {
+	feeds, err := f.db.GetEnabledFeeds()
+	if err != nil {
+		return fmt.Errorf("getting enabled feeds: %w", err)
+	}
+	feeds = webFeeds(feeds)
+	if len(feeds) == 0 {
+		return nil
+	}
+	if len(feeds) > connectivityProbes {
+		feeds = feeds[:connectivityProbes]
+	}
+
+	var lastErr error
+	for _, feed := range feeds {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		req, err := http.NewRequestWithContext(ctx, "HEAD", WebURL(feed.URL), nil)
+		if err != nil {
+			cancel()
+			lastErr = err
+			continue
+		}
+		resp, err := f.client.Do(req)
+		cancel()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		// Any HTTP response means the network itself is up
+		return nil
+	}
+
+	return fmt.Errorf("network unreachable: %w", lastErr)
+}
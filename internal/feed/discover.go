@@ -0,0 +1,76 @@
+package feed
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// feedLinkTypes are the <link rel="alternate"> types that advertise a feed
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+}
+
+// Discover fetches an HTML page and returns the feed URLs it advertises
+func (f *Fetcher) Discover(pageURL string) ([]string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing url %s: %w", pageURL, err)
+	}
+
+	req, err := f.newRequest(f.ctx, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetching %s: %w", pageURL, &HTTPError{resp.StatusCode})
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", pageURL, err)
+	}
+
+	var feeds []string
+	seen := map[string]bool{}
+	doc.Find(`link[rel="alternate"]`).Each(func(_ int, sel *goquery.Selection) {
+		typ, _ := sel.Attr("type")
+		href, ok := sel.Attr("href")
+		if !ok || !feedLinkTypes[strings.ToLower(typ)] {
+			return
+		}
+		ref, err := url.Parse(strings.TrimSpace(href))
+		if err != nil {
+			return
+		}
+		abs := base.ResolveReference(ref).String()
+		if !seen[abs] {
+			seen[abs] = true
+			feeds = append(feeds, abs)
+		}
+	})
+
+	return feeds, nil
+}
+
+// SiteURL returns the root of the site hosting a feed, used as the starting
+// point for discovering a replacement
+func SiteURL(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || u.Host == "" {
+		return feedURL
+	}
+	return u.Scheme + "://" + u.Host + "/"
+}
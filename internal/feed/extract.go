@@ -0,0 +1,189 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// lazySources are the attributes lazy-loading scripts read image sources
+// from, in order of preference
+var lazySources = []string{"data-src", "data-lazy-src", "data-original", "data-srcset"}
+
+// genericContent are tried in turn on pages no rule covers
+var genericContent = []string{"article", "main", "body"}
+
+// genericRemove are dropped from pages no rule covers
+var genericRemove = []string{"script", "style", "nav", "header", "footer", "aside", "form", "iframe"}
+
+// dateLayouts are the formats tried for dates extracted from pages
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+}
+
+// Extract fetches the article's page and replaces its content, and its
+// author and date where the rule finds them, with what the domain's
+// extraction rule selects. It returns false when no rule covers the domain.
+func (f *Fetcher) Extract(article *models.Article) (bool, error) {
+	rule, ok := f.cfg.Fetch.Rule(ArticleDomain(article.URL))
+	if !ok {
+		return false, nil
+	}
+
+	base, err := url.Parse(article.URL)
+	if err != nil {
+		return false, fmt.Errorf("parsing url %s: %w", article.URL, err)
+	}
+	doc, err := f.fetchPage(f.ctx, article.URL)
+	if err != nil {
+		return false, err
+	}
+
+	content, err := extractContent(doc, rule, base)
+	if err != nil {
+		return false, err
+	}
+	if content == "" {
+		return false, fmt.Errorf("extracting %s: nothing matches %q", article.URL, rule.Content)
+	}
+	article.Content = content
+
+	if rule.Author != "" {
+		if author := strings.TrimSpace(doc.Find(rule.Author).First().Text()); author != "" {
+			article.Author = author
+		}
+	}
+	if rule.Date != "" {
+		if published, ok := extractDate(doc.Find(rule.Date).First()); ok {
+			article.PublishedAt = published
+		}
+	}
+	return true, nil
+}
+
+// fetchPage fetches and parses an HTML page, keeping its final URL
+func (f *Fetcher) fetchPage(ctx context.Context, pageURL string) (*goquery.Document, error) {
+	req, err := f.newRequest(ctx, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetching %s: %w", pageURL, &HTTPError{resp.StatusCode})
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", pageURL, err)
+	}
+	// Where the page ended up after redirects
+	doc.Url = resp.Request.URL
+	return doc, nil
+}
+
+// extractContent returns the HTML of the elements matching the rule's
+// content selector, with lazy-loaded images given their real source and
+// links made absolute
+func extractContent(doc *goquery.Document, rule config.ExtractRule, base *url.URL) (string, error) {
+	for _, sel := range rule.Remove {
+		doc.Find(sel).Remove()
+	}
+
+	// Pages that lazy-load images keep a plain copy for browsers without
+	// JavaScript, which the HTML parser leaves as text
+	doc.Find("noscript").Each(func(_ int, s *goquery.Selection) {
+		s.ReplaceWithHtml(s.Text())
+	})
+
+	var parts []string
+	var err error
+	doc.Find(rule.Content).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		s.Find("img").Each(func(_ int, img *goquery.Selection) {
+			for _, attr := range lazySources {
+				if src, ok := img.Attr(attr); ok && src != "" {
+					img.SetAttr("src", strings.Fields(src)[0])
+					break
+				}
+			}
+		})
+		resolveLinks(s, base)
+
+		var html string
+		html, err = goquery.OuterHtml(s)
+		parts = append(parts, html)
+		return err == nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// extractGeneric returns the page's article or main element, or else its
+// body, without scripts and navigation, for pages no rule covers
+func extractGeneric(doc *goquery.Document, base *url.URL) (string, error) {
+	for _, sel := range genericContent {
+		if doc.Find(sel).Length() > 0 {
+			return extractContent(doc, config.ExtractRule{Content: sel, Remove: genericRemove}, base)
+		}
+	}
+	return "", nil
+}
+
+// resolveLinks makes link and image URLs within s absolute
+func resolveLinks(s *goquery.Selection, base *url.URL) {
+	for _, ref := range [][2]string{{"a", "href"}, {"img", "src"}} {
+		s.Find(ref[0]).Each(func(_ int, el *goquery.Selection) {
+			value, ok := el.Attr(ref[1])
+			// Links within the page stay relative, like footnotes
+			if !ok || strings.HasPrefix(value, "#") {
+				return
+			}
+			u, err := url.Parse(strings.TrimSpace(value))
+			if err != nil {
+				return
+			}
+			el.SetAttr(ref[1], base.ResolveReference(u).String())
+		})
+	}
+}
+
+// extractDate reads a date from an element's datetime or content attribute,
+// or else its text
+func extractDate(s *goquery.Selection) (time.Time, bool) {
+	if s.Length() == 0 {
+		return time.Time{}, false
+	}
+	value := strings.TrimSpace(s.Text())
+	for _, attr := range []string{"datetime", "content"} {
+		if v, ok := s.Attr(attr); ok {
+			value = strings.TrimSpace(v)
+			break
+		}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
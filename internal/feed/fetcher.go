@@ -1,79 +1,452 @@
 package feed
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/mmcdole/gofeed"
+	"github.com/thomaskoefod/newsreadr/internal/config"
 	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/github"
+	"github.com/thomaskoefod/newsreadr/internal/hooks"
+	"github.com/thomaskoefod/newsreadr/internal/httpclient"
+	"github.com/thomaskoefod/newsreadr/internal/nextcloud"
 	"github.com/thomaskoefod/newsreadr/pkg/models"
 )
 
 type Fetcher struct {
-	db     *database.DB
+	db     database.Store
+	cfg    *config.Config
 	parser *gofeed.Parser
+	client *http.Client
+	hooks  *hooks.Runner
+	// nextcloud, when set, replaces fetching the feeds directly
+	nextcloud *nextcloud.Client
+	// ctx is cancelled by Shutdown, aborting the requests in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.RWMutex
+	mutes *MuteMatcher
 }
 
-func NewFetcher(db *database.DB) *Fetcher {
-	return &Fetcher{
+func NewFetcher(db database.Store, cfg *config.Config) *Fetcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Fetcher{
 		db:     db,
-		parser: gofeed.NewParser(),
+		cfg:    cfg,
+		parser: newParser(),
+		client: httpclient.New(cfg.HTTP),
+		hooks:  hooks.New(cfg.Hooks),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if cfg.Nextcloud.URL != "" {
+		f.nextcloud = nextcloud.NewClient(cfg.Nextcloud.URL, cfg.Nextcloud.Username, cfg.Nextcloud.Password, f.client)
 	}
+	return f
+}
+
+// Shutdown aborts the requests in flight and stops a running fetch before
+// its next feed, so quitting doesn't wait for every feed
+func (f *Fetcher) Shutdown() {
+	f.cancel()
 }
 
-// FetchFeed fetches and parses an RSS feed
-func (f *Fetcher) FetchFeed(feedURL string) (*gofeed.Feed, error) {
-	feed, err := f.parser.ParseURL(feedURL)
+// newRequest builds a GET request with the User-Agent plus any credentials
+// and headers configured for the feed. ctx derives from f.ctx, so Shutdown
+// aborts it.
+func (f *Fetcher) newRequest(ctx context.Context, url string, settings *config.FeedConfig) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("parsing feed %s: %w", feedURL, err)
+		return nil, fmt.Errorf("creating request for %s: %w", url, err)
+	}
+	req.Header.Set("User-Agent", httpclient.UserAgent(f.cfg.HTTP))
+
+	if settings != nil {
+		for k, v := range settings.Headers {
+			req.Header.Set(k, v)
+		}
+		if settings.Username != "" || settings.Password != "" {
+			req.SetBasicAuth(settings.Username, settings.Password)
+		}
 	}
-	return feed, nil
+	return req, nil
 }
 
-// FetchAndStore fetches a feed and stores new articles in the database
-func (f *Fetcher) FetchAndStore(feed *models.Feed) (int, error) {
-	rssFeed, err := f.FetchFeed(feed.URL)
+// dropHeadersOffHost removes a feed's configured headers, such as API keys,
+// from a redirect to another host. Go itself only drops Authorization and
+// cookies.
+func dropHeadersOffHost(req *http.Request, via []*http.Request, settings *config.FeedConfig) {
+	if settings == nil || req.URL.Host == via[0].URL.Host {
+		return
+	}
+	for k := range settings.Headers {
+		req.Header.Del(k)
+	}
+}
+
+// LoadMutes reloads mute rules from the config and the database
+func (f *Fetcher) LoadMutes() error {
+	mutes, err := f.db.GetMutes()
 	if err != nil {
-		return 0, err
+		return err
+	}
+	matcher, err := NewMuteMatcher(append(f.cfg.Mute.Mutes(), mutes...))
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.mutes = matcher
+	f.mu.Unlock()
+	return nil
+}
+
+// Mutes returns the currently loaded mute rules
+func (f *Fetcher) Mutes() *MuteMatcher {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.mutes
+}
+
+// HTTPError is returned when a feed responds with a non-success status
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// TimeoutError is returned when a feed takes longer than its timeout
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.Timeout)
+}
+
+// FetchFeed fetches and parses an RSS feed, applying any per-feed settings.
+// When every redirect on the way was permanent (301/308), movedTo holds the
+// feed's new URL. Feeds taking longer than their timeout fail with a
+// TimeoutError.
+func (f *Fetcher) FetchFeed(feedURL string, settings *config.FeedConfig) (feed *gofeed.Feed, movedTo string, err error) {
+	ctx := f.ctx
+	timeout := f.cfg.FeedTimeout(settings)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	feed, movedTo, err = f.fetchFeed(ctx, feedURL, settings)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("fetching feed %s: %w", feedURL, &TimeoutError{timeout})
+	}
+	return feed, movedTo, err
+}
+
+func (f *Fetcher) fetchFeed(ctx context.Context, feedURL string, settings *config.FeedConfig) (feed *gofeed.Feed, movedTo string, err error) {
+	if IsSocial(feedURL) {
+		feed, err = f.fetchSocial(ctx, feedURL, settings)
+		return feed, "", err
+	}
+	if IsWatched(feedURL) {
+		feed, err = f.fetchWatched(ctx, feedURL, settings)
+		return feed, "", err
 	}
+	if github.IsIssues(feedURL) {
+		feed, err = f.fetchGitHubIssues(ctx, feedURL, settings)
+		return feed, "", err
+	}
+
+	permanent := true
+	client := *f.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		if req.Response != nil {
+			code := req.Response.StatusCode
+			if code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+				permanent = false
+			}
+		}
+		dropHeadersOffHost(req, via, settings)
+		return nil
+	}
+
+	req, err := f.newRequest(ctx, feedURL, settings)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching feed %s: %w", feedURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("fetching feed %s: %w", feedURL, &HTTPError{resp.StatusCode})
+	}
+
+	feed, err = f.parser.Parse(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsing feed %s: %w", feedURL, err)
+	}
+
+	if finalURL := resp.Request.URL.String(); permanent && finalURL != feedURL {
+		movedTo = finalURL
+	}
+	return feed, movedTo, nil
+}
+
+// FeedResult counts what happened to the items of one feed during a fetch
+type FeedResult struct {
+	FeedID     int64
+	FeedName   string
+	New        int
+	Duplicates int
+	Undated    int
+	Muted      int
+	Err        error
+	// HookErr holds the first on_article_fetched hook failure
+	HookErr error
+	// ExtractErr holds the first failure extracting an article's page,
+	// whose feed content is kept instead
+	ExtractErr error
+}
+
+// Summary collects the per-feed results of fetching all feeds
+type Summary struct {
+	Results []FeedResult
+}
+
+// TotalNew returns the number of new articles across all feeds
+func (s *Summary) TotalNew() int {
+	total := 0
+	for _, r := range s.Results {
+		total += r.New
+	}
+	return total
+}
+
+// Failed returns the number of feeds that could not be fetched
+func (s *Summary) Failed() int {
+	failed := 0
+	for _, r := range s.Results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
 
-	newArticles := 0
+// HookErr returns the first hook failure across all feeds
+func (s *Summary) HookErr() error {
+	for _, r := range s.Results {
+		if r.HookErr != nil {
+			return r.HookErr
+		}
+	}
+	return nil
+}
+
+// ExtractErr returns the first article extraction failure across all feeds
+func (s *Summary) ExtractErr() error {
+	for _, r := range s.Results {
+		if r.ExtractErr != nil {
+			return r.ExtractErr
+		}
+	}
+	return nil
+}
+
+// FetchAndStore fetches a feed and stores new articles in the database,
+// recording the outcome in the feed's health columns. onNew, when set, is
+// called with each new article right after it is stored.
+func (f *Fetcher) FetchAndStore(feed *models.Feed, onNew func(*models.Article)) (FeedResult, error) {
+	result := FeedResult{FeedID: feed.ID, FeedName: feed.Name}
+
+	rssFeed, movedTo, err := f.FetchFeed(feed.URL, f.cfg.FeedSettings(feed.URL, feed.Name))
+	if err != nil {
+		status := 0
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			status = httpErr.StatusCode
+		}
+		if recErr := f.db.RecordFeedFetch(feed.ID, status, err, nil); recErr != nil {
+			return result, recErr
+		}
+		return result, err
+	}
+
+	if movedTo != "" {
+		if err := f.db.UpdateFeedURL(feed.ID, movedTo); err != nil {
+			return result, fmt.Errorf("feed moved to %s but could not be updated: %w", movedTo, err)
+		}
+		feed.URL = movedTo
+	}
+
+	var newestItem *time.Time
+	if IsWatched(feed.URL) {
+		if newestItem, err = f.storePageChange(feed, rssFeed, &result, onNew); err != nil {
+			return result, err
+		}
+		rssFeed.Items = nil
+	}
 	for _, item := range rssFeed.Items {
 		article := f.convertToArticle(item, feed.ID)
 		if article == nil {
+			result.Undated++
 			continue
 		}
+		if newestItem == nil || article.PublishedAt.After(*newestItem) {
+			published := article.PublishedAt
+			newestItem = &published
+		}
 
-		// Try to insert, ignore duplicates (unique URL constraint)
-		if err := f.db.AddArticle(article); err != nil {
-			// Skip if duplicate
-			continue
+		if err := f.storeArticle(article, feed, &result, onNew); err != nil && !errors.Is(err, database.ErrDuplicate) {
+			return result, err
+		}
+	}
+
+	if err := f.db.RecordFeedFetch(feed.ID, http.StatusOK, nil, newestItem); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// storeArticle applies mutes and stores a fetched article, counting the
+// outcome in result. It returns database.ErrDuplicate for articles already
+// stored.
+func (f *Fetcher) storeArticle(article *models.Article, feed *models.Feed, result *FeedResult, onNew func(*models.Article)) error {
+	if repo := github.Repo(feed.URL); repo != "" {
+		labelGitHub(article, repo)
+	}
+
+	muted := f.Mutes().Match(article)
+	if muted && f.cfg.Mute.Action != "read" {
+		result.Muted++
+		return nil
+	}
+
+	// Try to insert, counting duplicates (unique URL constraint)
+	if err := f.db.AddArticle(article); err != nil {
+		if errors.Is(err, database.ErrDuplicate) {
+			result.Duplicates++
+		}
+		return err
+	}
+
+	if muted {
+		if err := f.db.MarkArticleMuted(article.ID); err != nil {
+			return err
+		}
+		result.Muted++
+		return nil
+	}
+	result.New++
+
+	// Sites with an extraction rule get their article from its page, and
+	// journal articles without an abstract get theirs from Crossref
+	extracted, err := f.Extract(article)
+	if err == nil && !extracted {
+		extracted, err = f.fetchAbstract(article)
+	}
+	if err != nil {
+		if result.ExtractErr == nil {
+			result.ExtractErr = err
+		}
+	} else if extracted {
+		if err := f.db.UpdateArticleContent(article); err != nil {
+			return err
 		}
-		newArticles++
 	}
 
-	return newArticles, nil
+	article.FeedName = feed.Name
+	if err := f.hooks.Fire(hooks.ArticleFetched, article); err != nil && result.HookErr == nil {
+		result.HookErr = err
+	}
+	if onNew != nil {
+		onNew(article)
+	}
+	return nil
 }
 
-// FetchAllFeeds fetches all enabled feeds
-func (f *Fetcher) FetchAllFeeds() (int, error) {
+// FetchAllFeeds fetches all enabled feeds, continuing past feeds that fail,
+// or syncs with Nextcloud News when it is configured, then reads the
+// newsletter mailbox if there is one. onNew is passed on to FetchAndStore.
+// onFeedDone, when set, is called after each feed with its result and how
+// many of the feeds are done.
+func (f *Fetcher) FetchAllFeeds(onNew func(*models.Article), onFeedDone func(result FeedResult, done, total int)) (*Summary, error) {
 	feeds, err := f.db.GetEnabledFeeds()
 	if err != nil {
-		return 0, fmt.Errorf("getting enabled feeds: %w", err)
+		return nil, fmt.Errorf("getting enabled feeds: %w", err)
+	}
+
+	if err := f.LoadMutes(); err != nil {
+		return nil, fmt.Errorf("loading mutes: %w", err)
 	}
 
-	totalNew := 0
+	summary := &Summary{}
+	feeds = webFeeds(feeds)
+	if f.nextcloud != nil {
+		if summary, err = f.syncNextcloud(onNew, onFeedDone); err != nil {
+			return summary, err
+		}
+		// Timelines and watched pages are still fetched here
+		var direct []models.Feed
+		for _, feed := range feeds {
+			if !isPlainFeed(feed.URL) {
+				direct = append(direct, feed)
+			}
+		}
+		feeds = direct
+	}
+
+	done := len(summary.Results)
 	for _, feed := range feeds {
-		count, err := f.FetchAndStore(&feed)
-		if err != nil {
-			// Log error but continue with other feeds
-			fmt.Printf("Error fetching feed %s: %v\n", feed.Name, err)
-			continue
+		if err := f.ctx.Err(); err != nil {
+			return summary, err
+		}
+		result, err := f.FetchAndStore(&feed, onNew)
+		result.Err = err
+		summary.Results = append(summary.Results, result)
+		if onFeedDone != nil {
+			onFeedDone(result, len(summary.Results), done+len(feeds))
 		}
-		totalNew += count
 	}
 
-	return totalNew, nil
+	if f.cfg.Newsletters.Host != "" {
+		if err := f.fetchNewsletters(summary, onNew, onFeedDone); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// isPlainFeed reports whether a feed is an RSS or Atom feed, which services
+// like Nextcloud News can subscribe to
+func isPlainFeed(feedURL string) bool {
+	return !IsNewsletter(feedURL) && !IsSocial(feedURL) && !IsWatched(feedURL) && !github.IsIssues(feedURL)
+}
+
+// webFeeds leaves out the newsletter senders, which aren't fetched over HTTP
+func webFeeds(feeds []models.Feed) []models.Feed {
+	var web []models.Feed
+	for _, feed := range feeds {
+		if !IsNewsletter(feed.URL) {
+			web = append(web, feed)
+		}
+	}
+	return web
 }
 
 // convertToArticle converts a gofeed.Item to our Article model
@@ -107,12 +480,18 @@ func (f *Fetcher) convertToArticle(item *gofeed.Item, feedID int64) *models.Arti
 		}
 	}
 
-	return &models.Article{
+	article := &models.Article{
 		FeedID:      feedID,
 		Title:       item.Title,
 		URL:         item.Link,
 		Content:     content,
 		Description: description,
 		PublishedAt: publishedAt,
+		Tags:        item.Categories,
+		Author:      itemAuthor(item),
+		GUID:        item.GUID,
+		CommentsURL: item.Custom[commentsKey],
 	}
+	paperMetadata(item, article)
+	return article
 }
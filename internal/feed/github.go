@@ -0,0 +1,109 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/github"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// githubAPI serves the GitHub REST API. Without a token it allows 60
+// requests an hour, plenty for a few issue feeds.
+const githubAPI = "https://api.github.com/repos/"
+
+// githubMarkdown renders issue descriptions, written in GitHub's markdown
+var githubMarkdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// githubIssue is an issue, or pull request, as the API lists them
+type githubIssue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	HTMLURL   string    `json:"html_url"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// fetchGitHubIssues reads the newest issues of a repository, leaving out
+// pull requests, as a feed. A token can be sent with the feed's headers
+// (Authorization: Bearer TOKEN) for private repositories.
+func (f *Fetcher) fetchGitHubIssues(ctx context.Context, feedURL string, settings *config.FeedConfig) (*gofeed.Feed, error) {
+	repo := github.Repo(feedURL)
+	if repo == "" {
+		return nil, fmt.Errorf("%s: not a repository's issues (github://github.com/ORG/REPO/issues)", feedURL)
+	}
+	var issues []githubIssue
+	if err := f.getJSON(ctx, githubAPI+repo+"/issues?state=all&sort=created&direction=desc&per_page=50", settings, &issues); err != nil {
+		return nil, err
+	}
+
+	feed := &gofeed.Feed{Title: repo + " issues"}
+	for _, issue := range issues {
+		if issue.PullRequest != nil {
+			continue
+		}
+		var body bytes.Buffer
+		if err := githubMarkdown.Convert([]byte(issue.Body), &body); err != nil {
+			return nil, fmt.Errorf("rendering issue #%d: %w", issue.Number, err)
+		}
+		published := issue.CreatedAt
+		item := &gofeed.Item{
+			Title:           fmt.Sprintf("%s (#%d)", issue.Title, issue.Number),
+			Link:            issue.HTMLURL,
+			Content:         body.String(),
+			PublishedParsed: &published,
+			Authors:         []*gofeed.Person{{Name: issue.User.Login}},
+			GUID:            issue.HTMLURL,
+		}
+		for _, label := range issue.Labels {
+			item.Categories = append(item.Categories, label.Name)
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// labelGitHub names the repository in the title and tags of an article
+// from a repository's feed, and makes the links in its release notes or
+// description absolute
+func labelGitHub(article *models.Article, repo string) {
+	if !strings.HasPrefix(article.Title, repo) {
+		article.Title = repo + ": " + article.Title
+	}
+	tagged := false
+	for _, tag := range article.Tags {
+		tagged = tagged || tag == repo
+	}
+	if !tagged {
+		article.Tags = append([]string{repo}, article.Tags...)
+	}
+
+	base, err := url.Parse(article.URL)
+	if err != nil || article.Content == "" {
+		return
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(article.Content))
+	if err != nil {
+		return
+	}
+	resolveLinks(doc.Selection, base)
+	if content, err := doc.Find("body").Html(); err == nil {
+		article.Content = content
+	}
+}
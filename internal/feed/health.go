@@ -0,0 +1,71 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// HealthState classifies how a feed has been behaving
+type HealthState int
+
+const (
+	HealthOK HealthState = iota
+	HealthUnknown
+	HealthSilent
+	HealthFailing
+	HealthDead
+)
+
+// Health describes the state of a feed for the health view
+type Health struct {
+	State  HealthState
+	Detail string
+}
+
+// NeedsAttention reports whether the feed should be checked for a replacement
+func (h Health) NeedsAttention() bool {
+	return h.State == HealthSilent || h.State == HealthDead || h.State == HealthFailing
+}
+
+// CheckHealth evaluates a feed's recorded fetch results. A feed is silent
+// when its newest item is older than silentDays.
+func CheckHealth(feed models.Feed, silentDays int, now time.Time) Health {
+	if feed.LastFetchedAt == nil {
+		return Health{HealthUnknown, "not fetched yet"}
+	}
+
+	if feed.LastError != "" {
+		switch feed.LastStatus {
+		case http.StatusNotFound, http.StatusGone:
+			return Health{HealthDead, fmt.Sprintf("%d %s", feed.LastStatus, http.StatusText(feed.LastStatus))}
+		}
+		detail := feed.LastError
+		if feed.LastSuccessAt != nil {
+			detail = fmt.Sprintf("%s (last success %s)", detail, feed.LastSuccessAt.Format("Jan 2"))
+		}
+		return Health{HealthFailing, detail}
+	}
+
+	// Watched pages change when they change
+	if IsWatched(feed.URL) {
+		if feed.LastItemAt == nil {
+			return Health{HealthOK, "watching"}
+		}
+		return Health{HealthOK, fmt.Sprintf("last changed %s", feed.LastItemAt.Format("Jan 2"))}
+	}
+
+	if feed.LastItemAt == nil {
+		return Health{HealthSilent, "no dated items"}
+	}
+	if silentDays > 0 {
+		days := int(now.Sub(*feed.LastItemAt).Hours() / 24)
+		if days >= silentDays {
+			return Health{HealthSilent, fmt.Sprintf("no new items for %d days", days)}
+		}
+	}
+
+	return Health{HealthOK, fmt.Sprintf("last item %s", feed.LastItemAt.Format("Jan 2"))}
+}
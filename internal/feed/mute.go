@@ -0,0 +1,82 @@
+package feed
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// MuteMatcher checks articles against a set of mute rules
+type MuteMatcher struct {
+	keywords []string
+	patterns []*regexp.Regexp
+	domains  []string
+}
+
+// NewMuteMatcher compiles mute rules into a matcher
+func NewMuteMatcher(mutes []models.Mute) (*MuteMatcher, error) {
+	mm := &MuteMatcher{}
+	for _, mute := range mutes {
+		value := strings.TrimSpace(mute.Value)
+		if value == "" {
+			continue
+		}
+		switch mute.Kind {
+		case models.MuteKeyword:
+			mm.keywords = append(mm.keywords, strings.ToLower(value))
+		case models.MuteRegex:
+			re, err := regexp.Compile("(?i)" + value)
+			if err != nil {
+				return nil, fmt.Errorf("compiling mute pattern %q: %w", value, err)
+			}
+			mm.patterns = append(mm.patterns, re)
+		case models.MuteDomain:
+			mm.domains = append(mm.domains, strings.ToLower(strings.TrimPrefix(value, "www.")))
+		default:
+			return nil, fmt.Errorf("unknown mute kind %q", mute.Kind)
+		}
+	}
+	return mm, nil
+}
+
+// Match reports whether the article is muted by any rule
+func (mm *MuteMatcher) Match(article *models.Article) bool {
+	if mm == nil {
+		return false
+	}
+
+	if host := ArticleDomain(article.URL); host != "" {
+		for _, d := range mm.domains {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				return true
+			}
+		}
+	}
+
+	text := article.Title + "\n" + article.Description
+	lower := strings.ToLower(text)
+	for _, k := range mm.keywords {
+		if strings.Contains(lower, k) {
+			return true
+		}
+	}
+	for _, re := range mm.patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ArticleDomain returns the lowercased host of an article URL without "www."
+func ArticleDomain(articleURL string) string {
+	u, err := url.Parse(articleURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
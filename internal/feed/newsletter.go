@@ -0,0 +1,335 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/imap"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+	"golang.org/x/net/html/charset"
+)
+
+// newsletterScheme prefixes the URL of a newsletter sender's feed, which is
+// mailto: their address
+const newsletterScheme = "mailto:"
+
+// webVersionLinks are the texts of links to a newsletter's copy on the web
+var webVersionLinks = []string{
+	"view in browser", "view in your browser", "view this email in your browser",
+	"view online", "read online", "web version", "open in browser", "view as a web page",
+}
+
+// newsletter is an email newsletter read from the mailbox
+type newsletter struct {
+	uid     uint32
+	sender  *mail.Address
+	article *models.Article
+}
+
+// IsNewsletter reports whether a feed is a newsletter sender rather than a
+// web feed
+func IsNewsletter(feedURL string) bool {
+	return strings.HasPrefix(feedURL, newsletterScheme)
+}
+
+// fetchNewsletters stores the unseen messages of the newsletter folder as
+// articles of their sender's feed, which is added when new, and marks them
+// seen. Results are added to summary, a failure to read the mailbox as one
+// failed result.
+func (f *Fetcher) fetchNewsletters(summary *Summary, onNew func(*models.Article), onFeedDone func(result FeedResult, done, total int)) error {
+	cfg := f.cfg.Newsletters
+	failed := func(err error) {
+		result := FeedResult{FeedName: "Newsletters (" + cfg.Host + ")", Err: err}
+		summary.Results = append(summary.Results, result)
+		if onFeedDone != nil {
+			onFeedDone(result, len(summary.Results), len(summary.Results))
+		}
+	}
+
+	client, err := imap.Dial(cfg.Host, cfg.Username, cfg.Password)
+	if err != nil {
+		failed(err)
+		return nil
+	}
+	defer client.Close()
+	if err := client.Select(cfg.Folder); err != nil {
+		failed(err)
+		return nil
+	}
+	uids, err := client.Unseen()
+	if err != nil {
+		failed(err)
+		return nil
+	}
+
+	// Messages are grouped by sender, in the order senders first appear
+	var senders []string
+	bySender := map[string][]newsletter{}
+	for _, uid := range uids {
+		raw, err := client.Fetch(uid)
+		if err != nil {
+			failed(err)
+			return nil
+		}
+		letter, err := parseNewsletter(raw)
+		if err != nil {
+			// Mail that isn't a newsletter is left unseen in the folder
+			continue
+		}
+		letter.uid = uid
+		address := strings.ToLower(letter.sender.Address)
+		if _, ok := bySender[address]; !ok {
+			senders = append(senders, address)
+		}
+		bySender[address] = append(bySender[address], letter)
+	}
+
+	feeds, err := f.db.GetFeeds()
+	if err != nil {
+		return fmt.Errorf("getting feeds: %w", err)
+	}
+	byURL := make(map[string]models.Feed, len(feeds))
+	for _, feed := range feeds {
+		byURL[feed.URL] = feed
+	}
+
+	done := len(summary.Results)
+	for _, address := range senders {
+		letters := bySender[address]
+		feed, ok := byURL[newsletterScheme+address]
+		if !ok {
+			name := letters[0].sender.Name
+			if name == "" {
+				name = letters[0].sender.Address
+			}
+			feed = models.Feed{URL: newsletterScheme + address, Name: name, Enabled: true}
+			if err := f.db.AddFeed(&feed); err != nil {
+				return err
+			}
+		}
+
+		result, err := f.storeNewsletters(client, &feed, letters, onNew)
+		result.Err = err
+		summary.Results = append(summary.Results, result)
+		if onFeedDone != nil {
+			onFeedDone(result, len(summary.Results), done+len(senders))
+		}
+	}
+	return nil
+}
+
+// storeNewsletters stores the newsletters of one sender and marks them
+// seen. Those of disabled senders are only marked seen.
+func (f *Fetcher) storeNewsletters(client *imap.Client, feed *models.Feed, letters []newsletter, onNew func(*models.Article)) (FeedResult, error) {
+	result := FeedResult{FeedID: feed.ID, FeedName: feed.Name}
+
+	var newestItem *time.Time
+	for _, letter := range letters {
+		if feed.Enabled {
+			article := letter.article
+			article.FeedID = feed.ID
+			if newestItem == nil || article.PublishedAt.After(*newestItem) {
+				published := article.PublishedAt
+				newestItem = &published
+			}
+			if err := f.storeArticle(article, feed, &result, onNew); err != nil && !errors.Is(err, database.ErrDuplicate) {
+				return result, err
+			}
+		}
+		if err := client.MarkSeen(letter.uid); err != nil {
+			return result, err
+		}
+	}
+
+	if err := f.db.RecordFeedFetch(feed.ID, http.StatusOK, nil, newestItem); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// parseNewsletter reads an email as an article. Its URL is the copy on the
+// web the newsletter links to, or else mid: its Message-ID.
+func parseNewsletter(raw []byte) (newsletter, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return newsletter{}, fmt.Errorf("parsing message: %w", err)
+	}
+	sender, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return newsletter{}, fmt.Errorf("parsing sender: %w", err)
+	}
+
+	decoder := &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	published, err := msg.Header.Date()
+	if err != nil {
+		published = time.Now()
+	}
+
+	content, err := messageHTML(msg.Header, msg.Body)
+	if err != nil {
+		return newsletter{}, err
+	}
+
+	id := strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>")
+	if id == "" {
+		sum := sha256.Sum256(raw)
+		id = hex.EncodeToString(sum[:16])
+	}
+	pageURL := webVersion(content)
+	if pageURL == "" {
+		pageURL = "mid:" + id
+	}
+
+	description := plainDescription(content)
+	return newsletter{
+		sender: sender,
+		article: &models.Article{
+			Title:       subject,
+			URL:         pageURL,
+			Content:     content,
+			Description: description,
+			PublishedAt: published,
+			Author:      sender.Name,
+			GUID:        id,
+		},
+	}, nil
+}
+
+// messageHTML returns the HTML part of a message, or else its text part as
+// paragraphs
+func messageHTML(header mail.Header, body io.Reader) (string, error) {
+	htmlPart, textPart, err := messageParts(header.Get("Content-Type"), header.Get("Content-Transfer-Encoding"), body)
+	if err != nil {
+		return "", fmt.Errorf("reading message: %w", err)
+	}
+	if strings.TrimSpace(htmlPart) != "" {
+		return htmlPart, nil
+	}
+	if strings.TrimSpace(textPart) == "" {
+		return "", fmt.Errorf("reading message: no text")
+	}
+
+	var paragraphs []string
+	for _, p := range strings.Split(strings.ReplaceAll(textPart, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, "<p>"+strings.ReplaceAll(html.EscapeString(p), "\n", "<br>")+"</p>")
+		}
+	}
+	return strings.Join(paragraphs, "\n"), nil
+}
+
+// messageParts decodes the first HTML and plain text parts of a message
+// body, looking into multipart bodies and skipping attachments
+func messageParts(contentType, encoding string, body io.Reader) (htmlPart, textPart string, err error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextRawPart()
+			if err == io.EOF {
+				return htmlPart, textPart, nil
+			}
+			if err != nil {
+				return htmlPart, textPart, err
+			}
+			if disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disposition == "attachment" {
+				continue
+			}
+			h, t, err := messageParts(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return htmlPart, textPart, err
+			}
+			if htmlPart == "" {
+				htmlPart = h
+			}
+			if textPart == "" {
+				textPart = t
+			}
+		}
+	}
+	if mediaType != "text/html" && mediaType != "text/plain" {
+		return "", "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	if label := params["charset"]; label != "" {
+		if body, err = charset.NewReaderLabel(label, body); err != nil {
+			return "", "", err
+		}
+	}
+	text, err := io.ReadAll(body)
+	if err != nil {
+		return "", "", err
+	}
+	if mediaType == "text/html" {
+		return string(text), "", nil
+	}
+	return "", string(text), nil
+}
+
+// webVersion returns the link to a newsletter's copy on the web, if it has
+// one
+func webVersion(content string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return ""
+	}
+	link := ""
+	doc.Find("a[href]").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+		text := strings.ToLower(strings.Join(strings.Fields(a.Text()), " "))
+		href := strings.TrimSpace(a.AttrOr("href", ""))
+		if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+			return true
+		}
+		for _, marker := range webVersionLinks {
+			if strings.Contains(text, marker) {
+				link = href
+				return false
+			}
+		}
+		return true
+	})
+	return link
+}
+
+// plainDescription returns the start of HTML content as plain text
+func plainDescription(content string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return ""
+	}
+	doc.Find("style, script, title").Remove()
+	text := []rune(strings.Join(strings.Fields(doc.Text()), " "))
+	if len(text) > 500 {
+		return string(text[:500]) + "..."
+	}
+	return string(text)
+}
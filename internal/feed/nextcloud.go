@@ -0,0 +1,183 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/nextcloud"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// syncNextcloud takes articles from Nextcloud News instead of fetching the
+// feeds. Articles read here are marked read there first, feeds subscribed on
+// either side are added to the other, unread items are stored like fetched
+// articles (and scored locally as usual), and articles read in Nextcloud are
+// marked read here.
+func (f *Fetcher) syncNextcloud(onNew func(*models.Article), onFeedDone func(result FeedResult, done, total int)) (*Summary, error) {
+	pending, err := f.db.GetPendingRemoteReads()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.nextcloud.MarkRead(pending); err != nil {
+		return nil, err
+	}
+	if err := f.db.ClearPendingRemoteReads(pending); err != nil {
+		return nil, err
+	}
+
+	remoteFeeds, feeds, err := f.syncNextcloudFeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := f.nextcloud.UnreadItems()
+	if err != nil {
+		return nil, err
+	}
+	unread := make(map[int64]bool, len(items))
+	byFeed := map[int64][]nextcloud.Item{}
+	for _, item := range items {
+		unread[item.ID] = true
+		byFeed[item.FeedID] = append(byFeed[item.FeedID], item)
+	}
+
+	var enabled []nextcloud.Feed
+	for _, rf := range remoteFeeds {
+		if feed, ok := feeds[rf.ID]; ok && feed.Enabled {
+			enabled = append(enabled, rf)
+		}
+	}
+
+	summary := &Summary{}
+	for _, rf := range enabled {
+		feed := feeds[rf.ID]
+		result, err := f.storeNextcloudItems(&feed, byFeed[rf.ID], onNew)
+		result.Err = err
+		summary.Results = append(summary.Results, result)
+		if onFeedDone != nil {
+			onFeedDone(result, len(summary.Results), len(enabled))
+		}
+	}
+
+	synced, err := f.db.GetUnreadRemoteIDs()
+	if err != nil {
+		return summary, err
+	}
+	for remoteID, articleID := range synced {
+		if unread[remoteID] {
+			continue
+		}
+		if err := f.db.MarkArticleReadRemotely(articleID); err != nil {
+			return summary, err
+		}
+	}
+	if err := f.db.DeleteReadArticles(); err != nil {
+		return summary, err
+	}
+
+	return summary, nil
+}
+
+// syncNextcloudFeeds subscribes Nextcloud to enabled local feeds it lacks and
+// adds its other feeds locally. It returns the Nextcloud feeds and maps their
+// IDs to the local feeds. Feeds Nextcloud refuses are recorded as failing.
+func (f *Fetcher) syncNextcloudFeeds() ([]nextcloud.Feed, map[int64]models.Feed, error) {
+	remoteFeeds, err := f.nextcloud.Feeds()
+	if err != nil {
+		return nil, nil, err
+	}
+	localFeeds, err := f.db.GetFeeds()
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting feeds: %w", err)
+	}
+
+	remoteURLs := make(map[string]bool, len(remoteFeeds))
+	for _, rf := range remoteFeeds {
+		remoteURLs[rf.URL] = true
+	}
+	byURL := make(map[string]models.Feed, len(localFeeds))
+	for _, feed := range localFeeds {
+		byURL[feed.URL] = feed
+		if !feed.Enabled || remoteURLs[feed.URL] || !isPlainFeed(feed.URL) {
+			continue
+		}
+		rf, err := f.nextcloud.AddFeed(feed.URL)
+		if err != nil {
+			if recErr := f.db.RecordFeedFetch(feed.ID, 0, err, nil); recErr != nil {
+				return nil, nil, recErr
+			}
+			continue
+		}
+		// Nextcloud may store the URL it was redirected to
+		byURL[rf.URL] = feed
+		remoteFeeds = append(remoteFeeds, *rf)
+	}
+
+	feeds := make(map[int64]models.Feed, len(remoteFeeds))
+	for _, rf := range remoteFeeds {
+		feed, ok := byURL[rf.URL]
+		if !ok {
+			feed = models.Feed{URL: rf.URL, Name: rf.Title, Enabled: true}
+			if err := f.db.AddFeed(&feed); err != nil {
+				return nil, nil, err
+			}
+			byURL[rf.URL] = feed
+		}
+		feeds[rf.ID] = feed
+	}
+	return remoteFeeds, feeds, nil
+}
+
+// storeNextcloudItems stores the unread items of a feed, linking articles
+// already stored to their item so reads can be synced
+func (f *Fetcher) storeNextcloudItems(feed *models.Feed, items []nextcloud.Item, onNew func(*models.Article)) (FeedResult, error) {
+	result := FeedResult{FeedID: feed.ID, FeedName: feed.Name}
+
+	var newestItem *time.Time
+	for _, item := range items {
+		if item.PubDate == 0 {
+			result.Undated++
+			continue
+		}
+		article := itemToArticle(item, feed.ID)
+		if newestItem == nil || article.PublishedAt.After(*newestItem) {
+			published := article.PublishedAt
+			newestItem = &published
+		}
+
+		err := f.storeArticle(article, feed, &result, onNew)
+		if errors.Is(err, database.ErrDuplicate) {
+			err = f.db.SetArticleRemoteID(article.URL, item.ID)
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if err := f.db.RecordFeedFetch(feed.ID, http.StatusOK, nil, newestItem); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// itemToArticle converts a Nextcloud News item to our Article model
+func itemToArticle(item nextcloud.Item, feedID int64) *models.Article {
+	description := item.Body
+	if runes := []rune(description); len(runes) > 500 {
+		description = string(runes[:500]) + "..."
+	}
+	return &models.Article{
+		FeedID:      feedID,
+		Title:       item.Title,
+		URL:         item.URL,
+		Content:     item.Body,
+		Description: description,
+		PublishedAt: time.Unix(item.PubDate, 0),
+		Author:      item.Author,
+		GUID:        item.GUID,
+		RemoteID:    item.ID,
+	}
+}
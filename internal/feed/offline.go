@@ -0,0 +1,196 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// teaserWords is the length below which feed content is taken to be a
+// teaser, and the full text is fetched from the page for offline reading
+const teaserWords = 150
+
+// maxImageBytes skips images larger than this
+const maxImageBytes = 10 << 20
+
+// CacheOffline makes queued and starred articles readable offline. The full
+// text of teasers is fetched from their page and their images are
+// downloaded; then images beyond offline.max_mb are evicted. It returns the
+// articles cached, with their new content. Articles that fail are left for
+// the next run, and the first failure is returned.
+func (f *Fetcher) CacheOffline() ([]models.Article, error) {
+	if f.cfg.Offline.MaxMB < 0 {
+		return nil, nil
+	}
+	articles, err := f.db.GetArticlesToCache()
+	if err != nil {
+		return nil, err
+	}
+
+	var cached []models.Article
+	var firstErr error
+	for _, article := range articles {
+		if err := f.cacheArticle(&article); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("caching %q for offline reading: %w", article.Title, err)
+			}
+			continue
+		}
+		cached = append(cached, article)
+	}
+
+	evicted, err := f.db.EvictOfflineImages(f.cfg.Offline.MaxBytes())
+	for _, path := range evicted {
+		os.Remove(path)
+	}
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return cached, firstErr
+}
+
+// cacheArticle fetches the full text of one article, if needed, and its
+// images
+func (f *Fetcher) cacheArticle(article *models.Article) error {
+	extracted, err := f.Extract(article)
+	if err != nil {
+		return err
+	}
+	if !extracted && wordCount(article.Content) < teaserWords {
+		if extracted, err = f.extractPage(article); err != nil {
+			return err
+		}
+	}
+	if extracted {
+		if err := f.db.UpdateArticleContent(article); err != nil {
+			return err
+		}
+	}
+
+	have, err := f.db.GetOfflineImages(article.ID)
+	if err != nil {
+		return err
+	}
+	for _, src := range imageURLs(article.Content) {
+		if _, ok := have[src]; ok {
+			continue
+		}
+		// A broken image shouldn't keep the text from being cached
+		img, err := f.downloadImage(src)
+		if err != nil {
+			continue
+		}
+		img.ArticleID = article.ID
+		if err := f.db.AddOfflineImage(img); err != nil {
+			return err
+		}
+	}
+	return f.db.MarkArticleCached(article.ID)
+}
+
+// extractPage replaces a teaser with the article or main element of its
+// page. It returns false, keeping the teaser, when the page has neither.
+func (f *Fetcher) extractPage(article *models.Article) (bool, error) {
+	base, err := url.Parse(article.URL)
+	if err != nil {
+		return false, fmt.Errorf("parsing url %s: %w", article.URL, err)
+	}
+	doc, err := f.fetchPage(f.ctx, article.URL)
+	if err != nil {
+		return false, err
+	}
+	content, err := extractGeneric(doc, base)
+	if err != nil || wordCount(content) <= wordCount(article.Content) {
+		return false, err
+	}
+	article.Content = content
+	return true, nil
+}
+
+// downloadImage saves an image in the offline directory, named by the hash
+// of its URL
+func (f *Fetcher) downloadImage(src string) (database.OfflineImage, error) {
+	img := database.OfflineImage{URL: src}
+	req, err := f.newRequest(f.ctx, src, nil)
+	if err != nil {
+		return img, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return img, fmt.Errorf("fetching %s: %w", src, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return img, fmt.Errorf("fetching %s: %w", src, &HTTPError{resp.StatusCode})
+	}
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(contentType, "image/") {
+		return img, fmt.Errorf("fetching %s: not an image (%s)", src, contentType)
+	}
+
+	ext := path.Ext(req.URL.Path)
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		ext = exts[0]
+	}
+	sum := sha256.Sum256([]byte(src))
+	if err := os.MkdirAll(f.cfg.Offline.Dir, 0755); err != nil {
+		return img, fmt.Errorf("creating offline directory: %w", err)
+	}
+	img.Path = filepath.Join(f.cfg.Offline.Dir, hex.EncodeToString(sum[:16])+ext)
+
+	out, err := os.Create(img.Path)
+	if err != nil {
+		return img, err
+	}
+	img.Size, err = io.Copy(out, io.LimitReader(resp.Body, maxImageBytes+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && img.Size > maxImageBytes {
+		err = fmt.Errorf("fetching %s: larger than %d MB", src, maxImageBytes>>20)
+	}
+	if err != nil {
+		os.Remove(img.Path)
+		return img, err
+	}
+	return img, nil
+}
+
+// imageURLs lists the web images in HTML content
+func imageURLs(content string) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	seen := map[string]bool{}
+	doc.Find("img[src]").Each(func(_ int, img *goquery.Selection) {
+		src := img.AttrOr("src", "")
+		if u, err := url.Parse(src); err == nil && (u.Scheme == "http" || u.Scheme == "https") && !seen[src] {
+			seen[src] = true
+			urls = append(urls, src)
+		}
+	})
+	return urls
+}
+
+// wordCount counts the words of HTML content
+func wordCount(content string) int {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return 0
+	}
+	return len(strings.Fields(doc.Text()))
+}
@@ -0,0 +1,344 @@
+package feed
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/thomaskoefod/newsreadr/internal/texmath"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// jatsElements are the HTML elements for the JATS markup of abstracts;
+// the rest becomes spans
+var jatsElements = map[string]string{
+	"p": "p", "italic": "em", "bold": "strong", "sup": "sup", "sub": "sub",
+	"list": "ul", "list-item": "li", "sec": "div",
+}
+
+// crossrefAPI looks up the metadata of a DOI
+const crossrefAPI = "https://api.crossref.org/works/"
+
+// paperAuthors is how many authors a paper's author names before "et al."
+const paperAuthors = 3
+
+// abstractWords is the length below which a journal article is taken to
+// have no abstract, and one is looked up on Crossref
+const abstractWords = 30
+
+// ErrNoPDF is returned by DownloadPDF for articles without a PDF to be
+// found
+var ErrNoPDF = errors.New("no PDF found for this article")
+
+var (
+	// arxivAnnouncement is the line arXiv's feeds put before each abstract
+	arxivAnnouncement = regexp.MustCompile(`(?s)^\s*arXiv:\S+\s+Announce Type:\s*\S+\s*(Abstract:\s*)?`)
+	// arxivPath matches the abstract page of an arXiv paper, capturing its ID
+	arxivPath = regexp.MustCompile(`^/abs/(.+?)/?$`)
+	// doiPattern matches a DOI
+	doiPattern = regexp.MustCompile(`\b10\.\d{4,9}/[^\s"'<>&#?]+`)
+	// jatsTag matches the JATS markup of Crossref abstracts
+	jatsTag = regexp.MustCompile(`(?s)<jats:title>.*?</jats:title>|</?jats:[a-z-]+`)
+	// unsafeFileName matches what doesn't belong in a file name
+	unsafeFileName = regexp.MustCompile(`[^\p{L}\p{N} ._-]+`)
+)
+
+// arxivID returns the ID of an arXiv paper from the URL of its abstract
+// page, or "" for other URLs
+func arxivID(articleURL string) string {
+	u, err := url.Parse(articleURL)
+	if err != nil || (u.Host != "arxiv.org" && !strings.HasSuffix(u.Host, ".arxiv.org")) {
+		return ""
+	}
+	if m := arxivPath.FindStringSubmatch(u.Path); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// itemDOI returns the DOI a journal feed gives an item, in prism:doi or
+// dc:identifier, or in its link or GUID
+func itemDOI(item *gofeed.Item) string {
+	candidates := []string{item.Link, item.GUID}
+	if prism, ok := item.Extensions["prism"]; ok {
+		for _, ext := range prism["doi"] {
+			candidates = append([]string{ext.Value}, candidates...)
+		}
+	}
+	if item.DublinCoreExt != nil {
+		candidates = append(candidates, item.DublinCoreExt.Identifier...)
+	}
+	for _, c := range candidates {
+		if doi := doiPattern.FindString(c); doi != "" {
+			return doi
+		}
+	}
+	return ""
+}
+
+// paperMetadata tidies up articles from arXiv and journal feeds: the
+// announcement line arXiv puts before abstracts is dropped, the math in
+// titles is made readable, every author is listed, with the first few as
+// the article's author, and the DOI is linked. Other articles are left
+// alone.
+func paperMetadata(item *gofeed.Item, article *models.Article) {
+	arxiv, doi := arxivID(article.URL), itemDOI(item)
+	if arxiv == "" && doi == "" {
+		return
+	}
+
+	article.Title = texmath.Text(strings.Join(strings.Fields(article.Title), " "))
+	authors := itemAuthors(item, arxiv != "")
+	if len(authors) > 0 {
+		article.Author = authorLine(authors)
+	}
+	article.Tags = uniqueTags(article.Tags)
+
+	abstract := article.Content
+	if arxiv != "" {
+		abstract = arxivAnnouncement.ReplaceAllString(abstract, "")
+		article.Description = arxivAnnouncement.ReplaceAllString(article.Description, "")
+	}
+	article.Description = texmath.HTML(article.Description)
+	// arXiv's abstracts are plain text
+	if abstract = strings.TrimSpace(abstract); abstract != "" && !strings.Contains(abstract, "<") {
+		abstract = "<p>" + strings.ReplaceAll(html.EscapeString(abstract), "\n\n", "</p><p>") + "</p>"
+	}
+
+	var header strings.Builder
+	if len(authors) > 1 {
+		header.WriteString("<p><strong>Authors:</strong> " + html.EscapeString(joinAuthors(authors)) + "</p>\n")
+	}
+	if doi != "" {
+		header.WriteString(fmt.Sprintf("<p><strong>DOI:</strong> <a href=\"https://doi.org/%s\">%s</a></p>\n", doi, html.EscapeString(doi)))
+	}
+	article.Content = header.String() + abstract
+}
+
+// itemAuthors lists the names of all an item's authors. arXiv's feeds give
+// them all in one, separated by commas.
+func itemAuthors(item *gofeed.Item, split bool) []string {
+	var names []string
+	for _, a := range item.Authors {
+		if a != nil && a.Name != "" {
+			names = append(names, a.Name)
+		}
+	}
+	if item.DublinCoreExt != nil && len(item.DublinCoreExt.Creator) > len(names) {
+		names = item.DublinCoreExt.Creator
+	}
+
+	var authors []string
+	for _, name := range names {
+		parts := []string{name}
+		if split {
+			parts = strings.Split(strings.ReplaceAll(name, " and ", ","), ",")
+		}
+		for _, part := range parts {
+			if part = strings.Join(strings.Fields(part), " "); part != "" {
+				authors = append(authors, part)
+			}
+		}
+	}
+	return authors
+}
+
+// authorLine names the first few authors of a paper
+func authorLine(authors []string) string {
+	if len(authors) > paperAuthors {
+		return joinAuthors(authors[:paperAuthors]) + " et al."
+	}
+	return joinAuthors(authors)
+}
+
+// joinAuthors lists authors, separated by semicolons when their names are
+// written family name first, as in "Doe, Jane"
+func joinAuthors(authors []string) string {
+	for _, author := range authors {
+		if strings.Contains(author, ",") {
+			return strings.Join(authors, "; ")
+		}
+	}
+	return strings.Join(authors, ", ")
+}
+
+// uniqueTags drops repeated tags, like categories a paper is both listed
+// and cross-listed in
+func uniqueTags(tags []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
+
+// articleDOI finds the DOI of an article in its URL, GUID or content
+func articleDOI(article *models.Article) string {
+	for _, s := range []string{article.URL, article.GUID, article.Content} {
+		if doi := doiPattern.FindString(s); doi != "" {
+			return doi
+		}
+	}
+	return ""
+}
+
+// fetchAbstract completes a journal article whose feed gives no abstract
+// from Crossref, along with its authors when the feed names none. It
+// returns whether the article changed.
+func (f *Fetcher) fetchAbstract(article *models.Article) (bool, error) {
+	doi := articleDOI(article)
+	if doi == "" || arxivID(article.URL) != "" || wordCount(article.Content) >= abstractWords {
+		return false, nil
+	}
+	var result struct {
+		Message struct {
+			Abstract string `json:"abstract"`
+			Author   []struct {
+				Given  string `json:"given"`
+				Family string `json:"family"`
+			} `json:"author"`
+		} `json:"message"`
+	}
+	err := f.getJSON(f.ctx, crossrefAPI+url.PathEscape(doi), nil, &result)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == 404 {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	if abstract := strings.TrimSpace(result.Message.Abstract); abstract != "" {
+		abstract = jatsTag.ReplaceAllStringFunc(abstract, func(tag string) string {
+			if strings.HasPrefix(tag, "<jats:title>") {
+				return ""
+			}
+			closing, name, _ := strings.Cut(tag, "jats:")
+			if element, ok := jatsElements[name]; ok {
+				return closing + element
+			}
+			return closing + "span"
+		})
+		article.Content += abstract
+		changed = true
+	}
+	if article.Author == "" && len(result.Message.Author) > 0 {
+		var authors []string
+		for _, a := range result.Message.Author {
+			authors = append(authors, strings.TrimSpace(a.Given+" "+a.Family))
+		}
+		article.Author = authorLine(authors)
+		changed = true
+	}
+	return changed, nil
+}
+
+// DownloadPDF saves the PDF of a paper in papers.pdf_dir and returns its
+// path. arXiv papers link their PDF by ID; journals name it in the
+// citation_pdf_url tag of the article's page. A PDF downloaded before is
+// not downloaded again.
+func (f *Fetcher) DownloadPDF(article models.Article) (string, error) {
+	pdfURL, err := f.pdfURL(article)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(f.cfg.Papers.PDFDir, pdfFileName(article))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	req, err := f.newRequest(f.ctx, pdfURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %w", pdfURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("fetching %s: %w", pdfURL, &HTTPError{resp.StatusCode})
+	}
+	// Publishers answer with a login or paywall page when the PDF needs a
+	// subscription
+	body := bufio.NewReader(resp.Body)
+	if magic, _ := body.Peek(5); string(magic) != "%PDF-" {
+		return "", fmt.Errorf("%s is not a PDF: it may need a subscription", pdfURL)
+	}
+
+	if err := os.MkdirAll(f.cfg.Papers.PDFDir, 0755); err != nil {
+		return "", fmt.Errorf("creating PDF directory: %w", err)
+	}
+	partial := path + ".part"
+	out, err := os.Create(partial)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, body)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(partial, path)
+	}
+	if err != nil {
+		os.Remove(partial)
+		return "", fmt.Errorf("saving %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// pdfURL finds where the PDF of a paper is
+func (f *Fetcher) pdfURL(article models.Article) (string, error) {
+	if id := arxivID(article.URL); id != "" {
+		return "https://arxiv.org/pdf/" + id, nil
+	}
+	if u, err := url.Parse(article.URL); err == nil && strings.HasSuffix(strings.ToLower(u.Path), ".pdf") {
+		return article.URL, nil
+	}
+	doc, err := f.fetchPage(f.ctx, article.URL)
+	if err != nil {
+		return "", err
+	}
+	pdf := strings.TrimSpace(doc.Find(`meta[name="citation_pdf_url"]`).First().AttrOr("content", ""))
+	if pdf == "" {
+		return "", ErrNoPDF
+	}
+	ref, err := url.Parse(pdf)
+	if err != nil {
+		return "", fmt.Errorf("parsing PDF url %s: %w", pdf, err)
+	}
+	// The page may have been reached through a redirect, e.g. from doi.org
+	return doc.Url.ResolveReference(ref).String(), nil
+}
+
+// pdfFileName names the PDF of a paper after its title, preceded by its
+// arXiv ID
+func pdfFileName(article models.Article) string {
+	name := strings.Join(strings.Fields(unsafeFileName.ReplaceAllString(article.Title, " ")), " ")
+	if runes := []rune(name); len(runes) > 100 {
+		name = strings.TrimSpace(string(runes[:100]))
+	}
+	if id := arxivID(article.URL); id != "" {
+		name = strings.TrimSpace(strings.ReplaceAll(id, "/", "_") + " " + name)
+	}
+	if name == "" {
+		name = "paper"
+	}
+	return name + ".pdf"
+}
@@ -0,0 +1,329 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/github"
+)
+
+// Schemes of feeds read from a social network's API. The rest of the URL is
+// that of the timeline's page in the web app, e.g.
+// mastodon://mastodon.social/tags/golang for https://mastodon.social/tags/golang.
+const (
+	mastodonScheme = "mastodon"
+	blueskyScheme  = "bluesky"
+)
+
+// blueskyAPI serves the public Bluesky API, which needs no account
+const blueskyAPI = "https://public.api.bsky.app/xrpc/"
+
+// errTimeline is returned for social feed URLs naming no supported timeline
+var errTimeline = errors.New("not a Mastodon hashtag (/tags/NAME) or list (/lists/ID), or a Bluesky profile (/profile/HANDLE), feed (/profile/HANDLE/feed/NAME) or list (/profile/HANDLE/lists/ID)")
+
+// socialTitleLength is how much of a post's text is its title when it
+// links to nothing with a title of its own
+const socialTitleLength = 100
+
+// IsSocial reports whether a feed is a Mastodon or Bluesky timeline
+func IsSocial(feedURL string) bool {
+	scheme, _, _ := strings.Cut(feedURL, "://")
+	return scheme == mastodonScheme || scheme == blueskyScheme
+}
+
+// WebURL returns the URL of a feed's page on the web, which for timelines
+// is the web app's page
+func WebURL(feedURL string) string {
+	if IsWatched(feedURL) {
+		return strings.TrimPrefix(feedURL, watchPrefix)
+	}
+	if IsSocial(feedURL) || github.IsIssues(feedURL) {
+		_, rest, _ := strings.Cut(feedURL, "://")
+		return "https://" + rest
+	}
+	return feedURL
+}
+
+// fetchSocial reads a Mastodon or Bluesky timeline as a feed. Posts that
+// link somewhere become items for the linked page, with the post as their
+// comments; other posts are items for themselves.
+func (f *Fetcher) fetchSocial(ctx context.Context, feedURL string, settings *config.FeedConfig) (*gofeed.Feed, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing feed url %s: %w", feedURL, err)
+	}
+	path := strings.Split(strings.Trim(u.Path, "/"), "/")
+	var feed *gofeed.Feed
+	if u.Scheme == mastodonScheme {
+		feed, err = f.fetchMastodon(ctx, u.Host, path, settings)
+	} else {
+		feed, err = f.fetchBluesky(ctx, path, settings)
+	}
+	if errors.Is(err, errTimeline) {
+		err = fmt.Errorf("%s: %w", feedURL, err)
+	}
+	return feed, err
+}
+
+// getJSON fetches and decodes a JSON API response
+func (f *Fetcher) getJSON(ctx context.Context, apiURL string, settings *config.FeedConfig, v any) error {
+	req, err := f.newRequest(ctx, apiURL, settings)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := *f.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		dropHeadersOffHost(req, via, settings)
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", apiURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("fetching %s: %w", apiURL, &HTTPError{resp.StatusCode})
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s: %w", apiURL, err)
+	}
+	return nil
+}
+
+// mastodonStatus is a post in a Mastodon timeline
+type mastodonStatus struct {
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	Content   string    `json:"content"`
+	Spoiler   string    `json:"spoiler_text"`
+	Account   struct {
+		Acct        string `json:"acct"`
+		DisplayName string `json:"display_name"`
+	} `json:"account"`
+	Card *struct {
+		URL         string `json:"url"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	} `json:"card"`
+	Reblog *mastodonStatus `json:"reblog"`
+}
+
+// fetchMastodon reads a hashtag (/tags/NAME) or list (/lists/ID) timeline.
+// Lists need an access token, sent with the feed's headers
+// (Authorization: Bearer TOKEN).
+func (f *Fetcher) fetchMastodon(ctx context.Context, host string, path []string, settings *config.FeedConfig) (*gofeed.Feed, error) {
+	if len(path) != 2 {
+		return nil, errTimeline
+	}
+	feed := &gofeed.Feed{}
+	var endpoint string
+	switch path[0] {
+	case "tags":
+		endpoint = "timelines/tag/" + url.PathEscape(path[1])
+		feed.Title = fmt.Sprintf("#%s on %s", path[1], host)
+	case "lists":
+		endpoint = "timelines/list/" + url.PathEscape(path[1])
+		feed.Title = fmt.Sprintf("List %s on %s", path[1], host)
+	default:
+		return nil, errTimeline
+	}
+
+	var statuses []mastodonStatus
+	if err := f.getJSON(ctx, "https://"+host+"/api/v1/"+endpoint+"?limit=40", settings, &statuses); err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.Reblog != nil {
+			status = *status.Reblog
+		}
+		published := status.CreatedAt
+		author := status.Account.DisplayName
+		if author == "" {
+			author = status.Account.Acct
+		}
+		item := &gofeed.Item{
+			Title:           socialTitle(htmlText(status.Content), author),
+			Link:            status.URL,
+			Content:         status.Content,
+			PublishedParsed: &published,
+			Authors:         []*gofeed.Person{{Name: author}},
+			GUID:            status.URL,
+		}
+		if status.Spoiler != "" {
+			item.Title = status.Spoiler
+		}
+		if status.Card != nil && status.Card.URL != "" {
+			linkItem(item, status.Card.URL, status.Card.Title, status.Card.Description)
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// blueskyPost is a post in a Bluesky feed
+type blueskyPost struct {
+	URI    string `json:"uri"`
+	Author struct {
+		Handle      string `json:"handle"`
+		DisplayName string `json:"displayName"`
+	} `json:"author"`
+	Record struct {
+		Text      string    `json:"text"`
+		CreatedAt time.Time `json:"createdAt"`
+		Facets    []struct {
+			Features []struct {
+				Type string `json:"$type"`
+				URI  string `json:"uri"`
+			} `json:"features"`
+		} `json:"facets"`
+	} `json:"record"`
+	Embed *struct {
+		External *struct {
+			URI         string `json:"uri"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"external"`
+	} `json:"embed"`
+}
+
+// fetchBluesky reads the posts of an account (/profile/HANDLE), a custom
+// feed (/profile/HANDLE/feed/NAME) or a list (/profile/HANDLE/lists/ID)
+func (f *Fetcher) fetchBluesky(ctx context.Context, path []string, settings *config.FeedConfig) (*gofeed.Feed, error) {
+	if len(path) < 2 || path[0] != "profile" || (len(path) != 2 && len(path) != 4) {
+		return nil, errTimeline
+	}
+	actor := path[1]
+	feed := &gofeed.Feed{}
+	var endpoint string
+	if len(path) == 2 {
+		endpoint = "app.bsky.feed.getAuthorFeed?actor=" + url.QueryEscape(actor)
+		feed.Title = "@" + actor + " on Bluesky"
+	} else {
+		// Feeds and lists are named by the DID of their owner
+		did, err := f.blueskyDID(ctx, actor, settings)
+		if err != nil {
+			return nil, err
+		}
+		switch path[2] {
+		case "feed":
+			endpoint = "app.bsky.feed.getFeed?feed=" + url.QueryEscape("at://"+did+"/app.bsky.feed.generator/"+path[3])
+			feed.Title = path[3] + " by @" + actor + " on Bluesky"
+		case "lists":
+			endpoint = "app.bsky.feed.getListFeed?list=" + url.QueryEscape("at://"+did+"/app.bsky.graph.list/"+path[3])
+			feed.Title = "List by @" + actor + " on Bluesky"
+		default:
+			return nil, errTimeline
+		}
+	}
+
+	var result struct {
+		Feed []struct {
+			Post blueskyPost `json:"post"`
+		} `json:"feed"`
+	}
+	if err := f.getJSON(ctx, blueskyAPI+endpoint+"&limit=50", settings, &result); err != nil {
+		return nil, err
+	}
+	for _, entry := range result.Feed {
+		post := entry.Post
+		published := post.Record.CreatedAt
+		author := post.Author.DisplayName
+		if author == "" {
+			author = post.Author.Handle
+		}
+		postURL := "https://bsky.app/profile/" + post.Author.Handle + "/post/" + post.URI[strings.LastIndexByte(post.URI, '/')+1:]
+		item := &gofeed.Item{
+			Title:           socialTitle(post.Record.Text, author),
+			Link:            postURL,
+			Content:         "<p>" + strings.ReplaceAll(html.EscapeString(post.Record.Text), "\n", "<br>") + "</p>",
+			PublishedParsed: &published,
+			Authors:         []*gofeed.Person{{Name: author}},
+			GUID:            post.URI,
+		}
+		if post.Embed != nil && post.Embed.External != nil && post.Embed.External.URI != "" {
+			linkItem(item, post.Embed.External.URI, post.Embed.External.Title, post.Embed.External.Description)
+		} else if link := blueskyLink(post); link != "" {
+			linkItem(item, link, "", "")
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// blueskyDID resolves a handle to the DID that names its records
+func (f *Fetcher) blueskyDID(ctx context.Context, handle string, settings *config.FeedConfig) (string, error) {
+	if strings.HasPrefix(handle, "did:") {
+		return handle, nil
+	}
+	var result struct {
+		DID string `json:"did"`
+	}
+	if err := f.getJSON(ctx, blueskyAPI+"com.atproto.identity.resolveHandle?handle="+url.QueryEscape(handle), settings, &result); err != nil {
+		return "", err
+	}
+	return result.DID, nil
+}
+
+// blueskyLink returns the first link in a post's text
+func blueskyLink(post blueskyPost) string {
+	for _, facet := range post.Record.Facets {
+		for _, feature := range facet.Features {
+			if feature.Type == "app.bsky.richtext.facet#link" && feature.URI != "" {
+				return feature.URI
+			}
+		}
+	}
+	return ""
+}
+
+// linkItem makes a post's item about the page it links to, keeping the
+// post as the item's comments
+func linkItem(item *gofeed.Item, link, title, description string) {
+	setCustom(item, commentsKey, item.Link)
+	item.Link = link
+	if title != "" {
+		item.Title = title
+	}
+	if description != "" {
+		item.Description = description
+		item.Content += "<blockquote><p>" + html.EscapeString(description) + "</p></blockquote>"
+	}
+}
+
+// socialTitle makes a title of the start of a post's text
+func socialTitle(text, author string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if line == "" {
+		return "Post by " + author
+	}
+	if runes := []rune(line); len(runes) > socialTitleLength {
+		return string(runes[:socialTitleLength]) + "..."
+	}
+	return line
+}
+
+// htmlText returns the text of an HTML fragment with paragraphs on lines of
+// their own
+func htmlText(content string) string {
+	content = strings.NewReplacer("</p>", "\n</p>", "<br>", "\n", "<br/>", "\n", "<br />", "\n").Replace(content)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(doc.Text())
+}
@@ -0,0 +1,86 @@
+package feed
+
+import (
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/atom"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// commentsKey is where the translators below keep an item's comments URL
+// in gofeed.Item.Custom, which the default translators leave out
+const commentsKey = "comments"
+
+// rssTranslator copies each item's <comments> URL
+type rssTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *rssTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+	source := feed.(*rss.Feed)
+	for i, item := range result.Items {
+		if i < len(source.Items) && source.Items[i].Comments != "" {
+			setCustom(item, commentsKey, source.Items[i].Comments)
+		}
+	}
+	return result, nil
+}
+
+// atomTranslator copies each entry's <link rel="replies"> URL
+type atomTranslator struct {
+	gofeed.DefaultAtomTranslator
+}
+
+func (t *atomTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultAtomTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+	source := feed.(*atom.Feed)
+	for i, item := range result.Items {
+		if i >= len(source.Entries) {
+			break
+		}
+		for _, link := range source.Entries[i].Links {
+			if link.Rel == "replies" && link.Href != "" {
+				setCustom(item, commentsKey, link.Href)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func setCustom(item *gofeed.Item, key, value string) {
+	if item.Custom == nil {
+		item.Custom = make(map[string]string)
+	}
+	item.Custom[key] = value
+}
+
+// newParser returns a feed parser that also keeps comments URLs
+func newParser() *gofeed.Parser {
+	p := gofeed.NewParser()
+	p.RSSTranslator = &rssTranslator{}
+	p.AtomTranslator = &atomTranslator{}
+	return p
+}
+
+// itemAuthor returns the name, or else the email, of an item's first author
+func itemAuthor(item *gofeed.Item) string {
+	for _, a := range item.Authors {
+		if a == nil {
+			continue
+		}
+		if a.Name != "" {
+			return a.Name
+		}
+		if a.Email != "" {
+			return a.Email
+		}
+	}
+	return ""
+}
@@ -0,0 +1,209 @@
+package feed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// watchPrefix marks a page watched for changes rather than a feed, as in
+// watch+https://example.com/changelog
+const watchPrefix = "watch+"
+
+// watchBlocks are the elements whose text starts a line of a watched page
+const watchBlocks = "p, li, h1, h2, h3, h4, h5, h6, pre, tr, dt, dd, blockquote, div, section, article, br"
+
+// maxDiffLines bounds the pages compared line by line; longer pages are
+// reported as changed without the details
+const maxDiffLines = 4000
+
+// IsWatched reports whether a feed is a page watched for changes
+func IsWatched(feedURL string) bool {
+	return strings.HasPrefix(feedURL, watchPrefix)
+}
+
+// fetchWatched fetches a watched page as a feed with a single item holding
+// the text of the watched part, one line per block. The part is the feed's
+// selector, else the domain's extraction rule, else the article or main
+// element.
+func (f *Fetcher) fetchWatched(ctx context.Context, feedURL string, settings *config.FeedConfig) (*gofeed.Feed, error) {
+	pageURL := strings.TrimPrefix(feedURL, watchPrefix)
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing url %s: %w", pageURL, err)
+	}
+	doc, err := f.fetchPage(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+
+	var content string
+	rule, ok := f.cfg.Fetch.Rule(ArticleDomain(pageURL))
+	if settings != nil && settings.Selector != "" {
+		rule, ok = config.ExtractRule{Content: settings.Selector}, true
+	}
+	if ok {
+		content, err = extractContent(doc, rule, base)
+		if err == nil && content == "" {
+			err = fmt.Errorf("watching %s: nothing matches %q", pageURL, rule.Content)
+		}
+	} else {
+		content, err = extractGeneric(doc, base)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &gofeed.Feed{
+		Title: title,
+		Items: []*gofeed.Item{{
+			Title:           title,
+			Link:            pageURL,
+			Content:         strings.Join(textLines(content), "\n"),
+			PublishedParsed: &now,
+		}},
+	}, nil
+}
+
+// storePageChange compares a watched page with its last snapshot and stores
+// an article listing what changed. The first check only takes a snapshot.
+// It returns when the page last changed, or nil when it didn't.
+func (f *Fetcher) storePageChange(feed *models.Feed, page *gofeed.Feed, result *FeedResult, onNew func(*models.Article)) (*time.Time, error) {
+	if len(page.Items) == 0 {
+		return nil, nil
+	}
+	current := page.Items[0].Content
+	previous, ok, err := f.db.GetPageSnapshot(feed.ID)
+	if err != nil {
+		return nil, err
+	}
+	if ok && previous == current {
+		return nil, nil
+	}
+	now := time.Now()
+	if !ok {
+		return &now, f.db.SavePageSnapshot(feed.ID, current)
+	}
+
+	added, content := pageChanges(splitLines(previous), splitLines(current))
+	title := feed.Name + " changed"
+	if added != "" {
+		title = feed.Name + ": " + added
+		if runes := []rune(title); len(runes) > socialTitleLength {
+			title = string(runes[:socialTitleLength]) + "..."
+		}
+	}
+	sum := sha256.Sum256([]byte(current))
+	version := hex.EncodeToString(sum[:8])
+	article := &models.Article{
+		FeedID: feed.ID,
+		Title:  title,
+		// Each version of the page is an article of its own
+		URL:         page.Items[0].Link + "#newsreadr-" + version,
+		Content:     content,
+		Description: plainDescription(content),
+		PublishedAt: now,
+		GUID:        version,
+	}
+	// A page changed back to an earlier version is stored already
+	if err := f.storeArticle(article, feed, result, onNew); err != nil && !errors.Is(err, database.ErrDuplicate) {
+		return nil, err
+	}
+	return &now, f.db.SavePageSnapshot(feed.ID, current)
+}
+
+// pageChanges lists the lines added to a page and, struck through, those
+// removed, in the order of the page. It also returns the first line added.
+func pageChanges(previous, current []string) (firstAdded, content string) {
+	var s strings.Builder
+	line := func(text string, removed bool) {
+		text = html.EscapeString(text)
+		if removed {
+			text = "<del>" + text + "</del>"
+		}
+		s.WriteString("<p>" + text + "</p>\n")
+	}
+
+	if len(previous)*len(current) > maxDiffLines*maxDiffLines/4 {
+		s.WriteString("<p>The page changed too much to list the changes.</p>\n")
+		return "", s.String()
+	}
+
+	// Longest common subsequence of lines, from the end
+	lcs := make([][]int, len(previous)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(current)+1)
+	}
+	for i := len(previous) - 1; i >= 0; i-- {
+		for j := len(current) - 1; j >= 0; j-- {
+			if previous[i] == current[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(previous) || j < len(current) {
+		switch {
+		case i < len(previous) && j < len(current) && previous[i] == current[j]:
+			i++
+			j++
+		case j < len(current) && (i == len(previous) || lcs[i][j+1] >= lcs[i+1][j]):
+			if firstAdded == "" {
+				firstAdded = current[j]
+			}
+			line(current[j], false)
+			j++
+		default:
+			line(previous[i], true)
+			i++
+		}
+	}
+	return firstAdded, s.String()
+}
+
+// textLines returns the text of HTML content, a line per block, without
+// empty lines
+func textLines(content string) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil
+	}
+	doc.Find("script, style").Remove()
+	doc.Find(watchBlocks).Each(func(_ int, s *goquery.Selection) {
+		s.BeforeHtml("\n")
+		s.AfterHtml("\n")
+	})
+
+	var lines []string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitLines splits a snapshot into its lines
+func splitLines(snapshot string) []string {
+	if snapshot == "" {
+		return nil
+	}
+	return strings.Split(snapshot, "\n")
+}
@@ -0,0 +1,82 @@
+// Package github turns references to GitHub repositories into feeds of
+// their releases, commits, tags or issues
+package github
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Scheme marks the feeds read from the GitHub API rather than an Atom feed,
+// as in github://github.com/ORG/REPO/issues
+const Scheme = "github"
+
+// kinds maps what can follow a repository reference to the path of its feed
+// and how the feed is named
+var kinds = map[string][2]string{
+	"":         {"releases.atom", "releases"},
+	"releases": {"releases.atom", "releases"},
+	"commits":  {"commits.atom", "commits"},
+	"tags":     {"tags.atom", "tags"},
+	"issues":   {"issues", "issues"},
+}
+
+// FeedURL expands a repository reference, github.com/ORG/REPO with or
+// without https:// and optionally followed by /releases (the default),
+// /commits, /tags or /issues, into the URL of its feed and a name for it.
+// It returns false for anything else, feed URLs included.
+func FeedURL(ref string) (feedURL, name string, ok bool) {
+	ref = strings.TrimSpace(ref)
+	ref = strings.TrimPrefix(strings.TrimPrefix(ref, "https://"), "http://")
+	ref = strings.TrimPrefix(ref, "www.")
+	rest, ok := strings.CutPrefix(ref, "github.com/")
+	if !ok {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	kind := ""
+	if len(parts) == 3 {
+		kind = parts[2]
+	}
+	feed, known := kinds[kind]
+	if !known {
+		return "", "", false
+	}
+
+	repo := parts[0] + "/" + strings.TrimSuffix(parts[1], ".git")
+	scheme := "https"
+	if feed[0] == "issues" {
+		scheme = Scheme
+	}
+	return scheme + "://github.com/" + repo + "/" + feed[0], repo + " " + feed[1], true
+}
+
+// Repo returns ORG/REPO for the feed of a GitHub repository, or "" for
+// other feeds
+func Repo(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || (u.Host != "github.com" && u.Host != "www.github.com") {
+		return ""
+	}
+	if u.Scheme != "https" && u.Scheme != "http" && u.Scheme != Scheme {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	last := parts[len(parts)-1]
+	if !strings.HasSuffix(last, ".atom") && !(u.Scheme == Scheme && last == "issues") {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// IsIssues reports whether a feed is a repository's issues, read from the
+// API
+func IsIssues(feedURL string) bool {
+	return strings.HasPrefix(feedURL, Scheme+"://")
+}
@@ -0,0 +1,94 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Lifecycle events that hooks can be attached to
+const (
+	ArticleFetched = "on_article_fetched"
+	ArticleRead    = "on_article_read"
+	ArticleSaved   = "on_article_saved"
+)
+
+// timeout bounds how long a single hook command may run
+const timeout = 30 * time.Second
+
+// Runner runs the shell commands configured for lifecycle events
+type Runner struct {
+	cfg config.HooksConfig
+}
+
+func New(cfg config.HooksConfig) *Runner {
+	return &Runner{cfg: cfg}
+}
+
+// commands returns the commands configured for an event
+func (r *Runner) commands(event string) []string {
+	switch event {
+	case ArticleFetched:
+		return r.cfg.OnArticleFetched
+	case ArticleRead:
+		return r.cfg.OnArticleRead
+	case ArticleSaved:
+		return r.cfg.OnArticleSaved
+	}
+	return nil
+}
+
+// Has reports whether any command is configured for the event
+func (r *Runner) Has(event string) bool {
+	return len(r.commands(event)) > 0
+}
+
+// Fire runs every command for the event in order, with the article as JSON
+// on stdin and NEWSREADR_EVENT set. It stops at the first failing command.
+func (r *Runner) Fire(event string, article *models.Article) error {
+	commands := r.commands(event)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(article)
+	if err != nil {
+		return fmt.Errorf("marshaling article for %s: %w", event, err)
+	}
+
+	for _, command := range commands {
+		if err := run(event, command, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run executes one hook command in the platform shell
+func run(event, command string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), "NEWSREADR_EVENT="+event)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("running %s hook: %w: %s", event, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
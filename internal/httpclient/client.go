@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+)
+
+// DefaultUserAgent is sent when no user agent is configured
+const DefaultUserAgent = "Mozilla/5.0 (compatible; newsreadr/1.0; +https://github.com/thomaskoefod/newsreadr)"
+
+// New builds the HTTP client used for fetching feeds and web content,
+// routed through the configured HTTP(S) or SOCKS5 proxy. Without a
+// configured proxy the standard proxy environment variables apply.
+// Requests are rate limited per host and retried after 429 responses.
+func New(cfg config.HTTPConfig) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != "" {
+		if proxyURL, err := url.Parse(cfg.Proxy); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	return &http.Client{
+		Transport: &limitedTransport{
+			base:       transport,
+			limiter:    newHostLimiter(cfg.RequestsPerMinute),
+			maxRetries: cfg.MaxRetries,
+		},
+	}
+}
+
+// UserAgent returns the configured user agent or the default one
+func UserAgent(cfg config.HTTPConfig) string {
+	if cfg.UserAgent != "" {
+		return cfg.UserAgent
+	}
+	return DefaultUserAgent
+}
@@ -0,0 +1,117 @@
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRetryAfter caps how long a single Retry-After wait may be
+	maxRetryAfter = 2 * time.Minute
+	// defaultRetryDelay is used for 429 responses without a Retry-After header
+	defaultRetryDelay = 30 * time.Second
+)
+
+// hostLimiter spaces out requests to the same host so that no host sees
+// more than one request per interval
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+}
+
+func newHostLimiter(requestsPerMinute int) *hostLimiter {
+	l := &hostLimiter{next: make(map[string]time.Time)}
+	if requestsPerMinute > 0 {
+		l.interval = time.Minute / time.Duration(requestsPerMinute)
+	}
+	return l
+}
+
+// reserve claims the next request slot for host and returns when it starts
+func (l *hostLimiter) reserve(host string) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	return at
+}
+
+// delay pushes back all requests to host by at least d
+func (l *hostLimiter) delay(host string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); l.next[host].Before(until) {
+		l.next[host] = until
+	}
+}
+
+// limitedTransport applies per-host rate limiting and honors Retry-After
+// on 429 and 503 responses
+type limitedTransport struct {
+	base       http.RoundTripper
+	limiter    *hostLimiter
+	maxRetries int
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	for attempt := 0; ; attempt++ {
+		if wait := time.Until(t.limiter.reserve(host)); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			}
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries || req.Body != nil {
+			return resp, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, nil
+		}
+
+		delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			if resp.StatusCode == http.StatusServiceUnavailable {
+				return resp, nil
+			}
+			delay = defaultRetryDelay << attempt
+		}
+		if delay > maxRetryAfter {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		t.limiter.delay(host, delay)
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
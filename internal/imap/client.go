@@ -0,0 +1,201 @@
+// Package imap is a minimal IMAP4rev1 client, enough to read the unseen
+// messages of a folder and mark them seen
+package imap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPort is the port of IMAP over TLS
+const DefaultPort = "993"
+
+// commandTimeout bounds each command, including downloading a message
+const commandTimeout = time.Minute
+
+// Client is a logged-in connection to an IMAP server over TLS
+type Client struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// response is an untagged response line, with the literals it carries
+type response struct {
+	text     string
+	literals [][]byte
+}
+
+// Dial connects to host (port 993 unless given as host:port) over TLS and
+// logs in
+func Dial(host, username, password string) (*Client, error) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, DefaultPort)
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", host, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", host, err)
+	}
+	c := &Client{conn: conn, r: bufio.NewReader(conn)}
+
+	conn.SetDeadline(time.Now().Add(commandTimeout))
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading greeting from %s: %w", host, err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected greeting from %s: %s", host, greeting)
+	}
+
+	if _, err := c.command("LOGIN %s %s", quote(username), quote(password)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("logging in to %s: %w", host, err)
+	}
+	return c, nil
+}
+
+// Close logs out and closes the connection
+func (c *Client) Close() error {
+	c.command("LOGOUT")
+	return c.conn.Close()
+}
+
+// Select opens a folder for reading and flagging messages
+func (c *Client) Select(folder string) error {
+	if _, err := c.command("SELECT %s", quote(folder)); err != nil {
+		return fmt.Errorf("selecting %s: %w", folder, err)
+	}
+	return nil
+}
+
+// Unseen returns the UIDs of the messages in the folder not yet seen
+func (c *Client) Unseen() ([]uint32, error) {
+	responses, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, fmt.Errorf("searching unseen messages: %w", err)
+	}
+	var uids []uint32
+	for _, resp := range responses {
+		fields := strings.Fields(resp.text)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "SEARCH") {
+			continue
+		}
+		for _, field := range fields[2:] {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("searching unseen messages: bad UID %q", field)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	return uids, nil
+}
+
+// Fetch downloads a whole message without marking it seen
+func (c *Client) Fetch(uid uint32) ([]byte, error) {
+	responses, err := c.command("UID FETCH %d (BODY.PEEK[])", uid)
+	if err != nil {
+		return nil, fmt.Errorf("fetching message %d: %w", uid, err)
+	}
+	for _, resp := range responses {
+		if strings.Contains(strings.ToUpper(resp.text), "FETCH") && len(resp.literals) > 0 {
+			return resp.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("fetching message %d: not found", uid)
+}
+
+// MarkSeen flags a message as seen
+func (c *Client) MarkSeen(uid uint32) error {
+	if _, err := c.command(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid); err != nil {
+		return fmt.Errorf("marking message %d seen: %w", uid, err)
+	}
+	return nil
+}
+
+// command sends a tagged command and collects the untagged responses until
+// its completion, which must be OK
+func (c *Client) command(format string, args ...any) ([]response, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%d", c.tag)
+	c.conn.SetDeadline(time.Now().Add(commandTimeout))
+	if _, err := fmt.Fprintf(c.conn, "%s "+format+"\r\n", append([]any{tag}, args...)...); err != nil {
+		return nil, err
+	}
+
+	var responses []response
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if status, ok := strings.CutPrefix(resp.text, tag+" "); ok {
+			if !strings.HasPrefix(strings.ToUpper(status), "OK") {
+				return nil, fmt.Errorf("server said: %s", status)
+			}
+			return responses, nil
+		}
+		responses = append(responses, resp)
+	}
+}
+
+// readResponse reads a response line, along with the literals ({n}
+// followed by n bytes) that continue it
+func (c *Client) readResponse() (response, error) {
+	var resp response
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return resp, err
+		}
+		resp.text += line
+		size, ok := literalSize(line)
+		if !ok {
+			return resp, nil
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, literal)
+	}
+}
+
+// readLine reads a line without its CRLF
+func (c *Client) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// literalSize returns n for a line ending in {n}
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	open := strings.LastIndexByte(line, '{')
+	if open < 0 {
+		return 0, false
+	}
+	size, err := strconv.Atoi(line[open+1 : len(line)-1])
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// quote makes an IMAP quoted string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
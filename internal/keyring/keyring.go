@@ -0,0 +1,45 @@
+// Package keyring keeps secrets in the operating system's credential store:
+// the Keychain on macOS, the Secret Service (GNOME Keyring, KWallet) on
+// Linux and BSD, and the Credential Manager on Windows
+package keyring
+
+import (
+	"errors"
+	"fmt"
+
+	gokeyring "github.com/zalando/go-keyring"
+)
+
+// service is the name secrets are stored under
+const service = "newsreadr"
+
+// ErrNotFound is returned when the keyring has no secret of that name
+var ErrNotFound = errors.New("not found in the keyring")
+
+// Get returns the secret stored as name
+func Get(name string) (string, error) {
+	secret, err := gokeyring.Get(service, name)
+	return secret, wrap(err)
+}
+
+// Set stores secret as name, replacing any previous value
+func Set(name, secret string) error {
+	return wrap(gokeyring.Set(service, name, secret))
+}
+
+// Delete removes the secret stored as name
+func Delete(name string) error {
+	return wrap(gokeyring.Delete(service, name))
+}
+
+// wrap reports a missing secret as ErrNotFound, and other failures, like a
+// desktop without a Secret Service, as keyring errors
+func wrap(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gokeyring.ErrNotFound):
+		return ErrNotFound
+	}
+	return fmt.Errorf("using the keyring: %w", err)
+}
@@ -0,0 +1,52 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+
+	gokeyring "github.com/zalando/go-keyring"
+)
+
+func TestKeyring(t *testing.T) {
+	gokeyring.MockInit()
+
+	if _, err := Get("raindrop"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing secret returned %v, want ErrNotFound", err)
+	}
+	if err := Delete("raindrop"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Delete of a missing secret returned %v, want ErrNotFound", err)
+	}
+
+	if err := Set("raindrop", "token"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, err := Get("raindrop"); got != "token" || err != nil {
+		t.Fatalf("Get = %q, %v, want %q", got, err, "token")
+	}
+	if err := Delete("raindrop"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := Get("raindrop"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestKeyringUnavailable(t *testing.T) {
+	unavailable := errors.New("no Secret Service on the bus")
+	gokeyring.MockInitWithError(unavailable)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"Get", func() error { _, err := Get("raindrop"); return err }},
+		{"Set", func() error { return Set("raindrop", "token") }},
+		{"Delete", func() error { return Delete("raindrop") }},
+	}
+	for _, tt := range tests {
+		err := tt.call()
+		if !errors.Is(err, unavailable) || errors.Is(err, ErrNotFound) {
+			t.Errorf("%s returned %v, want the keyring's error", tt.name, err)
+		}
+	}
+}
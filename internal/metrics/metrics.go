@@ -0,0 +1,162 @@
+// Package metrics counts what the fetch and scoring pipeline does and serves
+// the counts in the Prometheus text format
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/database"
+)
+
+// Metrics holds the counters of a running process. A nil *Metrics ignores
+// everything, so code can report unconditionally.
+type Metrics struct {
+	db database.Store
+
+	mu             sync.Mutex
+	feedsFetched   uint64
+	feedsFailed    uint64
+	articlesStored uint64
+	articlesScored uint64
+	scoringSeconds float64
+	ollamaErrors   uint64
+	lastFetch      time.Time
+}
+
+// New creates metrics whose database gauges are read from db when scraped
+func New(db database.Store) *Metrics {
+	return &Metrics{db: db}
+}
+
+// FeedFetched counts a feed fetch and the articles it stored
+func (m *Metrics) FeedFetched(stored int, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.feedsFailed++
+	} else {
+		m.feedsFetched++
+	}
+	m.articlesStored += uint64(stored)
+}
+
+// FetchDone records the end of a fetch cycle
+func (m *Metrics) FetchDone() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.lastFetch = time.Now()
+	m.mu.Unlock()
+}
+
+// ArticleScored counts an article scored in d
+func (m *Metrics) ArticleScored(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.articlesScored++
+	m.scoringSeconds += d.Seconds()
+	m.mu.Unlock()
+}
+
+// OllamaError counts a failed request to Ollama
+func (m *Metrics) OllamaError() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.ollamaErrors++
+	m.mu.Unlock()
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	feedsFetched, feedsFailed := m.feedsFetched, m.feedsFailed
+	stored, scored, seconds := m.articlesStored, m.articlesScored, m.scoringSeconds
+	ollamaErrors, lastFetch := m.ollamaErrors, m.lastFetch
+	m.mu.Unlock()
+
+	var n int64
+	write := func(name, kind, help string, samples ...string) error {
+		c, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		n += int64(c)
+		for _, s := range samples {
+			if err != nil {
+				break
+			}
+			c, err = fmt.Fprintf(w, "%s\n", s)
+			n += int64(c)
+		}
+		return err
+	}
+
+	err := errors.Join(
+		write("newsreadr_feeds_fetched_total", "counter", "Feed fetches by outcome.",
+			fmt.Sprintf(`newsreadr_feeds_fetched_total{result="ok"} %d`, feedsFetched),
+			fmt.Sprintf(`newsreadr_feeds_fetched_total{result="error"} %d`, feedsFailed)),
+		write("newsreadr_articles_stored_total", "counter", "New articles stored.",
+			fmt.Sprintf("newsreadr_articles_stored_total %d", stored)),
+		write("newsreadr_scoring_seconds", "summary", "Time taken to score an article.",
+			fmt.Sprintf("newsreadr_scoring_seconds_sum %g", seconds),
+			fmt.Sprintf("newsreadr_scoring_seconds_count %d", scored)),
+		write("newsreadr_ollama_errors_total", "counter", "Failed requests to Ollama.",
+			fmt.Sprintf("newsreadr_ollama_errors_total %d", ollamaErrors)),
+	)
+	if !lastFetch.IsZero() {
+		err = errors.Join(err, write("newsreadr_last_fetch_timestamp_seconds", "gauge", "When the last fetch cycle finished.",
+			fmt.Sprintf("newsreadr_last_fetch_timestamp_seconds %d", lastFetch.Unix())))
+	}
+
+	if m.db != nil {
+		stats, statsErr := m.db.GetStats()
+		if statsErr != nil {
+			return n, errors.Join(err, statsErr)
+		}
+		err = errors.Join(err,
+			write("newsreadr_database_size_bytes", "gauge", "Size of the database files.",
+				fmt.Sprintf(`newsreadr_database_size_bytes{file="data"} %d`, stats.SizeBytes),
+				fmt.Sprintf(`newsreadr_database_size_bytes{file="cache"} %d`, stats.CacheBytes)),
+			write("newsreadr_articles", "gauge", "Stored articles.",
+				fmt.Sprintf("newsreadr_articles %d", stats.Articles)),
+		)
+	}
+	return n, err
+}
+
+// ServeHTTP serves the metrics to a scraper
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+// Serve serves the metrics at /metrics on addr in the background. It returns
+// once the address is bound, so a port in use is reported right away.
+func (m *Metrics) Serve(addr string) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("serving metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(ln)
+	return srv, nil
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/httpclient"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Matrix posts headlines to a Matrix room
+type Matrix struct {
+	cfg    config.MatrixConfig
+	client *http.Client
+}
+
+func NewMatrix(cfg config.MatrixConfig, httpCfg config.HTTPConfig) *Matrix {
+	client := httpclient.New(httpCfg)
+	client.Timeout = 15 * time.Second
+	return &Matrix{cfg: cfg, client: client}
+}
+
+func (m *Matrix) Name() string {
+	return "matrix"
+}
+
+// Notify sends the headlines as a single m.room.message event
+func (m *Matrix) Notify(articles []models.Article) error {
+	payload := map[string]string{
+		"msgtype":        "m.text",
+		"body":           formatText(articles),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": strings.ReplaceAll(formatHTML(articles), "\n", "<br>"),
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+
+	txnID := fmt.Sprintf("newsreadr-%d", time.Now().UnixNano())
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(m.cfg.Homeserver, "/"), url.PathEscape(m.cfg.RoomID), txnID)
+
+	req, err := http.NewRequest("PUT", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+m.cfg.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
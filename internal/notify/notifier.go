@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Notifier posts high-relevance headlines to a chat service
+type Notifier interface {
+	Name() string
+	Notify(articles []models.Article) error
+}
+
+// Dispatcher sends the post-fetch webhook and headline notifications
+type Dispatcher struct {
+	cfg       config.NotifyConfig
+	webhook   *Webhook
+	notifiers []Notifier
+	topN      int
+
+	mu   sync.Mutex
+	sent []time.Time
+}
+
+// NewDispatcher builds a dispatcher for the configured webhook and
+// notifiers, or returns nil when none are configured. terminal is where
+// terminal notifications go, nil when there is no terminal to notify in.
+func NewDispatcher(cfg *config.Config, terminal io.Writer) (*Dispatcher, error) {
+	webhook, err := NewWebhook(cfg.Webhook, cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifiers []Notifier
+	if cfg.Notify.Matrix.RoomID != "" {
+		notifiers = append(notifiers, NewMatrix(cfg.Notify.Matrix, cfg.HTTP))
+	}
+	if cfg.Notify.Telegram.ChatID != "" {
+		notifiers = append(notifiers, NewTelegram(cfg.Notify.Telegram, cfg.HTTP))
+	}
+	if cfg.Notify.Terminal && terminal != nil {
+		notifiers = append(notifiers, NewTerminal(terminal))
+	}
+
+	if webhook == nil && len(notifiers) == 0 {
+		return nil, nil
+	}
+	return &Dispatcher{
+		cfg:       cfg.Notify,
+		webhook:   webhook,
+		notifiers: notifiers,
+		topN:      cfg.Webhook.TopArticles,
+	}, nil
+}
+
+// FetchDone reports a finished fetch/score cycle. unread must be ordered by
+// relevance; only articles fetched since the cycle started are considered.
+func (d *Dispatcher) FetchDone(newArticles, failedFeeds int, unread []models.Article, since time.Time) error {
+	var errs []error
+
+	if d.webhook != nil {
+		report := BuildReport(newArticles, failedFeeds, unread, since, d.topN)
+		if err := d.webhook.Send(report); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if headlines := d.headlines(unread, since); len(headlines) > 0 {
+		for _, n := range d.notifiers {
+			if err := n.Notify(headlines); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// headlines picks new articles above the threshold, limited to what the
+// hourly rate still allows
+func (d *Dispatcher) headlines(unread []models.Article, since time.Time) []models.Article {
+	if len(d.notifiers) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Forget notifications older than the rate window
+	cutoff := time.Now().Add(-time.Hour)
+	kept := d.sent[:0]
+	for _, t := range d.sent {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	d.sent = kept
+
+	var headlines []models.Article
+	for _, a := range unread {
+		if len(d.sent)+len(headlines) >= d.cfg.MaxPerHour {
+			break
+		}
+		if a.FetchedAt.Before(since) || a.RelevanceScore < d.cfg.Threshold {
+			continue
+		}
+		headlines = append(headlines, a)
+	}
+
+	now := time.Now()
+	for range headlines {
+		d.sent = append(d.sent, now)
+	}
+	return headlines
+}
+
+// formatText renders headlines as plain text, one per line
+func formatText(articles []models.Article) string {
+	var s strings.Builder
+	for i, a := range articles {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		fmt.Fprintf(&s, "%s (%.2f) %s", a.Title, a.RelevanceScore, a.URL)
+	}
+	return s.String()
+}
+
+// formatHTML renders headlines as linked HTML lines
+func formatHTML(articles []models.Article) string {
+	var s strings.Builder
+	for i, a := range articles {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		fmt.Fprintf(&s, "<a href=\"%s\">%s</a> (%.2f)",
+			html.EscapeString(a.URL), html.EscapeString(a.Title), a.RelevanceScore)
+	}
+	return s.String()
+}
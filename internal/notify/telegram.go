@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/httpclient"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+const telegramAPI = "https://api.telegram.org"
+
+// Telegram posts headlines to a Telegram chat through a bot
+type Telegram struct {
+	cfg    config.TelegramConfig
+	client *http.Client
+}
+
+func NewTelegram(cfg config.TelegramConfig, httpCfg config.HTTPConfig) *Telegram {
+	client := httpclient.New(httpCfg)
+	client.Timeout = 15 * time.Second
+	return &Telegram{cfg: cfg, client: client}
+}
+
+func (t *Telegram) Name() string {
+	return "telegram"
+}
+
+// Notify sends the headlines as a single HTML formatted message
+func (t *Telegram) Notify(articles []models.Article) error {
+	payload := map[string]interface{}{
+		"chat_id":                  t.cfg.ChatID,
+		"text":                     formatHTML(articles),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", telegramAPI, t.cfg.BotToken)
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The URL embeds the bot token, so keep it out of the error
+		return fmt.Errorf("sending message: %s", strings.ReplaceAll(err.Error(), t.cfg.BotToken, "***"))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Terminal shows headlines as desktop notifications through the terminal
+// the reader runs in, with the OSC 9 escape sequence that iTerm2, kitty,
+// WezTerm, foot and Windows Terminal, among others, understand. Inside tmux
+// the sequence is passed through to the outer terminal, which tmux only
+// allows with allow-passthrough on.
+type Terminal struct {
+	out  io.Writer
+	tmux bool
+}
+
+func NewTerminal(out io.Writer) *Terminal {
+	return &Terminal{out: out, tmux: os.Getenv("TMUX") != ""}
+}
+
+func (t *Terminal) Name() string {
+	return "terminal"
+}
+
+// Notify sends a notification per headline
+func (t *Terminal) Notify(articles []models.Article) error {
+	for _, a := range articles {
+		seq := "\x1b]9;" + printable(fmt.Sprintf("%s (%.2f)", a.Title, a.RelevanceScore)) + "\x07"
+		if t.tmux {
+			seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+		}
+		if _, err := io.WriteString(t.out, seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printable drops control characters, which would end the escape sequence
+// early
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
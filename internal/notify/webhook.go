@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/httpclient"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// FetchReport summarizes a fetch/score cycle for notifications
+type FetchReport struct {
+	NewArticles int              `json:"new_articles"`
+	FailedFeeds int              `json:"failed_feeds"`
+	TopArticles []models.Article `json:"top_articles"`
+	FinishedAt  time.Time        `json:"finished_at"`
+}
+
+// Webhook posts fetch reports to a configured URL
+type Webhook struct {
+	cfg       config.WebhookConfig
+	tmpl      *template.Template
+	client    *http.Client
+	userAgent string
+}
+
+// NewWebhook creates a webhook from config, or returns nil when no URL is set
+func NewWebhook(cfg config.WebhookConfig, httpCfg config.HTTPConfig) (*Webhook, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+
+	w := &Webhook{cfg: cfg, client: httpclient.New(httpCfg), userAgent: httpclient.UserAgent(httpCfg)}
+	w.client.Timeout = 15 * time.Second
+	if cfg.Template != "" {
+		tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("parsing webhook template: %w", err)
+		}
+		w.tmpl = tmpl
+	}
+	return w, nil
+}
+
+// Send posts the report, rendered through the template when one is
+// configured and as JSON otherwise
+func (w *Webhook) Send(report *FetchReport) error {
+	var body bytes.Buffer
+	contentType := w.cfg.ContentType
+	if w.tmpl != nil {
+		if err := w.tmpl.Execute(&body, report); err != nil {
+			return fmt.Errorf("rendering webhook template: %w", err)
+		}
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+	} else {
+		if err := json.NewEncoder(&body).Encode(report); err != nil {
+			return fmt.Errorf("marshaling webhook payload: %w", err)
+		}
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+
+	req, err := http.NewRequest("POST", w.cfg.URL, &body)
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", w.userAgent)
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// BuildReport assembles a report from a fetch, picking the topN highest
+// scored unread articles fetched since the cycle started
+func BuildReport(newArticles, failedFeeds int, unread []models.Article, since time.Time, topN int) *FetchReport {
+	report := &FetchReport{
+		NewArticles: newArticles,
+		FailedFeeds: failedFeeds,
+		TopArticles: []models.Article{},
+		FinishedAt:  time.Now(),
+	}
+	// unread is already ordered by relevance
+	for _, a := range unread {
+		if len(report.TopArticles) >= topN {
+			break
+		}
+		if a.FetchedAt.Before(since) {
+			continue
+		}
+		a.Content = ""
+		report.TopArticles = append(report.TopArticles, a)
+	}
+	return report
+}
+
+// toJSON is a template helper that renders a value as JSON
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,230 @@
+package query
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Scoring of fuzzy matches, after fzf: every matched character scores,
+// more so at the start of a word or right after the previous match, and
+// gaps between matched characters cost
+const (
+	fuzzyMatch       = 16
+	fuzzyGapStart    = -3
+	fuzzyGapExtend   = -1
+	fuzzyBoundary    = 8 // at the start of a word
+	fuzzyCamel       = 7 // at an upper case letter after a lower case one
+	fuzzyConsecutive = 4 // right after the previous matched character
+	fuzzyFirstFactor = 2 // for the first character of the term
+)
+
+// Fuzzy matches when the characters of a word appear in order, not
+// necessarily together, in the title or the feed name, as in fzf
+type Fuzzy struct {
+	Value string
+}
+
+func (e Fuzzy) Match(a *models.Article) bool {
+	return isSubsequence(e.Value, a.Title) || isSubsequence(e.Value, a.FeedName)
+}
+
+// isSubsequence reports whether the characters of pattern appear in order
+// in text, ignoring case
+func isSubsequence(pattern, text string) bool {
+	p := []rune(pattern)
+	if len(p) == 0 {
+		return true
+	}
+	for _, r := range text {
+		if unicode.ToLower(r) == p[0] {
+			if p = p[1:]; len(p) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fuzzyScore finds the best scoring way the characters of pattern, in
+// lower case, appear in order in text. It returns the score and the rune
+// positions of the matched characters in text, or false when they don't
+// all appear.
+func fuzzyScore(pattern, text string) (int, []int, bool) {
+	p, t := []rune(pattern), []rune(text)
+	if len(p) == 0 || !isSubsequence(pattern, text) {
+		return 0, nil, false
+	}
+	lower := make([]rune, len(t))
+	for j, r := range t {
+		lower[j] = unicode.ToLower(r)
+	}
+
+	// score[i][j] is the best score of the first i+1 characters of the
+	// pattern with the last one at j, from[i][j] where the one before is
+	const none = -1 << 30
+	score := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for i := range p {
+		score[i] = make([]int, len(t))
+		from[i] = make([]int, len(t))
+		// best is the best score of the previous character before j-1,
+		// less the gap up to j, and bestAt where it is
+		best, bestAt := none, -1
+		for j := range t {
+			if i > 0 && j >= 2 && score[i-1][j-2] != none && score[i-1][j-2]+fuzzyGapStart > best+fuzzyGapExtend {
+				best, bestAt = score[i-1][j-2]+fuzzyGapStart, j-2
+			} else if best != none {
+				best += fuzzyGapExtend
+			}
+			score[i][j] = none
+			if lower[j] != p[i] {
+				continue
+			}
+			bonus := charBonus(t, j)
+			if i == 0 {
+				score[i][j] = fuzzyMatch + bonus*fuzzyFirstFactor
+				from[i][j] = -1
+				continue
+			}
+			if j > 0 && score[i-1][j-1] != none {
+				score[i][j] = score[i-1][j-1] + fuzzyMatch + bonus + fuzzyConsecutive
+				from[i][j] = j - 1
+			}
+			if best != none && best+fuzzyMatch+bonus > score[i][j] {
+				score[i][j] = best + fuzzyMatch + bonus
+				from[i][j] = bestAt
+			}
+		}
+	}
+
+	last := len(p) - 1
+	end := -1
+	for j := range t {
+		if score[last][j] != none && (end < 0 || score[last][j] > score[last][end]) {
+			end = j
+		}
+	}
+	positions := make([]int, len(p))
+	for i, j := last, end; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+	return score[last][end], positions, true
+}
+
+// charBonus is the bonus for matching the character at j of text
+func charBonus(text []rune, j int) int {
+	if j == 0 {
+		return fuzzyBoundary
+	}
+	prev, cur := text[j-1], text[j]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(cur) || unicode.IsDigit(cur)):
+		return fuzzyBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return fuzzyCamel
+	}
+	return 0
+}
+
+// match is how a fuzzy word matches an article: in the title or the feed
+// name, whichever scores best
+func (e Fuzzy) match(a *models.Article) (score int, title, feed []int, ok bool) {
+	titleScore, titlePos, inTitle := fuzzyScore(e.Value, a.Title)
+	feedScore, feedPos, inFeed := fuzzyScore(e.Value, a.FeedName)
+	switch {
+	case inTitle && (!inFeed || titleScore >= feedScore):
+		return titleScore, titlePos, nil, true
+	case inFeed:
+		return feedScore, nil, feedPos, true
+	}
+	return 0, nil, nil, false
+}
+
+// Rank scores how well an article matches the fuzzy words of a query, for
+// sorting the best matches first: the scores of all the words, or of the
+// best matching side of an OR. Queries without fuzzy words rank every
+// article 0.
+func Rank(e Expr, a *models.Article) int {
+	switch e := e.(type) {
+	case And:
+		total := 0
+		for _, t := range e {
+			total += Rank(t, a)
+		}
+		return total
+	case Or:
+		best := 0
+		for _, t := range e {
+			if t.Match(a) {
+				best = max(best, Rank(t, a))
+			}
+		}
+		return best
+	case Fuzzy:
+		score, _, _, _ := e.match(a)
+		return score
+	}
+	return 0
+}
+
+// Sort orders articles by how well they match the fuzzy words of a query,
+// keeping their order otherwise
+func Sort(e Expr, articles []models.Article) {
+	ranks := make(map[int64]int, len(articles))
+	for i := range articles {
+		ranks[articles[i].ID] = Rank(e, &articles[i])
+	}
+	sort.SliceStable(articles, func(i, j int) bool {
+		return ranks[articles[i].ID] > ranks[articles[j].ID]
+	})
+}
+
+// Highlights returns the rune positions in the title and the feed name of
+// an article matched by the words and phrases of a query, for showing them
+func Highlights(e Expr, a *models.Article) (title, feed []int) {
+	switch e := e.(type) {
+	case And:
+		for _, t := range e {
+			tp, fp := Highlights(t, a)
+			title, feed = append(title, tp...), append(feed, fp...)
+		}
+	case Or:
+		for _, t := range e {
+			if t.Match(a) {
+				tp, fp := Highlights(t, a)
+				title, feed = append(title, tp...), append(feed, fp...)
+			}
+		}
+	case Fuzzy:
+		_, title, feed, _ = e.match(a)
+	case Text:
+		title = substringPositions(a.Title, e.Value)
+	}
+	return title, feed
+}
+
+// substringPositions returns the rune positions of the first occurrence of
+// value, in lower case, in text
+func substringPositions(text, value string) []int {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	v := []rune(value)
+	// Lower casing may change the length of the text, e.g. for "İ"
+	if len(v) == 0 || len(lower) != len(runes) {
+		return nil
+	}
+	for i := 0; i+len(v) <= len(lower); i++ {
+		if string(lower[i:i+len(v)]) == value {
+			positions := make([]int, len(v))
+			for k := range v {
+				positions[k] = i + k
+			}
+			return positions
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package query
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+func TestFuzzyPositions(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          []int
+		ok            bool
+	}{
+		{"gr", "Go Release", []int{0, 3}, true},
+		{"rust", "Trusted Rust", []int{8, 9, 10, 11}, true},
+		{"gh", "GitHub", []int{0, 3}, true},
+		{"ts", "TypeScript types", []int{0, 4}, true},
+		{"xyz", "Go Release", nil, false},
+		{"og", "Go", nil, false},
+	}
+	for _, tt := range tests {
+		_, positions, ok := fuzzyScore(tt.pattern, tt.text)
+		if ok != tt.ok || !slices.Equal(positions, tt.want) {
+			t.Errorf("fuzzyScore(%q, %q) matched %v at %v, want %v at %v", tt.pattern, tt.text, ok, positions, tt.ok, tt.want)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	// Each pair is ranked better first
+	tests := []struct {
+		query         string
+		better, worse string
+	}{
+		{"gr", "Go Release", "Aggregator"},
+		{"rust", "Rust 1.80", "Rather unusual story time"},
+		{"k8s", "K8s at scale", "Kubernetes 1.30 ships"},
+		{"ts", "TypeScript 5.5", "Tests"},
+		{"go OR rust", "Rust in Go", "Argonauts"},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		better := Rank(e, &models.Article{Title: tt.better})
+		worse := Rank(e, &models.Article{Title: tt.worse})
+		if better <= worse {
+			t.Errorf("%q ranks %q at %d, not above %q at %d", tt.query, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestRankWithoutFuzzyWords(t *testing.T) {
+	e, err := Parse(`"go" feed:blog score>0.5`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := Rank(e, &models.Article{Title: "go", FeedName: "Go Blog", RelevanceScore: 0.9}); r != 0 {
+		t.Errorf("Rank = %d, want 0", r)
+	}
+}
+
+func TestSort(t *testing.T) {
+	e, err := Parse("rel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	articles := []models.Article{
+		{ID: 1, Title: "Barrel of laughs"},
+		{ID: 2, Title: "Nothing to see"},
+		{ID: 3, Title: "Real estate"},
+		{ID: 4, Title: "Unrelated"},
+		{ID: 5, Title: "Go release notes"},
+	}
+	Sort(e, articles)
+	var ids []int64
+	for _, a := range articles {
+		ids = append(ids, a.ID)
+	}
+	// Equal ranks keep their order
+	if want := []int64{5, 3, 1, 4, 2}; !slices.Equal(ids, want) {
+		t.Errorf("Sort ordered %v, want %v", ids, want)
+	}
+}
+
+func TestHighlights(t *testing.T) {
+	e, err := Parse(`gb "release"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	title, feed := Highlights(e, &models.Article{Title: "Go 1.22 release", FeedName: "Go Blog"})
+	if want := []int{8, 9, 10, 11, 12, 13, 14}; !slices.Equal(title, want) {
+		t.Errorf("title highlights %v, want %v", title, want)
+	}
+	if want := []int{0, 3}; !slices.Equal(feed, want) {
+		t.Errorf("feed highlights %v, want %v", feed, want)
+	}
+}
@@ -0,0 +1,302 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Expr is a node of a parsed filter query
+type Expr interface {
+	Match(article *models.Article) bool
+}
+
+// And matches when all of its terms match
+type And []Expr
+
+func (e And) Match(a *models.Article) bool {
+	for _, t := range e {
+		if !t.Match(a) {
+			return false
+		}
+	}
+	return true
+}
+
+// Or matches when any of its terms match
+type Or []Expr
+
+func (e Or) Match(a *models.Article) bool {
+	for _, t := range e {
+		if t.Match(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// Not inverts its term
+type Not struct {
+	Expr Expr
+}
+
+func (e Not) Match(a *models.Article) bool {
+	return !e.Expr.Match(a)
+}
+
+// Text matches a case-insensitive substring of the title
+type Text struct {
+	Value string
+}
+
+func (e Text) Match(a *models.Article) bool {
+	return strings.Contains(strings.ToLower(a.Title), e.Value)
+}
+
+// Field matches a case-insensitive substring of a named field
+type Field struct {
+	Name  string
+	Value string
+}
+
+func (e Field) Match(a *models.Article) bool {
+	switch e.Name {
+	case "feed":
+		return strings.Contains(strings.ToLower(a.FeedName), e.Value)
+	case "title":
+		return strings.Contains(strings.ToLower(a.Title), e.Value)
+	case "url":
+		return strings.Contains(strings.ToLower(a.URL), e.Value)
+	case "tag":
+		for _, t := range a.Tags {
+			if strings.EqualFold(t, e.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Score compares the relevance score against a value
+type Score struct {
+	Op    string
+	Value float64
+}
+
+func (e Score) Match(a *models.Article) bool {
+	switch e.Op {
+	case ">":
+		return a.RelevanceScore > e.Value
+	case ">=":
+		return a.RelevanceScore >= e.Value
+	case "<":
+		return a.RelevanceScore < e.Value
+	case "<=":
+		return a.RelevanceScore <= e.Value
+	case "=":
+		return a.RelevanceScore == e.Value
+	}
+	return false
+}
+
+// Regex matches the title against a regular expression
+type Regex struct {
+	Re *regexp.Regexp
+}
+
+func (e Regex) Match(a *models.Article) bool {
+	return e.Re.MatchString(a.Title)
+}
+
+// Narrows reports whether everything next matches is matched by prev too,
+// as when typing on adds letters to words, phrases and field values or adds
+// terms. Results for prev can then be filtered for next instead of
+// everything. It is conservative: false doesn't mean next matches more.
+func Narrows(prev, next Expr) bool {
+	p, ok := prev.(And)
+	n, ok2 := next.(And)
+	if !ok || !ok2 || len(n) < len(p) {
+		return false
+	}
+	for i, term := range p {
+		if !narrowsTerm(term, n[i]) {
+			return false
+		}
+	}
+	// Any added term only narrows further. Negations are left out to stay
+	// conservative, since one grows from -r to -rust as it is typed and
+	// that step isn't a narrowing anyway.
+	for _, term := range n[len(p):] {
+		if _, ok := term.(Not); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// narrowsTerm reports whether next is prev or prev with a longer value
+func narrowsTerm(prev, next Expr) bool {
+	switch p := prev.(type) {
+	case Fuzzy:
+		n, ok := next.(Fuzzy)
+		return ok && strings.HasPrefix(n.Value, p.Value)
+	case Text:
+		n, ok := next.(Text)
+		return ok && strings.HasPrefix(n.Value, p.Value)
+	case Field:
+		n, ok := next.(Field)
+		if p.Name == "tag" {
+			return ok && n == p
+		}
+		return ok && n.Name == p.Name && strings.HasPrefix(n.Value, p.Value)
+	case Score:
+		n, ok := next.(Score)
+		return ok && n == p
+	case Not:
+		n, ok := next.(Not)
+		return ok && sameTerm(p.Expr, n.Expr)
+	}
+	return false
+}
+
+// sameTerm reports whether two terms are the same. Regexes never are, as
+// they are compiled anew.
+func sameTerm(a, b Expr) bool {
+	switch a.(type) {
+	case Fuzzy, Text, Field, Score:
+		return a == b
+	}
+	return false
+}
+
+// fields lists the names accepted in name:value terms
+var fields = map[string]bool{"feed": true, "title": true, "url": true, "tag": true}
+
+// scoreRe matches score comparisons such as score>0.7 or score<=.5
+var scoreRe = regexp.MustCompile(`^score(>=|<=|>|<|=)([0-9]*\.?[0-9]+)$`)
+
+// Parse parses a filter query. Terms are separated by spaces and must all
+// match; "OR" between terms matches either side and a leading "-" negates a
+// term. Supported terms are plain words (fuzzy over the title and feed name,
+// see Fuzzy; negated, a title substring), "quoted phrases" (title
+// substring), name:value for feed, title, url and tag, score comparisons like
+// score>0.7, and /regex/ against the title. An empty query returns a nil Expr.
+func Parse(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var groups Or
+	var current And
+	for i, tok := range tokens {
+		if tok == "OR" {
+			if len(current) == 0 || i == len(tokens)-1 {
+				return nil, fmt.Errorf("OR needs a term on both sides")
+			}
+			groups = append(groups, current)
+			current = nil
+			continue
+		}
+		term, err := parseTerm(tok)
+		if err != nil {
+			return nil, err
+		}
+		current = append(current, term)
+	}
+	groups = append(groups, current)
+
+	if len(groups) == 1 {
+		return groups[0], nil
+	}
+	return groups, nil
+}
+
+// parseTerm parses a single token into an expression
+func parseTerm(tok string) (Expr, error) {
+	if len(tok) > 1 && tok[0] == '-' {
+		inner, err := parseTerm(tok[1:])
+		if err != nil {
+			return nil, err
+		}
+		// Excluding everything with the letters in order would leave little
+		if f, ok := inner.(Fuzzy); ok {
+			inner = Text{f.Value}
+		}
+		return Not{inner}, nil
+	}
+
+	if len(tok) > 2 && tok[0] == '/' && tok[len(tok)-1] == '/' {
+		re, err := regexp.Compile("(?i)" + tok[1:len(tok)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %s: %w", tok, err)
+		}
+		return Regex{re}, nil
+	}
+
+	if tok[0] == '"' {
+		return Text{strings.ToLower(strings.Trim(tok, `"`))}, nil
+	}
+
+	if m := scoreRe.FindStringSubmatch(strings.ToLower(tok)); m != nil {
+		v, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid score %q: %w", m[2], err)
+		}
+		return Score{Op: m[1], Value: v}, nil
+	}
+
+	if name, value, ok := strings.Cut(tok, ":"); ok && fields[strings.ToLower(name)] {
+		return Field{Name: strings.ToLower(name), Value: strings.ToLower(strings.Trim(value, `"`))}, nil
+	}
+
+	return Fuzzy{strings.ToLower(tok)}, nil
+}
+
+// tokenize splits input on spaces, keeping "quoted phrases" and /regexes/
+// (which may contain spaces) together
+func tokenize(input string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	var closing byte
+
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case closing != 0:
+			cur.WriteByte(c)
+			if c == closing && (c != '/' || input[i-1] != '\\') {
+				closing = 0
+			}
+		case c == ' ' || c == '\t':
+			flush()
+		case c == '"':
+			closing = '"'
+			cur.WriteByte(c)
+		case c == '/' && (cur.Len() == 0 || cur.String() == "-"):
+			closing = '/'
+			cur.WriteByte(c)
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if closing != 0 {
+		return nil, fmt.Errorf("unterminated %c in filter", closing)
+	}
+	flush()
+
+	return tokens, nil
+}
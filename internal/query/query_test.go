@@ -0,0 +1,192 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// show prints an expression for comparing parse results, regexes by their
+// source since they are compiled anew
+func show(e Expr) string {
+	switch e := e.(type) {
+	case nil:
+		return "<nil>"
+	case And:
+		return "And(" + showAll(e) + ")"
+	case Or:
+		return "Or(" + showAll(e) + ")"
+	case Not:
+		return "Not(" + show(e.Expr) + ")"
+	case Regex:
+		return fmt.Sprintf("Regex(%s)", e.Re)
+	}
+	return fmt.Sprintf("%#v", e)
+}
+
+func showAll[T ~[]Expr](terms T) string {
+	shown := make([]string, len(terms))
+	for i, t := range terms {
+		shown[i] = show(t)
+	}
+	return strings.Join(shown, ", ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Expr
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"Go", And{Fuzzy{"go"}}},
+		{"go  rust", And{Fuzzy{"go"}, Fuzzy{"rust"}}},
+
+		// Quoting
+		{`"Range Over"`, And{Text{"range over"}}},
+		{`"range over" funcs`, And{Text{"range over"}, Fuzzy{"funcs"}}},
+		{`title:"Go 1.22"`, And{Field{"title", "go 1.22"}}},
+		{`/go 1\.2[0-9]/`, And{Regex{regexp.MustCompile(`(?i)go 1\.2[0-9]`)}}},
+		{`/a\/b/`, And{Regex{regexp.MustCompile(`(?i)a\/b`)}}},
+
+		// Negation
+		{"-crypto", And{Not{Text{"crypto"}}}},
+		{`-"pump and dump"`, And{Not{Text{"pump and dump"}}}},
+		{"-feed:reddit", And{Not{Field{"feed", "reddit"}}}},
+		{"-/^ask hn/", And{Not{Regex{regexp.MustCompile("(?i)^ask hn")}}}},
+		{"-score<0.5", And{Not{Score{"<", 0.5}}}},
+
+		// Field prefixes
+		{"feed:HN", And{Field{"feed", "hn"}}},
+		{"Feed:hn", And{Field{"feed", "hn"}}},
+		{"url:github.com", And{Field{"url", "github.com"}}},
+		{"tag:Go", And{Field{"tag", "go"}}},
+		{"author:pike", And{Fuzzy{"author:pike"}}},
+		{"score>0.7", And{Score{">", 0.7}}},
+		{"score>=.5", And{Score{">=", 0.5}}},
+		{"SCORE=1", And{Score{"=", 1}}},
+		{"score>high", And{Fuzzy{"score>high"}}},
+
+		// OR
+		{"go OR rust", Or{And{Fuzzy{"go"}}, And{Fuzzy{"rust"}}}},
+		{"feed:hn go OR tag:rust", Or{And{Field{"feed", "hn"}, Fuzzy{"go"}}, And{Field{"tag", "rust"}}}},
+		{"go or rust", And{Fuzzy{"go"}, Fuzzy{"or"}, Fuzzy{"rust"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			if show(got) != show(tt.want) {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, show(got), show(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`"unterminated`, `unterminated "`},
+		{`feed:"hacker news`, `unterminated "`},
+		{"/unterminated", "unterminated /"},
+		{"/[a-/", "invalid regex"},
+		{"OR go", "OR needs a term on both sides"},
+		{"go OR", "OR needs a term on both sides"},
+		{"go OR OR rust", "OR needs a term on both sides"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %s, want an error", tt.input, show(got))
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%q) failed with %q, want %q", tt.input, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNarrows(t *testing.T) {
+	tests := []struct {
+		prev, next string
+		want       bool
+	}{
+		// Extending a term
+		{"go", "go", true},
+		{"go", "gol", true},
+		{`"range"`, `"range over"`, true},
+		{"-crypto", "-crypto", true},
+		{"-cr", "-crypto", false},
+		{"gol", "go", false},
+		{"go", "og", false},
+
+		// Adding and removing terms
+		{"go", "go rust", true},
+		{"go", "go feed:hn", true},
+		{"go", "go score>0.5", true},
+		{"go rust", "go", false},
+		{"go rust", "rust", false},
+		{"go rust", "rust go", false},
+
+		// Negation and OR
+		{"go", "go -rust", false},
+		{"go", "-go", false},
+		{"go", "go OR rust", false},
+		{"go OR rust", "go OR rusty", false},
+
+		// Fields and tags
+		{"feed:h", "feed:hn", true},
+		{"feed:hn", "feed:h", false},
+		{"feed:hn", "title:hn", false},
+		{"feed", "feed:", false},
+		{"tag:go", "tag:go", true},
+		{"tag:go", "tag:gol", false},
+		{"score>0", "score>0.5", false},
+		{"score>0.5", "score>0.5", true},
+
+		// Regexes are compiled anew, so never compared
+		{"/go/", "/go/", false},
+		{"/go/", "/gol/", false},
+		{"/go/", "/go/ rust", false},
+		{"go", "go /rust/", true},
+	}
+	articles := []models.Article{
+		{Title: "Go 1.22 released", FeedName: "Hacker News", Tags: []string{"go"}, RelevanceScore: 0.8},
+		{Title: "Rust and Go", FeedName: "Lobsters", Tags: []string{"golang"}, RelevanceScore: 0.4},
+		{Title: "Crypto winter", FeedName: "HN", RelevanceScore: 0.1},
+		{Title: "Range over funcs in Go", FeedName: "Go Blog", Tags: []string{"go"}},
+		{Title: "Rusty tools", FeedName: "hnrss"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prev+" -> "+tt.next, func(t *testing.T) {
+			prev, err := Parse(tt.prev)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.prev, err)
+			}
+			next, err := Parse(tt.next)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.next, err)
+			}
+			if got := Narrows(prev, next); got != tt.want {
+				t.Errorf("Narrows(%s, %s) = %v, want %v", show(prev), show(next), got, tt.want)
+			}
+			// Filtering the results for prev must not lose any for next
+			if !tt.want {
+				return
+			}
+			for _, a := range articles {
+				if next.Match(&a) && !prev.Match(&a) {
+					t.Errorf("%q matches %q but not %q", a.Title, tt.next, tt.prev)
+				}
+			}
+		})
+	}
+}
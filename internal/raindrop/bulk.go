@@ -0,0 +1,62 @@
+package raindrop
+
+import "fmt"
+
+// bulkBatchSize is the most items the API accepts in one request
+const bulkBatchSize = 100
+
+// PartialError reports a batch save that failed after saving some items
+type PartialError struct {
+	Saved, Total int
+	Err          error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("saved %d of %d items: %v", e.Saved, e.Total, e.Err)
+}
+
+func (e *PartialError) Unwrap() error { return e.Err }
+
+type bulkRequest struct {
+	Items []RaindropItem `json:"items"`
+}
+
+type bulkResponse struct {
+	Result bool           `json:"result"`
+	Items  []RaindropItem `json:"items"`
+}
+
+// SaveItems saves items in batches, keeping to the rate limit. onBatch is
+// called with each batch once it is saved, so progress made before an error
+// isn't lost. It returns how many items were saved; when some were before
+// failing, the error is a *PartialError.
+func (c *Client) SaveItems(items []RaindropItem, onBatch func(saved []RaindropItem) error) (int, error) {
+	saved, err := c.saveItems(items, onBatch)
+	if err != nil && saved > 0 {
+		err = &PartialError{Saved: saved, Total: len(items), Err: err}
+	}
+	return saved, err
+}
+
+func (c *Client) saveItems(items []RaindropItem, onBatch func(saved []RaindropItem) error) (int, error) {
+	saved := 0
+	for start := 0; start < len(items); start += bulkBatchSize {
+		batch := items[start:min(start+bulkBatchSize, len(items))]
+
+		var result bulkResponse
+		if err := c.post("/raindrops", bulkRequest{Items: batch}, &result); err != nil {
+			return saved, err
+		}
+		if !result.Result {
+			return saved, fmt.Errorf("Raindrop API returned failure")
+		}
+
+		saved += len(batch)
+		if onBatch != nil {
+			if err := onBatch(batch); err != nil {
+				return saved, err
+			}
+		}
+	}
+	return saved, nil
+}
@@ -1,26 +1,43 @@
 package raindrop
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/thomaskoefod/newsreadr/pkg/models"
 )
 
 const raindropAPIURL = "https://api.raindrop.io/rest/v1"
 
+// requestInterval spaces out requests to stay within the API rate limit of
+// 120 requests per minute
+const requestInterval = 500 * time.Millisecond
+
 type Client struct {
 	apiToken string
 	client   *http.Client
+
+	mu          sync.Mutex
+	lastRequest time.Time
+	failures    int       // consecutive failed calls, see breakerThreshold
+	openUntil   time.Time // requests fail fast until then
 }
 
 type RaindropItem struct {
 	Link  string `json:"link"`
 	Title string `json:"title"`
 	Excerpt string `json:"excerpt,omitempty"`
+	Note    string      `json:"note,omitempty"`
+	Tags    []string    `json:"tags,omitempty"`
+	Collection *Collection `json:"collection,omitempty"`
+}
+
+// Collection refers to a Raindrop collection by ID
+type Collection struct {
+	ID int64 `json:"$id"`
 }
 
 type RaindropResponse struct {
@@ -35,42 +52,22 @@ func NewClient(apiToken string) *Client {
 	}
 }
 
-// SaveArticle saves an article to Raindrop.io
-func (c *Client) SaveArticle(article *models.Article) error {
+// SaveArticle saves an article to Raindrop.io with an optional note. The
+// excerpt defaults to the article description.
+func (c *Client) SaveArticle(article *models.Article, note, excerpt string) error {
+	if excerpt == "" {
+		excerpt = article.Description
+	}
 	item := RaindropItem{
 		Link:    article.URL,
 		Title:   article.Title,
-		Excerpt: article.Description,
-	}
-
-	jsonData, err := json.Marshal(item)
-	if err != nil {
-		return fmt.Errorf("marshaling article: %w", err)
-	}
-
-	url := fmt.Sprintf("%s/raindrop", raindropAPIURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiToken))
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("sending request to Raindrop: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("Raindrop API error (status %d): %s", resp.StatusCode, string(body))
+		Excerpt: excerpt,
+		Note:    note,
 	}
 
 	var result RaindropResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("decoding response: %w", err)
+	if err := c.post("/raindrop", item, &result); err != nil {
+		return err
 	}
 
 	if !result.Result {
@@ -80,26 +77,31 @@ func (c *Client) SaveArticle(article *models.Article) error {
 	return nil
 }
 
-// TestConnection tests the API token by making a simple request
-func (c *Client) TestConnection() error {
-	url := fmt.Sprintf("%s/user", raindropAPIURL)
-	req, err := http.NewRequest("GET", url, nil)
+// post sends body as JSON to the API path and decodes the response into out
+func (c *Client) post(path string, body, out any) error {
+	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return fmt.Errorf("marshaling request: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiToken))
-
-	resp, err := c.client.Do(req)
+	resp, err := c.send("POST", path, jsonData)
 	if err != nil {
-		return fmt.Errorf("sending request to Raindrop: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("Raindrop API error (status %d): %s", resp.StatusCode, string(body))
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
 	}
+	return nil
+}
 
+// TestConnection tests the API token by making a simple request
+func (c *Client) TestConnection() error {
+	resp, err := c.send("GET", "/user", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
 	return nil
 }
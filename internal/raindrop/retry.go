@@ -0,0 +1,166 @@
+package raindrop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxAttempts is how often a request is tried before giving up
+	maxAttempts = 4
+	// initialBackoff is the wait before the first retry, doubling after
+	// each failed attempt up to maxBackoff
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+	// maxRetryAfter is the longest Retry-After waited for; beyond it the
+	// request fails
+	maxRetryAfter = 2 * time.Minute
+	// breakerThreshold consecutive failed calls open the circuit breaker,
+	// failing further calls at once for breakerCooldown
+	breakerThreshold = 3
+	breakerCooldown  = 2 * time.Minute
+)
+
+// ErrCircuitOpen is returned without contacting Raindrop while the circuit
+// breaker is open after repeated failures
+var ErrCircuitOpen = errors.New("Raindrop keeps failing, pausing requests")
+
+// APIError is an error response from the Raindrop API
+type APIError struct {
+	Status int
+	Body   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Raindrop API error (status %d): %s", e.Status, e.Body)
+}
+
+// retryable reports whether a request may succeed when tried again
+func (e *APIError) retryable() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= 500
+}
+
+// send makes an API request, spacing requests by requestInterval and
+// retrying network errors, rate limiting (429, honouring Retry-After) and
+// server errors with exponential backoff. Any other non-200 status fails at
+// once. The caller closes the body of the returned response.
+func (c *Client) send(method, path string, body []byte) (*http.Response, error) {
+	if err := c.checkBreaker(); err != nil {
+		return nil, err
+	}
+
+	backoff := initialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, wait, err := c.attempt(method, path, body)
+		if err == nil {
+			c.recordResult(nil)
+			return resp, nil
+		}
+		lastErr = err
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.retryable() {
+			// The request itself is wrong, Raindrop is fine
+			c.recordResult(nil)
+			return nil, err
+		}
+		if attempt == maxAttempts || wait > maxRetryAfter {
+			break
+		}
+		if wait == 0 {
+			wait = backoff
+			backoff = min(backoff*2, maxBackoff)
+		}
+		time.Sleep(wait)
+	}
+
+	c.recordResult(lastErr)
+	return nil, lastErr
+}
+
+// attempt sends a request once. On a 429 it also returns the wait asked
+// for by Retry-After.
+func (c *Client) attempt(method, path string, body []byte) (*http.Response, time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, raindropAPIURL+path, reader)
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiToken))
+
+	c.mu.Lock()
+	if wait := requestInterval - time.Since(c.lastRequest); wait > 0 {
+		time.Sleep(wait)
+	}
+	c.lastRequest = time.Now()
+	c.mu.Unlock()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("sending request to Raindrop: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, 0, nil
+	}
+
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	var wait time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait = retryAfter(resp.Header.Get("Retry-After"))
+	}
+	return nil, wait, &APIError{Status: resp.StatusCode, Body: string(data)}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date,
+// returning zero when it is missing or invalid
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// checkBreaker fails fast while the circuit breaker is open
+func (c *Client) checkBreaker() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.openUntil) {
+		return fmt.Errorf("%w (retrying after %s)", ErrCircuitOpen, c.openUntil.Format("15:04:05"))
+	}
+	return nil
+}
+
+// recordResult counts consecutive failed calls, opening the circuit breaker
+// once there are breakerThreshold of them
+func (c *Client) recordResult(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.failures >= breakerThreshold {
+		c.openUntil = time.Now().Add(breakerCooldown)
+		c.failures = 0
+	}
+}
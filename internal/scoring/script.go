@@ -0,0 +1,82 @@
+package scoring
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// Script scores articles with a user-supplied Starlark function. The script
+// must define score(article) returning a number; article exposes ai_score,
+// feed, feed_weight, title, description, url, tags and age_hours.
+type Script struct {
+	cfg   *config.Config
+	path  string
+	score starlark.Callable
+}
+
+// LoadScript loads the scoring script configured under scoring.script
+func LoadScript(cfg *config.Config) (*Script, error) {
+	path := cfg.Scoring.Script
+	if path == "" {
+		return nil, fmt.Errorf("scoring.backend is script but scoring.script is not set")
+	}
+
+	thread := &starlark.Thread{Name: "load " + path}
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("loading scoring script %s: %w", path, err)
+	}
+
+	fn, ok := globals["score"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("scoring script %s does not define score(article)", path)
+	}
+	globals.Freeze()
+
+	return &Script{cfg: cfg, path: path, score: fn}, nil
+}
+
+// Score runs the script on an article, given its AI relevance score
+func (s *Script) Score(article *models.Article, aiScore float64) (float64, error) {
+	tags := make([]starlark.Value, len(article.Tags))
+	for i, t := range article.Tags {
+		tags[i] = starlark.String(t)
+	}
+
+	arg := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"ai_score":    starlark.Float(aiScore),
+		"feed":        starlark.String(article.FeedName),
+		"feed_weight": starlark.Float(s.feedWeight(article)),
+		"title":       starlark.String(article.Title),
+		"description": starlark.String(article.Description),
+		"url":         starlark.String(article.URL),
+		"tags":        starlark.NewList(tags),
+		"age_hours":   starlark.Float(time.Since(article.PublishedAt).Hours()),
+	})
+
+	thread := &starlark.Thread{Name: "score"}
+	result, err := starlark.Call(thread, s.score, starlark.Tuple{arg}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("running %s: %w", s.path, err)
+	}
+
+	score, ok := starlark.AsFloat(result)
+	if !ok {
+		return 0, fmt.Errorf("%s: score() returned %s, want a number", s.path, result.Type())
+	}
+	return score, nil
+}
+
+// feedWeight returns the configured weight of the article's feed, or 1
+func (s *Script) feedWeight(article *models.Article) float64 {
+	if settings := s.cfg.FeedSettings(article.FeedURL, article.FeedName); settings != nil && settings.Weight != 0 {
+		return settings.Weight
+	}
+	return 1
+}
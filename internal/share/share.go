@@ -0,0 +1,86 @@
+package share
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/atotto/clipboard"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// DefaultTargets are offered when no share targets are configured
+var DefaultTargets = []config.ShareTarget{
+	{Name: "Copy URL", Type: "clipboard", Template: "{{.URL}}"},
+	{Name: "Copy markdown link", Type: "clipboard", Template: "[{{.Title}}]({{.URL}})"},
+}
+
+// Targets returns the configured share targets or the defaults
+func Targets(cfg config.ShareConfig) []config.ShareTarget {
+	if len(cfg.Targets) == 0 {
+		return DefaultTargets
+	}
+	return cfg.Targets
+}
+
+// Run shares an article with the given target
+func Run(target config.ShareTarget, article *models.Article) error {
+	switch target.Type {
+	case "clipboard":
+		text, err := render(target.Name, target.Template, article)
+		if err != nil {
+			return err
+		}
+		if err := clipboard.WriteAll(text); err != nil {
+			return fmt.Errorf("copying to clipboard: %w", err)
+		}
+		return nil
+
+	case "command":
+		return runCommand(target.Command, article)
+
+	default:
+		return fmt.Errorf("share target %q has unknown type %q", target.Name, target.Type)
+	}
+}
+
+// render expands a text/template against the article
+func render(name, text string, article *models.Article) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing template for %q: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, article); err != nil {
+		return "", fmt.Errorf("rendering template for %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// runCommand runs a shell command with the article exposed as environment
+// variables. The article is never put in the command itself, where a feed
+// could inject shell syntax through a title.
+func runCommand(command string, article *models.Article) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"NEWSREADR_TITLE="+article.Title,
+		"NEWSREADR_URL="+article.URL,
+		"NEWSREADR_FEED="+article.FeedName,
+		"NEWSREADR_DESCRIPTION="+article.Description,
+	)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("running share command: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package share
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Article fields reach a command only through the environment, so shell
+// syntax in them is never run
+func TestRunCommandInjection(t *testing.T) {
+	tests := []string{
+		"$(touch x)",
+		"`touch x`",
+		"'; touch x; '",
+		`"; touch x; "`,
+		"{{.URL}} && touch x",
+	}
+	for _, title := range tests {
+		dir := t.TempDir()
+		t.Chdir(dir)
+
+		target := config.ShareTarget{Name: "Echo", Type: "command", Command: `printf '%s' "$NEWSREADR_TITLE" > title`}
+		if err := Run(target, &models.Article{Title: title, URL: "https://example.com/a"}); err != nil {
+			t.Fatalf("sharing %q: %v", title, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "x")); err == nil {
+			t.Errorf("sharing %q ran the command in its title", title)
+		}
+		if got, err := os.ReadFile(filepath.Join(dir, "title")); err != nil || string(got) != title {
+			t.Errorf("sharing %q passed NEWSREADR_TITLE=%q (%v)", title, got, err)
+		}
+	}
+}
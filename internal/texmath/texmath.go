@@ -0,0 +1,307 @@
+// Package texmath shows the TeX math of paper abstracts and titles as
+// Unicode text a terminal can display: \alpha becomes α, x^2 x², \frac{a}{b}
+// a/b and \mathbb{R} ℝ. What has no Unicode form is kept readable, as in
+// x^(n+1).
+package texmath
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// mathSpan matches $$...$$, $...$, \(...\) and \[...\]
+var mathSpan = regexp.MustCompile(`(?s)\$\$(.+?)\$\$|\$([^$\n]+?)\$|\\\((.+?)\\\)|\\\[(.+?)\\\]`)
+
+// Text converts the math in plain text
+func Text(s string) string {
+	return convert(s, func(text string) string { return text })
+}
+
+// HTML converts the math in HTML. Entities are decoded in the math and
+// the result is escaped again.
+func HTML(s string) string {
+	return convert(s, html.EscapeString)
+}
+
+func convert(s string, escape func(string) string) string {
+	if !strings.ContainsAny(s, `$\`) {
+		return s
+	}
+	return mathSpan.ReplaceAllStringFunc(s, func(span string) string {
+		groups := mathSpan.FindStringSubmatch(span)
+		// A price like "$5 and $10" isn't math
+		if groups[2] != "" && !isMath(groups[2]) {
+			return span
+		}
+		tex := html.UnescapeString(groups[1] + groups[2] + groups[3] + groups[4])
+		return escape(toText([]rune(tex)))
+	})
+}
+
+// isMath reports whether the text between single dollar signs looks like
+// math: it doesn't start or end with a space, and it uses TeX syntax or
+// doesn't start with a digit
+func isMath(tex string) bool {
+	first, _ := utf8.DecodeRuneInString(tex)
+	last, _ := utf8.DecodeLastRuneInString(tex)
+	if unicode.IsSpace(first) || unicode.IsSpace(last) {
+		return false
+	}
+	return strings.ContainsAny(tex, `\^_{}=`) || !unicode.IsDigit(first)
+}
+
+// toText converts TeX to text
+func toText(tex []rune) string {
+	var s strings.Builder
+	for i := 0; i < len(tex); {
+		switch c := tex[i]; c {
+		case '\\':
+			name, next := command(tex, i)
+			i = expand(&s, name, tex, next)
+		case '^', '_':
+			arg, next := argument(tex, i+1)
+			s.WriteString(script(toText(arg), c == '^'))
+			i = next
+		case '{', '}':
+			i++
+		case '~', '&':
+			s.WriteRune(' ')
+			i++
+		default:
+			s.WriteRune(c)
+			i++
+		}
+	}
+	return strings.Join(strings.Fields(s.String()), " ")
+}
+
+// command reads the name of the command starting with the backslash at i:
+// a run of letters, or a single other character
+func command(tex []rune, i int) (string, int) {
+	start := i + 1
+	if start >= len(tex) {
+		return "", start
+	}
+	end := start
+	for end < len(tex) && unicode.IsLetter(tex[end]) && tex[end] < unicode.MaxASCII {
+		end++
+	}
+	if end == start {
+		end++
+	}
+	return string(tex[start:end]), end
+}
+
+// argument reads the argument at i, skipping spaces: a group in braces,
+// without them, a command or a single character
+func argument(tex []rune, i int) ([]rune, int) {
+	for i < len(tex) && unicode.IsSpace(tex[i]) {
+		i++
+	}
+	if i >= len(tex) {
+		return nil, i
+	}
+	switch tex[i] {
+	case '{':
+		depth := 0
+		for j := i; j < len(tex); j++ {
+			switch tex[j] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					return tex[i+1 : j], j + 1
+				}
+			}
+		}
+		return tex[i+1:], len(tex)
+	case '\\':
+		_, next := command(tex, i)
+		return tex[i:next], next
+	}
+	return tex[i : i+1], i + 1
+}
+
+// expand writes the text of a command, reading its arguments from i, and
+// returns where the TeX continues
+func expand(s *strings.Builder, name string, tex []rune, i int) int {
+	switch name {
+	case "frac", "dfrac", "tfrac":
+		num, next := argument(tex, i)
+		den, next := argument(tex, next)
+		s.WriteString(operand(toText(num)) + "/" + operand(toText(den)))
+		return next
+	case "sqrt":
+		// The index of \sqrt[3]{x} becomes ∛ where there is one
+		root := "√"
+		if i < len(tex) && tex[i] == '[' {
+			if end := indexRune(tex, i, ']'); end >= 0 {
+				switch string(tex[i+1 : end]) {
+				case "3":
+					root = "∛"
+				case "4":
+					root = "∜"
+				}
+				i = end + 1
+			}
+		}
+		arg, next := argument(tex, i)
+		s.WriteString(root + operand(toText(arg)))
+		return next
+	case "mathbb", "mathbbm", "Bbb":
+		arg, next := argument(tex, i)
+		s.WriteString(mapRunes(toText(arg), doubleStruck))
+		return next
+	case "text", "textrm", "textbf", "textit", "textsf", "texttt", "emph", "mathrm", "mathbf",
+		"mathit", "mathsf", "mathtt", "mathcal", "mathscr", "mathfrak", "boldsymbol", "bm", "operatorname":
+		arg, next := argument(tex, i)
+		s.WriteString(toText(arg))
+		return next
+	case "left", "right", "big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr", "middle":
+		// \left. is an invisible delimiter
+		if i < len(tex) && tex[i] == '.' {
+			i++
+		}
+		return i
+	}
+	if mark, ok := accents[name]; ok {
+		arg, next := argument(tex, i)
+		s.WriteString(toText(arg) + mark)
+		return next
+	}
+	if symbol, ok := symbols[name]; ok {
+		s.WriteString(symbol)
+		return i
+	}
+	// Unknown commands, like \log or \sin, are named by themselves
+	s.WriteString(name)
+	return i
+}
+
+// operand wraps the numerator or denominator of a fraction in parentheses
+// when it is more than a single number or symbol
+func operand(text string) string {
+	if utf8.RuneCountInString(text) <= 1 || strings.IndexFunc(text, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+		return text
+	}
+	return "(" + text + ")"
+}
+
+// script writes text as a superscript or subscript, in Unicode when every
+// character has a form for it
+func script(text string, super bool) string {
+	forms, mark := subscripts, "_"
+	if super {
+		forms, mark = superscripts, "^"
+	}
+	if text == "" {
+		return ""
+	}
+	if converted, ok := mapAll(text, forms); ok {
+		return converted
+	}
+	if utf8.RuneCountInString(text) == 1 {
+		return mark + text
+	}
+	return mark + "(" + text + ")"
+}
+
+// mapAll maps every rune of text, failing when one has no mapping
+func mapAll(text string, forms map[rune]rune) (string, bool) {
+	var s strings.Builder
+	for _, r := range text {
+		form, ok := forms[r]
+		if !ok {
+			return "", false
+		}
+		s.WriteRune(form)
+	}
+	return s.String(), true
+}
+
+// mapRunes maps the runes of text that have a mapping
+func mapRunes(text string, forms map[rune]rune) string {
+	return strings.Map(func(r rune) rune {
+		if form, ok := forms[r]; ok {
+			return form
+		}
+		return r
+	}, text)
+}
+
+func indexRune(tex []rune, from int, r rune) int {
+	for i := from; i < len(tex); i++ {
+		if tex[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+	'+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', '−': '⁻', '*': '*', '∗': '*', '′': '′',
+	'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ', 'g': 'ᵍ', 'h': 'ʰ', 'i': 'ⁱ', 'j': 'ʲ',
+	'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ', 'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ', 'u': 'ᵘ',
+	'v': 'ᵛ', 'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ',
+	'A': 'ᴬ', 'B': 'ᴮ', 'D': 'ᴰ', 'E': 'ᴱ', 'G': 'ᴳ', 'H': 'ᴴ', 'I': 'ᴵ', 'J': 'ᴶ', 'K': 'ᴷ', 'L': 'ᴸ',
+	'M': 'ᴹ', 'N': 'ᴺ', 'O': 'ᴼ', 'P': 'ᴾ', 'R': 'ᴿ', 'T': 'ᵀ', 'U': 'ᵁ', 'V': 'ⱽ', 'W': 'ᵂ', '⊤': 'ᵀ',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+	'+': '₊', '-': '₋', '=': '₌', '(': '₍', ')': '₎', '−': '₋',
+	'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'l': 'ₗ', 'm': 'ₘ', 'n': 'ₙ', 'o': 'ₒ',
+	'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ', 't': 'ₜ', 'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ',
+}
+
+var doubleStruck = map[rune]rune{
+	'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ',
+	'E': '𝔼', 'F': '𝔽', 'K': '𝕂', '1': '𝟙',
+}
+
+// accents are written as combining marks after their argument
+var accents = map[string]string{
+	"hat": "̂", "widehat": "̂", "bar": "̄", "overline": "̅",
+	"tilde": "̃", "widetilde": "̃", "vec": "⃗", "dot": "̇", "ddot": "̈",
+}
+
+var symbols = map[string]string{
+	// Greek
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ",
+	"varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ",
+	"varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+	// Relations
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈",
+	"sim": "∼", "simeq": "≃", "cong": "≅", "equiv": "≡", "propto": "∝", "ll": "≪", "gg": "≫",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+	"supseteq": "⊇", "mid": "|", "parallel": "∥", "perp": "⊥", "models": "⊨", "vdash": "⊢",
+	// Operators
+	"times": "×", "cdot": "·", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗", "star": "⋆",
+	"circ": "∘", "bullet": "•", "oplus": "⊕", "otimes": "⊗", "cup": "∪", "cap": "∩",
+	"setminus": "∖", "wedge": "∧", "land": "∧", "vee": "∨", "lor": "∨", "neg": "¬", "lnot": "¬",
+	"sum": "∑", "prod": "∏", "coprod": "∐", "int": "∫", "iint": "∬", "oint": "∮",
+	"bigcup": "⋃", "bigcap": "⋂", "partial": "∂", "nabla": "∇",
+	// Arrows
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+	"Rightarrow": "⇒", "implies": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "iff": "⇔",
+	"mapsto": "↦", "uparrow": "↑", "downarrow": "↓", "longrightarrow": "⟶", "hookrightarrow": "↪",
+	// Other symbols
+	"infty": "∞", "forall": "∀", "exists": "∃", "nexists": "∄", "emptyset": "∅", "varnothing": "∅",
+	"ell": "ℓ", "hbar": "ℏ", "Re": "ℜ", "Im": "ℑ", "aleph": "ℵ", "prime": "′", "angle": "∠",
+	"top": "⊤", "bot": "⊥", "dagger": "†", "checkmark": "✓", "square": "□", "triangle": "△",
+	"ldots": "…", "cdots": "⋯", "dots": "…", "vdots": "⋮", "ddots": "⋱",
+	"langle": "⟨", "rangle": "⟩", "lceil": "⌈", "rceil": "⌉", "lfloor": "⌊", "rfloor": "⌋",
+	"lvert": "|", "rvert": "|", "vert": "|", "Vert": "‖", "lVert": "‖", "rVert": "‖", "|": "‖",
+	// Spacing and escaped characters
+	"quad": " ", "qquad": " ", ",": " ", ";": " ", ":": " ", " ": " ", "!": "", "\\": " ",
+	"{": "{", "}": "}", "%": "%", "&": "&", "_": "_", "#": "#", "$": "$",
+	"limits": "", "nolimits": "", "displaystyle": "", "textstyle": "",
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+type archivedMsg struct {
+	article models.Article
+}
+
+// archiveNames are how archives are named in messages
+var archiveNames = map[string]string{
+	feed.ArchiveWayback: "the Wayback Machine",
+	feed.ArchiveToday:   "archive.today",
+}
+
+// fetchArchived loads an article from the archive, keeping the archived
+// content unless the database is read-only
+func fetchArchived(fetcher *feed.Fetcher, db database.Store, article models.Article, archive string, save bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := fetcher.FetchArchived(&article, archive); err != nil {
+			if errors.Is(err, feed.ErrNoSnapshot) {
+				err = fmt.Errorf("%s has no copy of this article", archiveNames[archive])
+			}
+			return errorMsg{err}
+		}
+		if save {
+			if err := db.UpdateArticleContent(&article); err != nil {
+				return errorMsg{err}
+			}
+		}
+		return archivedMsg{article}
+	}
+}
+
+// loadArchived loads the open article from the configured archive
+func (m Model) loadArchived(article models.Article) (tea.Model, tea.Cmd) {
+	if m.cfg.Fetch.Archive == "none" {
+		m.statusMsg = "No archive configured (set fetch.archive)"
+		return m, nil
+	}
+	m.statusMsg = fmt.Sprintf("Loading from %s...", archiveNames[m.cfg.Fetch.Archive])
+	return m, fetchArchived(m.fetcher, m.db, article, m.cfg.Fetch.Archive, m.readOnly == "")
+}
+
+func (m Model) handleArchived(msg archivedMsg) (tea.Model, tea.Cmd) {
+	m = m.setArticleContent(msg.article)
+	m.viewport.GotoTop()
+	m.statusMsg = fmt.Sprintf("Loaded from %s", archiveNames[m.cfg.Fetch.Archive])
+	return m, nil
+}
+
+// paywallNotice offers the archive for articles that look like a paywall
+// or cookie notice
+func (m Model) paywallNotice(article models.Article) string {
+	if m.cfg.Fetch.Archive == "none" || !feed.IsPaywalled(article.Content) {
+		return ""
+	}
+	return warningStyle.Render(fmt.Sprintf("This looks like a paywall or cookie notice: press w to load the article from %s",
+		archiveNames[m.cfg.Fetch.Archive]))
+}
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// pickBriefingSources picks the most relevant articles of the list for the
+// briefing, ui.briefing_articles of them
+func (m Model) pickBriefingSources() []models.Article {
+	articles := slices.Clone(m.allArticles)
+	slices.SortStableFunc(articles, func(a, b models.Article) int {
+		return cmp.Compare(b.RelevanceScore, a.RelevanceScore)
+	})
+	if len(articles) > m.cfg.UI.BriefingArticles {
+		articles = articles[:m.cfg.UI.BriefingArticles]
+	}
+	return articles
+}
+
+// openBriefing shows the briefing, writing it the first time
+func (m Model) openBriefing() (tea.Model, tea.Cmd) {
+	m.briefingViewport = viewport.New(m.width, max(m.height-4, 5))
+	m.view = ViewBriefing
+	if m.briefingText != "" || m.briefingBusy {
+		m.briefingViewport.SetContent(m.renderBriefingText())
+		return m, nil
+	}
+	return m.writeBriefing()
+}
+
+// writeBriefing asks Ollama to narrate the most relevant articles, the
+// briefing streaming into the view
+func (m Model) writeBriefing() (tea.Model, tea.Cmd) {
+	if m.checkedConnection && !m.ollamaOnline {
+		m.view = ViewArticleList
+		return m.showToast(severityWarning, "The briefing needs Ollama, which is unreachable")
+	}
+	sources := m.pickBriefingSources()
+	if len(sources) == 0 {
+		m.view = ViewArticleList
+		return m.showToast(severityInfo, "No unread articles to brief you on")
+	}
+
+	m.briefingSources = sources
+	m.briefingText = ""
+	m.briefingBusy = true
+	aiClient := m.aiClient
+	m.briefingStream = startStream(m.crash, streamID{kind: "briefing"}, func(onToken func(string)) (string, error) {
+		return aiClient.Briefing(sources, onToken)
+	})
+	m.briefingViewport.SetContent(m.renderBriefingText())
+	m.briefingViewport.GotoTop()
+	return m, waitForStream(m.briefingStream)
+}
+
+// handleBriefingStream applies a streamed token or the end of the briefing
+func (m Model) handleBriefingStream(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var toastCmd tea.Cmd
+	switch msg := msg.(type) {
+	case streamTokenMsg:
+		m.briefingText += msg.token
+	case streamDoneMsg:
+		m.briefingBusy = false
+		if msg.err != nil {
+			m.briefingText = ""
+			m, toastCmd = m.showToast(severityError, msg.err.Error())
+		} else {
+			m.briefingText = msg.text
+		}
+	}
+	m.briefingViewport.SetContent(m.renderBriefingText())
+	return m, tea.Batch(waitForStream(m.briefingStream), toastCmd)
+}
+
+func (m Model) handleBriefingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Briefing.Back):
+		m.view = ViewArticleList
+		return m, nil
+	case key.Matches(msg, keys.Briefing.Scroll):
+		var cmd tea.Cmd
+		m.briefingViewport, cmd = m.briefingViewport.Update(msg)
+		return m, cmd
+	case key.Matches(msg, keys.Briefing.Rewrite):
+		if m.briefingBusy {
+			return m, nil
+		}
+		return m.writeBriefing()
+	case key.Matches(msg, keys.Briefing.Open):
+		n, _ := strconv.Atoi(msg.String())
+		if n < 1 || n > len(m.briefingSources) {
+			return m, nil
+		}
+		article, err := withContent(m.db, m.briefingSources[n-1])
+		if err != nil {
+			return m, func() tea.Msg { return errorMsg{err} }
+		}
+		return m.openArticle(article), m.explainMetadata(article)
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+	}
+	return m, nil
+}
+
+// renderBriefingText renders the briefing as markdown followed by the
+// numbered articles it cites. While it streams in it is only wrapped.
+func (m Model) renderBriefingText() string {
+	if m.briefingBusy {
+		text := m.briefingText
+		if text == "" {
+			text = "Writing your briefing…"
+		}
+		return lipgloss.NewStyle().Width(max(rendererWidth(m.width)-4, 20)).Padding(0, 2).Render(text)
+	}
+	if m.briefingText == "" {
+		return fmt.Sprintf("No briefing yet (%s: write it).\n", keys.Briefing.Rewrite.Help().Key)
+	}
+
+	var sources strings.Builder
+	for i, a := range m.briefingSources {
+		fmt.Fprintf(&sources, "%d. [%s](%s) — %s\n", i+1, a.Title, a.URL, a.FeedName)
+	}
+	markdown := m.briefingText + "\n\n## Sources\n\n" + sources.String()
+	renderer, err := m.renderers.get(m.renderers.key(m.width))
+	if err != nil {
+		return markdown
+	}
+	rendered, err := renderer.Render(markdown)
+	if err != nil {
+		return markdown
+	}
+	return rendered
+}
+
+func (m Model) renderBriefing() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Briefing"))
+	s.WriteString("\n")
+	s.WriteString(m.briefingViewport.View())
+	s.WriteString("\n")
+
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render(fmt.Sprintf("%s: read a cited article • %s: write again • %s: scroll • %s: back",
+		keys.Briefing.Open.Help().Key, keys.Briefing.Rewrite.Help().Key,
+		keys.Briefing.Scroll.Help().Key, keys.Briefing.Back.Help().Key)))
+
+	return s.String()
+}
@@ -1,25 +1,121 @@
 package tui
 
 import (
+	"bytes"
 	"fmt"
+	"net/url"
 	"os/exec"
-	"runtime"
+	"strings"
+	"text/template"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
 )
 
-// openBrowser opens a URL in the default browser
+// openBrowser opens a URL in the default browser, using the platform
+// command from browserCommand (browser_darwin.go, browser_windows.go or
+// browser_unix.go)
 func openBrowser(url string) error {
-	var cmd *exec.Cmd
+	return startBrowser(browserCommand(url))
+}
 
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		cmd = exec.Command("xdg-open", url)
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+// startBrowser starts a browser without waiting for it
+func startBrowser(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("opening browser: %w", err)
 	}
+	// Reap the launcher once it exits so it doesn't linger as a zombie
+	go cmd.Wait()
+	return nil
+}
 
-	return cmd.Start()
+// openInBrowser opens an article at its browserURL in open.browser, or
+// the default browser. A terminal browser takes over the screen until it
+// exits, through the returned command.
+func (m Model) openInBrowser(article models.Article) (tea.Cmd, error) {
+	articleURL, err := browserURL(m.cfg.Open, article)
+	if err != nil {
+		return nil, err
+	}
+	if m.cfg.Open.Browser == "" {
+		return nil, openBrowser(articleURL)
+	}
+	args, err := m.cfg.Open.BrowserArgs(articleURL)
+	if err != nil {
+		return nil, fmt.Errorf("open.browser: %w", err)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if !m.cfg.Open.TerminalBrowser() {
+		return nil, startBrowser(cmd)
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return errorMsg{fmt.Errorf("running %s: %w", args[0], err)}
+		}
+		return nil
+	}), nil
+}
+
+// browserURL is where an article opens: its URL without the query
+// parameters in open.strip_params, rewritten by the template for its domain
+// in open.rewrites
+func browserURL(cfg config.OpenConfig, article models.Article) (string, error) {
+	article.URL = stripParams(article.URL, cfg.StripParams)
+	domain := feed.ArticleDomain(article.URL)
+	text, ok := cfg.Rewrite(domain)
+	if !ok {
+		return article.URL, nil
+	}
+	tmpl, err := template.New(domain).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing open.rewrites template for %s: %w", domain, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, &article); err != nil {
+		return "", fmt.Errorf("rendering open.rewrites template for %s: %w", domain, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// stripParams removes the named query parameters from a URL. A name ending
+// in * removes every parameter it starts.
+func stripParams(articleURL string, names []string) string {
+	if len(names) == 0 {
+		return articleURL
+	}
+	u, err := url.Parse(articleURL)
+	if err != nil || u.RawQuery == "" {
+		return articleURL
+	}
+	query := u.Query()
+	stripped := false
+	for param := range query {
+		for _, name := range names {
+			prefix, wildcard := strings.CutSuffix(name, "*")
+			if param == name || (wildcard && strings.HasPrefix(param, prefix)) {
+				query.Del(param)
+				stripped = true
+			}
+		}
+	}
+	// Encoding sorts the parameters, so only do it when needed
+	if !stripped {
+		return articleURL
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// recordOpen records that an article was opened in the browser, for the
+// stats and drift report
+func recordOpen(db database.Store, article models.Article) tea.Cmd {
+	return func() tea.Msg {
+		if err := db.RecordArticleOpen(article.ID); err != nil {
+			return errorMsg{err}
+		}
+		return nil
+	}
 }
@@ -0,0 +1,7 @@
+package tui
+
+import "os/exec"
+
+func browserCommand(url string) *exec.Cmd {
+	return exec.Command("open", url)
+}
@@ -0,0 +1,9 @@
+//go:build !darwin && !windows
+
+package tui
+
+import "os/exec"
+
+func browserCommand(url string) *exec.Cmd {
+	return exec.Command("xdg-open", url)
+}
@@ -0,0 +1,23 @@
+//go:build !darwin && !windows
+
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBrowserCommand(t *testing.T) {
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://example.com/a", []string{"xdg-open", "https://example.com/a"}},
+		{"https://example.com/a?b=1&c=2", []string{"xdg-open", "https://example.com/a?b=1&c=2"}},
+	}
+	for _, tt := range tests {
+		if got := browserCommand(tt.url).Args; !slices.Equal(got, tt.want) {
+			t.Errorf("browserCommand(%q) runs %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
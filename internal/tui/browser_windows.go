@@ -0,0 +1,9 @@
+package tui
+
+import "os/exec"
+
+// browserCommand uses rundll32 rather than "cmd /c start", which treats
+// & and ^ in query strings as shell syntax and a quoted URL as a title
+func browserCommand(url string) *exec.Cmd {
+	return exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+}
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// scoreCalibration spreads relevance scores over [0, 1] relative to the
+// unread articles, since raw similarities bunch up in a narrow range, and
+// shows them as bands of stars
+type scoreCalibration struct {
+	raw    bool // show the raw scores instead
+	zscore bool // calibrate by z-score rather than min-max
+	bands  []float64
+
+	// spread is false with fewer than two distinct scores, when the raw
+	// score is used as is
+	spread     bool
+	min, max   float64
+	mean, sdev float64
+}
+
+// newScoreCalibration calibrates against the scored articles given
+func newScoreCalibration(cfg config.ScoringConfig, articles []models.Article) scoreCalibration {
+	c := scoreCalibration{
+		raw:    cfg.Display == "raw",
+		zscore: cfg.Calibration == "zscore",
+		bands:  cfg.Bands,
+		min:    math.Inf(1),
+		max:    math.Inf(-1),
+	}
+	var n int
+	var sum, sumSq float64
+	for _, a := range articles {
+		if !a.Scored {
+			continue
+		}
+		n++
+		sum += a.RelevanceScore
+		sumSq += a.RelevanceScore * a.RelevanceScore
+		c.min = min(c.min, a.RelevanceScore)
+		c.max = max(c.max, a.RelevanceScore)
+	}
+	if n < 2 || c.max == c.min {
+		return c
+	}
+	c.spread = true
+	c.mean = sum / float64(n)
+	c.sdev = math.Sqrt(max(sumSq/float64(n)-c.mean*c.mean, 0))
+	if c.sdev == 0 {
+		c.spread = false
+	}
+	return c
+}
+
+// level is where a score falls among the calibrated ones, from 0 to 1. By
+// z-score, the mean maps to 0.5 and a standard deviation above it to 0.84.
+func (c scoreCalibration) level(score float64) float64 {
+	var level float64
+	switch {
+	case !c.spread:
+		level = score
+	case c.zscore:
+		level = 0.5 * (1 + math.Erf((score-c.mean)/c.sdev/math.Sqrt2))
+	default:
+		level = (score - c.min) / (c.max - c.min)
+	}
+	return min(max(level, 0), 1)
+}
+
+// stars is the band of a score: one star, and another for each band its
+// level reaches
+func (c scoreCalibration) stars(score float64) int {
+	level := c.level(score)
+	n := 1
+	for _, b := range c.bands {
+		if level >= b {
+			n++
+		}
+	}
+	return n
+}
+
+// maxStars is how many stars the top band shows
+func (c scoreCalibration) maxStars() int {
+	return len(c.bands) + 1
+}
+
+// width is the width of the score column
+func (c scoreCalibration) width() int {
+	if c.raw {
+		return 4
+	}
+	return c.maxStars()
+}
+
+// format shows an article's score as stars, or the raw score with
+// scoring.display: raw, and pendingScore while it is awaited
+func (c scoreCalibration) format(article models.Article) string {
+	switch {
+	case !article.Scored:
+		return pendingScore
+	case c.raw:
+		return fmt.Sprintf("%.2f", article.RelevanceScore)
+	}
+	n := c.stars(article.RelevanceScore)
+	return strings.Repeat("★", n) + strings.Repeat("☆", c.maxStars()-n)
+}
+
+// detail shows an article's band followed by its raw score, for the
+// article view
+func (c scoreCalibration) detail(article models.Article) string {
+	if c.raw || !article.Scored {
+		return c.format(article)
+	}
+	return fmt.Sprintf("%s (%.2f)", c.format(article), article.RelevanceScore)
+}
+
+// describe spells out an article's band for plain mode, with the raw
+// score
+func (c scoreCalibration) describe(article models.Article) string {
+	if c.raw || !article.Scored {
+		return fmt.Sprintf("score %.2f", article.RelevanceScore)
+	}
+	return fmt.Sprintf("%d of %d stars (score %.2f)", c.stars(article.RelevanceScore), c.maxStars(), article.RelevanceScore)
+}
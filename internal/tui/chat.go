@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// chatContentLimit caps how many characters of article text are sent with
+// each question
+const chatContentLimit = 12000
+
+var (
+	chatUserStyle      = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	chatAssistantStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("42"))
+)
+
+// chatSystemPrompt grounds the conversation in the article's text
+func (m Model) chatSystemPrompt(article models.Article) string {
+	content := article.Content
+	if content == "" {
+		content = article.Description
+	}
+	if markdown, err := m.mdConverter.ConvertString(content); err == nil {
+		content = markdown
+	}
+	if runes := []rune(content); len(runes) > chatContentLimit {
+		content = string(runes[:chatContentLimit]) + "\n[article truncated]"
+	}
+
+	return fmt.Sprintf(`You answer questions about the article below. Base your answers on the article; say so when it doesn't cover something.
+
+Title: %s
+Feed: %s
+URL: %s
+
+%s`, article.Title, article.FeedName, article.URL, content)
+}
+
+// openChat shows the chat pane for an article, keeping the conversation
+// when it is the same article as last time
+func (m Model) openChat(article models.Article) (tea.Model, tea.Cmd) {
+	if m.checkedConnection && !m.ollamaOnline {
+		return m.showToast(severityWarning, "Asking about articles needs Ollama, which is unreachable")
+	}
+
+	if m.chatArticleID != article.ID {
+		m.chatArticleID = article.ID
+		m.chatMessages = []ai.ChatMessage{{Role: "system", Content: m.chatSystemPrompt(article)}}
+		m.chatReply = ""
+	}
+	m.chatViewport = viewport.New(m.width, max(m.height-6, 5))
+	m.chatViewport.SetContent(m.renderChatLog())
+	m.chatViewport.GotoBottom()
+
+	m.view = ViewChat
+	m.chatInput.SetValue("")
+	m.chatInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m Model) handleChatKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Chat.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Chat.Back):
+		m.chatInput.Blur()
+		m.view = ViewArticleDetail
+		return m, nil
+	case key.Matches(msg, keys.Chat.Scroll):
+		var cmd tea.Cmd
+		m.chatViewport, cmd = m.chatViewport.Update(msg)
+		return m, cmd
+	case key.Matches(msg, keys.Chat.Send):
+		question := strings.TrimSpace(m.chatInput.Value())
+		if question == "" || m.chatBusy {
+			return m, nil
+		}
+		m.chatInput.SetValue("")
+		m.chatMessages = append(m.chatMessages, ai.ChatMessage{Role: "user", Content: question})
+		m.chatBusy = true
+		m.chatReply = ""
+		messages := m.chatMessages
+		aiClient := m.aiClient
+		m.chatStream = startStream(m.crash, streamID{kind: "chat", articleID: m.chatArticleID}, func(onToken func(string)) (string, error) {
+			return aiClient.Chat(messages, onToken)
+		})
+		m.chatViewport.SetContent(m.renderChatLog())
+		m.chatViewport.GotoBottom()
+		return m, waitForStream(m.chatStream)
+	}
+
+	var cmd tea.Cmd
+	m.chatInput, cmd = m.chatInput.Update(msg)
+	return m, cmd
+}
+
+// handleChatStream applies a streamed token or the end of an answer
+func (m Model) handleChatStream(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var toastCmd tea.Cmd
+	switch msg := msg.(type) {
+	case streamTokenMsg:
+		if msg.id.articleID == m.chatArticleID {
+			m.chatReply += msg.token
+		}
+	case streamDoneMsg:
+		// Only one answer streams at a time, even if it was for another article
+		m.chatBusy = false
+		m.chatReply = ""
+		if msg.id.articleID == m.chatArticleID {
+			if msg.err != nil {
+				m, toastCmd = m.showToast(severityError, msg.err.Error())
+				// Drop the unanswered question so it can be asked again
+				m.chatMessages = m.chatMessages[:len(m.chatMessages)-1]
+			} else {
+				m.chatMessages = append(m.chatMessages, ai.ChatMessage{Role: "assistant", Content: msg.text})
+			}
+		}
+	}
+
+	atBottom := m.chatViewport.AtBottom()
+	m.chatViewport.SetContent(m.renderChatLog())
+	if atBottom {
+		m.chatViewport.GotoBottom()
+	}
+	return m, tea.Batch(waitForStream(m.chatStream), toastCmd)
+}
+
+// renderChatLog renders the conversation so far, including a partial reply
+func (m Model) renderChatLog() string {
+	width := max(m.width-2, 20)
+	wrap := lipgloss.NewStyle().Width(width)
+
+	var s strings.Builder
+	for _, msg := range m.chatMessages {
+		switch msg.Role {
+		case "user":
+			s.WriteString(chatUserStyle.Render("You"))
+		case "assistant":
+			s.WriteString(chatAssistantStyle.Render("Answer"))
+		default:
+			continue
+		}
+		s.WriteString("\n")
+		s.WriteString(wrap.Render(msg.Content))
+		s.WriteString("\n\n")
+	}
+	if m.chatBusy {
+		s.WriteString(chatAssistantStyle.Render("Answer"))
+		s.WriteString("\n")
+		reply := m.chatReply
+		if reply == "" {
+			reply = "…"
+		}
+		s.WriteString(wrap.Render(reply))
+		s.WriteString("\n")
+	}
+	if len(m.chatMessages) <= 1 && !m.chatBusy {
+		s.WriteString(helpStyle.Render("Ask anything about this article."))
+	}
+	return s.String()
+}
+
+func (m Model) renderChat() string {
+	var s strings.Builder
+
+	title := "Ask"
+	if i, ok := m.list.SelectedItem().(articleItem); ok && i.article.ID == m.chatArticleID {
+		title = "Ask: " + i.article.Title
+	}
+	s.WriteString(titleStyle.Render(truncate(title, max(m.width-4, 20))))
+	s.WriteString("\n")
+	s.WriteString(m.chatViewport.View())
+	s.WriteString("\n")
+	s.WriteString(filterStyle.Render("> ") + m.chatInput.View())
+	s.WriteString("\n")
+
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("enter: ask • ↑/↓,pgup/pgdn: scroll • esc: back to article"))
+
+	return s.String()
+}
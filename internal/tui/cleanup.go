@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+)
+
+type cleanupPreviewMsg struct {
+	days   int
+	counts []database.CleanupCount
+}
+
+type cleanupDoneMsg struct {
+	deleted int
+}
+
+// cleanupTotals sums what a cleanup would delete
+func cleanupTotals(counts []database.CleanupCount) (old, read int) {
+	for _, c := range counts {
+		old += c.Old
+		read += c.Read
+	}
+	return old, read
+}
+
+func maxAgeDays(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func previewCleanup(db database.Store, days int) tea.Cmd {
+	return func() tea.Msg {
+		counts, err := db.PreviewCleanup(maxAgeDays(days))
+		if err != nil {
+			return errorMsg{err}
+		}
+		return cleanupPreviewMsg{days: days, counts: counts}
+	}
+}
+
+// runCleanup deletes articles older than days and read articles, then
+// prunes the embedding cache and vacuums if needed
+func runCleanup(db database.Store, cfg *config.Config, days int) tea.Cmd {
+	return func() tea.Msg {
+		maxAge := maxAgeDays(days)
+		counts, err := db.PreviewCleanup(maxAge)
+		if err != nil {
+			return errorMsg{err}
+		}
+		old, read := cleanupTotals(counts)
+
+		if err := db.DeleteOldArticles(maxAge); err != nil {
+			return errorMsg{err}
+		}
+		if err := db.DeleteReadArticles(); err != nil {
+			return errorMsg{err}
+		}
+		if _, err := db.PruneEmbeddings(cfg.Database.EmbeddingCacheMaxAge()); err != nil {
+			return errorMsg{err}
+		}
+		if _, err := db.VacuumIfNeeded(cfg.Database.AutoVacuumThreshold()); err != nil {
+			return errorMsg{err}
+		}
+		return cleanupDoneMsg{deleted: old + read}
+	}
+}
+
+// openCleanup previews a cleanup with the configured max age
+func (m Model) openCleanup() (tea.Model, tea.Cmd) {
+	if m.readOnly != "" {
+		return m.showReadOnly()
+	}
+	m.view = ViewCleanup
+	m.cleanup = nil
+	m.cleanupDays = m.cfg.UI.ArticleMaxAgeDays
+	m.cleanupConfirm = false
+	m.statusMsg = ""
+	return m, previewCleanup(m.db, m.cleanupDays)
+}
+
+func (m Model) handleCleanupKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.cleanupConfirm {
+		m.cleanupConfirm = false
+		if key.Matches(msg, keys.Cleanup.Confirm) {
+			// The new max age also applies to the list for the rest of the
+			// session
+			m.cfg.UI.ArticleMaxAgeDays = m.cleanupDays
+			m.statusMsg = "Cleaning up..."
+			return m, runCleanup(m.db, m.cfg, m.cleanupDays)
+		}
+		m.statusMsg = "Cleanup cancelled"
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Cleanup.Back):
+		m.view = ViewArticleList
+		return m, nil
+	case key.Matches(msg, keys.Cleanup.Older):
+		m.cleanupDays++
+		return m, previewCleanup(m.db, m.cleanupDays)
+	case key.Matches(msg, keys.Cleanup.Newer):
+		if m.cleanupDays > 1 {
+			m.cleanupDays--
+		}
+		return m, previewCleanup(m.db, m.cleanupDays)
+	case key.Matches(msg, keys.Cleanup.Run):
+		old, read := cleanupTotals(m.cleanup)
+		if old+read == 0 {
+			m.statusMsg = "Nothing to clean up"
+			return m, nil
+		}
+		m.cleanupConfirm = true
+		m.statusMsg = ""
+		return m, nil
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m Model) handleCleanupPreview(msg cleanupPreviewMsg) (tea.Model, tea.Cmd) {
+	// Ignore previews for a max age that has since been adjusted
+	if msg.days == m.cleanupDays {
+		m.cleanup = msg.counts
+	}
+	return m, nil
+}
+
+func (m Model) handleCleanupDone(msg cleanupDoneMsg) (tea.Model, tea.Cmd) {
+	m.view = ViewArticleList
+	m, cmd := m.showToast(severityInfo, fmt.Sprintf("Deleted %d articles", msg.deleted))
+	return m, tea.Batch(cmd, m.reloadArticles())
+}
+
+func (m Model) renderCleanup() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Cleanup"))
+	s.WriteString("\n")
+
+	maxAge := fmt.Sprintf("Max age: %d days", m.cleanupDays)
+	if m.cleanupDays != m.cfg.UI.ArticleMaxAgeDays {
+		maxAge += fmt.Sprintf(" (configured: %d)", m.cfg.UI.ArticleMaxAgeDays)
+	}
+	s.WriteString(maxAge)
+	s.WriteString("\n\n")
+
+	if m.cleanup == nil {
+		s.WriteString("Loading...\n")
+	} else if len(m.cleanup) == 0 {
+		s.WriteString("Nothing to clean up: no read articles and none older than the max age.\n")
+	} else {
+		nameWidth := len("Feed")
+		for _, c := range m.cleanup {
+			nameWidth = max(nameWidth, lipgloss.Width(c.FeedName))
+		}
+		nameWidth = min(nameWidth, 40)
+
+		row := func(name, old, read, total string) string {
+			name = truncate(name, nameWidth)
+			pad := strings.Repeat(" ", nameWidth-lipgloss.Width(name))
+			return fmt.Sprintf("%s%s  %5s  %5s  %5s\n", name, pad, old, read, total)
+		}
+
+		s.WriteString(helpStyle.Render(strings.TrimRight(row("Feed", "Old", "Read", "Total"), "\n")))
+		s.WriteString("\n")
+		for _, c := range m.cleanup {
+			s.WriteString(row(c.FeedName, fmt.Sprint(c.Old), fmt.Sprint(c.Read), fmt.Sprint(c.Old+c.Read)))
+		}
+		old, read := cleanupTotals(m.cleanup)
+		s.WriteString(articleTitleStyle.Render(strings.TrimRight(row("All feeds", fmt.Sprint(old), fmt.Sprint(read), fmt.Sprint(old+read)), "\n")))
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render("Queued articles are kept however old they are. Deleted articles stay in the trash (T) for 7 days."))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	if m.cleanupConfirm {
+		old, read := cleanupTotals(m.cleanup)
+		s.WriteString(warningStyle.Render(fmt.Sprintf("Delete %d articles? y: delete • any other key: cancel", old+read)))
+		s.WriteString("\n")
+	} else if m.statusMsg != "" {
+		s.WriteString(statusStyle.Render(m.statusMsg))
+		s.WriteString("\n")
+	}
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("+/-: change max age • enter: clean up • esc: back • q: quit"))
+
+	return s.String()
+}
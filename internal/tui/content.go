@@ -0,0 +1,294 @@
+package tui
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+
+	html2md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/thomaskoefod/newsreadr/internal/texmath"
+)
+
+// articleWrap is the widest articles are wrapped at
+const articleWrap = 100
+
+// newRenderer creates the markdown renderer for articles in the dark or
+// light style, whichever suits the terminal, wrapping at width. Code blocks
+// are colored by the style itself for the "auto" codeTheme, not at all for
+// "none", or with the chroma style of that name.
+func newRenderer(codeTheme string, width int) (*glamour.TermRenderer, error) {
+	style := styles.LightStyleConfig
+	if lipgloss.HasDarkBackground() {
+		style = styles.DarkStyleConfig
+	}
+	switch codeTheme {
+	case "auto":
+	case "none":
+		style.CodeBlock.Chroma = nil
+		style.CodeBlock.Theme = ""
+	default:
+		style.CodeBlock.Chroma = nil
+		style.CodeBlock.Theme = codeTheme
+	}
+	return glamour.NewTermRenderer(
+		glamour.WithStyles(style),
+		glamour.WithWordWrap(width),
+	)
+}
+
+// articleTheme names the look of rendered articles: the code theme and
+// whether the dark or light style suits the terminal
+func articleTheme(codeTheme string) string {
+	if lipgloss.HasDarkBackground() {
+		return codeTheme + "/dark"
+	}
+	return codeTheme + "/light"
+}
+
+// rendererKey identifies a markdown renderer by wrap width and theme
+type rendererKey struct {
+	width int
+	theme string
+}
+
+// sharedRenderer is a glamour renderer that background renders can use
+// too, which it isn't safe for on its own
+type sharedRenderer struct {
+	mu       sync.Mutex
+	renderer *glamour.TermRenderer
+}
+
+func (r *sharedRenderer) Render(markdown string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.renderer.Render(markdown)
+}
+
+// rendererPool keeps a renderer for each wrap width and theme used, so
+// resizing back and forth or rendering in the background doesn't create
+// them over and over. There are few, as widths are capped at articleWrap.
+type rendererPool struct {
+	codeTheme string
+	mu        sync.Mutex
+	renderers map[rendererKey]*sharedRenderer
+}
+
+func newRendererPool(codeTheme string) *rendererPool {
+	return &rendererPool{codeTheme: codeTheme, renderers: map[rendererKey]*sharedRenderer{}}
+}
+
+// key is the key of the renderer for articles in a viewport width wide
+func (p *rendererPool) key(width int) rendererKey {
+	return rendererKey{rendererWidth(width), articleTheme(p.codeTheme)}
+}
+
+// get returns the renderer for a key, creating it the first time
+func (p *rendererPool) get(key rendererKey) (*sharedRenderer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r, ok := p.renderers[key]; ok {
+		return r, nil
+	}
+	renderer, err := newRenderer(p.codeTheme, key.width)
+	if err != nil {
+		return nil, err
+	}
+	r := &sharedRenderer{renderer: renderer}
+	p.renderers[key] = r
+	return r, nil
+}
+
+// rendererWidth is the wrap width for articles in a terminal width wide.
+// Tables are fitted to it as well.
+func rendererWidth(width int) int {
+	return max(min(width, articleWrap), 20)
+}
+
+// newConverter creates the HTML to markdown converter for articles. Tables
+// become GFM tables, with each cell kept to one line, and struck out text
+// is kept struck out.
+func newConverter() *html2md.Converter {
+	conv := html2md.NewConverter("", true, nil).Before(labelCodeBlocks, labelFootnotes)
+	conv.Use(plugin.Table(), plugin.Strikethrough("~~"))
+	conv.AddRules(html2md.Rule{
+		Filter:      []string{"th", "td"},
+		Replacement: tableCell,
+	})
+	return conv
+}
+
+// tableCell writes a table cell with its lines joined, as a GFM table row
+// can't span lines and line breaks in it wouldn't render
+func tableCell(content string, cell *goquery.Selection, _ *html2md.Options) *string {
+	content = strings.Join(strings.Fields(content), " ")
+	prefix := " "
+	if cell.Prev().Length() == 0 {
+		prefix = "| "
+	}
+	return html2md.String(prefix + content + " |")
+}
+
+// codeClassPrefixes introduce the language in class names highlighters and
+// publishing tools put on code blocks
+var codeClassPrefixes = []string{"language-", "lang-", "highlight-source-"}
+
+// labelCodeBlocks leaves each pre element with a code element whose class
+// is language-NAME, or no class when the language is unknown, so the fence
+// the converter writes names a language the highlighter knows
+func labelCodeBlocks(doc *goquery.Selection) {
+	doc.Find("pre").Each(func(_ int, pre *goquery.Selection) {
+		lang := codeLanguage(pre)
+		code := pre.Find("code").First()
+		if code.Length() == 0 {
+			pre.WrapInnerHtml("<code></code>")
+			code = pre.Find("code").First()
+		}
+		if lang == "" {
+			code.RemoveAttr("class")
+		} else {
+			code.SetAttr("class", "language-"+lang)
+		}
+	})
+}
+
+// labelFootnotes turns links within the article, which lead nowhere in the
+// terminal, into text. Footnote references become [1] markers, so they
+// aren't run into the word before them, and links back from the footnotes
+// are dropped. The footnotes themselves stay a list at the end.
+func labelFootnotes(doc *goquery.Selection) {
+	doc.Find(`a[href^="#"]`).Each(func(_ int, a *goquery.Selection) {
+		switch {
+		case isBacklink(a):
+			a.Remove()
+		case isNoteRef(a):
+			label := "[" + strings.Trim(strings.TrimSpace(a.Text()), "[]") + "]"
+			if parent := a.Parent(); parent.Is("sup") && parent.Children().Length() == 1 {
+				a = parent
+			}
+			a.ReplaceWithHtml(html.EscapeString(label))
+		default:
+			a.ReplaceWithSelection(a.Contents())
+		}
+	})
+}
+
+// isBacklink reports whether a links from a footnote back to its reference
+func isBacklink(a *goquery.Selection) bool {
+	class := a.AttrOr("class", "")
+	text := strings.TrimRight(strings.TrimSpace(a.Text()), "\ufe0e\ufe0f")
+	return a.AttrOr("role", "") == "doc-backlink" ||
+		strings.Contains(class, "backref") || strings.Contains(class, "reversefootnote") ||
+		text == "↩" || text == "↑"
+}
+
+// isNoteRef reports whether a links to a footnote
+func isNoteRef(a *goquery.Selection) bool {
+	return a.AttrOr("role", "") == "doc-noteref" || a.Parent().AttrOr("role", "") == "doc-noteref" ||
+		strings.Contains(a.AttrOr("class", ""), "footnote") || a.Parent().Is("sup")
+}
+
+// codeLanguage looks for the language of a code block on its code element,
+// the pre element and the pre element's parent
+func codeLanguage(pre *goquery.Selection) string {
+	for _, s := range []*goquery.Selection{pre.Find("code").First(), pre, pre.Parent()} {
+		for _, attr := range []string{"data-lang", "data-language", "lang"} {
+			if lang, ok := s.Attr(attr); ok && lexers.Get(lang) != nil {
+				return lang
+			}
+		}
+		for _, class := range strings.Fields(s.AttrOr("class", "")) {
+			for _, prefix := range codeClassPrefixes {
+				if lang, ok := strings.CutPrefix(class, prefix); ok && lexers.Get(lang) != nil {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+var (
+	// htmlTag matches markup left in converted markdown
+	htmlTag = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9]*(\s[^<>]*)?/?>`)
+	// markdownCode matches fenced code blocks and inline code, where tags
+	// are legitimate
+	markdownCode = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	// blockEnd matches tags ending a line of text
+	blockEnd = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|blockquote|pre|section|article)>`)
+	// blankLines matches runs of empty lines
+	blankLines = regexp.MustCompile(`\n\s*\n\s*`)
+)
+
+// stripPolicy removes every tag along with script and style contents
+var stripPolicy = bluemonday.StrictPolicy().AddSpaceWhenStrippingTag(true)
+
+// articleMarkdown converts article HTML to markdown for glamour, with TeX
+// math shown as text. Feeds that escape their HTML a second time are
+// unescaped and converted again. When conversion fails or still leaves tags
+// behind, the content is reduced to plain text so markup never reaches the
+// viewport.
+func (m Model) articleMarkdown(content string) string {
+	content = texmath.HTML(content)
+	md, err := m.mdConverter.ConvertString(content)
+	if err == nil && !leaksHTML(md) {
+		return md
+	}
+
+	if unescaped := html.UnescapeString(content); unescaped != content {
+		md, err := m.mdConverter.ConvertString(unescaped)
+		if err == nil && !leaksHTML(md) {
+			return md
+		}
+		content = unescaped
+	}
+	return plainText(content)
+}
+
+// leaksHTML reports whether markdown contains tags outside of code
+func leaksHTML(md string) bool {
+	return htmlTag.MatchString(markdownCode.ReplaceAllString(md, ""))
+}
+
+// plainText strips all markup from HTML and decodes its entities, keeping
+// paragraphs apart
+func plainText(content string) string {
+	content = blockEnd.ReplaceAllString(content, "$0\n\n")
+	text := html.UnescapeString(stripPolicy.Sanitize(content))
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	text = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// localImages points the images of HTML content at their downloaded copies,
+// given as a map of image URL to file
+func localImages(content string, images map[string]string) string {
+	if len(images) == 0 {
+		return content
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+	doc.Find("img[src]").Each(func(_ int, img *goquery.Selection) {
+		if path, ok := images[img.AttrOr("src", "")]; ok {
+			img.SetAttr("src", "file://"+path)
+		}
+	})
+	local, err := doc.Find("body").Html()
+	if err != nil {
+		return content
+	}
+	return local
+}
@@ -0,0 +1,194 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+)
+
+// crashHistory is how many of the latest messages a crash report lists
+const crashHistory = 30
+
+// crashRecorder keeps what a crash report needs: the latest messages the
+// model handled and the first panic. Copies of the model and the commands
+// running in the background share it, hence the lock.
+type crashRecorder struct {
+	mu     sync.Mutex
+	recent []string
+	at     time.Time
+	reason any
+	stack  []byte
+}
+
+// observe adds a message to the history
+func (c *crashRecorder) observe(msg tea.Msg) {
+	line := time.Now().Format("15:04:05.000") + " " + describeMsg(msg)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recent = append(c.recent, line)
+	if len(c.recent) > crashHistory {
+		c.recent = c.recent[1:]
+	}
+}
+
+// describeMsg names a message for the history. Only keys, status messages
+// and errors are spelled out; other messages may carry article content.
+func describeMsg(msg tea.Msg) string {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return "key " + msg.String()
+	case tea.WindowSizeMsg:
+		return fmt.Sprintf("resize %dx%d", msg.Width, msg.Height)
+	case statusMsg:
+		return "status: " + string(msg)
+	case errorMsg:
+		return "error: " + msg.err.Error()
+	}
+	return fmt.Sprintf("%T", msg)
+}
+
+// catch records a panic and panics again, so Bubble Tea still restores the
+// terminal. It has to be deferred.
+func (c *crashRecorder) catch() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	c.record(r)
+	panic(r)
+}
+
+// crashMsg hands a panic in a goroutine of the reader's own to Update, which
+// panics again so Bubble Tea restores the terminal as it does for commands
+type crashMsg struct {
+	reason any
+}
+
+// rescue records a panic in a goroutine of the reader's own, which Bubble
+// Tea doesn't recover, and passes it to deliver as a crashMsg instead of
+// panicking again. A nil deliver only records it. It has to be deferred.
+func (c *crashRecorder) rescue(deliver func(tea.Msg)) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	c.record(r)
+	if deliver != nil {
+		deliver(crashMsg{r})
+	}
+}
+
+// record keeps the first panic and where it happened
+func (c *crashRecorder) record(r any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reason == nil {
+		c.at, c.reason, c.stack = time.Now(), r, debug.Stack()
+	}
+}
+
+// Crashed reports whether a panic was recorded, including one after the
+// program quit, while waiting on the background jobs
+func (m Model) Crashed() bool {
+	m.crash.mu.Lock()
+	defer m.crash.mu.Unlock()
+	return m.crash.reason != nil
+}
+
+// guard catches panics in a command and, when it returns a batch, in the
+// commands of the batch
+func (c *crashRecorder) guard(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer c.catch()
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for i := range batch {
+				batch[i] = c.guard(batch[i])
+			}
+		}
+		return msg
+	}
+}
+
+// WriteCrashReport writes what led to a panic to a file in the data
+// directory: the panic and its stack, the latest messages and a summary of
+// the configuration without credentials. It returns the file's path.
+func (m Model) WriteCrashReport() (string, error) {
+	c := m.crash
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	at := c.at
+	if at.IsZero() {
+		at = time.Now()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "newsreadr crash report, %s\n", at.Format(time.RFC3339))
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+	}
+	fmt.Fprintf(&b, "Version %s, %s on %s/%s\n\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	if c.reason != nil {
+		fmt.Fprintf(&b, "panic: %v\n\n%s\n", c.reason, c.stack)
+	} else {
+		b.WriteString("The panic was not recorded; its stack was printed on exit.\n\n")
+	}
+
+	b.WriteString("Latest messages:\n")
+	for _, line := range c.recent {
+		b.WriteString("  " + line + "\n")
+	}
+	b.WriteString("\nConfiguration:\n")
+	for _, line := range configSummary(m.cfg) {
+		b.WriteString("  " + line + "\n")
+	}
+
+	dir := config.DataDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating data directory: %w", err)
+	}
+	path := filepath.Join(dir, "crash-"+at.Format("20060102-150405")+".txt")
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return "", fmt.Errorf("writing crash report: %w", err)
+	}
+	return path, nil
+}
+
+// configSummary describes the settings that shape the reader, leaving out
+// credentials, feed URLs and interests: integrations are only listed as
+// configured or not
+func configSummary(cfg *config.Config) []string {
+	configured := func(set bool) string {
+		if set {
+			return "configured"
+		}
+		return "not configured"
+	}
+	return []string{
+		fmt.Sprintf("database: %s, cache %s, encrypted %t", cfg.Database.Path, cfg.Database.CachePath, cfg.Database.EncryptionKey != ""),
+		fmt.Sprintf("feeds: %d in the config, managed via %s", len(cfg.Feeds), cfg.FeedsManageVia),
+		fmt.Sprintf("interests: %d, %d groups, %d to avoid, managed via %s", len(cfg.Interests), len(cfg.InterestGroups), len(cfg.AvoidInterests), cfg.InterestsManageVia),
+		fmt.Sprintf("ollama: %s, model %s, %d workers", cfg.Ollama.Host, cfg.Ollama.Model, cfg.Ollama.Workers),
+		fmt.Sprintf("scoring: backend %q", cfg.Scoring.Backend),
+		fmt.Sprintf("ui: list mode %s, group by %s, code theme %s, refresh %s, max age %d days", cfg.UI.ListMode, cfg.UI.GroupBy, cfg.UI.CodeTheme, cfg.UI.RefreshInterval, cfg.UI.ArticleMaxAgeDays),
+		"http proxy: " + configured(cfg.HTTP.Proxy != ""),
+		"nextcloud: " + configured(cfg.Nextcloud.URL != ""),
+		"newsletters: " + configured(cfg.Newsletters.Host != ""),
+		"raindrop: " + configured(cfg.Raindrop.APIToken != ""),
+		"webhook: " + configured(cfg.Webhook.URL != ""),
+		fmt.Sprintf("save targets: %d", len(cfg.Save.Targets)),
+	}
+}
@@ -0,0 +1,26 @@
+package tui
+
+import "testing"
+
+// A panic while streaming reaches Update as a crashMsg instead of killing
+// the program from a goroutine Bubble Tea doesn't watch
+func TestStreamPanic(t *testing.T) {
+	crash := &crashRecorder{}
+	ch := startStream(crash, streamID{kind: "chat"}, func(onToken func(string)) (string, error) {
+		onToken("partial")
+		panic("boom")
+	})
+
+	if msg, ok := (<-ch).(streamTokenMsg); !ok || msg.token != "partial" {
+		t.Fatalf("first message is %#v, want the token", msg)
+	}
+	if msg, ok := (<-ch).(crashMsg); !ok || msg.reason != "boom" {
+		t.Fatalf("second message is %#v, want a crashMsg", msg)
+	}
+	if _, open := <-ch; open {
+		t.Error("stream not closed after the panic")
+	}
+	if crash.reason != "boom" || len(crash.stack) == 0 {
+		t.Errorf("recorded %v, want the panic and its stack", crash.reason)
+	}
+}
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// driftSampleSize is how many recently read articles the drift report examines
+const driftSampleSize = 100
+
+type driftLoadedMsg struct {
+	report *ai.DriftReport
+}
+
+type interestAdoptedMsg struct {
+	label string
+}
+
+func analyzeDrift(aiClient *ai.Client) tea.Cmd {
+	return func() tea.Msg {
+		report, err := aiClient.AnalyzeDrift(driftSampleSize)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return driftLoadedMsg{report}
+	}
+}
+
+func adoptInterest(db database.Store, label string) tea.Cmd {
+	return func() tea.Msg {
+		if err := db.AddInterest(&models.UserInterest{Description: label, Weight: 1.0}); err != nil {
+			return errorMsg{err}
+		}
+		return interestAdoptedMsg{label}
+	}
+}
+
+func (m Model) handleDriftKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Drift.Back):
+		m.view = ViewArticleList
+		return m, nil
+	case key.Matches(msg, upKey):
+		if m.driftCursor > 0 {
+			m.driftCursor--
+		}
+		return m, nil
+	case key.Matches(msg, downKey):
+		if m.drift != nil && m.driftCursor < len(m.drift.Topics)-1 {
+			m.driftCursor++
+		}
+		return m, nil
+	case key.Matches(msg, keys.Drift.Adopt):
+		if m.drift != nil && m.driftCursor < len(m.drift.Topics) {
+			return m, adoptInterest(m.db, m.drift.Topics[m.driftCursor].Label)
+		}
+	case key.Matches(msg, keys.Drift.Reanalyze):
+		if m.checkedConnection && !m.ollamaOnline {
+			return m.showToast(severityWarning, "Ollama is unreachable")
+		}
+		m.drift = nil
+		return m, analyzeDrift(m.aiClient)
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+		return m, nil
+	}
+	return m, nil
+}
+
+// removeDriftTopic drops an adopted topic from the report
+func (m *Model) removeDriftTopic(label string) {
+	if m.drift == nil {
+		return
+	}
+	topics := m.drift.Topics[:0]
+	for _, t := range m.drift.Topics {
+		if t.Label != label {
+			topics = append(topics, t)
+		}
+	}
+	m.drift.Topics = topics
+	if m.driftCursor >= len(topics) && m.driftCursor > 0 {
+		m.driftCursor = len(topics) - 1
+	}
+}
+
+func (m Model) renderDrift() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Interest Drift"))
+	s.WriteString("\n")
+
+	switch {
+	case m.drift == nil:
+		s.WriteString("Analyzing recently read articles...\n")
+	case m.drift.Sampled == 0:
+		s.WriteString("No read history yet.\n")
+	default:
+		r := m.drift
+		s.WriteString(fmt.Sprintf("Compared your last %d reads with your interests.\n", r.Sampled))
+		if r.ClosestInterest != "" {
+			s.WriteString(fmt.Sprintf("Your reading is closest to %q (similarity %.2f).\n", r.ClosestInterest, r.CentroidSimilarity))
+		}
+		s.WriteString("\n")
+
+		if len(r.Topics) == 0 {
+			s.WriteString("No emerging topics outside your interests.\n")
+		}
+		for i, t := range r.Topics {
+			cursor := "  "
+			if i == m.driftCursor {
+				cursor = "> "
+			}
+			s.WriteString(articleTitleStyle.Render(fmt.Sprintf("%s%s", cursor, t.Label)))
+			s.WriteString("\n")
+			s.WriteString(helpStyle.Render(fmt.Sprintf("    %d reads • best interest match %.2f", len(t.Titles), t.Similarity)))
+			s.WriteString("\n")
+			for j, title := range t.Titles {
+				if j == 3 {
+					s.WriteString(helpStyle.Render(fmt.Sprintf("    … and %d more", len(t.Titles)-3)))
+					s.WriteString("\n")
+					break
+				}
+				s.WriteString("    • " + title + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n")
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	} else if m.statusMsg != "" {
+		s.WriteString(statusStyle.Render(m.statusMsg))
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓: select • a: adopt as interest • r: re-run • esc: back"))
+
+	return s.String()
+}
@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+type entitiesLoadedMsg struct {
+	entities []database.EntityCount
+}
+
+type entityArticlesMsg struct {
+	entity   models.Entity
+	articles []models.Article
+}
+
+// entitiesExtractedMsg ends an extraction pass with how many articles were
+// looked at
+type entitiesExtractedMsg struct {
+	n   int
+	err error
+}
+
+func loadEntities(db database.Store) tea.Cmd {
+	return func() tea.Msg {
+		entities, err := db.GetEntities()
+		if err != nil {
+			return errorMsg{err}
+		}
+		return entitiesLoadedMsg{entities}
+	}
+}
+
+func loadEntityArticles(db database.Store, entity models.Entity) tea.Cmd {
+	return func() tea.Msg {
+		articles, err := db.GetEntityArticles(entity)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return entityArticlesMsg{entity, articles}
+	}
+}
+
+// extractEntities tags the articles not looked at yet with the people,
+// companies and projects they name, reporting progress as status
+func extractEntities(send func(tea.Msg), aiClient *ai.Client) tea.Cmd {
+	return func() tea.Msg {
+		n, err := aiClient.ExtractAllEntities(func(done, total int) {
+			send(statusMsg(fmt.Sprintf("Extracting entities: %d/%d articles", done, total)))
+		})
+		return entitiesExtractedMsg{n, err}
+	}
+}
+
+// startExtraction runs an extraction pass unless one is running
+func (m Model) startExtraction() (tea.Model, tea.Cmd) {
+	switch {
+	case m.extracting:
+		return m, nil
+	case m.readOnly != "":
+		return m.showReadOnly()
+	case m.checkedConnection && !m.ollamaOnline:
+		return m.showToast(severityWarning, "Extracting entities needs Ollama, which is unreachable")
+	}
+	m.extracting = true
+	m.statusMsg = "Extracting entities..."
+	return m, m.sender.track(extractEntities(m.sender.Send, m.aiClient))
+}
+
+func (m Model) handleEntitiesExtracted(msg entitiesExtractedMsg) (tea.Model, tea.Cmd) {
+	m.extracting = false
+	var reload tea.Cmd
+	if m.view == ViewEntities {
+		reload = loadEntities(m.db)
+	}
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		return m, nil
+	case msg.err != nil:
+		var toastCmd tea.Cmd
+		m.statusMsg = ""
+		m, toastCmd = m.showToast(severityWarning, fmt.Sprintf("Extracted entities from %d articles, then failed: %v", msg.n, msg.err))
+		return m, tea.Batch(toastCmd, reload)
+	case msg.n > 0:
+		m.statusMsg = fmt.Sprintf("Extracted entities from %d articles", msg.n)
+	default:
+		m.statusMsg = ""
+	}
+	return m, reload
+}
+
+// openEntities shows the entity browser
+func (m Model) openEntities() (tea.Model, tea.Cmd) {
+	m.view = ViewEntities
+	m.entityCursor = 0
+	m.entityInput.SetValue("")
+	m.entityInput.Focus()
+	return m, tea.Batch(loadEntities(m.db), textinput.Blink)
+}
+
+// filteredEntities are the entities whose name contains the filter
+func (m Model) filteredEntities() []database.EntityCount {
+	filter := strings.ToLower(strings.TrimSpace(m.entityInput.Value()))
+	if filter == "" {
+		return m.entities
+	}
+	var entities []database.EntityCount
+	for _, e := range m.entities {
+		if strings.Contains(strings.ToLower(e.Name), filter) {
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+func (m Model) handleEntitiesKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	entities := m.filteredEntities()
+	switch {
+	case key.Matches(msg, keys.Entities.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Entities.Back):
+		if m.entityInput.Value() != "" {
+			m.entityInput.SetValue("")
+			m.entityCursor = 0
+			return m, nil
+		}
+		m.entityInput.Blur()
+		m.view = ViewArticleList
+		return m, nil
+	case key.Matches(msg, keys.Entities.Up):
+		if m.entityCursor > 0 {
+			m.entityCursor--
+		}
+		return m, nil
+	case key.Matches(msg, keys.Entities.Down):
+		if m.entityCursor < len(entities)-1 {
+			m.entityCursor++
+		}
+		return m, nil
+	case key.Matches(msg, keys.Entities.Open):
+		if m.entityCursor < len(entities) {
+			return m, loadEntityArticles(m.db, entities[m.entityCursor].Entity)
+		}
+		return m, nil
+	case key.Matches(msg, keys.Entities.Extract):
+		return m.startExtraction()
+	}
+
+	var cmd tea.Cmd
+	m.entityInput, cmd = m.entityInput.Update(msg)
+	m.entityCursor = 0
+	return m, cmd
+}
+
+// showEntityArticles lists the articles naming an entity like search
+// results
+func (m Model) showEntityArticles(msg entityArticlesMsg) Model {
+	m.entityInput.Blur()
+	m.view = ViewArticleList
+	m.searchQuery = msg.entity.Name
+	m.list.Title = "Mentioning " + msg.entity.Name
+	m = m.setListArticles(msg.articles)
+	m.statusMsg = fmt.Sprintf("%d articles (esc: back to all articles)", len(msg.articles))
+	return m
+}
+
+func (m Model) renderEntities() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Entities"))
+	s.WriteString("\n")
+	s.WriteString(filterStyle.Render("Filter: ") + m.entityInput.View())
+	s.WriteString("\n\n")
+
+	entities := m.filteredEntities()
+	switch {
+	case len(m.entities) == 0:
+		s.WriteString(fmt.Sprintf("No entities yet. Press %s to extract them from the articles with Ollama,\nor set ollama.extract_entities to extract them after every fetch.\n",
+			keys.Entities.Extract.Help().Key))
+	case len(entities) == 0:
+		s.WriteString("No entity matches the filter.\n")
+	}
+
+	// Keep the cursor in view, leaving room for the title, filter and help
+	height := max(m.height-8, 5)
+	start := max(m.entityCursor-height+1, 0)
+	for i := start; i < len(entities) && i < start+height; i++ {
+		e := entities[i]
+		cursor := "  "
+		if i == m.entityCursor {
+			cursor = "> "
+		}
+		s.WriteString(cursor + truncate(e.Name, max(m.width-30, 20)))
+		s.WriteString(helpStyle.Render(fmt.Sprintf("  %s, %d unread", e.Kind, e.Articles)))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	} else if m.extracting {
+		s.WriteString(statusStyle.Render(m.statusMsg))
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render(fmt.Sprintf("type to filter • ↑/↓: select • enter: list its articles • %s: extract from new articles • esc: back",
+		keys.Entities.Extract.Help().Key)))
+
+	return s.String()
+}
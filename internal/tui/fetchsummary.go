@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+type fetchDoneMsg struct {
+	summary *feed.Summary
+	// articles is the unread list after the fetch and cleanup
+	articles []models.Article
+	manual   bool
+	// notifyErr is set when post-fetch notifications could not be sent
+	notifyErr error
+}
+
+// refreshArticles replaces the unread list while keeping the filter and
+// selection. It leaves the list alone while it is replaced by the queue or
+// search results, or while a view shows the selected article.
+func (m Model) refreshArticles(articles []models.Article) Model {
+	if m.showQueue || m.searchQuery != "" {
+		return m
+	}
+	if m.view != ViewArticleList && m.view != ViewInterests {
+		return m
+	}
+	selected := m.list.SelectedItem()
+	m.allArticles = articles
+	m.calibration = newScoreCalibration(m.cfg.Scoring, articles)
+	m.applyFilter(false)
+	m.restoreSelection(selected)
+	return m
+}
+
+// mergeArticles updates the unread list in place from a fresh copy: listed
+// articles keep their position and take the new score, articles no longer
+// unread are dropped and new ones are added at the end, so the list doesn't
+// jump around while scores arrive. Like refreshArticles it leaves a list
+// showing something else alone. Press r to sort by the new scores.
+func (m Model) mergeArticles(articles []models.Article) Model {
+	if m.showQueue || m.searchQuery != "" {
+		return m
+	}
+	if m.view != ViewArticleList && m.view != ViewInterests {
+		return m
+	}
+
+	fresh := make(map[int64]models.Article, len(articles))
+	for _, a := range articles {
+		fresh[a.ID] = a
+	}
+	merged := make([]models.Article, 0, len(articles))
+	for _, a := range m.allArticles {
+		if updated, ok := fresh[a.ID]; ok {
+			merged = append(merged, updated)
+			delete(fresh, a.ID)
+		}
+	}
+	for _, a := range articles {
+		if _, ok := fresh[a.ID]; ok {
+			merged = append(merged, a)
+		}
+	}
+
+	selected := m.list.SelectedItem()
+	m.allArticles = merged
+	m.calibration = newScoreCalibration(m.cfg.Scoring, merged)
+	m.applyFilter(false)
+	m.restoreSelection(selected)
+	return m
+}
+
+// fetchStatus is the one-line status shown after a fetch
+func fetchStatus(summary *feed.Summary) string {
+	return fmt.Sprintf("Fetched %d new articles", summary.TotalNew())
+}
+
+// fetchWarnings lists what went wrong in an otherwise completed fetch
+func fetchWarnings(summary *feed.Summary, notifyErr error) []string {
+	var warnings []string
+	if failed := summary.Failed(); failed > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d feeds failed, press R for details", failed))
+	}
+	if err := summary.HookErr(); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	if err := summary.ExtractErr(); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	if notifyErr != nil {
+		warnings = append(warnings, "notification failed: "+notifyErr.Error())
+	}
+	return warnings
+}
+
+// handleFetchDone shows the fetch result, with a warning when some of it failed
+func (m Model) handleFetchDone(msg fetchDoneMsg) (tea.Model, tea.Cmd) {
+	m.lastFetch = msg.summary
+	m.lastFetchAt = time.Now()
+	m.lastFetchNotifyErr = msg.notifyErr
+	// Catch up on anything the progress messages missed, e.g. while
+	// another view was shown
+	m = m.mergeArticles(msg.articles)
+	m.statusMsg = fetchStatus(msg.summary)
+	if msg.manual && m.view == ViewArticleList {
+		m.view = ViewFetchSummary
+	}
+
+	// Newly scored articles have embeddings to index
+	cmds := []tea.Cmd{buildIndex(m.aiClient)}
+	if client := m.scoringClient(); client != nil && m.cfg.Ollama.ExtractEntities && !m.extracting {
+		m.extracting = true
+		cmds = append(cmds, m.sender.track(extractEntities(m.sender.Send, client)))
+	}
+	if warnings := fetchWarnings(msg.summary, msg.notifyErr); len(warnings) > 0 {
+		var toastCmd tea.Cmd
+		m, toastCmd = m.showToast(severityWarning, strings.Join(warnings, "; "))
+		cmds = append(cmds, toastCmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) handleFetchSummaryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.FetchSummary.Back):
+		m.view = ViewArticleList
+		return m, m.reloadArticles()
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m Model) renderFetchSummary() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Fetch Summary"))
+	s.WriteString("\n")
+
+	if m.lastFetch == nil {
+		s.WriteString("No fetch has completed yet.\n")
+	} else {
+		nameWidth := len("Feed")
+		for _, r := range m.lastFetch.Results {
+			if w := lipgloss.Width(r.FeedName); w > nameWidth {
+				nameWidth = w
+			}
+		}
+		if nameWidth > 40 {
+			nameWidth = 40
+		}
+
+		row := func(name, n, dup, undated, muted, status string) string {
+			name = truncate(name, nameWidth)
+			pad := strings.Repeat(" ", nameWidth-lipgloss.Width(name))
+			return fmt.Sprintf("%s%s  %5s  %5s  %7s  %5s  %s\n", name, pad, n, dup, undated, muted, status)
+		}
+
+		s.WriteString(helpStyle.Render(strings.TrimRight(row("Feed", "New", "Dupes", "No date", "Muted", "Status"), "\n")))
+		s.WriteString("\n")
+		for _, r := range m.lastFetch.Results {
+			status := statusStyle.Render("ok")
+			if r.Err != nil {
+				status = errorStyle.Render(r.Err.Error())
+			}
+			s.WriteString(row(r.FeedName,
+				fmt.Sprint(r.New), fmt.Sprint(r.Duplicates), fmt.Sprint(r.Undated), fmt.Sprint(r.Muted), status))
+		}
+		s.WriteString("\n")
+		s.WriteString(fetchStatus(m.lastFetch))
+		s.WriteString("\n")
+		for _, w := range fetchWarnings(m.lastFetch, m.lastFetchNotifyErr) {
+			s.WriteString(warningStyle.Render(w))
+			s.WriteString("\n")
+		}
+	}
+
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("enter/esc: back to articles • q: quit"))
+
+	return s.String()
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/query"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// filterDelay is how long typing in the filter has to pause before a long
+// list is filtered, so it isn't rebuilt on every key
+const filterDelay = 150 * time.Millisecond
+
+// debounceFrom is the number of articles from which filtering waits for a
+// pause in typing; shorter lists are filtered as you type
+const debounceFrom = 500
+
+// filterDueMsg asks for the filter to be applied after a pause in typing.
+// The seq tells it apart from the keys typed since.
+type filterDueMsg struct {
+	seq int
+}
+
+// scheduleFilter applies the filter after the query changed, right away for
+// short lists and once typing pauses for long ones
+func (m Model) scheduleFilter() (Model, tea.Cmd) {
+	m.filterSeq++
+	if len(m.allArticles) < debounceFrom {
+		m.applyFilter(true)
+		return m, nil
+	}
+	seq := m.filterSeq
+	return m, tea.Tick(filterDelay, func(time.Time) tea.Msg {
+		return filterDueMsg{seq}
+	})
+}
+
+// handleFilterDue applies the filter unless more was typed since it was
+// scheduled, or the filter was closed
+func (m Model) handleFilterDue(msg filterDueMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.filterSeq || !m.isFiltering {
+		return m, nil
+	}
+	m.applyFilter(true)
+	return m, nil
+}
+
+// filterCandidates returns the articles a query has to be matched against.
+// When it only narrows the query the list was filtered with, that's the
+// articles listed, in the order of the unfiltered list so ties rank the
+// same however fast the query was typed.
+func (m Model) filterCandidates(expr query.Expr) []models.Article {
+	if m.filterExpr == nil || m.searchQuery != "" || !query.Narrows(m.filterExpr, expr) {
+		return m.allArticles
+	}
+	listed := make(map[int64]bool, len(m.articles))
+	for _, a := range m.articles {
+		listed[a.ID] = true
+	}
+	candidates := make([]models.Article, 0, len(m.articles))
+	for _, a := range m.allArticles {
+		if listed[a.ID] {
+			candidates = append(candidates, a)
+		}
+	}
+	return candidates
+}
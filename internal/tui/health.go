@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+var (
+	healthOKStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	healthWarnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	healthDeadStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+type feedsLoadedMsg struct {
+	feeds []models.Feed
+}
+
+type feedsDiscoveredMsg struct {
+	feedID     int64
+	candidates []string
+}
+
+type feedReplacedMsg struct {
+	url string
+}
+
+func loadFeeds(db database.Store) tea.Cmd {
+	return func() tea.Msg {
+		feeds, err := db.GetFeeds()
+		if err != nil {
+			return errorMsg{err}
+		}
+		return feedsLoadedMsg{feeds}
+	}
+}
+
+func discoverFeeds(fetcher *feed.Fetcher, f models.Feed) tea.Cmd {
+	return func() tea.Msg {
+		candidates, err := fetcher.Discover(feed.SiteURL(f.URL))
+		if err != nil {
+			return errorMsg{err}
+		}
+		return feedsDiscoveredMsg{feedID: f.ID, candidates: candidates}
+	}
+}
+
+func replaceFeedURL(db database.Store, feedID int64, url string) tea.Cmd {
+	return func() tea.Msg {
+		if err := db.UpdateFeedURL(feedID, url); err != nil {
+			return errorMsg{err}
+		}
+		return feedReplacedMsg{url}
+	}
+}
+
+func (m Model) handleHealthKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Health.Back):
+		m.view = ViewArticleList
+		return m, nil
+	case key.Matches(msg, upKey):
+		if m.healthCursor > 0 {
+			m.healthCursor--
+			m.discovered = nil
+		}
+		return m, nil
+	case key.Matches(msg, downKey):
+		if m.healthCursor < len(m.feeds)-1 {
+			m.healthCursor++
+			m.discovered = nil
+		}
+		return m, nil
+	case key.Matches(msg, keys.Health.Discover):
+		if m.healthCursor < len(m.feeds) {
+			f := m.feeds[m.healthCursor]
+			m.statusMsg = fmt.Sprintf("Looking for feeds on %s...", feed.SiteURL(f.URL))
+			return m, discoverFeeds(m.fetcher, f)
+		}
+	case key.Matches(msg, keys.Health.Reload):
+		return m, loadFeeds(m.db)
+	case key.Matches(msg, keys.Health.Replace):
+		n := int(msg.String()[0] - '1')
+		if m.discovered != nil && n < len(m.discovered.candidates) && m.healthCursor < len(m.feeds) {
+			return m, replaceFeedURL(m.db, m.discovered.feedID, m.discovered.candidates[n])
+		}
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m Model) renderHealth() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Feed Health"))
+	s.WriteString("\n")
+
+	if len(m.feeds) == 0 {
+		s.WriteString("No feeds configured.\n")
+	}
+
+	now := time.Now()
+	for i, f := range m.feeds {
+		h := feed.CheckHealth(f, m.cfg.Fetch.SilentDays, now)
+
+		marker, style := "✓", healthOKStyle
+		switch h.State {
+		case feed.HealthUnknown:
+			marker, style = "?", helpStyle
+		case feed.HealthSilent, feed.HealthFailing:
+			marker, style = "!", healthWarnStyle
+		case feed.HealthDead:
+			marker, style = "✗", healthDeadStyle
+		}
+
+		cursor := "  "
+		if i == m.healthCursor {
+			cursor = "> "
+		}
+		name := f.Name
+		if !f.Enabled {
+			name += " (disabled)"
+		}
+		s.WriteString(fmt.Sprintf("%s%s %s ", cursor, style.Render(marker), name))
+		s.WriteString(style.Render(h.Detail))
+		s.WriteString("\n")
+
+		if i != m.healthCursor {
+			continue
+		}
+		s.WriteString(helpStyle.Render("    " + f.URL))
+		s.WriteString("\n")
+		if h.NeedsAttention() && m.discovered == nil {
+			s.WriteString(helpStyle.Render("    press d to look for a replacement feed on the site"))
+			s.WriteString("\n")
+		}
+		if m.discovered != nil && m.discovered.feedID == f.ID {
+			if len(m.discovered.candidates) == 0 {
+				s.WriteString(helpStyle.Render("    no feeds advertised on " + feed.SiteURL(f.URL)))
+				s.WriteString("\n")
+			}
+			for n, c := range m.discovered.candidates {
+				if n == 9 {
+					break
+				}
+				s.WriteString(fmt.Sprintf("    %d: %s\n", n+1, c))
+			}
+		}
+	}
+
+	s.WriteString("\n")
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	} else if m.statusMsg != "" {
+		s.WriteString(statusStyle.Render(m.statusMsg))
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓: select • d: discover feeds • 1-9: use discovered feed • r: reload • esc: back"))
+
+	return s.String()
+}
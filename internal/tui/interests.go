@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// Prompts shown in the interests view
+const (
+	interestPromptAdd   = "add"
+	interestPromptGroup = "group"
+)
+
+type interestsLoadedMsg struct {
+	interests []models.UserInterest
+	status    string
+}
+
+func loadInterests(db database.Store, status string) tea.Cmd {
+	return func() tea.Msg {
+		interests, err := db.GetInterests()
+		if err != nil {
+			return errorMsg{err}
+		}
+		return interestsLoadedMsg{interests: interests, status: status}
+	}
+}
+
+func addInterestTo(db database.Store, interest models.UserInterest) tea.Cmd {
+	return func() tea.Msg {
+		if err := db.AddInterest(&interest); err != nil {
+			return errorMsg{err}
+		}
+		return loadInterests(db, fmt.Sprintf("Added interest %q", interest.Description))()
+	}
+}
+
+func updateInterest(db database.Store, interest models.UserInterest, status string) tea.Cmd {
+	return func() tea.Msg {
+		if err := db.UpdateInterest(&interest); err != nil {
+			return errorMsg{err}
+		}
+		return loadInterests(db, status)()
+	}
+}
+
+func deleteInterest(db database.Store, interest models.UserInterest) tea.Cmd {
+	return func() tea.Msg {
+		if err := db.DeleteInterest(interest.ID); err != nil {
+			return errorMsg{err}
+		}
+		return loadInterests(db, fmt.Sprintf("Removed interest %q", interest.Description))()
+	}
+}
+
+// rescoreDoneMsg ends a rescore with the reordered unread list
+type rescoreDoneMsg struct {
+	total    int
+	articles []models.Article
+	err      error
+}
+
+// rescoreArticles scores the unread articles against the current interests
+// in the background, sending the scores through send after each batch
+func rescoreArticles(send func(tea.Msg), aiClient *ai.Client, db database.Store, cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		n, err := aiClient.Rescore(false, cfg.UI.ArticleMaxAgeDays, func(batch map[int64]float64, scored, total int, err error) {
+			send(articleScoredMsg{scores: batch, status: fmt.Sprintf("Rescoring: %d/%d articles", scored, total), err: err})
+		})
+		done := rescoreDoneMsg{total: n, err: err}
+		if err == nil {
+			maxAge := time.Duration(cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
+			done.articles, done.err = db.GetUnreadArticleList(maxAge)
+		}
+		return done
+	}
+}
+
+// startRescore rescores the unread articles unless a rescore is running
+func (m Model) startRescore() (tea.Model, tea.Cmd) {
+	switch {
+	case m.rescoring:
+		return m, nil
+	case m.readOnly != "":
+		return m.showReadOnly()
+	case m.checkedConnection && !m.ollamaOnline:
+		return m.showToast(severityWarning, "Rescoring needs Ollama, which is unreachable")
+	}
+	m.rescoring = true
+	m.statusMsg = "Rescoring unread articles..."
+	return m, m.sender.track(rescoreArticles(m.sender.Send, m.aiClient, m.db, m.cfg))
+}
+
+func (m Model) handleRescoreDone(msg rescoreDoneMsg) (tea.Model, tea.Cmd) {
+	m.rescoring = false
+	if msg.err != nil {
+		return m.showToast(severityError, msg.err.Error())
+	}
+	m = m.refreshArticles(msg.articles)
+	m.statusMsg = fmt.Sprintf("Rescored %d articles", msg.total)
+	return m, nil
+}
+
+// selectedInterest returns the interest under the cursor
+func (m Model) selectedInterest() (models.UserInterest, bool) {
+	if m.interestCursor < len(m.interests) {
+		return m.interests[m.interestCursor], true
+	}
+	return models.UserInterest{}, false
+}
+
+// startInterestPrompt opens the given prompt with an initial value
+func (m Model) startInterestPrompt(prompt, value string) (tea.Model, tea.Cmd) {
+	m.interestPrompt = prompt
+	m.interestInput.SetValue(value)
+	m.interestInput.CursorEnd()
+	m.interestInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m Model) handleInterestsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Interests.Back):
+		m.view = ViewArticleList
+		return m, nil
+	case key.Matches(msg, upKey):
+		if m.interestCursor > 0 {
+			m.interestCursor--
+		}
+	case key.Matches(msg, downKey):
+		if m.interestCursor < len(m.interests)-1 {
+			m.interestCursor++
+		}
+	case key.Matches(msg, keys.Interests.Add):
+		return m.startInterestPrompt(interestPromptAdd, "")
+	case key.Matches(msg, keys.Interests.Group):
+		if i, ok := m.selectedInterest(); ok {
+			return m.startInterestPrompt(interestPromptGroup, i.Group)
+		}
+	case key.Matches(msg, keys.Interests.WeightUp, keys.Interests.WeightDown):
+		if i, ok := m.selectedInterest(); ok {
+			if key.Matches(msg, keys.Interests.WeightDown) {
+				i.Weight -= 0.1
+			} else {
+				i.Weight += 0.1
+			}
+			if i.Weight < 0.1 {
+				i.Weight = 0.1
+			}
+			return m, updateInterest(m.db, i, fmt.Sprintf("Weight of %q is now %.1f", i.Description, i.Weight))
+		}
+	case key.Matches(msg, keys.Interests.Avoid):
+		if i, ok := m.selectedInterest(); ok {
+			i.Avoid = !i.Avoid
+			status := fmt.Sprintf("Now avoiding %q", i.Description)
+			if !i.Avoid {
+				status = fmt.Sprintf("No longer avoiding %q", i.Description)
+			}
+			return m, updateInterest(m.db, i, status)
+		}
+	case key.Matches(msg, keys.Interests.Remove):
+		if i, ok := m.selectedInterest(); ok {
+			return m, deleteInterest(m.db, i)
+		}
+	case key.Matches(msg, keys.Interests.Reload):
+		return m, loadInterests(m.db, "")
+	case key.Matches(msg, keys.Interests.Rescore):
+		return m.startRescore()
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+	}
+	return m, nil
+}
+
+func (m Model) handleInterestInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.interestPrompt = ""
+		m.interestInput.Blur()
+		return m, nil
+	case "enter":
+		prompt := m.interestPrompt
+		value := strings.TrimSpace(m.interestInput.Value())
+		m.interestPrompt = ""
+		m.interestInput.Blur()
+
+		selected, ok := m.selectedInterest()
+		switch {
+		case prompt == interestPromptAdd && value != "":
+			// New interests join the section of the selected one
+			return m, addInterestTo(m.db, models.UserInterest{Description: value, Weight: 1.0, Group: selected.Group, Avoid: selected.Avoid})
+		case prompt == interestPromptGroup && ok:
+			selected.Group = value
+			return m, updateInterest(m.db, selected, fmt.Sprintf("Moved %q to %s", selected.Description, groupLabel(value)))
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.interestInput, cmd = m.interestInput.Update(msg)
+	return m, cmd
+}
+
+// groupLabel names a group for display
+func groupLabel(group string) string {
+	if group == "" {
+		return "(ungrouped)"
+	}
+	return group
+}
+
+// sectionLabel names the section an interest is listed under
+func sectionLabel(interest models.UserInterest) string {
+	if interest.Avoid {
+		return "Avoid"
+	}
+	return groupLabel(interest.Group)
+}
+
+func (m Model) renderInterests() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Interests"))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("Articles are scored against each group and keep their best group score,"))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("minus their closest match among the topics to avoid."))
+	s.WriteString("\n\n")
+
+	if len(m.interests) == 0 {
+		s.WriteString("No interests yet. Press a to add one.\n")
+	}
+
+	for i, interest := range m.interests {
+		if i == 0 || sectionLabel(interest) != sectionLabel(m.interests[i-1]) {
+			if i > 0 {
+				s.WriteString("\n")
+			}
+			s.WriteString(filterStyle.Render(sectionLabel(interest)))
+			s.WriteString("\n")
+		}
+		cursor := "  "
+		if i == m.interestCursor {
+			cursor = "> "
+		}
+		s.WriteString(fmt.Sprintf("%s%s %s\n", cursor, helpStyle.Render(fmt.Sprintf("%.1f", interest.Weight)), interest.Description))
+	}
+
+	s.WriteString("\n")
+	switch m.interestPrompt {
+	case interestPromptAdd:
+		s.WriteString(filterStyle.Render("New interest: ") + m.interestInput.View())
+		s.WriteString("\n")
+	case interestPromptGroup:
+		s.WriteString(filterStyle.Render("Group: ") + m.interestInput.View() + helpStyle.Render(" (empty for ungrouped)"))
+		s.WriteString("\n")
+	}
+
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	} else if m.statusMsg != "" {
+		s.WriteString(statusStyle.Render(m.statusMsg))
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓: select • a: add • g: set group • v: avoid • +/-: weight • x: remove • R: rescore articles • esc: back"))
+
+	return s.String()
+}
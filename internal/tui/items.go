@@ -2,21 +2,58 @@ package tui
 
 import (
 	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 	"github.com/thomaskoefod/newsreadr/pkg/models"
+	"golang.org/x/net/html"
 )
 
 type articleItem struct {
-	article models.Article
+	article     models.Article
+	calibration scoreCalibration
+	// titleMatches and feedMatches are the rune positions of the characters
+	// the filter matched in the title and the feed name
+	titleMatches, feedMatches []int
 }
 
 func (i articleItem) Title() string {
-	return i.article.Title
+	return i.marks() + i.article.Title
+}
+
+// marks are the markers shown before the title of starred and queued
+// articles
+func (i articleItem) marks() string {
+	marks := ""
+	if i.article.Starred {
+		marks += "★ "
+	}
+	if i.article.Queued {
+		marks += "» "
+	}
+	return marks
+}
+
+// pendingScore stands in for the score of articles awaiting scoring
+const pendingScore = "…"
+
+// score formats the relevance score as its band of stars, or pendingScore
+// while it is awaited
+func (i articleItem) score() string {
+	return i.calibration.format(i.article)
 }
 
 func (i articleItem) Description() string {
-	return fmt.Sprintf("%.2f | %s", i.article.RelevanceScore, i.article.PublishedAt.Format("Jan 2, 2006"))
+	desc := fmt.Sprintf("%s | %s", i.score(), i.article.PublishedAt.Format("Jan 2, 2006"))
+	if i.article.FeedName != "" {
+		desc += " | " + i.article.FeedName
+	}
+	return desc + "\n" + snippet(i.article.Description)
 }
 
 func (i articleItem) FilterValue() string {
@@ -24,3 +61,174 @@ func (i articleItem) FilterValue() string {
 }
 
 var _ list.Item = articleItem{}
+
+// fittedItem is an article whose title and description lines are cut with
+// truncate, and the characters matched by the filter highlighted, before the
+// default delegate sees them
+type fittedItem struct {
+	articleItem
+	width int
+	// titleStyle and descStyle are the delegate's styles for the row,
+	// matchStyle the one for matched characters
+	titleStyle, descStyle, matchStyle lipgloss.Style
+}
+
+func (f fittedItem) Title() string {
+	title := truncate(f.articleItem.Title(), f.width)
+	offset := utf8.RuneCountInString(f.marks())
+	return highlight(title, shift(f.titleMatches, offset), f.titleStyle, f.matchStyle)
+}
+
+func (f fittedItem) Description() string {
+	lines := strings.Split(f.articleItem.Description(), "\n")
+	// The feed name ends the first line
+	offset := utf8.RuneCountInString(lines[0]) - utf8.RuneCountInString(f.article.FeedName)
+	for i, line := range lines {
+		lines[i] = truncate(line, f.width)
+	}
+	lines[0] = highlight(lines[0], shift(f.feedMatches, offset), f.descStyle, f.matchStyle)
+	return strings.Join(lines, "\n")
+}
+
+// shift moves rune positions along by offset
+func shift(positions []int, offset int) []int {
+	shifted := make([]int, len(positions))
+	for i, p := range positions {
+		shifted[i] = p + offset
+	}
+	return shifted
+}
+
+// highlight renders the runes of s at the given positions in style with
+// match on top, and the rest in style, like the list does for its own
+// filter. The ellipsis of a truncated line is never highlighted.
+func highlight(s string, positions []int, style, match lipgloss.Style) string {
+	if len(positions) == 0 {
+		return s
+	}
+	if strings.HasSuffix(s, "…") {
+		last := utf8.RuneCountInString(s) - 1
+		positions = slices.DeleteFunc(slices.Clone(positions), func(p int) bool { return p >= last })
+	}
+	unmatched := style.Inline(true)
+	return lipgloss.StyleRunes(s, positions, unmatched.Inherit(match), unmatched)
+}
+
+// cellWidth measures text the way lipgloss does, counting ambiguous East
+// Asian characters as one cell whatever the locale
+var cellWidth = func() *runewidth.Condition {
+	c := runewidth.NewCondition()
+	c.EastAsianWidth = false
+	return c
+}()
+
+// truncate shortens s to at most width cells, adding an ellipsis. It cuts
+// between grapheme clusters, so wide CJK characters and emoji made of
+// several runes are never split.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return cellWidth.Truncate(s, width, "…")
+}
+
+// snippet returns the text of an HTML description on a single line
+func snippet(description string) string {
+	var s strings.Builder
+	z := html.NewTokenizer(strings.NewReader(description))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(s.String()), " ")
+		case html.TextToken:
+			s.Write(z.Text())
+			s.WriteByte(' ')
+		}
+	}
+}
+
+// compactFeedWidth is the width of the feed column in compact mode
+const compactFeedWidth = 16
+
+// articleDelegate renders articles either in detail (title, details and a
+// snippet) or compactly, one line per article
+type articleDelegate struct {
+	list.DefaultDelegate
+	compact bool
+}
+
+func newArticleDelegate(compact bool) articleDelegate {
+	d := articleDelegate{DefaultDelegate: list.NewDefaultDelegate(), compact: compact}
+	if compact {
+		d.ShowDescription = false
+		d.SetHeight(1)
+		d.SetSpacing(0)
+	} else {
+		d.SetHeight(3)
+	}
+	return d
+}
+
+func (d articleDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if s, ok := item.(sectionItem); ok {
+		d.renderSection(w, m, index, s)
+		return
+	}
+	if !d.compact {
+		if i, ok := item.(articleItem); ok && m.Width() > 0 {
+			style := d.Styles.NormalTitle
+			fitted := fittedItem{
+				articleItem: i,
+				width:       m.Width() - style.GetPaddingLeft() - style.GetPaddingRight(),
+				titleStyle:  d.Styles.NormalTitle,
+				descStyle:   d.Styles.NormalDesc,
+				matchStyle:  d.Styles.FilterMatch,
+			}
+			if index == m.Index() {
+				fitted.titleStyle, fitted.descStyle = d.Styles.SelectedTitle, d.Styles.SelectedDesc
+			}
+			item = fitted
+		}
+		d.DefaultDelegate.Render(w, m, index, item)
+		return
+	}
+	i, ok := item.(articleItem)
+	if !ok || m.Width() <= 0 {
+		return
+	}
+
+	style := d.Styles.NormalTitle
+	if index == m.Index() {
+		style = d.Styles.SelectedTitle
+	}
+	width := m.Width() - style.GetPaddingLeft() - style.GetPaddingRight()
+
+	feed := truncate(i.article.FeedName, compactFeedWidth)
+	feedMatches := i.feedMatches
+	if feed != i.article.FeedName {
+		// Not past the ellipsis
+		last := utf8.RuneCountInString(feed) - 1
+		feedMatches = slices.DeleteFunc(slices.Clone(feedMatches), func(p int) bool { return p >= last })
+	}
+	feed += strings.Repeat(" ", max(compactFeedWidth-lipgloss.Width(feed), 0))
+	before := fmt.Sprintf("%*s  ", i.calibration.width(), i.score())
+	line := before + feed + "  " + i.Title()
+	matches := append(shift(feedMatches, utf8.RuneCountInString(before)),
+		shift(i.titleMatches, utf8.RuneCountInString(before+feed+"  "+i.marks()))...)
+	fmt.Fprint(w, style.Render(highlight(truncate(line, width), matches, style, d.Styles.FilterMatch)))
+}
+
+// renderSection renders a section header on one line, padded to the height
+// of an article row
+func (d articleDelegate) renderSection(w io.Writer, m list.Model, index int, s sectionItem) {
+	if m.Width() <= 0 {
+		return
+	}
+	style := d.Styles.NormalTitle.Bold(true)
+	if index == m.Index() {
+		style = d.Styles.SelectedTitle.Bold(true)
+	}
+	width := m.Width() - style.GetPaddingLeft() - style.GetPaddingRight()
+	fmt.Fprint(w, style.Render(truncate(s.Title(), width)))
+	fmt.Fprint(w, strings.Repeat("\n", max(d.Height()-1, 0)))
+}
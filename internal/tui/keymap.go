@@ -0,0 +1,338 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Key bindings for every view. The handlers match against these and the help
+// screen is generated from them, so the two can't drift apart.
+
+func binding(help, desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(help, desc))
+}
+
+// note documents input that isn't a key binding, e.g. filter syntax
+func note(help, desc string) key.Binding {
+	return key.NewBinding(key.WithHelp(help, desc))
+}
+
+var (
+	quitKey = binding("q, ctrl+c", "Quit", "q", "ctrl+c")
+	helpKey = binding("?", "Show/hide this help", "?")
+	upKey   = binding("↑/↓, j/k", "Move the selection", "up", "k")
+	downKey = binding("", "", "down", "j")
+)
+
+type listKeyMap struct {
+	Navigate, Open, Browser, BrowserRead, Star, Snooze, Queue     key.Binding
+	QueueView, Share                                              key.Binding
+	Mute, MuteDomain, Filter, Search, ClearSearch, Refresh, Fetch key.Binding
+	LastFetch, DeleteOld, Trash, Density, Stats, Drift, Health    key.Binding
+	Interests, Messages, Group, Collapse, Expand, Pane, New       key.Binding
+	Briefing, Entities                                            key.Binding
+}
+
+type filterKeyMap struct {
+	Words, Field, Tag, Score, Regex, Exclude, Apply, Cancel key.Binding
+}
+
+type detailKeyMap struct {
+	LineUp, LineDown, PageUp, PageDown, Top, Bottom    key.Binding
+	MarkRead, Browser, BrowserRead, Save, Star, Snooze key.Binding
+	Queue, MuteDomain, Archive, PDF                    key.Binding
+	Related, Timeline, Ask, Share, Metadata, Back      key.Binding
+}
+
+type relatedKeyMap struct {
+	Open, Back key.Binding
+}
+
+type briefingKeyMap struct {
+	Open, Rewrite, Scroll, Back key.Binding
+}
+
+type timelineKeyMap struct {
+	Open, Browser, Back key.Binding
+}
+
+type entitiesKeyMap struct {
+	Up, Down, Open, Extract, Back, Quit key.Binding
+}
+
+type chatKeyMap struct {
+	Send, Scroll, Back, Quit key.Binding
+}
+
+type interestsKeyMap struct {
+	Add, Group, Avoid, WeightUp, WeightDown, Remove, Reload, Rescore, Back key.Binding
+}
+
+type driftKeyMap struct {
+	Adopt, Reanalyze, Back key.Binding
+}
+
+type healthKeyMap struct {
+	Discover, Replace, Reload, Back key.Binding
+}
+
+type statsKeyMap struct {
+	Reload, Back key.Binding
+}
+
+type cleanupKeyMap struct {
+	Older, Newer, Run, Confirm, Back key.Binding
+}
+
+type trashKeyMap struct {
+	Restore, Back key.Binding
+}
+
+type fetchSummaryKeyMap struct {
+	Back key.Binding
+}
+
+type messagesKeyMap struct {
+	Scroll, Back key.Binding
+}
+
+type helpKeyMap struct {
+	Scroll, Close key.Binding
+}
+
+type setupKeyMap struct {
+	Next, Pick, Back, Cancel key.Binding
+}
+
+type keyMap struct {
+	List         listKeyMap
+	Filter       filterKeyMap
+	Detail       detailKeyMap
+	Related      relatedKeyMap
+	Timeline     timelineKeyMap
+	Chat         chatKeyMap
+	Briefing     briefingKeyMap
+	Entities     entitiesKeyMap
+	Interests    interestsKeyMap
+	Drift        driftKeyMap
+	Health       healthKeyMap
+	Stats        statsKeyMap
+	Cleanup      cleanupKeyMap
+	Trash        trashKeyMap
+	FetchSummary fetchSummaryKeyMap
+	Messages     messagesKeyMap
+	Help         helpKeyMap
+	Setup        setupKeyMap
+}
+
+var keys = keyMap{
+	List: listKeyMap{
+		Navigate:    note("↑/↓, j/k", "Navigate articles"),
+		Open:        binding("enter", "Read article", "enter"),
+		Browser:     binding("o", "Open article in browser", "o"),
+		BrowserRead: binding("O", "Open article in browser and mark it read", "O"),
+		Star:        binding("*", "Star/unstar article", "*"),
+		Snooze:      binding("z", "Snooze article (then h: 1 hour, t: tonight, m: tomorrow, w: next week)", "z"),
+		Queue:       binding("l", "Add/remove article from read-later queue", "l"),
+		QueueView:   binding("L", "Switch between unread list and read-later queue", "L"),
+		Share:       binding("S", "Share article (copy URL, markdown link or configured targets)", "S"),
+		Mute:        binding("m", "Mute a keyword or /regex/", "m"),
+		MuteDomain:  binding("M", "Mute the selected article's domain", "M"),
+		Filter:      binding("/, f", "Filter articles (see Filter Mode)", "/", "f"),
+		Search:      binding("ctrl+s", "Semantic search: find articles about a topic", "ctrl+s"),
+		ClearSearch: binding("esc", "Clear search results", "esc"),
+		Refresh:     binding("r", "Refresh article list", "r"),
+		Fetch:       binding("F", "Fetch new articles from feeds (shows a per-feed summary)", "F"),
+		LastFetch:   binding("R", "Show the last fetch summary", "R"),
+		DeleteOld:   binding("d", "Clean up: preview and delete old and read articles", "d"),
+		Trash:       binding("T", "Trash: restore articles deleted in the last 7 days", "T"),
+		Density:     binding("v", "Switch between the detailed and compact (one line) list", "v"),
+		Group:       binding("s", "Group the list into sections by feed or by date (Today, Yesterday, This Week, Older), or back to a single list", "s"),
+		Collapse:    binding("←/h", "Collapse the section of the selected article (grouped list)", "left", "h"),
+		Expand:      binding("→", "Expand the selected section (grouped list)", "right"),
+		Stats:       binding("t", "Show reading statistics", "t"),
+		Drift:       binding("D", "Interest drift report", "D"),
+		Health:      binding("H", "Feed health (failing, moved or silent feeds)", "H"),
+		Interests:   binding("I", "Manage interests, their weights and groups, and topics to avoid", "I"),
+		Messages:    binding("E", "Message history: recent status messages, warnings and errors", "E"),
+		Pane:        binding("tab", "Terminals 160+ columns wide: move between the feeds, articles and preview panes (shift+tab back)", "tab", "shift+tab"),
+		New:         binding("N", "Show the high-relevance articles new since the last session (esc: dismiss the banner)", "N"),
+		Briefing:    binding("B", "Briefing: the most relevant unread articles narrated by Ollama", "B"),
+		Entities:    binding("e", "Entities: browse the people, companies and projects articles name", "e"),
+	},
+	Filter: filterKeyMap{
+		Words:   note("words", `Fuzzy match on title and feed, best first ("quoted phrase" for exact)`),
+		Field:   note("feed:verge", `Feed name contains "verge" (also title:, url:)`),
+		Tag:     note("tag:go", `Article has tag "go"`),
+		Score:   note("score>0.7", "Score comparison (>, >=, <, <=, =)"),
+		Regex:   note("/regex/", "Title matches regular expression"),
+		Exclude: note("-term", "Exclude matches; a OR b matches either side"),
+		Apply:   binding("enter", "Apply filter and exit filter mode", "enter"),
+		Cancel:  binding("esc", "Cancel filter and show all articles", "esc"),
+	},
+	Detail: detailKeyMap{
+		LineUp:      binding("↑/↓, j/k", "Scroll line by line", "up", "k"),
+		LineDown:    binding("", "", "down", "j"),
+		PageUp:      binding("pgup/pgdn", "Scroll page by page (also b, f)", "pgup", "b"),
+		PageDown:    binding("space", "Page down", "pgdown", "f", " "),
+		Top:         binding("home/g", "Go to top", "home", "g"),
+		Bottom:      binding("end/G", "Go to bottom", "end", "G"),
+		MarkRead:    binding("enter", "Mark as read and delete article", "enter"),
+		Browser:     binding("o", "Open article in browser", "o"),
+		BrowserRead: binding("O", "Open article in browser and mark it read", "O"),
+		Save:        binding("s", "Save article to Raindrop.io or a save target, with an optional note", "s"),
+		Star:        binding("*", "Star/unstar article", "*"),
+		Snooze:      binding("z", "Snooze article", "z"),
+		Queue:       binding("l", "Add/remove article from read-later queue", "l"),
+		MuteDomain:  binding("M", "Mute this article's domain", "M"),
+		Archive:     binding("w", "Load the article from the web archive, e.g. past a paywall", "w"),
+		PDF:         binding("p", "Download the paper's PDF (arXiv and journal articles) to papers.pdf_dir", "p"),
+		Related:     binding("r", "More like this: similar unread articles", "r"),
+		Timeline:    binding("t", "Story timeline: stored articles about the same story, oldest first", "t"),
+		Ask:         binding("a", "Ask questions about the article (answers stream in)", "a"),
+		Share:       binding("S", "Share article", "S"),
+		Metadata:    binding("i", "Show/hide metadata: feed, author, tags, GUID, URLs and score breakdown", "i"),
+		Back:        binding("esc", "Back to list", "esc", "backspace"),
+	},
+	Related: relatedKeyMap{
+		Open: binding("enter", "Open the selected article", "enter"),
+		Back: binding("esc, r", "Back to the article", "esc", "r"),
+	},
+	Timeline: timelineKeyMap{
+		Open:    binding("enter", "Read the selected article (read ones open in the browser)", "enter"),
+		Browser: binding("o", "Open the selected article in the browser", "o"),
+		Back:    binding("esc, t", "Back to the article", "esc", "t"),
+	},
+	Chat: chatKeyMap{
+		Send:   binding("enter", "Ask the question", "enter"),
+		Scroll: binding("↑/↓, pgup/pgdn", "Scroll the conversation", "up", "down", "pgup", "pgdown"),
+		Back:   binding("esc", "Back to the article (the conversation is kept)", "esc"),
+		Quit:   binding("ctrl+c", "Quit", "ctrl+c"),
+	},
+	Briefing: briefingKeyMap{
+		Open:    binding("1-9", "Read the article cited as [n]", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+		Rewrite: binding("r", "Write the briefing again from the current list", "r"),
+		Scroll:  binding("↑/↓, pgup/pgdn", "Scroll the briefing", "up", "down", "k", "j", "pgup", "pgdown"),
+		Back:    binding("esc, B", "Back to list (the briefing is kept)", "esc", "B"),
+	},
+	Entities: entitiesKeyMap{
+		Up:      binding("↑/↓", "Select an entity (type to filter them)", "up"),
+		Down:    binding("", "", "down"),
+		Open:    binding("enter", "List the unread articles naming it", "enter"),
+		Extract: binding("ctrl+e", "Extract entities from the articles not looked at yet", "ctrl+e"),
+		Back:    binding("esc", "Clear the filter, or back to list", "esc"),
+		Quit:    binding("ctrl+c", "Quit", "ctrl+c"),
+	},
+	Interests: interestsKeyMap{
+		Add:        binding("a", "Add an interest to the selected section", "a"),
+		Group:      binding("g", "Move the interest to another group", "g"),
+		Avoid:      binding("v", "Toggle whether it is a topic to avoid", "v"),
+		WeightUp:   binding("+/-", "Raise or lower its weight", "+", "="),
+		WeightDown: binding("", "", "-"),
+		Remove:     binding("x", "Remove the interest", "x"),
+		Reload:     binding("r", "Reload interests", "r"),
+		Rescore:    binding("R", "Rescore unread articles against the interests", "R"),
+		Back:       binding("esc, I", "Back to list", "esc", "I"),
+	},
+	Drift: driftKeyMap{
+		Adopt:     binding("a", "Adopt the selected topic as an interest", "a"),
+		Reanalyze: binding("r", "Analyze again", "r"),
+		Back:      binding("esc, D", "Back to list", "esc", "D"),
+	},
+	Health: healthKeyMap{
+		Discover: binding("d", "Look for feeds on the selected feed's site", "d"),
+		Replace:  binding("1-9", "Replace the feed URL with a discovered feed", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+		Reload:   binding("r", "Reload feeds", "r"),
+		Back:     binding("esc, H", "Back to list", "esc", "H"),
+	},
+	Stats: statsKeyMap{
+		Reload: binding("r", "Reload statistics", "r"),
+		Back:   binding("esc, t", "Back to list", "esc", "t"),
+	},
+	Cleanup: cleanupKeyMap{
+		Older:   binding("+/-", "Raise or lower the max age by a day", "+", "="),
+		Newer:   binding("", "", "-"),
+		Run:     binding("enter", "Delete the previewed articles (asks to confirm)", "enter"),
+		Confirm: binding("y", "Confirm the cleanup", "y"),
+		Back:    binding("esc, d", "Back to list", "esc", "d"),
+	},
+	Trash: trashKeyMap{
+		Restore: binding("u", "Restore the selected article as unread", "u"),
+		Back:    binding("esc, T", "Back to list", "esc", "T"),
+	},
+	FetchSummary: fetchSummaryKeyMap{
+		Back: binding("esc, enter, R", "Back to list", "esc", "enter", "R"),
+	},
+	Messages: messagesKeyMap{
+		Scroll: binding("↑/↓, pgup/pgdn", "Scroll the history", "up", "down", "k", "j", "pgup", "pgdown"),
+		Back:   binding("esc, E", "Back", "esc", "E"),
+	},
+	Help: helpKeyMap{
+		Scroll: binding("↑/↓, pgup/pgdn", "Scroll this help", "up", "down", "k", "j", "pgup", "pgdown"),
+		Close:  binding("esc, ?, q", "Close help", "esc", "?", "q"),
+	},
+	Setup: setupKeyMap{
+		Next:   binding("enter", "Add the item or go to the next step", "enter"),
+		Pick:   binding("1-9", "Add a discovered feed", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+		Back:   binding("esc", "Previous step", "esc"),
+		Cancel: binding("ctrl+c", "Cancel setup without writing a config", "ctrl+c"),
+	},
+}
+
+// helpSection is one titled group of bindings on the help screen
+type helpSection struct {
+	title    string
+	bindings []key.Binding
+}
+
+func helpSections() []helpSection {
+	l, f, d := keys.List, keys.Filter, keys.Detail
+	i, dr, h := keys.Interests, keys.Drift, keys.Health
+	return []helpSection{
+		{"Article List", []key.Binding{
+			l.Navigate, l.Open, l.Browser, l.BrowserRead, l.Star, l.Snooze, l.Queue, l.QueueView, l.Share,
+			l.Mute, l.MuteDomain, l.Filter, l.Search, l.ClearSearch, l.Refresh, l.Fetch,
+			l.LastFetch, l.DeleteOld, l.Trash, l.Density, l.Group, l.Collapse, l.Expand, l.Stats, l.Drift,
+			l.Health, l.Interests, l.Messages, l.Pane, l.New, l.Briefing, l.Entities, quitKey,
+		}},
+		{"Filter Mode", []key.Binding{f.Words, f.Field, f.Tag, f.Score, f.Regex, f.Exclude, f.Apply, f.Cancel}},
+		{"Article Detail", []key.Binding{
+			d.LineUp, d.PageUp, d.PageDown, d.Top, d.Bottom, d.MarkRead, d.Browser, d.BrowserRead, d.Save,
+			d.Star, d.Snooze, d.Queue, d.MuteDomain, d.Archive, d.PDF, d.Related, d.Timeline, d.Ask, d.Share, d.Metadata, d.Back, quitKey,
+		}},
+		{"More Like This", []key.Binding{upKey, keys.Related.Open, keys.Related.Back}},
+		{"Story Timeline", []key.Binding{upKey, keys.Timeline.Open, keys.Timeline.Browser, keys.Timeline.Back}},
+		{"Ask", []key.Binding{keys.Chat.Send, keys.Chat.Scroll, keys.Chat.Back, keys.Chat.Quit}},
+		{"Briefing", []key.Binding{keys.Briefing.Open, keys.Briefing.Rewrite, keys.Briefing.Scroll, keys.Briefing.Back}},
+		{"Entities", []key.Binding{keys.Entities.Up, keys.Entities.Open, keys.Entities.Extract, keys.Entities.Back, keys.Entities.Quit}},
+		{"Interests", []key.Binding{upKey, i.Add, i.Group, i.Avoid, i.WeightUp, i.Remove, i.Reload, i.Rescore, i.Back}},
+		{"Interest Drift", []key.Binding{upKey, dr.Adopt, dr.Reanalyze, dr.Back}},
+		{"Feed Health", []key.Binding{upKey, h.Discover, h.Replace, h.Reload, h.Back}},
+		{"Reading Statistics", []key.Binding{keys.Stats.Reload, keys.Stats.Back}},
+		{"Cleanup", []key.Binding{keys.Cleanup.Older, keys.Cleanup.Run, keys.Cleanup.Confirm, keys.Cleanup.Back}},
+		{"Trash", []key.Binding{upKey, keys.Trash.Restore, keys.Trash.Back}},
+		{"Fetch Summary", []key.Binding{keys.FetchSummary.Back}},
+		{"Messages", []key.Binding{keys.Messages.Scroll, keys.Messages.Back}},
+		{"General", []key.Binding{helpKey, keys.Help.Scroll}},
+	}
+}
+
+// renderKeyHelp lists the bindings of every view
+func renderKeyHelp() string {
+	var s strings.Builder
+	s.WriteString("NewsReadr - Keyboard Shortcuts\n")
+	for _, section := range helpSections() {
+		s.WriteString("\n")
+		s.WriteString(filterStyle.Render(section.title + ":"))
+		s.WriteString("\n")
+		for _, b := range section.bindings {
+			h := b.Help()
+			pad := strings.Repeat(" ", max(14-lipgloss.Width(h.Key), 1))
+			s.WriteString(fmt.Sprintf("  %s%s%s\n", h.Key, pad, h.Desc))
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// messageLogSize is how many status and error messages are kept
+const messageLogSize = 200
+
+// logEntry is a status message or toast as it was shown
+type logEntry struct {
+	at    time.Time
+	text  string
+	level severity
+}
+
+// messageLog is a ring buffer of the most recent messages
+type messageLog struct {
+	entries []logEntry
+	next    int
+}
+
+func (l *messageLog) add(e logEntry) {
+	if len(l.entries) < messageLogSize {
+		l.entries = append(l.entries, e)
+		return
+	}
+	l.entries[l.next] = e
+	l.next = (l.next + 1) % messageLogSize
+}
+
+// newestFirst returns the entries from the most recent one back
+func (l *messageLog) newestFirst() []logEntry {
+	out := make([]logEntry, 0, len(l.entries))
+	for i := range l.entries {
+		idx := (l.next - 1 - i + 2*len(l.entries)) % len(l.entries)
+		out = append(out, l.entries[idx])
+	}
+	return out
+}
+
+// recordMessages logs the status message and toast if an update changed them
+func (m Model) recordMessages(prevStatus string, prevToast int) Model {
+	now := time.Now()
+	if m.statusMsg != "" && m.statusMsg != prevStatus {
+		m.messages.add(logEntry{at: now, text: m.statusMsg})
+	}
+	if m.toast.text != "" && m.toast.id != prevToast {
+		m.messages.add(logEntry{at: now, text: m.toast.text, level: m.toast.level})
+	}
+	return m
+}
+
+// openMessages shows the message history, returning to the current view
+func (m Model) openMessages() Model {
+	m.messagesReturn = m.view
+	m.view = ViewMessages
+	m.messagesViewport = viewport.New(m.width, max(m.height-4, 5))
+	m.messagesViewport.SetContent(m.renderMessageLog())
+	return m
+}
+
+func (m Model) handleMessagesKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Messages.Back):
+		m.view = m.messagesReturn
+		return m, nil
+	case key.Matches(msg, keys.Messages.Scroll):
+		var cmd tea.Cmd
+		m.messagesViewport, cmd = m.messagesViewport.Update(msg)
+		return m, cmd
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+	}
+	return m, nil
+}
+
+func (m Model) renderMessageLog() string {
+	entries := m.messages.newestFirst()
+	if len(entries) == 0 {
+		return "No messages yet.\n"
+	}
+
+	var s strings.Builder
+	for _, e := range entries {
+		stamp := helpStyle.Render(e.at.Format("15:04:05"))
+		var text string
+		switch e.level {
+		case severityError:
+			text = errorStyle.Render("error: " + e.text)
+		case severityWarning:
+			text = warningStyle.Render("warning: " + e.text)
+		default:
+			text = statusStyle.Render(e.text)
+		}
+		s.WriteString(fmt.Sprintf("%s  %s\n", stamp, text))
+	}
+	return s.String()
+}
+
+func (m Model) renderMessages() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Messages"))
+	s.WriteString("\n")
+	s.WriteString(m.messagesViewport.View())
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(fmt.Sprintf("newest first • %s: scroll • %s: back",
+		keys.Messages.Scroll.Help().Key, keys.Messages.Back.Help().Key)))
+
+	return s.String()
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+var metadataStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("241")).
+	Padding(0, 1)
+
+// metadataMatches is how many interest matches the score breakdown lists
+const metadataMatches = 5
+
+type scoreExplainedMsg struct {
+	articleID int64
+	breakdown *ai.ScoreBreakdown
+	err       error
+}
+
+func explainScore(aiClient *ai.Client, article models.Article) tea.Cmd {
+	return func() tea.Msg {
+		b, err := aiClient.ExplainScore(&article)
+		return scoreExplainedMsg{articleID: article.ID, breakdown: b, err: err}
+	}
+}
+
+// toggleMetadata shows or hides the metadata panel of the open article
+func (m Model) toggleMetadata() (tea.Model, tea.Cmd) {
+	m.showMetadata = !m.showMetadata
+	if !m.showMetadata {
+		// Forget the breakdown so reopening the panel reflects any
+		// interest changes since
+		m.breakdownFor = 0
+	}
+	i, ok := m.list.SelectedItem().(articleItem)
+	if !ok {
+		return m, nil
+	}
+	m = m.refreshDetail(i.article)
+	return m, m.explainMetadata(i.article)
+}
+
+// explainMetadata starts computing the score breakdown for an article when
+// the metadata panel is shown and it isn't known yet
+func (m Model) explainMetadata(article models.Article) tea.Cmd {
+	if !m.showMetadata || m.aiClient == nil || m.breakdownFor == article.ID {
+		return nil
+	}
+	return explainScore(m.aiClient, article)
+}
+
+func (m Model) handleScoreExplained(msg scoreExplainedMsg) (tea.Model, tea.Cmd) {
+	m.breakdownFor = msg.articleID
+	m.breakdown = msg.breakdown
+	m.breakdownErr = msg.err
+	if i, ok := m.list.SelectedItem().(articleItem); ok && i.article.ID == msg.articleID && m.view == ViewArticleDetail {
+		m = m.refreshDetail(i.article)
+	}
+	if msg.breakdown != nil && msg.breakdown.Skipped != nil {
+		return m.showToast(severityWarning, firstProblem(msg.breakdown.Skipped))
+	}
+	return m, nil
+}
+
+// refreshDetail re-renders the open article, keeping the scroll position
+func (m Model) refreshDetail(article models.Article) Model {
+	offset := m.viewport.YOffset
+	m.articleContent = m.formatArticleForView(article)
+	m.viewport.SetContent(m.articleContent)
+	m.viewport.SetYOffset(offset)
+	return m
+}
+
+// renderMetadata lists everything known about an article, to help explain
+// why it ranked where it did
+func (m Model) renderMetadata(article models.Article) string {
+	var s strings.Builder
+	row := func(label, value string) {
+		if value == "" {
+			value = helpStyle.Render("-")
+		}
+		s.WriteString(fmt.Sprintf("%-10s %s\n", label+":", value))
+	}
+
+	row("Feed", article.FeedName)
+	row("Author", article.Author)
+	row("Tags", strings.Join(article.Tags, ", "))
+	row("GUID", article.GUID)
+	row("Published", article.PublishedAt.Local().Format("Jan 2, 2006 15:04"))
+	row("Fetched", article.FetchedAt.Local().Format("Jan 2, 2006 15:04"))
+	row("Words", fmt.Sprintf("%d", wordCount(article)))
+	row("URL", article.URL)
+	row("Comments", article.CommentsURL)
+
+	scored := "not scored yet"
+	if article.Scored {
+		scored = fmt.Sprintf("%.3f", article.RelevanceScore)
+	}
+	row("Score", scored)
+
+	switch {
+	case m.breakdownFor != article.ID:
+		s.WriteString(helpStyle.Render("Computing score breakdown..."))
+	case m.breakdownErr != nil:
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Score breakdown unavailable: %v", m.breakdownErr)))
+	default:
+		s.WriteString(renderBreakdown(m.breakdown, article))
+	}
+
+	return metadataStyle.Render(strings.TrimRight(s.String(), "\n"))
+}
+
+// renderBreakdown shows the group scores, the avoid penalty and the closest
+// interests. The stored score differs from the AI score when a scoring
+// script adjusted it or the interests changed since.
+func renderBreakdown(b *ai.ScoreBreakdown, article models.Article) string {
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("AI score:  %.3f", b.Score))
+	if article.Scored && math.Abs(b.Score-article.RelevanceScore) >= 0.001 {
+		s.WriteString(helpStyle.Render(" (differs from the stored score: adjusted by script or interests changed)"))
+	}
+	s.WriteString("\n")
+
+	groups := make([]string, 0, len(b.Groups))
+	for name := range b.Groups {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+	if len(groups) > 1 {
+		for _, name := range groups {
+			label := name
+			if label == "" {
+				label = "(ungrouped)"
+			}
+			s.WriteString(fmt.Sprintf("  group %-20s %.3f\n", label, b.Groups[name]))
+		}
+	}
+	if b.Penalty > 0 {
+		s.WriteString(fmt.Sprintf("  %-27s-%.3f\n", "avoid penalty", b.Penalty))
+	}
+
+	s.WriteString("Closest interests:\n")
+	for i, match := range b.Matches {
+		if i == metadataMatches {
+			break
+		}
+		desc := match.Interest.Description
+		if match.Interest.Avoid {
+			desc = "avoid: " + desc
+		} else if match.Interest.Group != "" {
+			desc = match.Interest.Group + ": " + desc
+		}
+		s.WriteString(fmt.Sprintf("  %.3f  ×%.1f  %s\n", match.Similarity, match.Interest.Weight, desc))
+	}
+	return s.String()
+}
+
+// wordCount counts the words of the article body, or of the description
+// when the feed has no content
+func wordCount(article models.Article) int {
+	text := article.Content
+	if text == "" {
+		text = article.Description
+	}
+	return len(strings.Fields(snippet(text)))
+}
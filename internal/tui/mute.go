@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+type mutedMsg struct {
+	mute   models.Mute
+	hidden int
+}
+
+// parseMuteTerm turns prompt input into a mute rule; /text/ is a regex
+func parseMuteTerm(term string) models.Mute {
+	term = strings.TrimSpace(term)
+	if len(term) > 2 && strings.HasPrefix(term, "/") && strings.HasSuffix(term, "/") {
+		return models.Mute{Kind: models.MuteRegex, Value: term[1 : len(term)-1]}
+	}
+	return models.Mute{Kind: models.MuteKeyword, Value: term}
+}
+
+// addMute stores a mute rule, reloads the fetcher's rules and hides
+// already stored articles that now match
+func addMute(db database.Store, fetcher *feed.Fetcher, articles []models.Article, mute models.Mute) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := feed.NewMuteMatcher([]models.Mute{mute}); err != nil {
+			return errorMsg{err}
+		}
+		if err := db.AddMute(&mute); err != nil {
+			return errorMsg{err}
+		}
+		if err := fetcher.LoadMutes(); err != nil {
+			return errorMsg{err}
+		}
+
+		hidden := 0
+		for i := range articles {
+			if !fetcher.Mutes().Match(&articles[i]) {
+				continue
+			}
+			if err := db.MarkArticleMuted(articles[i].ID); err != nil {
+				return errorMsg{err}
+			}
+			hidden++
+		}
+		return mutedMsg{mute: mute, hidden: hidden}
+	}
+}
+
+// startMuting opens the mute term prompt
+func (m Model) startMuting() (tea.Model, tea.Cmd) {
+	m.isMuting = true
+	m.muteInput.SetValue("")
+	m.muteInput.Focus()
+	return m, textinput.Blink
+}
+
+// muteDomain mutes the domain of the given article
+func (m Model) muteDomain(article models.Article) (tea.Model, tea.Cmd) {
+	domain := feed.ArticleDomain(article.URL)
+	if domain == "" {
+		m.statusMsg = "Article has no domain to mute"
+		return m, nil
+	}
+	return m, addMute(m.db, m.fetcher, m.allArticles, models.Mute{Kind: models.MuteDomain, Value: domain})
+}
+
+func (m Model) handleMuteInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.isMuting = false
+		m.muteInput.Blur()
+		return m, nil
+	case "enter":
+		m.isMuting = false
+		m.muteInput.Blur()
+		mute := parseMuteTerm(m.muteInput.Value())
+		if mute.Value == "" {
+			return m, nil
+		}
+		return m, addMute(m.db, m.fetcher, m.allArticles, mute)
+	}
+
+	var cmd tea.Cmd
+	m.muteInput, cmd = m.muteInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) renderMuteInput() string {
+	return filterStyle.Render("Mute: ") + m.muteInput.View() +
+		helpStyle.Render(" (keyword or /regex/, enter: mute, esc: cancel)") + "\n\n"
+}
+
+func mutedStatus(msg mutedMsg) string {
+	return fmt.Sprintf("Muted %s %q (%d articles hidden)", msg.mute.Kind, msg.mute.Value, msg.hidden)
+}
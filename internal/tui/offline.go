@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+)
+
+// connectivityTimeout bounds each reachability probe at startup
+const connectivityTimeout = 5 * time.Second
+
+var offlineStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("0")).
+	Background(lipgloss.Color("214")).
+	Padding(0, 1)
+
+type connectivityMsg struct {
+	network bool
+	ollama  bool
+	manual  bool
+}
+
+// checkConnectivity probes the feeds and Ollama; the result decides whether
+// a fetch and scoring run are attempted
+func checkConnectivity(fetcher *feed.Fetcher, aiClient *ai.Client, manual bool) tea.Cmd {
+	return func() tea.Msg {
+		network := fetcher.CheckConnectivity(connectivityTimeout) == nil
+		ollama := aiClient.Ping(connectivityTimeout) == nil
+		return connectivityMsg{network: network, ollama: ollama, manual: manual}
+	}
+}
+
+// handleConnectivity records the connection state and starts a fetch when online
+func (m Model) handleConnectivity(msg connectivityMsg) (tea.Model, tea.Cmd) {
+	m.checkedConnection = true
+	m.online = msg.network
+	m.ollamaOnline = msg.ollama
+
+	if !m.online {
+		return m.showToast(severityWarning, "Offline: showing cached articles (press F to retry)")
+	}
+	if m.readOnly != "" {
+		return m.showReadOnly()
+	}
+	fetch := m.sender.track(fetchFeeds(m.sender.Send, m.fetcher, m.db, m.scoringClient(), m.notifier, m.cfg, msg.manual))
+	if !m.ollamaOnline {
+		var toastCmd tea.Cmd
+		m, toastCmd = m.showToast(severityWarning, "Ollama unreachable: fetching without scoring")
+		return m, tea.Batch(fetch, toastCmd)
+	}
+	return m, fetch
+}
+
+// startFetch fetches feeds, re-checking connectivity first when offline
+func (m Model) startFetch() (tea.Model, tea.Cmd) {
+	if m.readOnly != "" {
+		return m.showReadOnly()
+	}
+	if !m.online || !m.ollamaOnline {
+		m.statusMsg = "Checking connection..."
+		return m, checkConnectivity(m.fetcher, m.aiClient, true)
+	}
+	return m, tea.Batch(
+		m.sender.track(fetchFeeds(m.sender.Send, m.fetcher, m.db, m.scoringClient(), m.notifier, m.cfg, true)),
+		func() tea.Msg { return statusMsg("Fetching new articles...") },
+	)
+}
+
+// scoringClient returns the AI client, or nil when Ollama is unreachable
+func (m Model) scoringClient() *ai.Client {
+	if !m.ollamaOnline {
+		return nil
+	}
+	return m.aiClient
+}
+
+// connectionBadge renders an indicator when running degraded
+func (m Model) connectionBadge() string {
+	switch {
+	case m.readOnly != "":
+		return offlineStyle.Render("READ-ONLY") + " "
+	case !m.checkedConnection:
+		return ""
+	case !m.online:
+		return offlineStyle.Render("OFFLINE") + " "
+	case !m.ollamaOnline:
+		return offlineStyle.Render("NO AI") + " "
+	}
+	return ""
+}
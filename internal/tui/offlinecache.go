@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+type offlineCachedMsg struct {
+	articles []models.Article
+	err      error
+}
+
+// cacheOffline fetches the full text and images of queued and starred
+// articles not yet cached
+func cacheOffline(fetcher *feed.Fetcher) tea.Cmd {
+	return func() tea.Msg {
+		articles, err := fetcher.CacheOffline()
+		return offlineCachedMsg{articles: articles, err: err}
+	}
+}
+
+// cacheOfflineNow caches a newly queued or starred article straight away,
+// unless this instance can't fetch
+func (m Model) cacheOfflineNow() tea.Cmd {
+	if m.readOnly != "" || !m.online || m.cfg.Offline.MaxMB < 0 {
+		return nil
+	}
+	return cacheOffline(m.fetcher)
+}
+
+func (m Model) handleOfflineCached(msg offlineCachedMsg) (tea.Model, tea.Cmd) {
+	for _, article := range msg.articles {
+		m = m.setArticleContent(article)
+	}
+	if msg.err != nil {
+		return m.showToast(severityError, msg.err.Error())
+	}
+	if len(msg.articles) == 1 {
+		m.statusMsg = "Saved for offline reading"
+	} else if len(msg.articles) > 1 {
+		m.statusMsg = fmt.Sprintf("Saved %d articles for offline reading", len(msg.articles))
+	}
+	return m, nil
+}
+
+// setArticleContent updates an article's content, author and date in place,
+// redrawing it when open
+func (m Model) setArticleContent(article models.Article) Model {
+	m.rendered.forget(article.ID)
+	update := func(a *models.Article) {
+		a.Content = article.Content
+		a.Author = article.Author
+		a.PublishedAt = article.PublishedAt
+	}
+	for i := range m.allArticles {
+		if m.allArticles[i].ID == article.ID {
+			update(&m.allArticles[i])
+		}
+	}
+	for i := range m.articles {
+		if m.articles[i].ID == article.ID {
+			update(&m.articles[i])
+			m.updateArticleItem(m.articles[i])
+		}
+	}
+	if i, ok := m.list.SelectedItem().(articleItem); ok && i.article.ID == article.ID && m.view == ViewArticleDetail {
+		m = m.refreshDetail(i.article)
+	}
+	return m
+}
@@ -0,0 +1,271 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// wideWidth is the terminal width from which the article list is shown
+// between a feeds pane and a preview of the selected article
+const wideWidth = 160
+
+// feedsPaneWidth is the width of the feeds pane
+const feedsPaneWidth = 28
+
+// pane is the pane of the wide layout with the focus
+type pane int
+
+const (
+	paneArticles pane = iota
+	panePreview
+	paneFeeds
+)
+
+// Titles of the panes, dimmed in those without the focus. The blank line
+// titleStyle leaves below would truncate the title of the narrower list.
+var (
+	paneTitleStyle      = titleStyle.MarginBottom(0)
+	unfocusedTitleStyle = paneTitleStyle.Foreground(lipgloss.Color("241"))
+)
+
+// feedEntry is a row of the feeds pane; the first, with no name, stands for
+// all feeds
+type feedEntry struct {
+	name  string
+	count int
+}
+
+// wide reports whether the terminal is wide enough for three panes
+func (m Model) wide() bool {
+	return m.width >= wideWidth
+}
+
+// paneWidths returns the widths of the feeds, articles and preview panes,
+// leaving a column between them for a separator
+func (m Model) paneWidths() (feeds, articles, preview int) {
+	rest := m.width - feedsPaneWidth - 2
+	articles = rest * 45 / 100
+	return feedsPaneWidth, articles, rest - articles
+}
+
+// listWidth is the width of the article list, the whole terminal unless
+// it's wide
+func (m Model) listWidth() int {
+	if !m.wide() {
+		return m.width
+	}
+	_, articles, _ := m.paneWidths()
+	return articles
+}
+
+// shownArticles are the articles listed: those left by the filter and
+// search, narrowed to the feed picked in the feeds pane
+func (m Model) shownArticles() []models.Article {
+	if !m.wide() || m.feedFilter == "" {
+		return m.articles
+	}
+	var shown []models.Article
+	for _, a := range m.articles {
+		if a.FeedName == m.feedFilter {
+			shown = append(shown, a)
+		}
+	}
+	return shown
+}
+
+// feedEntries lists the feeds with unread articles, by name
+func (m Model) feedEntries() []feedEntry {
+	counts := map[string]int{}
+	for _, a := range m.allArticles {
+		counts[a.FeedName]++
+	}
+	entries := []feedEntry{{count: len(m.allArticles)}}
+	for name, count := range counts {
+		if name != "" {
+			entries = append(entries, feedEntry{name, count})
+		}
+	}
+	sort.Slice(entries[1:], func(i, j int) bool {
+		return strings.ToLower(entries[i+1].name) < strings.ToLower(entries[j+1].name)
+	})
+	return entries
+}
+
+// resizePanes fits the preview to the window. Going from the wide layout
+// to the narrow one or back, the list is rebuilt as the feed picked in the
+// feeds pane only applies to the wide layout.
+func (m Model) resizePanes(wasWide bool) Model {
+	if m.wide() {
+		_, _, preview := m.paneWidths()
+		m.preview.Width = preview
+		m.preview.Height = max(m.height-6, 1)
+		m.previewFor = 0
+	}
+	if m.wide() != wasWide {
+		m = m.focusPane(paneArticles)
+		m = m.setListItems()
+	}
+	return m
+}
+
+// focusPane moves the focus to a pane, dimming the title of the article
+// list while it doesn't have it
+func (m Model) focusPane(p pane) Model {
+	m.pane = p
+	switch {
+	case !m.wide():
+		m.list.Styles.Title = titleStyle
+	case p == paneArticles:
+		m.list.Styles.Title = paneTitleStyle
+	default:
+		m.list.Styles.Title = unfocusedTitleStyle
+	}
+	return m
+}
+
+// handlePaneKeys handles the keys of the wide layout: tab moves the focus,
+// the arrows move through feeds or scroll the preview when one of them has
+// it. It reports whether it handled the key; the others act on the
+// selected article as usual.
+func (m Model) handlePaneKeys(msg tea.KeyMsg) (Model, bool) {
+	if key.Matches(msg, keys.List.Pane) {
+		if msg.String() == "shift+tab" {
+			return m.focusPane((m.pane + 2) % 3), true
+		}
+		return m.focusPane((m.pane + 1) % 3), true
+	}
+
+	switch m.pane {
+	case paneFeeds:
+		switch {
+		case key.Matches(msg, upKey):
+			return m.pickFeed(-1), true
+		case key.Matches(msg, downKey):
+			return m.pickFeed(1), true
+		case key.Matches(msg, keys.List.Open):
+			return m.focusPane(paneArticles), true
+		}
+
+	case panePreview:
+		switch {
+		case key.Matches(msg, upKey):
+			m.preview.LineUp(1)
+		case key.Matches(msg, downKey):
+			m.preview.LineDown(1)
+		case msg.String() == "pgup":
+			m.preview.PageUp()
+		case msg.String() == "pgdown" || msg.String() == " ":
+			m.preview.PageDown()
+		case msg.String() == "home":
+			m.preview.GotoTop()
+		case msg.String() == "end":
+			m.preview.GotoBottom()
+		default:
+			return m, false
+		}
+		return m, true
+	}
+	return m, false
+}
+
+// pickFeed moves the selection in the feeds pane, listing the articles of
+// the newly selected feed
+func (m Model) pickFeed(delta int) Model {
+	entries := m.feedEntries()
+	i := 0
+	for j, e := range entries {
+		if e.name == m.feedFilter {
+			i = j
+		}
+	}
+	i = max(0, min(i+delta, len(entries)-1))
+	m.feedFilter = entries[i].name
+	m.list.SetItems(m.listItems())
+	m.list.ResetSelected()
+	return m
+}
+
+// updatePreview shows the selected article in the preview pane when it
+// changed
+func (m Model) updatePreview() Model {
+	if !m.wide() || m.view != ViewArticleList {
+		return m
+	}
+	i, ok := m.list.SelectedItem().(articleItem)
+	id := int64(-1)
+	if ok {
+		id = i.article.ID
+	}
+	if id == m.previewFor {
+		return m
+	}
+	m.previewFor = id
+	if !ok {
+		m.preview.SetContent("")
+		return m
+	}
+	article, err := withContent(m.db, i.article)
+	if err != nil {
+		m.preview.SetContent(errorStyle.Render(err.Error()))
+		return m
+	}
+	pm := m
+	pm.viewport.Width = m.preview.Width
+	m.preview.SetContent(pm.formatArticleForView(article))
+	m.preview.GotoTop()
+	return m
+}
+
+// renderPanes lays out the feeds pane, the article list and the preview
+// side by side
+func (m Model) renderPanes() string {
+	feedsWidth, articlesWidth, previewWidth := m.paneWidths()
+	height := m.height - 4
+
+	header := func(title string, p pane) string {
+		if m.pane == p {
+			return paneTitleStyle.Render(title) + "\n\n"
+		}
+		return unfocusedTitleStyle.Render(title) + "\n\n"
+	}
+
+	var feeds strings.Builder
+	feeds.WriteString(header("Feeds", paneFeeds))
+	entries := m.feedEntries()
+	cursor := 0
+	for i, e := range entries {
+		if e.name == m.feedFilter {
+			cursor = i
+		}
+	}
+	rows := max(height-2, 1)
+	for i := max(0, cursor-rows+1); i < len(entries) && i < max(0, cursor-rows+1)+rows; i++ {
+		name := entries[i].name
+		if name == "" {
+			name = "All feeds"
+		}
+		row := truncate(fmt.Sprintf("%s (%d)", name, entries[i].count), feedsWidth-2)
+		if i == cursor {
+			feeds.WriteString("> " + filterStyle.Render(row) + "\n")
+		} else {
+			feeds.WriteString("  " + row + "\n")
+		}
+	}
+
+	preview := header("Preview", panePreview) + m.preview.View()
+
+	column := func(s string, width int) string {
+		return lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Render(s)
+	}
+	separator := helpStyle.Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		column(feeds.String(), feedsWidth), separator,
+		column(m.list.View(), articlesWidth), separator,
+		column(preview, previewWidth))
+}
@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+type pdfDownloadedMsg struct {
+	path string
+}
+
+// fetchPDF downloads the PDF of a paper
+func fetchPDF(fetcher *feed.Fetcher, article models.Article) tea.Cmd {
+	return func() tea.Msg {
+		path, err := fetcher.DownloadPDF(article)
+		if errors.Is(err, feed.ErrNoPDF) {
+			err = fmt.Errorf("%w (only arXiv papers and journals naming their PDF have one)", err)
+		}
+		if err != nil {
+			return errorMsg{err}
+		}
+		return pdfDownloadedMsg{path}
+	}
+}
+
+// downloadPDF saves the PDF of the open article in papers.pdf_dir
+func (m Model) downloadPDF(article models.Article) (tea.Model, tea.Cmd) {
+	if m.checkedConnection && !m.online {
+		m.statusMsg = "Offline: the PDF can't be downloaded"
+		return m, nil
+	}
+	m.statusMsg = "Downloading the PDF..."
+	return m, fetchPDF(m.fetcher, article)
+}
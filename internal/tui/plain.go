@@ -0,0 +1,447 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/internal/hooks"
+	"github.com/thomaskoefod/newsreadr/internal/notify"
+	"github.com/thomaskoefod/newsreadr/internal/texmath"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+	"golang.org/x/term"
+)
+
+// Plain is the reader for screen readers and braille displays, started with
+// -plain. It prints plain lines, without colors, box drawing or the
+// alternate screen, and reads a command per line: articles are listed a
+// line each and picked by number, and read as text a page at a time.
+type Plain struct {
+	cfg      *config.Config
+	db       database.Store
+	fetcher  *feed.Fetcher
+	aiClient *ai.Client
+	notifier *notify.Dispatcher
+	hooks    *hooks.Runner
+	readOnly string
+
+	in        *bufio.Scanner
+	out       io.Writer
+	width     int
+	pageLines int
+
+	articles    []models.Article
+	calibration scoreCalibration
+	page        int
+}
+
+const plainListHelp = `Commands:
+  NUMBER  read that article
+  n, p    next or previous page of the list
+  l       list the page again
+  r       reload the list
+  f       fetch new articles
+  h       this help
+  q       quit`
+
+const plainReadHelp = `Commands:
+  enter   next page
+  b       previous page
+  m       mark read and go back to the list
+  s       star or unstar
+  o       open in the browser
+  h       this help
+  q       back to the list`
+
+func NewPlain(cfg *config.Config, db database.Store, fetcher *feed.Fetcher, aiClient *ai.Client, notifier *notify.Dispatcher) *Plain {
+	return &Plain{
+		cfg:      cfg,
+		db:       db,
+		fetcher:  fetcher,
+		aiClient: aiClient,
+		notifier: notifier,
+		hooks:    hooks.New(cfg.Hooks),
+	}
+}
+
+// ReadOnly leaves fetching to the instance holding the database lock
+func (p *Plain) ReadOnly(reason error) {
+	p.readOnly = reason.Error()
+}
+
+// Run reads commands from in until q or the end of the input. Lines are
+// wrapped and pages sized to the terminal when out is one.
+func (p *Plain) Run(in io.Reader, out io.Writer) error {
+	p.in, p.out = bufio.NewScanner(in), out
+	p.width, p.pageLines = 80, 20
+	if f, ok := out.(*os.File); ok {
+		if w, h, err := term.GetSize(int(f.Fd())); err == nil {
+			p.width, p.pageLines = max(w, 20), max(h-2, 5)
+		}
+	}
+
+	if p.readOnly != "" {
+		p.println("Read-only: " + p.readOnly + "; it fetches for both.")
+	}
+	if err := p.reload(); err != nil {
+		return err
+	}
+	p.println("Type a number to read an article, or h for help.")
+	p.listPage()
+
+	for {
+		line, ok := p.prompt("> ")
+		if !ok {
+			return nil
+		}
+		switch line {
+		case "":
+		case "q":
+			return nil
+		case "h", "?":
+			p.println(plainListHelp)
+		case "n":
+			if (p.page+1)*p.pageLines < len(p.articles) {
+				p.page++
+			}
+			p.listPage()
+		case "p":
+			p.page = max(p.page-1, 0)
+			p.listPage()
+		case "l":
+			p.listPage()
+		case "r":
+			if err := p.reload(); err != nil {
+				p.println("Error: " + err.Error())
+			}
+			p.listPage()
+		case "f":
+			p.fetch()
+			p.listPage()
+		default:
+			n, err := strconv.Atoi(line)
+			if err != nil || n < 1 || n > len(p.articles) {
+				p.println(fmt.Sprintf("No article %s. Type a number from 1 to %d, or h for help.", line, len(p.articles)))
+				continue
+			}
+			if !p.read(n - 1) {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *Plain) println(s string) {
+	fmt.Fprintln(p.out, s)
+}
+
+// prompt asks for a command, returning false at the end of the input
+func (p *Plain) prompt(prompt string) (string, bool) {
+	fmt.Fprint(p.out, prompt)
+	if !p.in.Scan() {
+		p.println("")
+		return "", false
+	}
+	return strings.ToLower(strings.TrimSpace(p.in.Text())), true
+}
+
+// reload loads the unread articles
+func (p *Plain) reload() error {
+	maxAge := time.Duration(p.cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
+	articles, err := p.db.GetUnreadArticleList(maxAge)
+	if err != nil {
+		return err
+	}
+	p.articles = articles
+	p.calibration = newScoreCalibration(p.cfg.Scoring, articles)
+	p.page = 0
+	p.println(fmt.Sprintf("%d unread articles.", len(articles)))
+	return nil
+}
+
+// listPage lists the current page of articles, a line each
+func (p *Plain) listPage() {
+	if len(p.articles) == 0 {
+		p.println("No articles. Type f to fetch new ones.")
+		return
+	}
+	start := p.page * p.pageLines
+	end := min(start+p.pageLines, len(p.articles))
+	for i := start; i < end; i++ {
+		p.println(fmt.Sprintf("%d. %s", i+1, plainSummary(p.articles[i], p.calibration)))
+	}
+	pages := (len(p.articles) + p.pageLines - 1) / p.pageLines
+	p.println(fmt.Sprintf("Page %d of %d.", p.page+1, pages))
+}
+
+// plainSummary describes an article on one line: its title, then its
+// plainDetails
+func plainSummary(a models.Article, c scoreCalibration) string {
+	title := strings.Join(strings.Fields(a.Title), " ")
+	details := plainDetails(a, c)
+	switch {
+	case details == "":
+		return title
+	case strings.HasSuffix(title, ".") || strings.HasSuffix(title, "?") || strings.HasSuffix(title, "!"):
+		return title + " " + details
+	}
+	return title + ". " + details
+}
+
+// plainDetails lists an article's feed, score and whether it is starred or
+// queued. The score is spelled out as its band, not drawn as stars.
+func plainDetails(a models.Article, c scoreCalibration) string {
+	var details []string
+	if a.FeedName != "" {
+		details = append(details, a.FeedName)
+	}
+	if a.Scored {
+		details = append(details, c.describe(a))
+	}
+	if a.Starred {
+		details = append(details, "starred")
+	}
+	if a.Queued {
+		details = append(details, "queued")
+	}
+	return strings.Join(details, ", ")
+}
+
+// read pages through an article until q or m, returning false at the end
+// of the input
+func (p *Plain) read(i int) bool {
+	article, err := withContent(p.db, p.articles[i])
+	if err != nil {
+		p.println("Error: " + err.Error())
+	}
+	lines := p.articleLines(article)
+	pages := max((len(lines)+p.pageLines-1)/p.pageLines, 1)
+	page := 0
+	show := true
+	for {
+		if show {
+			start := page * p.pageLines
+			for _, line := range lines[start:min(start+p.pageLines, len(lines))] {
+				p.println(line)
+			}
+			if page == pages-1 {
+				p.println(fmt.Sprintf("End of article, page %d of %d. Type m to mark it read, q for the list, h for help.", page+1, pages))
+			} else {
+				p.println(fmt.Sprintf("Page %d of %d. Press enter for more, q for the list, h for help.", page+1, pages))
+			}
+		}
+		show = false
+
+		line, ok := p.prompt("> ")
+		if !ok {
+			return false
+		}
+		switch line {
+		case "":
+			if page < pages-1 {
+				page++
+				show = true
+			}
+		case "b":
+			page = max(page-1, 0)
+			show = true
+		case "q":
+			p.listPage()
+			return true
+		case "h", "?":
+			p.println(plainReadHelp)
+		case "m":
+			// Marking read moves the article to the trash, as in the reader
+			if err := p.db.MarkArticleRead(article.ID); err != nil {
+				p.println("Error: " + err.Error())
+				continue
+			}
+			if err := p.db.DeleteReadArticles(); err != nil {
+				p.println("Error: " + err.Error())
+			}
+			if err := p.hooks.Fire(hooks.ArticleRead, &article); err != nil {
+				p.println("Error: " + err.Error())
+			}
+			p.articles = append(p.articles[:i:i], p.articles[i+1:]...)
+			p.page = min(p.page, max((len(p.articles)-1)/p.pageLines, 0))
+			p.println("Marked as read.")
+			p.listPage()
+			return true
+		case "s":
+			err := p.toggleStar(&article)
+			p.articles[i] = article
+			if article.Starred {
+				p.println("Starred.")
+			} else {
+				p.println("Unstarred.")
+			}
+			if err != nil {
+				p.println("Error: " + err.Error())
+			}
+		case "o":
+			if err := p.open(article); err != nil {
+				p.println("Error: " + err.Error())
+				continue
+			}
+			p.println("Opened in the browser.")
+		default:
+			p.println("Unknown command " + line + ". Type h for help.")
+		}
+	}
+}
+
+// articleLines is the text of an article, wrapped to the terminal, after
+// its title, details, date and URL
+func (p *Plain) articleLines(a models.Article) []string {
+	header := []string{strings.Join(strings.Fields(a.Title), " ")}
+	if details := plainDetails(a, p.calibration); details != "" {
+		header = append(header, details)
+	}
+	if !a.PublishedAt.IsZero() {
+		header = append(header, "Published "+a.PublishedAt.Format("January 2, 2006"))
+	}
+	if a.URL != "" {
+		header = append(header, a.URL)
+	}
+	content := a.Content
+	if content == "" {
+		content = a.Description
+	}
+	text := plainText(texmath.HTML(content))
+	if text == "" {
+		text = "This article has no text; type o to open it in the browser."
+	}
+
+	var lines []string
+	for _, line := range header {
+		lines = append(lines, wrapWords(line, p.width-1)...)
+	}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		lines = append(lines, "")
+		lines = append(lines, wrapWords(paragraph, p.width-1)...)
+	}
+	return lines
+}
+
+// wrapWords wraps text at spaces to lines of at most width cells, leaving
+// longer words whole
+func wrapWords(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(text) {
+		if line.Len() > 0 && cellWidth.StringWidth(line.String())+1+cellWidth.StringWidth(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// fetch fetches, scores and cleans up articles as the reader does, then
+// reloads the list
+func (p *Plain) fetch() {
+	if p.readOnly != "" {
+		p.println("Read-only: " + p.readOnly + "; it fetches for both.")
+		return
+	}
+	if p.fetcher.CheckConnectivity(connectivityTimeout) != nil {
+		p.println("Offline: showing cached articles.")
+		return
+	}
+	scoring := p.aiClient
+	if p.aiClient.Ping(connectivityTimeout) != nil {
+		p.println("Ollama unreachable: fetching without scoring.")
+		scoring = nil
+	}
+	p.println("Fetching new articles...")
+
+	send := func(msg tea.Msg) {
+		if err, ok := msg.(errorMsg); ok {
+			p.println("Error: " + err.err.Error())
+		}
+	}
+	switch msg := fetchFeeds(send, p.fetcher, p.db, scoring, p.notifier, p.cfg, true)().(type) {
+	case errorMsg:
+		p.println("Fetch failed: " + msg.err.Error())
+	case fetchDoneMsg:
+		p.println(fetchStatus(msg.summary) + ".")
+		for _, r := range msg.summary.Results {
+			if r.Err != nil {
+				p.println(fmt.Sprintf("%s failed: %v", r.FeedName, r.Err))
+			}
+		}
+		if err := msg.summary.HookErr(); err != nil {
+			p.println(err.Error())
+		}
+		if err := msg.summary.ExtractErr(); err != nil {
+			p.println(err.Error())
+		}
+		if msg.notifyErr != nil {
+			p.println("Notification failed: " + msg.notifyErr.Error())
+		}
+	}
+	if err := p.reload(); err != nil {
+		p.println("Error: " + err.Error())
+	}
+}
+
+// toggleStar stars or unstars an article
+func (p *Plain) toggleStar(article *models.Article) error {
+	if article.Starred {
+		if err := p.db.UnstarArticle(article.URL); err != nil {
+			return err
+		}
+		article.Starred = false
+		return nil
+	}
+	if err := p.db.StarArticle(article.ID); err != nil {
+		return err
+	}
+	article.Starred = true
+	return p.hooks.Fire(hooks.ArticleSaved, article)
+}
+
+// open opens an article in open.browser, or the default browser. A
+// terminal browser runs in this terminal until it exits.
+func (p *Plain) open(article models.Article) error {
+	articleURL, err := browserURL(p.cfg.Open, article)
+	if err != nil {
+		return err
+	}
+	if p.cfg.Open.Browser == "" {
+		err = openBrowser(articleURL)
+	} else {
+		args, argsErr := p.cfg.Open.BrowserArgs(articleURL)
+		if argsErr != nil {
+			return fmt.Errorf("open.browser: %w", argsErr)
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		if p.cfg.Open.TerminalBrowser() {
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+			err = cmd.Run()
+		} else {
+			err = startBrowser(cmd)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return p.db.RecordArticleOpen(article.ID)
+}
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// renderCacheSize is how many rendered articles are kept
+const renderCacheSize = 32
+
+// prefetchDelay is how long the cursor has to rest on an article before it
+// is rendered in the background, so scrolling through the list doesn't
+// render every article passed
+const prefetchDelay = 250 * time.Millisecond
+
+// renderKey identifies an article rendered by a renderer of the pool, at
+// its width and in its theme
+type renderKey struct {
+	id int64
+	rendererKey
+}
+
+// renderCache keeps the most recently used rendered article bodies. Copies
+// of the model and background renders share it, hence the lock.
+type renderCache struct {
+	mu     sync.Mutex
+	bodies map[renderKey]string
+	// recent lists the keys, most recently used last
+	recent []renderKey
+	size   int
+}
+
+func newRenderCache(size int) *renderCache {
+	return &renderCache{bodies: map[renderKey]string{}, size: size}
+}
+
+// get returns a rendered body, marking it used
+func (c *renderCache) get(key renderKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	body, ok := c.bodies[key]
+	if ok {
+		c.touch(key)
+	}
+	return body, ok
+}
+
+// put stores a rendered body, evicting the least recently used beyond the
+// size of the cache
+func (c *renderCache) put(key renderKey, body string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bodies[key] = body
+	c.touch(key)
+	for len(c.recent) > c.size {
+		delete(c.bodies, c.recent[0])
+		c.recent = c.recent[1:]
+	}
+}
+
+// forget drops an article at every width and theme, when its content
+// changed
+func (c *renderCache) forget(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recent = slices.DeleteFunc(c.recent, func(k renderKey) bool {
+		if k.id == id {
+			delete(c.bodies, k)
+			return true
+		}
+		return false
+	})
+}
+
+// retain drops the articles not rendered by one of the given renderers,
+// after a resize or a change of theme
+func (c *renderCache) retain(renderers ...rendererKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recent = slices.DeleteFunc(c.recent, func(k renderKey) bool {
+		if !slices.Contains(renderers, k.rendererKey) {
+			delete(c.bodies, k)
+			return true
+		}
+		return false
+	})
+}
+
+// touch moves a key to the end of recent
+func (c *renderCache) touch(key renderKey) {
+	if i := slices.Index(c.recent, key); i >= 0 {
+		c.recent = slices.Delete(c.recent, i, i+1)
+	}
+	c.recent = append(c.recent, key)
+}
+
+// prefetchDueMsg asks for an article to be rendered once the cursor rested
+// on it
+type prefetchDueMsg struct {
+	id int64
+}
+
+// renderKey is the key of an article rendered for the article view
+func (m Model) renderKey(article models.Article) renderKey {
+	return renderKey{article.ID, m.renderers.key(m.viewport.Width)}
+}
+
+// renderKeys are the renderers articles are shown with: the article view's
+// and the preview's in the wide layout
+func (m Model) renderKeys() []rendererKey {
+	keys := []rendererKey{m.renderers.key(m.viewport.Width)}
+	if m.wide() {
+		keys = append(keys, m.renderers.key(m.preview.Width))
+	}
+	return keys
+}
+
+// schedulePrefetch waits for the cursor to rest on a newly selected article
+// in the list before rendering it
+func (m Model) schedulePrefetch() (Model, tea.Cmd) {
+	if m.view != ViewArticleList || !m.ready {
+		return m, nil
+	}
+	i, ok := m.list.SelectedItem().(articleItem)
+	if !ok || i.article.ID == m.prefetchFor {
+		return m, nil
+	}
+	m.prefetchFor = i.article.ID
+	if _, ok := m.rendered.get(m.renderKey(i.article)); ok {
+		return m, nil
+	}
+	id := i.article.ID
+	return m, tea.Tick(prefetchDelay, func(time.Time) tea.Msg {
+		return prefetchDueMsg{id}
+	})
+}
+
+// handlePrefetchDue renders the selected article in the background if the
+// cursor is still on it, so opening it is instant
+func (m Model) handlePrefetchDue(msg prefetchDueMsg) (tea.Model, tea.Cmd) {
+	i, ok := m.list.SelectedItem().(articleItem)
+	if !ok || i.article.ID != msg.id || m.view != ViewArticleList {
+		return m, nil
+	}
+	key := m.renderKey(i.article)
+	if _, ok := m.rendered.get(key); ok {
+		return m, nil
+	}
+	article := i.article
+	return m, func() tea.Msg {
+		renderer, err := m.renderers.get(key.rendererKey)
+		if err != nil {
+			return nil
+		}
+		article, err := withContent(m.db, article)
+		if err != nil {
+			return nil
+		}
+		if body, err := renderer.Render(m.bodyMarkdown(article)); err == nil {
+			m.rendered.put(key, body)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// sender delivers messages from background jobs to the running program,
+// so long jobs can report progress before their command returns. It is
+// shared by every copy of the model. It also keeps count of the jobs
+// writing to the database, for quitting to wait on.
+type sender struct {
+	program *tea.Program
+	jobs    sync.WaitGroup
+	// mu guards closed, set once quitting waits on the jobs
+	mu     sync.Mutex
+	closed bool
+}
+
+// Send delivers msg to the program, dropping it when none is attached
+func (s *sender) Send(msg tea.Msg) {
+	if s != nil && s.program != nil {
+		s.program.Send(msg)
+	}
+}
+
+// Attach connects the model to the program running it. Without it, fetches
+// and rescores only report their final result.
+func (m Model) Attach(p *tea.Program) {
+	m.sender.program = p
+}
+
+// track counts a background job from when its command starts until it
+// returns. Commands Bubble Tea never ran aren't waited on, and ones starting
+// after quitting began are skipped, the database being about to close.
+func (s *sender) track(cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil
+		}
+		s.jobs.Add(1)
+		s.mu.Unlock()
+		defer s.jobs.Done()
+		return cmd()
+	}
+}
+
+// Wait waits up to timeout for the fetches and rescores still running once
+// the program quit, after their fetcher and AI client were shut down, so
+// the scores they computed are written before the database is closed. It
+// reports whether they all finished.
+func (m Model) Wait(timeout time.Duration) bool {
+	m.sender.mu.Lock()
+	m.sender.closed = true
+	m.sender.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer m.crash.rescue(nil)
+		m.sender.jobs.Wait()
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// perFeedDoneMsg is sent while fetching after each feed, with the articles
+// it added
+type perFeedDoneMsg struct {
+	result   feed.FeedResult
+	articles []models.Article
+	done     int
+	total    int
+}
+
+// articleScoredMsg is sent each time a batch of scores has been written,
+// with the job's progress as status
+type articleScoredMsg struct {
+	scores map[int64]float64
+	status string
+	// err is what went wrong since the last batch, scoring carrying on
+	err error
+}
+
+// handlePerFeedDone adds a feed's new articles to the list, awaiting scores
+func (m Model) handlePerFeedDone(msg perFeedDoneMsg) (tea.Model, tea.Cmd) {
+	if len(msg.articles) > 0 {
+		m = m.mergeArticles(append(append([]models.Article(nil), m.allArticles...), msg.articles...))
+	}
+	m.statusMsg = fmt.Sprintf("Fetching: %d/%d feeds, %s", msg.done, msg.total, msg.result.FeedName)
+	return m, nil
+}
+
+// handleArticleScored fills in the scores of listed articles in place
+func (m Model) handleArticleScored(msg articleScoredMsg) (tea.Model, tea.Cmd) {
+	if len(msg.scores) > 0 {
+		updated := make([]models.Article, len(m.allArticles))
+		copy(updated, m.allArticles)
+		for i := range updated {
+			if score, ok := msg.scores[updated[i].ID]; ok {
+				updated[i].RelevanceScore = score
+				updated[i].Scored = true
+			}
+		}
+		m = m.mergeArticles(updated)
+	}
+	m.statusMsg = msg.status
+	if msg.err != nil {
+		return m.showToast(severityWarning, firstProblem(msg.err))
+	}
+	return m, nil
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+const (
+	listTitle  = "NewsReadr - Your Personalized News"
+	queueTitle = "NewsReadr - Read Later Queue"
+)
+
+func loadQueue(db database.Store) tea.Cmd {
+	return func() tea.Msg {
+		articles, err := db.GetQueuedArticles()
+		if err != nil {
+			return errorMsg{err}
+		}
+		return articlesLoadedMsg{articles: articles, queue: true}
+	}
+}
+
+// reloadArticles reloads whichever article list is currently shown
+func (m Model) reloadArticles() tea.Cmd {
+	if m.showQueue {
+		return loadQueue(m.db)
+	}
+	return loadArticles(m.db, m.cfg)
+}
+
+// toggleQueueView switches between the unread list and the read-later queue
+func (m Model) toggleQueueView() (tea.Model, tea.Cmd) {
+	m.showQueue = !m.showQueue
+	if m.showQueue {
+		m.list.Title = queueTitle
+	} else {
+		m.list.Title = listTitle
+	}
+	return m, m.reloadArticles()
+}
+
+// toggleQueued adds or removes an article from the read-later queue
+func (m Model) toggleQueued(article models.Article) (tea.Model, tea.Cmd) {
+	queued := !article.Queued
+	if err := m.db.SetArticleQueued(article.ID, queued); err != nil {
+		return m, func() tea.Msg { return errorMsg{err} }
+	}
+
+	if m.showQueue && !queued {
+		m.statusMsg = "Removed from queue"
+		return m, m.reloadArticles()
+	}
+
+	for i := range m.allArticles {
+		if m.allArticles[i].ID == article.ID {
+			m.allArticles[i].Queued = queued
+		}
+	}
+	for i := range m.articles {
+		if m.articles[i].ID == article.ID {
+			m.articles[i].Queued = queued
+			m.updateArticleItem(m.articles[i])
+		}
+	}
+
+	if queued {
+		m.statusMsg = "Added to read-later queue"
+		return m, m.cacheOfflineNow()
+	}
+	m.statusMsg = "Removed from queue"
+	return m, nil
+}
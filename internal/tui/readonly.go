@@ -0,0 +1,16 @@
+package tui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// ReadOnly returns the model set up to leave fetching, scoring and cleanup
+// to the instance holding the database lock; reason is shown when one is
+// attempted. Reading, starring and saving articles still work.
+func (m Model) ReadOnly(reason error) Model {
+	m.readOnly = reason.Error()
+	return m
+}
+
+// showReadOnly explains why a fetch or cleanup isn't done here
+func (m Model) showReadOnly() (tea.Model, tea.Cmd) {
+	return m.showToast(severityWarning, "Read-only: "+m.readOnly+"; it fetches for both")
+}
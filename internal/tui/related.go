@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/vector"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// relatedCount is how many similar articles the related view lists
+const relatedCount = 10
+
+type relatedArticle struct {
+	article    models.Article
+	similarity float64
+}
+
+type indexBuiltMsg struct {
+	index *vector.Index
+}
+
+type relatedMsg struct {
+	source  models.Article
+	related []relatedArticle
+}
+
+func buildIndex(aiClient *ai.Client) tea.Cmd {
+	return func() tea.Msg {
+		index, err := aiClient.BuildIndex()
+		if err != nil {
+			return errorMsg{err}
+		}
+		return indexBuiltMsg{index}
+	}
+}
+
+// findRelated looks up the unread articles most similar to source
+func findRelated(aiClient *ai.Client, index *vector.Index, source models.Article, articles []models.Article) tea.Cmd {
+	return func() tea.Msg {
+		emb, err := aiClient.ArticleEmbedding(&source)
+		if err != nil {
+			return errorMsg{err}
+		}
+
+		byID := make(map[int64]models.Article, len(articles))
+		for _, a := range articles {
+			byID[a.ID] = a
+		}
+
+		var related []relatedArticle
+		for _, r := range index.Search(emb, relatedCount+1) {
+			a, ok := byID[r.ID]
+			if !ok || a.ID == source.ID {
+				continue
+			}
+			related = append(related, relatedArticle{article: a, similarity: r.Similarity})
+			if len(related) == relatedCount {
+				break
+			}
+		}
+		return relatedMsg{source: source, related: related}
+	}
+}
+
+// showRelated starts looking up articles similar to the given one
+func (m Model) showRelated(article models.Article) (tea.Model, tea.Cmd) {
+	if m.vectors == nil {
+		m.statusMsg = "Similarity index is still being built"
+		return m, nil
+	}
+	m.statusMsg = "Finding related articles..."
+	return m, findRelated(m.aiClient, m.vectors, article, m.allArticles)
+}
+
+// openArticle selects an article in the list, clearing the filter if it
+// hides it, and shows it in the detail view
+func (m Model) openArticle(article models.Article) Model {
+	m = m.revealArticle(article)
+	if !m.selectArticle(article.ID) {
+		m.isFiltering = false
+		m.filterInput.SetValue("")
+		m.filterExpr = nil
+		m.articles = m.allArticles
+		m.list.SetItems(m.listItems())
+		m = m.revealArticle(article)
+		m.selectArticle(article.ID)
+	}
+	m.updateArticleItem(article)
+
+	m.view = ViewArticleDetail
+	m.articleContent = m.formatArticleForView(article)
+	m.viewport.SetContent(m.articleContent)
+	m.viewport.GotoTop()
+	return m
+}
+
+func (m Model) handleRelatedKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Related.Back):
+		m.view = ViewArticleDetail
+	case key.Matches(msg, upKey):
+		if m.relatedCursor > 0 {
+			m.relatedCursor--
+		}
+	case key.Matches(msg, downKey):
+		if m.relatedCursor < len(m.related)-1 {
+			m.relatedCursor++
+		}
+	case key.Matches(msg, keys.Related.Open):
+		if m.relatedCursor < len(m.related) {
+			article, err := withContent(m.db, m.related[m.relatedCursor].article)
+			if err != nil {
+				return m, func() tea.Msg { return errorMsg{err} }
+			}
+			return m.openArticle(article), m.explainMetadata(article)
+		}
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+	}
+	return m, nil
+}
+
+func (m Model) renderRelated() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("More Like This"))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(truncate(m.relatedSource.Title, max(m.width-2, 20))))
+	s.WriteString("\n\n")
+
+	if len(m.related) == 0 {
+		s.WriteString("No similar unread articles found.\n")
+	}
+	for i, r := range m.related {
+		cursor := "  "
+		if i == m.relatedCursor {
+			cursor = "> "
+		}
+		line := fmt.Sprintf("%.2f  %s", r.similarity, r.article.Title)
+		s.WriteString(cursor + truncate(line, max(m.width-30, 20)))
+		s.WriteString(helpStyle.Render("  " + r.article.FeedName))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓: select • enter: open • esc: back to article"))
+
+	return s.String()
+}
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/bookmark"
+	"github.com/thomaskoefod/newsreadr/internal/hooks"
+	"github.com/thomaskoefod/newsreadr/internal/raindrop"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+type articleSavedMsg struct {
+	article    models.Article
+	target     string
+	err        error
+	summaryErr error // the bookmark was saved with the description instead
+}
+
+// saveArticle saves an article with a note in the background, as saving may
+// wait out rate limits, retries or a slow command. With aiClient set, an AI
+// summary becomes the bookmark's excerpt.
+func saveArticle(saver bookmark.Saver, aiClient *ai.Client, article models.Article, note string) tea.Cmd {
+	return func() tea.Msg {
+		var excerpt string
+		var summaryErr error
+		if aiClient != nil {
+			excerpt, summaryErr = aiClient.Summarize(&article)
+		}
+		err := saver.Save(&article, note, excerpt)
+		return articleSavedMsg{article: article, target: saver.Name(), err: err, summaryErr: summaryErr}
+	}
+}
+
+// savePrompt lists the numbered save targets
+func savePrompt(targets []bookmark.Target) string {
+	parts := make([]string, 0, len(targets)+1)
+	for i, t := range targets {
+		if i == 9 {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%d: %s", i+1, t.Name()))
+	}
+	parts = append(parts, "esc: cancel")
+	return "Save to: " + strings.Join(parts, " • ")
+}
+
+// startSave saves the selected article, first asking where when there are
+// several targets
+func (m Model) startSave() (tea.Model, tea.Cmd) {
+	if _, ok := m.list.SelectedItem().(articleItem); !ok {
+		return m, nil
+	}
+	switch len(m.saveTargets) {
+	case 0:
+		return m.showToast(severityWarning, "Nowhere to save: set raindrop.api_token or add save.targets")
+	case 1:
+		return m.startNote(0)
+	}
+	m.savePending = true
+	m.statusMsg = savePrompt(m.saveTargets)
+	return m, nil
+}
+
+// handleSaveKey takes the save target picked from the menu
+func (m Model) handleSaveKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.savePending = false
+	key := msg.String()
+	if len(key) != 1 || key[0] < '1' || key[0] > '9' || int(key[0]-'1') >= len(m.saveTargets) {
+		m.statusMsg = "Save cancelled"
+		return m, nil
+	}
+	m.statusMsg = ""
+	return m.startNote(int(key[0] - '1'))
+}
+
+// startNote opens the prompt for a note to save with the bookmark
+func (m Model) startNote(target int) (tea.Model, tea.Cmd) {
+	m.saveTarget = target
+	m.isNoting = true
+	m.noteInput.SetValue("")
+	m.noteInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m Model) handleNoteInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.isNoting = false
+		m.noteInput.Blur()
+		return m, nil
+	case "enter":
+		m.isNoting = false
+		m.noteInput.Blur()
+		i, ok := m.list.SelectedItem().(articleItem)
+		if !ok {
+			return m, nil
+		}
+		target := m.saveTargets[m.saveTarget]
+		var aiClient *ai.Client
+		if target.Summarize && !(m.checkedConnection && !m.ollamaOnline) {
+			aiClient = m.aiClient
+		}
+		m.statusMsg = fmt.Sprintf("Saving to %s...", target.Name())
+		if aiClient != nil {
+			m.statusMsg = fmt.Sprintf("Summarizing and saving to %s...", target.Name())
+		}
+		return m, saveArticle(target, aiClient, i.article, m.noteInput.Value())
+	}
+
+	var cmd tea.Cmd
+	m.noteInput, cmd = m.noteInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) renderNoteInput() string {
+	return filterStyle.Render("Note: ") + m.noteInput.View() +
+		helpStyle.Render(fmt.Sprintf(" (optional, enter: save to %s, esc: cancel)", m.saveTargets[m.saveTarget].Name())) + "\n"
+}
+
+func (m Model) handleArticleSaved(msg articleSavedMsg) (tea.Model, tea.Cmd) {
+	if errors.Is(msg.err, raindrop.ErrCircuitOpen) {
+		return m.showToast(severityWarning, msg.err.Error())
+	}
+	if msg.err != nil {
+		return m.showToast(severityError, msg.err.Error())
+	}
+	hook := fireHook(m.hooks, m.db, hooks.ArticleSaved, msg.article)
+	if msg.summaryErr != nil {
+		m, toastCmd := m.showToast(severityWarning, fmt.Sprintf("Saved to %s without a summary: %v", msg.target, msg.summaryErr))
+		return m, tea.Batch(toastCmd, hook)
+	}
+	m.statusMsg = "Saved to " + msg.target
+	return m, hook
+}
@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/vector"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// searchResultCount caps how many articles a semantic search returns
+const searchResultCount = 50
+
+type searchResultsMsg struct {
+	query    string
+	articles []models.Article
+}
+
+// semanticSearch embeds the query and returns the most similar stored
+// articles, best match first
+func semanticSearch(aiClient *ai.Client, index *vector.Index, db database.Store, query string, loaded []models.Article) tea.Cmd {
+	return func() tea.Msg {
+		emb, err := aiClient.GetEmbedding(query)
+		if err != nil {
+			return errorMsg{fmt.Errorf("embedding search query: %w", err)}
+		}
+
+		byID := make(map[int64]models.Article, len(loaded))
+		for _, a := range loaded {
+			byID[a.ID] = a
+		}
+
+		var articles []models.Article
+		for _, r := range index.Search(emb, searchResultCount) {
+			if a, ok := byID[r.ID]; ok {
+				articles = append(articles, a)
+				continue
+			}
+			// Stored but not in the current list, e.g. snoozed
+			if a, err := db.GetArticleByID(r.ID); err == nil {
+				articles = append(articles, *a)
+			}
+		}
+		return searchResultsMsg{query: query, articles: articles}
+	}
+}
+
+// startSearch opens the semantic search prompt
+func (m Model) startSearch() (tea.Model, tea.Cmd) {
+	if m.checkedConnection && !m.ollamaOnline {
+		return m.showToast(severityWarning, "Semantic search needs Ollama, which is unreachable")
+	}
+	if m.vectors == nil {
+		m.statusMsg = "Similarity index is still being built"
+		return m, nil
+	}
+	m.isSearching = true
+	m.searchInput.SetValue("")
+	m.searchInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m Model) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.isSearching = false
+		m.searchInput.Blur()
+		return m, nil
+	case "enter":
+		m.isSearching = false
+		m.searchInput.Blur()
+		query := m.searchInput.Value()
+		if query == "" {
+			return m, nil
+		}
+		m.statusMsg = "Searching..."
+		return m, semanticSearch(m.aiClient, m.vectors, m.db, query, m.allArticles)
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return m, cmd
+}
+
+// showSearchResults replaces the list with ranked search results
+func (m Model) showSearchResults(msg searchResultsMsg) Model {
+	m.searchQuery = msg.query
+	m.list.Title = "Semantic search: " + msg.query
+	m = m.setListArticles(msg.articles)
+	m.statusMsg = fmt.Sprintf("%d results (esc: back to all articles)", len(msg.articles))
+	return m
+}
+
+// clearSearch restores the list shown before a semantic search
+func (m Model) clearSearch() Model {
+	m.searchQuery = ""
+	if m.showQueue {
+		m.list.Title = queueTitle
+	} else {
+		m.list.Title = listTitle
+	}
+	return m.setListArticles(m.allArticles)
+}
+
+// setListArticles shows the given articles in the list from the top
+func (m Model) setListArticles(articles []models.Article) Model {
+	m.articles = articles
+	m.list.SetItems(m.listItems())
+	m.list.ResetSelected()
+	return m
+}
+
+func (m Model) renderSearchInput() string {
+	return filterStyle.Render("Search: ") + m.searchInput.View() +
+		helpStyle.Render(" (describe a topic, enter: search, esc: cancel)") + "\n\n"
+}
@@ -0,0 +1,291 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/thomaskoefod/newsreadr/internal/query"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// grouping is how the article list is divided into sections
+type grouping int
+
+const (
+	groupNone grouping = iota // a single list ordered by relevance
+	groupFeed                 // a section per feed
+	groupDate                 // sections by publication date, see dateBuckets
+)
+
+// groupingNames maps the ui.group_by setting to its grouping, in the order
+// the grouping key cycles through them
+var groupingNames = []string{"none", "feed", "date"}
+
+// dateBuckets are the sections of a list grouped by date, newest first.
+// This Week covers the seven days up to today.
+var dateBuckets = []string{"Today", "Yesterday", "This Week", "Older"}
+
+// dateBucket returns the index in dateBuckets for an article published at t
+func dateBucket(t, now time.Time) int {
+	y, mo, d := now.Local().Date()
+	today := time.Date(y, mo, d, 0, 0, 0, 0, time.Local)
+	switch t = t.Local(); {
+	case !t.Before(today):
+		return 0
+	case !t.Before(today.AddDate(0, 0, -1)):
+		return 1
+	case !t.Before(today.AddDate(0, 0, -6)):
+		return 2
+	}
+	return 3
+}
+
+func parseGrouping(name string) grouping {
+	for i, n := range groupingNames {
+		if n == name {
+			return grouping(i)
+		}
+	}
+	return groupNone
+}
+
+func (g grouping) status() string {
+	switch g {
+	case groupFeed:
+		return "Grouped by feed"
+	case groupDate:
+		return "Grouped by date"
+	}
+	return "Not grouped"
+}
+
+// sectionItem is the header row of a section in a grouped list
+type sectionItem struct {
+	key       string
+	title     string
+	count     int
+	collapsed bool
+}
+
+func (s sectionItem) Title() string {
+	marker := "▾"
+	if s.collapsed {
+		marker = "▸"
+	}
+	return fmt.Sprintf("%s %s (%d)", marker, s.title, s.count)
+}
+
+func (s sectionItem) Description() string { return "" }
+
+// FilterValue is empty so headers never match the list's own filter
+func (s sectionItem) FilterValue() string { return "" }
+
+var _ list.Item = sectionItem{}
+
+// sectionOf returns the key and title of the section an article belongs to
+func (m Model) sectionOf(article models.Article) (string, string) {
+	if m.grouping == groupDate {
+		bucket := dateBuckets[dateBucket(article.PublishedAt, time.Now())]
+		return bucket, bucket
+	}
+	name := article.FeedName
+	if name == "" {
+		name = "(unknown feed)"
+	}
+	return name, name
+}
+
+// listItems builds the list rows for the shown articles. When grouped by
+// feed, sections appear in the order of their best ranked article; date
+// sections are newest first. Articles keep their order within a section. Search results are never grouped, as
+// their order is how well they match.
+func (m Model) listItems() []list.Item {
+	articles := m.shownArticles()
+	if m.grouping == groupNone || m.searchQuery != "" {
+		items := make([]list.Item, len(articles))
+		for i, article := range articles {
+			items[i] = m.newArticleItem(article)
+		}
+		return items
+	}
+
+	var order []sectionItem
+	members := map[string][]models.Article{}
+	for _, article := range articles {
+		key, title := m.sectionOf(article)
+		if _, ok := members[key]; !ok {
+			order = append(order, sectionItem{key: key, title: title})
+		}
+		members[key] = append(members[key], article)
+	}
+	if m.grouping == groupDate {
+		sort.SliceStable(order, func(i, j int) bool {
+			return slices.Index(dateBuckets, order[i].key) < slices.Index(dateBuckets, order[j].key)
+		})
+	}
+
+	items := make([]list.Item, 0, len(articles)+len(order))
+	for _, section := range order {
+		section.count = len(members[section.key])
+		section.collapsed = m.collapsed[section.key]
+		items = append(items, section)
+		if section.collapsed {
+			continue
+		}
+		for _, article := range members[section.key] {
+			items = append(items, m.newArticleItem(article))
+		}
+	}
+	return items
+}
+
+// newArticleItem makes the row of an article, marking the characters the
+// filter matched
+func (m Model) newArticleItem(article models.Article) articleItem {
+	item := articleItem{article: article, calibration: m.calibration}
+	if m.filterExpr != nil && m.searchQuery == "" && m.filterExpr.Match(&article) {
+		item.titleMatches, item.feedMatches = query.Highlights(m.filterExpr, &article)
+	}
+	return item
+}
+
+// setListItems rebuilds the list rows from the shown articles, keeping the
+// selected row where it still exists
+func (m Model) setListItems() Model {
+	selected := m.list.SelectedItem()
+	m.list.SetItems(m.listItems())
+	m.restoreSelection(selected)
+	return m
+}
+
+// restoreSelection selects a row taken from the list before it was
+// rebuilt, reporting whether it is still there
+func (m *Model) restoreSelection(selected list.Item) bool {
+	switch s := selected.(type) {
+	case articleItem:
+		return m.selectArticle(s.article.ID)
+	case sectionItem:
+		return m.selectSection(s.key)
+	}
+	return false
+}
+
+// selectNearest selects the row that was selected in rows, a copy of the
+// list from before it was rebuilt, or when that's gone the nearest row
+// around it that's still there, looking below first as that's where the
+// next article was. Without any, the first row is selected.
+func (m *Model) selectNearest(rows []list.Item, selected int) {
+	index := make(map[any]int, len(m.list.Items()))
+	for i, item := range m.list.Items() {
+		index[rowKey(item)] = i
+	}
+	for d := 0; d < len(rows); d++ {
+		for _, i := range []int{selected + d, selected - d} {
+			if i < 0 || i >= len(rows) {
+				continue
+			}
+			if j, ok := index[rowKey(rows[i])]; ok {
+				m.list.Select(j)
+				return
+			}
+		}
+	}
+	m.list.ResetSelected()
+}
+
+// rowKey identifies a row of the list across rebuilds
+func rowKey(item list.Item) any {
+	switch i := item.(type) {
+	case articleItem:
+		return i.article.ID
+	case sectionItem:
+		return i.key
+	}
+	return nil
+}
+
+// selectArticle selects the row of an article, reporting whether it is
+// shown. Articles in a collapsed section are not.
+func (m *Model) selectArticle(id int64) bool {
+	for i, item := range m.list.Items() {
+		if a, ok := item.(articleItem); ok && a.article.ID == id {
+			m.list.Select(i)
+			return true
+		}
+	}
+	return false
+}
+
+// selectSection selects the header row of a section
+func (m *Model) selectSection(key string) bool {
+	for i, item := range m.list.Items() {
+		if s, ok := item.(sectionItem); ok && s.key == key {
+			m.list.Select(i)
+			return true
+		}
+	}
+	return false
+}
+
+// updateArticleItem redraws the row of an article that changed in place
+func (m *Model) updateArticleItem(article models.Article) {
+	for i, item := range m.list.Items() {
+		if a, ok := item.(articleItem); ok && a.article.ID == article.ID {
+			m.list.SetItem(i, m.newArticleItem(article))
+			return
+		}
+	}
+}
+
+// revealArticle expands the section holding an article so it can be
+// selected
+func (m Model) revealArticle(article models.Article) Model {
+	key, _ := m.sectionOf(article)
+	if m.collapsed[key] {
+		delete(m.collapsed, key)
+		m.list.SetItems(m.listItems())
+	}
+	return m
+}
+
+// selectedSection returns the section of the selected row, whether it is
+// the header or one of its articles
+func (m Model) selectedSection() (string, bool) {
+	switch s := m.list.SelectedItem().(type) {
+	case sectionItem:
+		return s.key, true
+	case articleItem:
+		key, _ := m.sectionOf(s.article)
+		return key, true
+	}
+	return "", false
+}
+
+// setSectionCollapsed collapses or expands the section of the selected row.
+// Collapsing from one of its articles moves the selection to the header.
+func (m Model) setSectionCollapsed(collapsed bool) Model {
+	key, ok := m.selectedSection()
+	if !ok || m.grouping == groupNone || m.searchQuery != "" || m.collapsed[key] == collapsed {
+		return m
+	}
+	if collapsed {
+		m.collapsed[key] = true
+	} else {
+		delete(m.collapsed, key)
+	}
+	m.list.SetItems(m.listItems())
+	m.selectSection(key)
+	return m
+}
+
+// cycleGrouping switches to the next way of grouping the list
+func (m Model) cycleGrouping() Model {
+	m.grouping = (m.grouping + 1) % grouping(len(groupingNames))
+	m.collapsed = map[string]bool{}
+	m = m.setListItems()
+	m.statusMsg = m.grouping.status()
+	return m
+}
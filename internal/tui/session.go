@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+var bannerStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("0")).
+	Background(lipgloss.Color("86")).
+	Padding(0, 1)
+
+// lastSessionMsg carries where the last session left off
+type lastSessionMsg struct {
+	session *database.Session
+}
+
+func loadLastSession(db database.Store) tea.Cmd {
+	return func() tea.Msg {
+		session, err := db.GetLastSession()
+		if err != nil {
+			return errorMsg{err}
+		}
+		return lastSessionMsg{session}
+	}
+}
+
+// sinceLastSession returns the unread articles that arrived since the last
+// session, and those of them scored at least notify.threshold. Fetches
+// during this session add to them until the banner is dismissed.
+func (m Model) sinceLastSession() (fresh, relevant []models.Article) {
+	if m.lastSession == nil || m.showQueue {
+		return nil, nil
+	}
+	for _, a := range m.allArticles {
+		if a.ID <= m.lastSession.LastArticleID {
+			continue
+		}
+		fresh = append(fresh, a)
+		if a.Scored && a.RelevanceScore >= m.cfg.Notify.Threshold {
+			relevant = append(relevant, a)
+		}
+	}
+	return fresh, relevant
+}
+
+// renderBanner tells what arrived since the last session, above the list
+func (m Model) renderBanner() string {
+	fresh, relevant := m.sinceLastSession()
+	if len(fresh) == 0 || m.searchQuery != "" {
+		return ""
+	}
+	noun := "articles"
+	if len(fresh) == 1 {
+		noun = "article"
+	}
+	text := fmt.Sprintf("%d new %s %s", len(fresh), noun, sinceLabel(m.lastSession.EndedAt, time.Now()))
+	if len(relevant) > 0 {
+		text += fmt.Sprintf(", %d high-relevance", len(relevant))
+	}
+	return bannerStyle.Render(text) +
+		helpStyle.Render(fmt.Sprintf(" (%s: show them, esc: dismiss)", keys.List.New.Help().Key)) + "\n"
+}
+
+// showSinceLastSession lists the high-relevance articles that arrived since
+// the last session, or all that arrived when none scored high, like search
+// results. It dismisses the banner.
+func (m Model) showSinceLastSession() Model {
+	fresh, relevant := m.sinceLastSession()
+	if len(fresh) == 0 {
+		return m
+	}
+	label := "New since last session"
+	articles := fresh
+	if len(relevant) > 0 {
+		label, articles = "High-relevance since last session", relevant
+	}
+	m.lastSession = nil
+	m.searchQuery = label
+	m.list.Title = label
+	m = m.setListArticles(articles)
+	m.statusMsg = fmt.Sprintf("%d articles (esc: back to all articles)", len(articles))
+	return m
+}
+
+// sinceLabel describes when the last session ended relative to now:
+// "since 09:14" the same day, "since yesterday", the weekday within a week
+// or the date
+func sinceLabel(at, now time.Time) string {
+	y, mo, d := now.Date()
+	today := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+	switch {
+	case !at.Before(today):
+		return "since " + at.Format("15:04")
+	case !at.Before(today.AddDate(0, 0, -1)):
+		return "since yesterday"
+	case !at.Before(today.AddDate(0, 0, -6)):
+		return "since " + at.Format("Monday")
+	}
+	return "since " + at.Format("Jan 2")
+}
@@ -0,0 +1,453 @@
+package tui
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/feed"
+	"github.com/thomaskoefod/newsreadr/internal/github"
+	"github.com/thomaskoefod/newsreadr/internal/raindrop"
+)
+
+// setupStep is one page of the first-run wizard
+type setupStep int
+
+const (
+	setupDatabase setupStep = iota
+	setupFeeds
+	setupInterests
+	setupOllamaHost
+	setupOllamaModel
+	setupRaindrop
+	setupReview
+)
+
+var setupStepTitles = map[setupStep]string{
+	setupDatabase:    "Database",
+	setupFeeds:       "Feeds",
+	setupInterests:   "Interests",
+	setupOllamaHost:  "Ollama server",
+	setupOllamaModel: "Ollama model",
+	setupRaindrop:    "Raindrop.io",
+	setupReview:      "Review",
+}
+
+// setupModel is the first-run wizard. It fills in a default configuration
+// step by step; nothing is written until the review step is confirmed.
+type setupModel struct {
+	cfg     *config.Config
+	fetcher *feed.Fetcher
+
+	step       setupStep
+	input      textinput.Model
+	feeds      []config.FeedConfig
+	interests  []config.InterestConfig
+	candidates []string // feeds discovered on a page, picked with 1-9
+	busy       bool
+	// checked is the last value tested against Ollama or Raindrop, so that
+	// pressing enter again keeps it even though the test failed
+	checked string
+	status  string
+	err     error
+	done    bool
+}
+
+type setupFeedMsg struct {
+	feed config.FeedConfig
+	err  error
+}
+
+type setupCandidatesMsg struct {
+	candidates []string
+}
+
+type setupCheckMsg struct {
+	value string
+	err   error
+}
+
+// RunSetup runs the first-run wizard, filling in cfg. It reports whether the
+// wizard was completed rather than cancelled.
+func RunSetup(cfg *config.Config) (bool, error) {
+	m := setupModel{
+		cfg:     cfg,
+		fetcher: feed.NewFetcher(nil, cfg),
+		input:   textinput.New(),
+	}
+	m.input.CharLimit = 500
+	m = m.enterStep(setupDatabase)
+
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return false, fmt.Errorf("running setup: %w", err)
+	}
+	return final.(setupModel).done, nil
+}
+
+// setupAddFeed adds url when it is a feed, and otherwise looks for the
+// feeds the page advertises
+func setupAddFeed(fetcher *feed.Fetcher, feedURL string) tea.Cmd {
+	return func() tea.Msg {
+		// github.com/ORG/REPO subscribes to the repository's releases
+		repoName := ""
+		if repoURL, name, ok := github.FeedURL(feedURL); ok {
+			feedURL, repoName = repoURL, name
+		}
+		parsed, movedTo, err := fetcher.FetchFeed(feedURL, nil)
+		if err == nil {
+			if movedTo != "" {
+				feedURL = movedTo
+			}
+			name := repoName
+			if name == "" {
+				name = strings.TrimSpace(parsed.Title)
+			}
+			if name == "" {
+				if u, perr := url.Parse(feedURL); perr == nil {
+					name = u.Host
+				}
+			}
+			return setupFeedMsg{feed: config.FeedConfig{URL: feedURL, Name: name}}
+		}
+
+		candidates, derr := fetcher.Discover(feedURL)
+		if derr != nil || len(candidates) == 0 {
+			return setupFeedMsg{err: err}
+		}
+		return setupCandidatesMsg{candidates}
+	}
+}
+
+func setupPingOllama(host string) tea.Cmd {
+	return func() tea.Msg {
+		err := ai.NewClient(host, "", nil).Ping(connectivityTimeout)
+		return setupCheckMsg{value: host, err: err}
+	}
+}
+
+func setupTestRaindrop(token string) tea.Cmd {
+	return func() tea.Msg {
+		return setupCheckMsg{value: token, err: raindrop.NewClient(token).TestConnection()}
+	}
+}
+
+func (m setupModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// enterStep moves to a step and prepares the input for it
+func (m setupModel) enterStep(step setupStep) setupModel {
+	m.step = step
+	m.status = ""
+	m.err = nil
+	m.checked = ""
+	m.candidates = nil
+	m.input.EchoMode = textinput.EchoNormal
+	m.input.Placeholder = ""
+	m.input.SetValue("")
+
+	switch step {
+	case setupDatabase:
+		m.input.SetValue(m.cfg.Database.Path)
+	case setupFeeds:
+		m.input.Placeholder = "https://example.com/feed.xml or a site to search for feeds"
+	case setupInterests:
+		m.input.Placeholder = "e.g. rust and systems programming"
+	case setupOllamaHost:
+		m.input.SetValue(m.cfg.Ollama.Host)
+	case setupOllamaModel:
+		m.input.SetValue(m.cfg.Ollama.Model)
+	case setupRaindrop:
+		m.input.SetValue(m.cfg.Raindrop.APIToken)
+		m.input.EchoMode = textinput.EchoPassword
+		m.input.Placeholder = "API token (leave empty to skip)"
+	}
+
+	if step == setupReview {
+		m.input.Blur()
+	} else {
+		m.input.Focus()
+		m.input.CursorEnd()
+	}
+	return m
+}
+
+func (m setupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKeys(msg)
+
+	case setupFeedMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.feeds = append(m.feeds, msg.feed)
+		m.status = fmt.Sprintf("Added %s", msg.feed.Name)
+		m.input.SetValue("")
+		return m, nil
+
+	case setupCandidatesMsg:
+		m.busy = false
+		m.status = ""
+		m.candidates = msg.candidates
+		m.input.Blur()
+		return m, nil
+
+	case setupCheckMsg:
+		m.busy = false
+		m.checked = msg.value
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		return m.accept(msg.value), nil
+	}
+
+	return m, nil
+}
+
+func (m setupModel) handleKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, keys.Setup.Cancel) {
+		return m, tea.Quit
+	}
+	if m.busy {
+		return m, nil
+	}
+
+	if len(m.candidates) > 0 {
+		switch {
+		case key.Matches(msg, keys.Setup.Pick):
+			n := int(msg.String()[0] - '1')
+			if n < len(m.candidates) {
+				candidate := m.candidates[n]
+				m.candidates = nil
+				m.input.Focus()
+				m.busy = true
+				m.status = "Checking " + candidate + "..."
+				return m, setupAddFeed(m.fetcher, candidate)
+			}
+		case key.Matches(msg, keys.Setup.Back):
+			m.candidates = nil
+			m.input.Focus()
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, keys.Setup.Back):
+		if m.step > setupDatabase {
+			return m.enterStep(m.step - 1), nil
+		}
+		return m, nil
+	case key.Matches(msg, keys.Setup.Next):
+		return m.submit()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// submit handles enter: items are added to the feed and interest lists until
+// the input is left empty, and connections are tested before moving on
+func (m setupModel) submit() (tea.Model, tea.Cmd) {
+	value := strings.TrimSpace(m.input.Value())
+	m.err = nil
+
+	switch m.step {
+	case setupFeeds:
+		if value == "" {
+			return m.accept(""), nil
+		}
+		m.busy = true
+		m.status = "Checking " + value + "..."
+		return m, setupAddFeed(m.fetcher, value)
+
+	case setupInterests:
+		if value == "" {
+			return m.accept(""), nil
+		}
+		m.interests = append(m.interests, config.InterestConfig{Description: value})
+		m.input.SetValue("")
+		return m, nil
+
+	case setupOllamaHost:
+		if value == "" || value == m.checked {
+			return m.accept(value), nil
+		}
+		m.busy = true
+		m.status = "Connecting to " + value + "..."
+		return m, setupPingOllama(value)
+
+	case setupRaindrop:
+		if value == "" || value == m.checked {
+			return m.accept(value), nil
+		}
+		m.busy = true
+		m.status = "Testing the token..."
+		return m, setupTestRaindrop(value)
+
+	case setupReview:
+		if len(m.feeds) > 0 {
+			m.cfg.Feeds = m.feeds
+		}
+		if len(m.interests) > 0 {
+			m.cfg.Interests = m.interests
+		}
+		m.done = true
+		return m, tea.Quit
+	}
+
+	return m.accept(value), nil
+}
+
+// accept stores the value of the current step and moves to the next one.
+// An empty value keeps the default.
+func (m setupModel) accept(value string) setupModel {
+	switch m.step {
+	case setupDatabase:
+		if value != "" {
+			m.cfg.Database.Path = value
+		}
+	case setupOllamaHost:
+		if value != "" {
+			m.cfg.Ollama.Host = strings.TrimRight(value, "/")
+		}
+	case setupOllamaModel:
+		if value != "" {
+			m.cfg.Ollama.Model = value
+		}
+	case setupRaindrop:
+		m.cfg.Raindrop.APIToken = value
+	}
+	return m.enterStep(m.step + 1)
+}
+
+func (m setupModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("NewsReadr Setup - %d/%d: %s",
+		m.step+1, setupReview+1, setupStepTitles[m.step])))
+	s.WriteString("\n")
+
+	switch m.step {
+	case setupDatabase:
+		s.WriteString("Where should articles be stored?\n")
+	case setupFeeds:
+		s.WriteString("Add feeds one at a time. A site's address works too: its advertised feeds are listed.\n")
+		s.WriteString("github.com/ORG/REPO follows a repository's releases (add /commits, /tags or /issues for those).\n")
+		if len(m.feeds) == 0 {
+			s.WriteString(helpStyle.Render("Leave empty to start with the example feeds (" + feedNames(m.cfg.Feeds) + ")."))
+			s.WriteString("\n")
+		}
+		for _, f := range m.feeds {
+			s.WriteString(fmt.Sprintf("  • %s %s\n", f.Name, helpStyle.Render(f.URL)))
+		}
+	case setupInterests:
+		s.WriteString("Describe what you like to read about, one interest at a time. Articles are scored against these.\n")
+		if len(m.interests) == 0 {
+			s.WriteString(helpStyle.Render("Leave empty to start with: " + interestNames(m.cfg.Interests)))
+			s.WriteString("\n")
+		}
+		for _, i := range m.interests {
+			s.WriteString(fmt.Sprintf("  • %s\n", i.Description))
+		}
+	case setupOllamaHost:
+		s.WriteString("Ollama scores articles and answers questions about them. Which server should be used?\n")
+	case setupOllamaModel:
+		s.WriteString("Which model should generate text (summaries, answers)?\n")
+	case setupRaindrop:
+		s.WriteString("Optionally save articles to Raindrop.io: paste a test token from its integration settings.\n")
+	case setupReview:
+		s.WriteString(m.renderSetupReview())
+	}
+
+	s.WriteString("\n")
+	if len(m.candidates) > 0 {
+		s.WriteString("Feeds found on the page:\n")
+		for n, c := range m.candidates {
+			if n == 9 {
+				break
+			}
+			s.WriteString(fmt.Sprintf("  %d: %s\n", n+1, c))
+		}
+	} else if m.step != setupReview {
+		s.WriteString(filterStyle.Render("> ") + m.input.View())
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	switch {
+	case m.err != nil && (m.step == setupOllamaHost || m.step == setupRaindrop):
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v (enter: keep it anyway)", m.err)))
+		s.WriteString("\n")
+	case m.err != nil:
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		s.WriteString("\n")
+	case m.status != "":
+		s.WriteString(statusStyle.Render(m.status))
+		s.WriteString("\n")
+	}
+
+	switch {
+	case len(m.candidates) > 0:
+		s.WriteString(helpStyle.Render("1-9: add feed • esc: none of these • ctrl+c: cancel setup"))
+	case m.step == setupReview:
+		s.WriteString(helpStyle.Render("enter: save and start • esc: back • ctrl+c: cancel setup"))
+	case m.step == setupFeeds || m.step == setupInterests:
+		s.WriteString(helpStyle.Render("enter: add (empty: next step) • esc: back • ctrl+c: cancel setup"))
+	default:
+		s.WriteString(helpStyle.Render("enter: next • esc: back • ctrl+c: cancel setup"))
+	}
+
+	return s.String()
+}
+
+// renderSetupReview summarizes the configuration about to be written
+func (m setupModel) renderSetupReview() string {
+	feeds, interests := m.feeds, m.interests
+	if len(feeds) == 0 {
+		feeds = m.cfg.Feeds
+	}
+	if len(interests) == 0 {
+		interests = m.cfg.Interests
+	}
+	raindropStatus := "not configured"
+	if m.cfg.Raindrop.APIToken != "" {
+		raindropStatus = "configured"
+	}
+
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("Database:  %s\n", m.cfg.Database.Path))
+	s.WriteString(fmt.Sprintf("Feeds:     %s\n", feedNames(feeds)))
+	s.WriteString(fmt.Sprintf("Interests: %s\n", interestNames(interests)))
+	s.WriteString(fmt.Sprintf("Ollama:    %s (model %s)\n", m.cfg.Ollama.Host, m.cfg.Ollama.Model))
+	s.WriteString(fmt.Sprintf("Raindrop:  %s\n", raindropStatus))
+	s.WriteString(helpStyle.Render("Everything else uses the defaults; edit the config file to change it later."))
+	s.WriteString("\n")
+	return s.String()
+}
+
+func feedNames(feeds []config.FeedConfig) string {
+	names := make([]string, len(feeds))
+	for i, f := range feeds {
+		names[i] = f.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func interestNames(interests []config.InterestConfig) string {
+	names := make([]string, len(interests))
+	for i, interest := range interests {
+		names[i] = interest.Description
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/config"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/internal/share"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+// sharePrompt lists the numbered share targets
+func sharePrompt(targets []config.ShareTarget) string {
+	parts := make([]string, 0, len(targets)+1)
+	for i, t := range targets {
+		if i == 9 {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%d: %s", i+1, t.Name))
+	}
+	parts = append(parts, "esc: cancel")
+	return "Share: " + strings.Join(parts, " • ")
+}
+
+func shareArticle(target config.ShareTarget, db database.Store, article models.Article) tea.Cmd {
+	return func() tea.Msg {
+		// Templates may use the content
+		article, err := withContent(db, article)
+		if err != nil {
+			return errorMsg{err}
+		}
+		if err := share.Run(target, &article); err != nil {
+			return errorMsg{err}
+		}
+		return statusMsg(fmt.Sprintf("Shared via %s", target.Name))
+	}
+}
+
+// startShare opens the share menu for the selected article
+func (m Model) startShare() (tea.Model, tea.Cmd) {
+	if _, ok := m.list.SelectedItem().(articleItem); !ok {
+		return m, nil
+	}
+	m.sharePending = true
+	m.statusMsg = sharePrompt(share.Targets(m.cfg.Share))
+	return m, nil
+}
+
+// handleShareKey runs the share target picked from the menu
+func (m Model) handleShareKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.sharePending = false
+
+	targets := share.Targets(m.cfg.Share)
+	key := msg.String()
+	if len(key) != 1 || key[0] < '1' || key[0] > '9' || int(key[0]-'1') >= len(targets) {
+		m.statusMsg = "Share cancelled"
+		return m, nil
+	}
+
+	i, ok := m.list.SelectedItem().(articleItem)
+	if !ok {
+		return m, nil
+	}
+	return m, shareArticle(targets[key[0]-'1'], m.db, i.article)
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const snoozePrompt = "Snooze until: h: 1 hour • t: tonight • m: tomorrow • w: next week • esc: cancel"
+
+// snoozeUntil resolves a snooze option key to a wake-up time and a label
+func snoozeUntil(key string, now time.Time) (time.Time, string, bool) {
+	at := func(days, hour int) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day()+days, hour, 0, 0, 0, now.Location())
+	}
+
+	switch key {
+	case "h":
+		return now.Add(time.Hour), "in 1 hour", true
+	case "t":
+		tonight := at(0, 19)
+		if !tonight.After(now) {
+			// Already evening, push it a few hours out instead
+			tonight = now.Add(3 * time.Hour)
+		}
+		return tonight, "tonight", true
+	case "m":
+		return at(1, 8), "tomorrow morning", true
+	case "w":
+		days := (int(time.Monday) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return at(days, 8), "next week", true
+	}
+	return time.Time{}, "", false
+}
+
+// handleSnoozeKey completes a pending snooze with the chosen option
+func (m Model) handleSnoozeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.snoozePending = false
+
+	until, label, ok := snoozeUntil(msg.String(), time.Now())
+	if !ok {
+		m.statusMsg = "Snooze cancelled"
+		return m, nil
+	}
+
+	i, ok := m.list.SelectedItem().(articleItem)
+	if !ok {
+		return m, nil
+	}
+	if err := m.db.SnoozeArticle(i.article.ID, until); err != nil {
+		return m, func() tea.Msg { return errorMsg{err} }
+	}
+
+	m.view = ViewArticleList
+	return m, tea.Batch(
+		m.reloadArticles(),
+		func() tea.Msg {
+			return statusMsg(fmt.Sprintf("Snoozed %s (until %s)", label, until.Format("Mon Jan 2 15:04")))
+		},
+	)
+}
@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+)
+
+const (
+	statsDays     = 28
+	statsTopFeeds = 10
+	statsBarWidth = 40
+)
+
+var barStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("86"))
+
+type statsLoadedMsg struct {
+	stats *database.ReadingStats
+}
+
+func loadStats(db database.Store) tea.Cmd {
+	return func() tea.Msg {
+		stats, err := db.GetReadingStats(statsDays, statsTopFeeds)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return statsLoadedMsg{stats}
+	}
+}
+
+func (m Model) handleStatsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Stats.Back):
+		m.view = ViewArticleList
+		return m, nil
+	case key.Matches(msg, keys.Stats.Reload):
+		return m, loadStats(m.db)
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m Model) renderStats() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Reading Statistics"))
+	s.WriteString("\n")
+
+	if m.stats == nil {
+		s.WriteString("Loading...\n")
+	} else {
+		st := m.stats
+		s.WriteString(fmt.Sprintf("Articles read: %d\n", st.TotalRead))
+		s.WriteString(fmt.Sprintf("Opened in browser: %d • read only in the terminal: %d\n", st.Opened, st.TotalRead-st.ReadOpened))
+		s.WriteString(fmt.Sprintf("Average relevance: read %.2f • skipped %.2f\n\n", st.AvgRead, st.AvgSkipped))
+
+		s.WriteString(articleTitleStyle.Render(fmt.Sprintf("Read per day (last %d days)", statsDays)))
+		s.WriteString("\n")
+		s.WriteString(renderPeriodBars(st.PerDay))
+		s.WriteString("\n")
+
+		s.WriteString(articleTitleStyle.Render("Read per week"))
+		s.WriteString("\n")
+		s.WriteString(renderPeriodBars(st.PerWeek))
+		s.WriteString("\n")
+
+		s.WriteString(articleTitleStyle.Render("Top feeds"))
+		s.WriteString("\n")
+		labels := make([]string, len(st.TopFeeds))
+		counts := make([]int, len(st.TopFeeds))
+		for i, fc := range st.TopFeeds {
+			labels[i] = fc.FeedName
+			counts[i] = fc.Count
+		}
+		s.WriteString(renderBars(labels, counts))
+	}
+
+	s.WriteString("\n")
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("r: reload • esc: back • q: quit"))
+
+	return s.String()
+}
+
+// renderPeriodBars renders a bar chart of per-period read counts
+func renderPeriodBars(periods []database.PeriodCount) string {
+	labels := make([]string, len(periods))
+	counts := make([]int, len(periods))
+	for i, pc := range periods {
+		labels[i] = pc.Period
+		counts[i] = pc.Count
+	}
+	return renderBars(labels, counts)
+}
+
+// renderBars renders labelled horizontal bars scaled to the largest count
+func renderBars(labels []string, counts []int) string {
+	if len(counts) == 0 {
+		return helpStyle.Render("  no data yet") + "\n"
+	}
+
+	maxCount, labelWidth := 0, 0
+	for i, c := range counts {
+		if c > maxCount {
+			maxCount = c
+		}
+		if w := lipgloss.Width(labels[i]); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	var s strings.Builder
+	for i, c := range counts {
+		width := 0
+		if maxCount > 0 {
+			width = c * statsBarWidth / maxCount
+		}
+		if width == 0 && c > 0 {
+			width = 1
+		}
+		pad := strings.Repeat(" ", labelWidth-lipgloss.Width(labels[i]))
+		s.WriteString(fmt.Sprintf("  %s%s %s %d\n", labels[i], pad, barStyle.Render(strings.Repeat("█", width)), c))
+	}
+	return s.String()
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+)
+
+// statusBarData is what the ui.status_bar template is executed with
+type statusBarData struct {
+	View      string // "list" or "article"
+	Unread    int    // articles in the unread list, or the queue when shown
+	Relevant  int    // of those, the ones scoring at least notify.threshold
+	Shown     int    // articles in the list, after the filter
+	Queue     bool   // the read-later queue is shown
+	Filter    string
+	Search    string
+	LastFetch string // e.g. "5m ago", or "never"
+	Network   string // "online", "offline" or "unknown"
+	Ollama    string // "online", "offline" or "unknown"
+	ReadOnly  bool   // another instance holds the database
+	Keys      string // the key hints shown without a template
+}
+
+type lastFetchLoadedMsg struct {
+	at time.Time
+}
+
+// parseStatusBar parses the ui.status_bar template, nil when there is none
+func parseStatusBar(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return template.New("status_bar").Parse(text)
+}
+
+// loadLastFetch finds when feeds were last fetched, for the status bar
+// before this instance fetches them
+func loadLastFetch(db database.Store) tea.Cmd {
+	return func() tea.Msg {
+		feeds, err := db.GetFeeds()
+		if err != nil {
+			return errorMsg{err}
+		}
+		var last time.Time
+		for _, f := range feeds {
+			if f.LastFetchedAt != nil && f.LastFetchedAt.After(last) {
+				last = *f.LastFetchedAt
+			}
+		}
+		return lastFetchLoadedMsg{last}
+	}
+}
+
+// renderStatusBar renders the bottom line of a view: the ui.status_bar
+// template if there is one, or else the key hints
+func (m Model) renderStatusBar(view, keys string) string {
+	if m.statusBar == nil {
+		return helpStyle.Render(keys)
+	}
+	unread, relevant := m.unreadCounts()
+	data := statusBarData{
+		View:      view,
+		Unread:    unread,
+		Relevant:  relevant,
+		Shown:     len(m.shownArticles()),
+		Queue:     m.showQueue,
+		Filter:    m.filterInput.Value(),
+		Search:    m.searchQuery,
+		LastFetch: since(m.lastFetchAt, time.Now()),
+		Network:   onlineState(m.checkedConnection, m.online),
+		Ollama:    onlineState(m.checkedConnection, m.ollamaOnline),
+		ReadOnly:  m.readOnly != "",
+		Keys:      keys,
+	}
+	var s strings.Builder
+	if err := m.statusBar.Execute(&s, data); err != nil {
+		return errorStyle.Render(fmt.Sprintf("ui.status_bar: %v", err))
+	}
+	line := strings.ReplaceAll(strings.TrimSpace(s.String()), "\n", " ")
+	return helpStyle.Render(truncate(line, m.width))
+}
+
+func onlineState(checked, online bool) string {
+	switch {
+	case !checked:
+		return "unknown"
+	case online:
+		return "online"
+	}
+	return "offline"
+}
+
+// since describes how long ago t was, "never" when it is zero
+func since(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case t.IsZero():
+		return "never"
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamID identifies what a streamed response belongs to, so pieces that
+// arrive after the user moved on can be told apart from the current ones
+type streamID struct {
+	kind      string // "chat", "briefing"
+	articleID int64
+}
+
+// streamTokenMsg carries the next piece of a streamed response
+type streamTokenMsg struct {
+	id    streamID
+	token string
+}
+
+// streamDoneMsg ends a streamed response with the full text or an error
+type streamDoneMsg struct {
+	id   streamID
+	text string
+	err  error
+}
+
+// startStream runs generate in the background, sending each token and then
+// the final result through the returned channel, which is closed afterwards.
+// Read it with waitForStream, re-issuing the command after every message.
+// A panic in generate is recorded by crash and sent on as a crashMsg.
+func startStream(crash *crashRecorder, id streamID, generate func(onToken func(string)) (string, error)) chan tea.Msg {
+	ch := make(chan tea.Msg)
+	go func() {
+		defer close(ch)
+		defer crash.rescue(func(msg tea.Msg) { ch <- msg })
+		text, err := generate(func(token string) {
+			ch <- streamTokenMsg{id: id, token: token}
+		})
+		ch <- streamDoneMsg{id: id, text: text, err: err}
+	}()
+	return ch
+}
+
+// waitForStream delivers the next message from a streamed response
+func waitForStream(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// handleStream routes a streamed token or result to the view it belongs to
+func (m Model) handleStream(id streamID, msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch id.kind {
+	case "chat":
+		return m.handleChatStream(msg)
+	case "briefing":
+		return m.handleBriefingStream(msg)
+	}
+	return m, nil
+}
@@ -0,0 +1,253 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+const (
+	// timelineSimilarity is how similar an article has to be to count as
+	// the same story on its own
+	timelineSimilarity = 0.85
+	// timelineLooseSimilarity is how similar it has to be when it also names
+	// someone or something the selected article does
+	timelineLooseSimilarity = 0.7
+	// timelineCount caps how many other articles the timeline lists
+	timelineCount = 20
+)
+
+// commonTitleWords are capitalized in headlines without naming anything
+var commonTitleWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"how": true, "why": true, "what": true, "when": true, "who": true, "this": true,
+	"that": true, "new": true, "now": true, "its": true, "are": true, "was": true,
+	"will": true, "has": true, "have": true, "after": true, "over": true, "about": true,
+	"you": true, "your": true, "our": true, "more": true, "than": true, "not": true,
+}
+
+// timelineEntry is an article of a story's timeline
+type timelineEntry struct {
+	article models.Article
+	shared  []string // names it shares with the selected article
+	source  bool     // the selected article itself
+	listed  bool     // in the article list, so it can be read in the reader
+}
+
+type timelineMsg struct {
+	source  models.Article
+	entries []timelineEntry
+}
+
+// storyTerms are the names an article mentions as far as its title and
+// tags tell: its tags, and the capitalized words and version numbers of its
+// title, lowercased
+func storyTerms(article models.Article) map[string]bool {
+	terms := map[string]bool{}
+	for _, tag := range article.Tags {
+		terms[strings.ToLower(tag)] = true
+	}
+	words := strings.FieldsFunc(article.Title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-'
+	})
+	for _, word := range words {
+		word = strings.Trim(word, ".-")
+		lower := strings.ToLower(word)
+		if len(word) < 2 || commonTitleWords[lower] {
+			continue
+		}
+		first := []rune(word)[0]
+		if unicode.IsUpper(first) || strings.ContainsFunc(word, unicode.IsDigit) {
+			terms[lower] = true
+		}
+	}
+	return terms
+}
+
+// addEntityTerms adds the names of the entities extracted from an article
+// to its terms. Articles not looked at yet have none.
+func addEntityTerms(db database.Store, articleID int64, terms map[string]bool) {
+	entities, err := db.GetArticleEntities(articleID)
+	if err != nil {
+		return
+	}
+	for _, e := range entities {
+		terms[strings.ToLower(e.Name)] = true
+	}
+}
+
+// sharedTerms lists the terms of b also in a, sorted
+func sharedTerms(a, b map[string]bool) []string {
+	var shared []string
+	for term := range b {
+		if a[term] {
+			shared = append(shared, term)
+		}
+	}
+	slices.Sort(shared)
+	return shared
+}
+
+// findTimeline looks up the stored articles, read or not, about the same
+// story as source: very similar ones, and fairly similar ones naming
+// someone or something it does. They are ordered by publication date.
+func findTimeline(aiClient *ai.Client, db database.Store, source models.Article, loaded []models.Article) tea.Cmd {
+	return func() tea.Msg {
+		matches, err := aiClient.SimilarStored(&source, timelineLooseSimilarity)
+		if err != nil {
+			return errorMsg{fmt.Errorf("finding the story's articles: %w", err)}
+		}
+
+		listed := make(map[int64]models.Article, len(loaded))
+		for _, a := range loaded {
+			listed[a.ID] = a
+		}
+		terms := storyTerms(source)
+		addEntityTerms(db, source.ID, terms)
+
+		entries := []timelineEntry{{article: source, source: true, listed: true}}
+		for _, match := range matches {
+			if len(entries) > timelineCount {
+				break
+			}
+			a, ok := listed[match.ArticleID]
+			if !ok {
+				stored, err := db.GetArticleByID(match.ArticleID)
+				if err != nil || stored == nil {
+					continue
+				}
+				a = *stored
+			}
+			other := storyTerms(a)
+			addEntityTerms(db, a.ID, other)
+			shared := sharedTerms(terms, other)
+			if match.Similarity < timelineSimilarity && len(shared) == 0 {
+				continue
+			}
+			entries = append(entries, timelineEntry{article: a, shared: shared, listed: ok})
+		}
+
+		slices.SortStableFunc(entries, func(a, b timelineEntry) int {
+			return a.article.PublishedAt.Compare(b.article.PublishedAt)
+		})
+		return timelineMsg{source: source, entries: entries}
+	}
+}
+
+// showTimeline starts looking up the story of the given article
+func (m Model) showTimeline(article models.Article) (tea.Model, tea.Cmd) {
+	m.statusMsg = "Following the story..."
+	return m, findTimeline(m.aiClient, m.db, article, m.allArticles)
+}
+
+// handleTimeline shows a looked up timeline, the selected article selected
+func (m Model) handleTimeline(msg timelineMsg) Model {
+	m.timeline = msg.entries
+	m.timelineSource = msg.source
+	m.timelineCursor = slices.IndexFunc(msg.entries, func(e timelineEntry) bool { return e.source })
+	m.statusMsg = ""
+	m.view = ViewTimeline
+	return m
+}
+
+func (m Model) handleTimelineKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Timeline.Back):
+		m.view = ViewArticleDetail
+	case key.Matches(msg, upKey):
+		if m.timelineCursor > 0 {
+			m.timelineCursor--
+		}
+	case key.Matches(msg, downKey):
+		if m.timelineCursor < len(m.timeline)-1 {
+			m.timelineCursor++
+		}
+	case key.Matches(msg, keys.Timeline.Open):
+		if m.timelineCursor >= len(m.timeline) {
+			return m, nil
+		}
+		entry := m.timeline[m.timelineCursor]
+		if !entry.listed {
+			// Read or snoozed articles aren't in the list the reader acts on
+			return m.openTimelineInBrowser(entry.article)
+		}
+		article, err := withContent(m.db, entry.article)
+		if err != nil {
+			return m, func() tea.Msg { return errorMsg{err} }
+		}
+		return m.openArticle(article), m.explainMetadata(article)
+	case key.Matches(msg, keys.Timeline.Browser):
+		if m.timelineCursor < len(m.timeline) {
+			return m.openTimelineInBrowser(m.timeline[m.timelineCursor].article)
+		}
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+	}
+	return m, nil
+}
+
+func (m Model) openTimelineInBrowser(article models.Article) (tea.Model, tea.Cmd) {
+	browse, err := m.openInBrowser(article)
+	if err != nil {
+		return m, func() tea.Msg { return errorMsg{err} }
+	}
+	return m, tea.Batch(browse, recordOpen(m.db, article))
+}
+
+func (m Model) renderTimeline() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Story Timeline"))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(truncate(m.timelineSource.Title, max(m.width-2, 20))))
+	s.WriteString("\n\n")
+
+	if len(m.timeline) <= 1 {
+		s.WriteString("No other stored articles about this story.\n")
+	}
+	for i, e := range m.timeline {
+		cursor := "  "
+		if i == m.timelineCursor {
+			cursor = "> "
+		}
+		marker := "  "
+		if e.source {
+			marker = "● "
+		}
+		date := "undated     "
+		if !e.article.PublishedAt.IsZero() {
+			date = e.article.PublishedAt.Local().Format("Jan 02 15:04")
+		}
+		line := truncate(fmt.Sprintf("%s  %s%s", date, marker, e.article.Title), max(m.width-30, 20))
+		if !e.listed {
+			line = helpStyle.Render(line)
+		}
+		s.WriteString(cursor + line)
+		details := "  " + e.article.FeedName
+		if len(e.shared) > 0 {
+			details += " · " + strings.Join(e.shared, ", ")
+		}
+		s.WriteString(helpStyle.Render(truncate(details, 40)))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("Oldest first; ● is the selected article. Dimmed ones are read or not in the list and open in the browser."))
+	s.WriteString("\n")
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓: select • enter: open • o: browser • esc: back to article"))
+
+	return s.String()
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// unreadCounts returns the number of unread articles and of those scoring
+// at least notify.threshold
+func (m Model) unreadCounts() (unread, relevant int) {
+	for _, a := range m.allArticles {
+		if a.Scored && a.RelevanceScore >= m.cfg.Notify.Threshold {
+			relevant++
+		}
+	}
+	return len(m.allArticles), relevant
+}
+
+// windowTitle is the terminal title with the number of unread articles and
+// of those scoring at least notify.threshold, or "" while the queue is
+// shown or the unread list has yet to load
+func (m Model) windowTitle() string {
+	if m.showQueue || (m.allArticles == nil && m.title == "") {
+		return ""
+	}
+	unread, relevant := m.unreadCounts()
+	if relevant == 0 {
+		return fmt.Sprintf("newsreadr: %d unread", unread)
+	}
+	return fmt.Sprintf("newsreadr: %d unread, %d relevant", unread, relevant)
+}
+
+// updateTitle sets the terminal title when ui.terminal_title is on and the
+// counts changed. tmux shows it as the pane title, and passes it on to the
+// outer terminal with set-titles on.
+func (m Model) updateTitle() (Model, tea.Cmd) {
+	if !m.cfg.UI.TerminalTitle {
+		return m, nil
+	}
+	title := m.windowTitle()
+	if title == "" || title == m.title {
+		return m, nil
+	}
+	m.title = title
+	return m, tea.SetWindowTitle(title)
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// severity says how serious a message is
+type severity int
+
+const (
+	severityInfo    severity = iota
+	severityWarning          // something went partly wrong, e.g. one feed failed
+	severityError            // an action failed
+)
+
+// How long a toast stays in the status bar
+const (
+	warningToastDuration = 6 * time.Second
+	errorToastDuration   = 10 * time.Second
+)
+
+var (
+	warningToastStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("0")).
+				Background(lipgloss.Color("214")).
+				Padding(0, 1)
+
+	errorToastStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("160")).
+			Bold(true).
+			Padding(0, 1)
+)
+
+// toast is a warning or error shown in place of the status message until it
+// expires. The id tells an expiry apart from that of an earlier toast.
+type toast struct {
+	id    int
+	level severity
+	text  string
+}
+
+type toastExpiredMsg struct {
+	id int
+}
+
+// showToast shows a warning or error and schedules its removal
+func (m Model) showToast(level severity, text string) (Model, tea.Cmd) {
+	m.toastSeq++
+	m.toast = toast{id: m.toastSeq, level: level, text: text}
+
+	duration := warningToastDuration
+	if level == severityError {
+		duration = errorToastDuration
+	}
+	id := m.toastSeq
+	return m, tea.Tick(duration, func(time.Time) tea.Msg {
+		return toastExpiredMsg{id}
+	})
+}
+
+// handleToastExpired clears the toast unless a newer one replaced it
+func (m Model) handleToastExpired(msg toastExpiredMsg) (tea.Model, tea.Cmd) {
+	if msg.id == m.toast.id {
+		m.toast = toast{}
+	}
+	return m, nil
+}
+
+// renderToast renders the current toast, or nothing when there is none
+func (m Model) renderToast() string {
+	switch {
+	case m.toast.text == "":
+		return ""
+	case m.toast.level == severityError:
+		return errorToastStyle.Render("Error: " + m.toast.text)
+	default:
+		return warningToastStyle.Render("Warning: " + m.toast.text)
+	}
+}
+
+// firstProblem fits joined errors on the toast's line: the first, and
+// how many more there were
+func firstProblem(err error) string {
+	lines := strings.FieldsFunc(err.Error(), func(r rune) bool { return r == '\n' })
+	if len(lines) <= 1 {
+		return strings.TrimSpace(err.Error())
+	}
+	return fmt.Sprintf("%s (and %d more)", lines[0], len(lines)-1)
+}
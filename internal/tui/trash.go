@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomaskoefod/newsreadr/internal/database"
+	"github.com/thomaskoefod/newsreadr/pkg/models"
+)
+
+type trashLoadedMsg struct {
+	trash []database.TrashedArticle
+}
+
+type articleRestoredMsg struct {
+	article models.Article
+	queued  bool
+}
+
+func loadTrash(db database.Store) tea.Cmd {
+	return func() tea.Msg {
+		trash, err := db.GetTrashedArticles()
+		if err != nil {
+			return errorMsg{err}
+		}
+		return trashLoadedMsg{trash}
+	}
+}
+
+// restoreArticle takes an article out of the trash. Articles past the max
+// age go to the read-later queue, as the next cleanup would delete them
+// again otherwise.
+func restoreArticle(db database.Store, article models.Article, maxAge time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		queued, err := db.RestoreArticle(article.ID, maxAge)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return articleRestoredMsg{article: article, queued: queued}
+	}
+}
+
+func (m Model) handleTrashKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, quitKey):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Trash.Back):
+		m.view = ViewArticleList
+		return m, m.reloadArticles()
+	case key.Matches(msg, upKey):
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+	case key.Matches(msg, downKey):
+		if m.trashCursor < len(m.trash)-1 {
+			m.trashCursor++
+		}
+	case key.Matches(msg, keys.Trash.Restore):
+		if m.trashCursor < len(m.trash) {
+			maxAge := time.Duration(m.cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
+			return m, restoreArticle(m.db, m.trash[m.trashCursor].Article, maxAge)
+		}
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
+	}
+	return m, nil
+}
+
+func (m Model) handleTrashLoaded(msg trashLoadedMsg) (tea.Model, tea.Cmd) {
+	m.trash = msg.trash
+	if m.trashCursor >= len(m.trash) {
+		m.trashCursor = max(len(m.trash)-1, 0)
+	}
+	return m, nil
+}
+
+func (m Model) handleArticleRestored(msg articleRestoredMsg) (tea.Model, tea.Cmd) {
+	m.statusMsg = fmt.Sprintf("Restored %q", msg.article.Title)
+	if msg.queued {
+		m.statusMsg += " to the read-later queue"
+	}
+	return m, loadTrash(m.db)
+}
+
+func (m Model) renderTrash() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Trash"))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(fmt.Sprintf("Deleted articles are kept for %d days", int(database.TrashRetention.Hours()/24))))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(fmt.Sprintf(
+		"Restored articles older than %d days go to the read-later queue, or the next cleanup would delete them again",
+		m.cfg.UI.ArticleMaxAgeDays)))
+	s.WriteString("\n\n")
+
+	if len(m.trash) == 0 {
+		s.WriteString("The trash is empty.\n")
+	}
+	for i, t := range m.trash {
+		cursor := "  "
+		if i == m.trashCursor {
+			cursor = "> "
+		}
+		s.WriteString(cursor + truncate(t.Article.Title, max(m.width-40, 20)))
+		s.WriteString(helpStyle.Render(fmt.Sprintf("  %s • deleted %s", t.Article.FeedName, t.DeletedAt.Local().Format("Jan 2 15:04"))))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	if m.statusMsg != "" {
+		s.WriteString(statusStyle.Render(m.statusMsg))
+		s.WriteString("\n")
+	}
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render("↑/↓: select • u: restore • esc: back • q: quit"))
+
+	return s.String()
+}
@@ -3,20 +3,25 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"text/template"
 	"time"
 
 	html2md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/thomaskoefod/newsreadr/internal/ai"
+	"github.com/thomaskoefod/newsreadr/internal/bookmark"
 	"github.com/thomaskoefod/newsreadr/internal/config"
 	"github.com/thomaskoefod/newsreadr/internal/database"
 	"github.com/thomaskoefod/newsreadr/internal/feed"
-	"github.com/thomaskoefod/newsreadr/internal/raindrop"
+	"github.com/thomaskoefod/newsreadr/internal/hooks"
+	"github.com/thomaskoefod/newsreadr/internal/notify"
+	"github.com/thomaskoefod/newsreadr/internal/query"
+	"github.com/thomaskoefod/newsreadr/internal/vector"
 	"github.com/thomaskoefod/newsreadr/pkg/models"
 )
 
@@ -26,34 +31,144 @@ const (
 	ViewArticleList View = iota
 	ViewArticleDetail
 	ViewHelp
+	ViewStats
+	ViewDrift
+	ViewHealth
+	ViewFetchSummary
+	ViewInterests
+	ViewRelated
+	ViewChat
+	ViewMessages
+	ViewCleanup
+	ViewTrash
+	ViewBriefing
+	ViewTimeline
+	ViewEntities
 )
 
 type Model struct {
 	cfg        *config.Config
-	db         *database.DB
+	db         database.Store
 	fetcher    *feed.Fetcher
 	aiClient   *ai.Client
-	rdClient   *raindrop.Client
+	saveTargets []bookmark.Target
+	saveTarget int // index in saveTargets being saved to
+	savePending bool
+	notifier   *notify.Dispatcher
+	hooks      *hooks.Runner
 	view       View
 	articles   []models.Article
 	allArticles []models.Article // Keep unfiltered list
+	calibration scoreCalibration // spreads the scores of the unread list
 	list       list.Model
 	viewport   viewport.Model
 	filterInput textinput.Model
+	filterExpr  query.Expr // parsed filter, for ranking and highlighting
+	muteInput   textinput.Model
+	isMuting    bool
+	noteInput   textinput.Model
+	isNoting    bool
 	isFiltering bool
+	snoozePending bool
+	sharePending bool
+	showQueue   bool
+	loadedQueue bool // the list holds the queue, which showQueue asks for
 	cursor     int
 	width      int
 	height     int
-	err        error
+	toast      toast
+	toastSeq   int
+	filterSeq  int // typing in the filter, see scheduleFilter
 	statusMsg  string
 	articleContent string
-	renderer   *glamour.TermRenderer
+	renderers  *rendererPool
+	rendered   *renderCache // article bodies rendered recently or prefetched
+	prefetchFor int64       // the article schedulePrefetch last saw selected
 	mdConverter *html2md.Converter
+	stats      *database.ReadingStats
+	drift      *ai.DriftReport
+	driftCursor int
+	feeds      []models.Feed
+	healthCursor int
+	cleanup    []database.CleanupCount
+	cleanupDays int // max age of the previewed cleanup
+	cleanupConfirm bool
+	trash      []database.TrashedArticle
+	trashCursor int
+	interests  []models.UserInterest
+	interestCursor int
+	interestInput textinput.Model
+	interestPrompt string
+	vectors    *vector.Index
+	related    []relatedArticle
+	relatedCursor int
+	relatedSource models.Article
+	timeline   []timelineEntry
+	timelineCursor int
+	timelineSource models.Article
+	entities   []database.EntityCount
+	entityCursor int
+	entityInput textinput.Model
+	extracting bool // an entity extraction pass is running
+	searchInput textinput.Model
+	isSearching bool
+	searchQuery string
+	chatInput  textinput.Model
+	chatViewport viewport.Model
+	chatMessages []ai.ChatMessage
+	chatArticleID int64
+	chatReply  string
+	chatBusy   bool
+	chatStream chan tea.Msg
+	// The briefing, the articles it cites in order, and whether it is
+	// still streaming in
+	briefingViewport viewport.Model
+	briefingText string
+	briefingSources []models.Article
+	briefingBusy bool
+	briefingStream chan tea.Msg
+	rescoring  bool
+	compactList bool
+	grouping   grouping
+	collapsed  map[string]bool // keys of the collapsed sections
+	sender     *sender
+	crash      *crashRecorder
+	showMetadata bool
+	breakdown  *ai.ScoreBreakdown
+	breakdownFor int64
+	breakdownErr error
+	helpViewport viewport.Model
+	helpReturn  View
+	messages   messageLog
+	// lastSession, until the banner is dismissed, tells which articles
+	// arrived since the last session
+	lastSession *database.Session
+	messagesViewport viewport.Model
+	messagesReturn View
+	discovered *feedsDiscoveredMsg
+	lastFetch  *feed.Summary
+	lastFetchNotifyErr error
+	checkedConnection bool
+	online     bool
+	ollamaOnline bool
+	// readOnly says why this instance doesn't fetch, score or clean up,
+	// empty unless another instance holds the database lock
+	readOnly   string
+	title      string // terminal title last set
+	statusBar  *template.Template // ui.status_bar, nil for the key hints
+	// The wide layout's focused pane, feed picked in the feeds pane ("" for
+	// all) and preview of the selected article
+	pane       pane
+	feedFilter string
+	preview    viewport.Model
+	previewFor int64 // article in the preview, -1 for none, 0 to redraw
+	lastFetchAt time.Time
 	ready      bool
 }
 
 type articlesLoadedMsg struct {
 	articles []models.Article
+	queue    bool // loaded from the read-later queue rather than the unread list
 }
 
 type errorMsg struct {
@@ -74,6 +189,9 @@ var (
 	errorStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("196"))
 
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214"))
+
 	statusStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("42"))
 
@@ -87,61 +205,130 @@ var (
 			Bold(true)
 )
 
-func New(cfg *config.Config, db *database.DB, fetcher *feed.Fetcher, aiClient *ai.Client, rdClient *raindrop.Client) Model {
+func New(cfg *config.Config, db database.Store, fetcher *feed.Fetcher, aiClient *ai.Client, saveTargets []bookmark.Target, notifier *notify.Dispatcher) Model {
 	items := []list.Item{}
-	delegate := list.NewDefaultDelegate()
-	l := list.New(items, delegate, 0, 0)
-	l.Title = "NewsReadr - Your Personalized News"
+	compact := cfg.UI.ListMode == "compact"
+	l := list.New(items, newArticleDelegate(compact), 0, 0)
+	l.Title = listTitle
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(false) // Disable built-in filtering, we'll use our own
 	l.Styles.Title = titleStyle
 
-	// Create glamour renderer for markdown
-	renderer, _ := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(100),
-	)
-
 	// Create HTML to Markdown converter
-	converter := html2md.NewConverter("", true, nil)
+	converter := newConverter()
+
+	// Checked when the config was loaded
+	statusBar, _ := parseStatusBar(cfg.UI.StatusBar)
 
 	// Create filter input
 	ti := textinput.New()
-	ti.Placeholder = "Type to filter articles..."
+	ti.Placeholder = "words, feed:name, tag:go, score>0.7, /regex/"
 	ti.CharLimit = 100
 	ti.Width = 50
 
+	mi := textinput.New()
+	mi.Placeholder = "keyword or /regex/"
+	mi.CharLimit = 100
+	mi.Width = 50
+
+	ni := textinput.New()
+	ni.Placeholder = "why you are saving it"
+	ni.CharLimit = 500
+	ni.Width = 60
+
+	ii := textinput.New()
+	ii.Placeholder = "topic description"
+	ii.CharLimit = 200
+	ii.Width = 50
+
+	si := textinput.New()
+	si.Placeholder = "e.g. new approaches to battery storage"
+	si.CharLimit = 200
+	si.Width = 50
+
+	ci := textinput.New()
+	ci.Placeholder = "ask a question about the article"
+	ci.CharLimit = 500
+	ci.Width = 80
+
+	ei := textinput.New()
+	ei.Placeholder = "e.g. Kubernetes"
+	ei.CharLimit = 100
+	ei.Width = 50
+
 	return Model{
 		cfg:         cfg,
 		db:          db,
 		fetcher:     fetcher,
 		aiClient:    aiClient,
-		rdClient:    rdClient,
+		saveTargets: saveTargets,
+		notifier:    notifier,
+		hooks:       hooks.New(cfg.Hooks),
 		view:        ViewArticleList,
 		list:        l,
-		renderer:    renderer,
+		renderers:   newRendererPool(cfg.UI.CodeTheme),
+		rendered:    newRenderCache(renderCacheSize),
 		mdConverter: converter,
 		filterInput: ti,
+		muteInput:   mi,
+		noteInput:   ni,
+		interestInput: ii,
+		searchInput: si,
+		chatInput:   ci,
+		entityInput: ei,
+		compactList: compact,
+		grouping:    parseGrouping(cfg.UI.GroupBy),
+		collapsed:   map[string]bool{},
+		calibration: newScoreCalibration(cfg.Scoring, nil),
+		sender:      &sender{},
+		crash:       &crashRecorder{},
+		statusBar:   statusBar,
 		isFiltering: false,
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	var lastFetch tea.Cmd
+	if m.statusBar != nil {
+		lastFetch = loadLastFetch(m.db)
+	}
+	return m.crash.guard(tea.Batch(
 		loadArticles(m.db, m.cfg),
-		fetchFeeds(m.fetcher, m.db, m.aiClient, m.cfg),
+		loadLastSession(m.db),
+		lastFetch,
+		checkConnectivity(m.fetcher, m.aiClient, false),
+		buildIndex(m.aiClient),
 		tea.EnterAltScreen,
-	)
+	))
 }
 
+// Update handles a message, then records any status message or toast it produced
+// in the message history and updates the terminal title. Panics, in it and
+// in the commands it returns, are recorded for the crash report.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer m.crash.catch()
+	m.crash.observe(msg)
+	prevStatus, prevToast := m.statusMsg, m.toast.id
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		var title, prefetch tea.Cmd
+		nm, title = nm.recordMessages(prevStatus, prevToast).updatePreview().updateTitle()
+		nm, prefetch = nm.schedulePrefetch()
+		next, cmd = nm, tea.Batch(cmd, title, prefetch)
+	}
+	return next, m.crash.guard(cmd)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
+		wasWide := m.wide()
 		m.width = msg.Width
 		m.height = msg.Height
-		m.list.SetSize(msg.Width, msg.Height-4)
+		m.list.SetSize(m.listWidth(), msg.Height-4)
+		m = m.resizePanes(wasWide)
 		
 		if !m.ready {
 			m.viewport = viewport.New(msg.Width, msg.Height-6)
@@ -151,58 +338,266 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - 6
 		}
+		m.chatViewport.Width = msg.Width
+		m.chatViewport.Height = max(msg.Height-6, 5)
+		m.helpViewport.Width = msg.Width
+		m.helpViewport.Height = max(msg.Height-2, 5)
+		m.messagesViewport.Width = msg.Width
+		m.messagesViewport.Height = max(msg.Height-4, 5)
+		m.briefingViewport.Width = msg.Width
+		m.briefingViewport.Height = max(msg.Height-4, 5)
+		if m.view == ViewChat {
+			m.chatViewport.SetContent(m.renderChatLog())
+		}
+		if m.view == ViewBriefing {
+			m.briefingViewport.SetContent(m.renderBriefingText())
+		}
+
+		// Articles are wrapped, and their tables fitted, to the new width;
+		// those rendered for others are of no more use
+		m.rendered.retain(m.renderKeys()...)
+		if i, ok := m.list.SelectedItem().(articleItem); ok && m.view == ViewArticleDetail {
+			m = m.refreshDetail(i.article)
+		}
 		
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.isMuting {
+			return m.handleMuteInput(msg)
+		}
+		if m.isNoting {
+			return m.handleNoteInput(msg)
+		}
+		if m.interestPrompt != "" {
+			return m.handleInterestInput(msg)
+		}
+		if m.isSearching {
+			return m.handleSearchInput(msg)
+		}
+		if m.view == ViewChat {
+			return m.handleChatKeys(msg)
+		}
+		if m.view == ViewEntities {
+			return m.handleEntitiesKeys(msg)
+		}
+
 		// Handle filter input first if we're in filtering mode
 		if m.isFiltering && m.view == ViewArticleList {
-			switch msg.String() {
-			case "esc":
+			switch {
+			case key.Matches(msg, keys.Filter.Cancel):
 				m.isFiltering = false
+				m.filterSeq++
 				m.filterInput.SetValue("")
 				m.filterInput.Blur()
+				m.filterExpr = nil
 				// Reset to all articles
 				m.articles = m.allArticles
-				items := make([]list.Item, len(m.articles))
-				for i, article := range m.articles {
-					items[i] = articleItem{article}
-				}
-				m.list.SetItems(items)
+				m.list.SetItems(m.listItems())
 				m.statusMsg = fmt.Sprintf("Showing all %d articles", len(m.articles))
 				return m, nil
-			case "enter":
+			case key.Matches(msg, keys.Filter.Apply):
 				m.isFiltering = false
 				m.filterInput.Blur()
+				// Without waiting for a pause in typing
+				m.filterSeq++
+				m.applyFilter(true)
 				m.statusMsg = fmt.Sprintf("Filtered to %d articles", len(m.articles))
 				return m, nil
 			default:
 				// Pass input to the textinput
+				value := m.filterInput.Value()
 				m.filterInput, cmd = m.filterInput.Update(msg)
-				m.applyFilter()
-				return m, cmd
+				if m.filterInput.Value() == value {
+					// The cursor moved
+					return m, cmd
+				}
+				var filterCmd tea.Cmd
+				m, filterCmd = m.scheduleFilter()
+				return m, tea.Batch(cmd, filterCmd)
 			}
 		}
 		
 		return m.handleKeyPress(msg)
 
 	case articlesLoadedMsg:
+		if msg.queue != m.showQueue {
+			// Loaded for the other list, fetch the one being shown instead
+			return m, m.reloadArticles()
+		}
+		// Keep the place in the same list, e.g. when pressing r
+		var rows []list.Item
+		selected := m.list.Index()
+		if msg.queue == m.loadedQueue {
+			rows = m.list.Items()
+		}
+		m.loadedQueue = msg.queue
+		if m.searchQuery != "" {
+			m = m.clearSearch()
+		}
 		m.articles = msg.articles
 		m.allArticles = msg.articles // Store unfiltered list
-		items := make([]list.Item, len(m.articles))
-		for i, article := range m.articles {
-			items[i] = articleItem{article}
+		if !msg.queue {
+			m.calibration = newScoreCalibration(m.cfg.Scoring, msg.articles)
 		}
-		m.list.SetItems(items)
-		m.list.SetSize(m.width, m.height-4) // Force layout recalculation
-		m.list.ResetSelected()
+		m.list.SetItems(m.listItems())
+		m.list.SetSize(m.listWidth(), m.height-4) // Force layout recalculation
+		m.selectNearest(rows, selected)
 		m.statusMsg = fmt.Sprintf("Loaded %d articles", len(m.articles))
 		return m, nil
 
-	case errorMsg:
-		m.err = msg.err
+	case lastSessionMsg:
+		m.lastSession = msg.session
+		return m, nil
+
+	case mutedMsg:
+		m.statusMsg = mutedStatus(msg)
+		if msg.hidden == 0 {
+			return m, nil
+		}
+		m.view = ViewArticleList
+		return m, m.reloadArticles()
+
+	case statsLoadedMsg:
+		m.stats = msg.stats
+		return m, nil
+
+	case cleanupPreviewMsg:
+		return m.handleCleanupPreview(msg)
+
+	case cleanupDoneMsg:
+		return m.handleCleanupDone(msg)
+
+	case trashLoadedMsg:
+		return m.handleTrashLoaded(msg)
+
+	case articleRestoredMsg:
+		return m.handleArticleRestored(msg)
+
+	case archivedMsg:
+		return m.handleArchived(msg)
+
+	case pdfDownloadedMsg:
+		m.statusMsg = "Saved the PDF to " + msg.path
+		return m, nil
+
+	case driftLoadedMsg:
+		m.drift = msg.report
+		m.driftCursor = 0
+		return m, nil
+
+	case connectivityMsg:
+		return m.handleConnectivity(msg)
+
+	case perFeedDoneMsg:
+		return m.handlePerFeedDone(msg)
+
+	case articleScoredMsg:
+		return m.handleArticleScored(msg)
+
+	case rescoreDoneMsg:
+		return m.handleRescoreDone(msg)
+
+	case fetchDoneMsg:
+		return m.handleFetchDone(msg)
+
+	case streamTokenMsg:
+		return m.handleStream(msg.id, msg)
+
+	case streamDoneMsg:
+		return m.handleStream(msg.id, msg)
+
+	case crashMsg:
+		// Raised again here so Bubble Tea restores the terminal and quits
+		panic(msg.reason)
+
+	case searchResultsMsg:
+		return m.showSearchResults(msg), nil
+
+	case indexBuiltMsg:
+		m.vectors = msg.index
+		return m, nil
+
+	case relatedMsg:
+		m.related = msg.related
+		m.relatedSource = msg.source
+		m.relatedCursor = 0
+		m.statusMsg = ""
+		m.view = ViewRelated
+		return m, nil
+
+	case timelineMsg:
+		return m.handleTimeline(msg), nil
+
+	case entitiesLoadedMsg:
+		m.entities = msg.entities
+		return m, nil
+
+	case entityArticlesMsg:
+		return m.showEntityArticles(msg), nil
+
+	case entitiesExtractedMsg:
+		return m.handleEntitiesExtracted(msg)
+
+	case interestsLoadedMsg:
+		m.interests = msg.interests
+		if m.interestCursor >= len(m.interests) {
+			m.interestCursor = max(len(m.interests)-1, 0)
+		}
+		if msg.status != "" {
+			m.statusMsg = msg.status
+		}
+		return m, nil
+
+	case lastFetchLoadedMsg:
+		if m.lastFetchAt.IsZero() {
+			m.lastFetchAt = msg.at
+		}
 		return m, nil
 
+	case feedsLoadedMsg:
+		m.feeds = msg.feeds
+		if m.healthCursor >= len(m.feeds) {
+			m.healthCursor = 0
+		}
+		return m, nil
+
+	case feedsDiscoveredMsg:
+		m.discovered = &msg
+		m.statusMsg = fmt.Sprintf("Found %d feeds", len(msg.candidates))
+		return m, nil
+
+	case feedReplacedMsg:
+		m.discovered = nil
+		m.statusMsg = fmt.Sprintf("Feed URL changed to %s", msg.url)
+		return m, loadFeeds(m.db)
+
+	case interestAdoptedMsg:
+		m.removeDriftTopic(msg.label)
+		m.statusMsg = fmt.Sprintf("Added interest %q", msg.label)
+		return m, nil
+
+	case errorMsg:
+		return m.showToast(severityError, msg.err.Error())
+
+	case offlineCachedMsg:
+		return m.handleOfflineCached(msg)
+
+	case articleSavedMsg:
+		return m.handleArticleSaved(msg)
+
+	case scoreExplainedMsg:
+		return m.handleScoreExplained(msg)
+
+	case toastExpiredMsg:
+		return m.handleToastExpired(msg)
+
+	case filterDueMsg:
+		return m.handleFilterDue(msg)
+
+	case prefetchDueMsg:
+		return m.handlePrefetchDue(msg)
+
 	case statusMsg:
 		m.statusMsg = string(msg)
 		return m, nil
@@ -218,6 +613,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.snoozePending {
+		return m.handleSnoozeKey(msg)
+	}
+	if m.sharePending {
+		return m.handleShareKey(msg)
+	}
+	if m.savePending {
+		return m.handleSaveKey(msg)
+	}
+
 	switch m.view {
 	case ViewArticleList:
 		return m.handleListKeys(msg)
@@ -225,50 +630,208 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleDetailKeys(msg)
 	case ViewHelp:
 		return m.handleHelpKeys(msg)
+	case ViewStats:
+		return m.handleStatsKeys(msg)
+	case ViewDrift:
+		return m.handleDriftKeys(msg)
+	case ViewHealth:
+		return m.handleHealthKeys(msg)
+	case ViewFetchSummary:
+		return m.handleFetchSummaryKeys(msg)
+	case ViewInterests:
+		return m.handleInterestsKeys(msg)
+	case ViewRelated:
+		return m.handleRelatedKeys(msg)
+	case ViewMessages:
+		return m.handleMessagesKeys(msg)
+	case ViewCleanup:
+		return m.handleCleanupKeys(msg)
+	case ViewTrash:
+		return m.handleTrashKeys(msg)
+	case ViewBriefing:
+		return m.handleBriefingKeys(msg)
+	case ViewTimeline:
+		return m.handleTimelineKeys(msg)
 	}
 	return m, nil
 }
 
 func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	if m.wide() {
+		var handled bool
+		if m, handled = m.handlePaneKeys(msg); handled {
+			return m, nil
+		}
+	}
+
+	switch {
+	case key.Matches(msg, quitKey):
 		return m, tea.Quit
 
-	case "enter":
+	case key.Matches(msg, keys.List.Open):
 		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			article, err := withContent(m.db, i.article)
+			if err != nil {
+				return m, func() tea.Msg { return errorMsg{err} }
+			}
+			// Kept on the row for the actions of the article view
+			m.updateArticleItem(article)
 			m.view = ViewArticleDetail
-			content := m.formatArticleForView(i.article)
+			content := m.formatArticleForView(article)
 			m.articleContent = content
 			m.viewport.SetContent(content)
 			m.viewport.GotoTop()
+			return m, m.explainMetadata(article)
+		}
+		if s, ok := m.list.SelectedItem().(sectionItem); ok {
+			m = m.setSectionCollapsed(!s.collapsed)
 			return m, nil
 		}
 
-	case "/", "f":
+	case key.Matches(msg, keys.List.Collapse) && m.grouping != groupNone && m.searchQuery == "":
+		m = m.setSectionCollapsed(true)
+		return m, nil
+
+	case key.Matches(msg, keys.List.Expand) && m.grouping != groupNone && m.searchQuery == "":
+		m = m.setSectionCollapsed(false)
+		return m, nil
+
+	case key.Matches(msg, keys.List.Browser):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			browse, err := m.openInBrowser(i.article)
+			if err != nil {
+				return m, func() tea.Msg { return errorMsg{err} }
+			}
+			m.statusMsg = "Opened in browser"
+			return m, tea.Batch(browse, recordOpen(m.db, i.article))
+		}
+
+	case key.Matches(msg, keys.List.BrowserRead):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.readInBrowser(i.article)
+		}
+
+	case key.Matches(msg, keys.List.Search):
+		return m.startSearch()
+
+	case key.Matches(msg, keys.List.ClearSearch):
+		if m.searchQuery != "" {
+			m = m.clearSearch()
+			m.statusMsg = fmt.Sprintf("Showing all %d articles", len(m.articles))
+		} else {
+			m.lastSession = nil // dismiss the banner
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.List.New):
+		m = m.showSinceLastSession()
+		return m, nil
+
+	case key.Matches(msg, keys.List.Filter):
 		m.isFiltering = true
 		m.filterInput.Focus()
 		return m, textinput.Blink
 
-	case "r":
+	case key.Matches(msg, keys.List.Refresh):
 		return m, tea.Batch(
-			loadArticles(m.db, m.cfg),
+			m.reloadArticles(),
 			func() tea.Msg { return statusMsg("Refreshing articles...") },
 		)
 
-	case "F":
-		return m, tea.Batch(
-			fetchFeeds(m.fetcher, m.db, m.aiClient, m.cfg),
-			func() tea.Msg { return statusMsg("Fetching new articles...") },
-		)
+	case key.Matches(msg, keys.List.Fetch):
+		return m.startFetch()
 
-	case "d":
-		return m, tea.Batch(
-			deleteOldArticles(m.db, m.cfg),
-			func() tea.Msg { return statusMsg("Deleting old articles...") },
-		)
+	case key.Matches(msg, keys.List.DeleteOld):
+		return m.openCleanup()
+
+	case key.Matches(msg, keys.List.Trash):
+		m.view = ViewTrash
+		m.statusMsg = ""
+		return m, loadTrash(m.db)
+
+	case key.Matches(msg, keys.List.Star):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.toggleStar(i.article)
+		}
+
+	case key.Matches(msg, keys.List.Queue):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.toggleQueued(i.article)
+		}
+
+	case key.Matches(msg, keys.List.QueueView):
+		return m.toggleQueueView()
+
+	case key.Matches(msg, keys.List.Share):
+		return m.startShare()
+
+	case key.Matches(msg, keys.List.Mute):
+		return m.startMuting()
+
+	case key.Matches(msg, keys.List.MuteDomain):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.muteDomain(i.article)
+		}
+
+	case key.Matches(msg, keys.List.Snooze):
+		if _, ok := m.list.SelectedItem().(articleItem); ok {
+			m.snoozePending = true
+			m.statusMsg = snoozePrompt
+			return m, nil
+		}
+
+	case key.Matches(msg, keys.List.Density):
+		m.compactList = !m.compactList
+		m.list.SetDelegate(newArticleDelegate(m.compactList))
+		m.statusMsg = "Detailed list"
+		if m.compactList {
+			m.statusMsg = "Compact list"
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.List.Group):
+		m = m.cycleGrouping()
+		return m, nil
+
+	case key.Matches(msg, keys.List.Messages):
+		m = m.openMessages()
+		return m, nil
 
-	case "?":
-		m.view = ViewHelp
+	case key.Matches(msg, keys.List.Briefing):
+		return m.openBriefing()
+
+	case key.Matches(msg, keys.List.Entities):
+		return m.openEntities()
+
+	case key.Matches(msg, keys.List.Stats):
+		m.view = ViewStats
+		return m, loadStats(m.db)
+
+	case key.Matches(msg, keys.List.LastFetch):
+		if m.lastFetch != nil {
+			m.view = ViewFetchSummary
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.List.Health):
+		m.view = ViewHealth
+		m.discovered = nil
+		return m, loadFeeds(m.db)
+
+	case key.Matches(msg, keys.List.Interests):
+		m.view = ViewInterests
+		return m, loadInterests(m.db, "")
+
+	case key.Matches(msg, keys.List.Drift):
+		if m.checkedConnection && !m.ollamaOnline {
+			return m.showToast(severityWarning, "Interest drift needs Ollama, which is unreachable")
+		}
+		m.view = ViewDrift
+		m.drift = nil
+		return m, analyzeDrift(m.aiClient)
+
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
 		return m, nil
 	}
 
@@ -278,63 +841,121 @@ func (m Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleDetailKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	switch {
+	case key.Matches(msg, quitKey):
 		return m, tea.Quit
 
-	case "esc", "backspace":
+	case key.Matches(msg, keys.Detail.Back):
 		m.view = ViewArticleList
 		return m, nil
 
-	case "enter":
+	case key.Matches(msg, keys.Detail.MarkRead):
 		// Mark as read and delete
 		if i, ok := m.list.SelectedItem().(articleItem); ok {
 			m.db.MarkArticleRead(i.article.ID)
 			m.db.DeleteReadArticles()
 			m.view = ViewArticleList
 			return m, tea.Batch(
-				loadArticles(m.db, m.cfg),
+				m.reloadArticles(),
 				func() tea.Msg { return statusMsg("Article marked as read") },
+				fireHook(m.hooks, m.db, hooks.ArticleRead, i.article),
 			)
 		}
 
-	case "o":
+	case key.Matches(msg, keys.Detail.Browser):
 		// Open in browser
 		if i, ok := m.list.SelectedItem().(articleItem); ok {
-			openBrowser(i.article.URL)
-			return m, func() tea.Msg { return statusMsg("Opened in browser") }
+			browse, err := m.openInBrowser(i.article)
+			if err != nil {
+				return m, func() tea.Msg { return errorMsg{err} }
+			}
+			return m, tea.Batch(
+				browse,
+				func() tea.Msg { return statusMsg("Opened in browser") },
+				recordOpen(m.db, i.article),
+			)
 		}
 
-	case "s":
-		// Send to Raindrop
+	case key.Matches(msg, keys.Detail.BrowserRead):
 		if i, ok := m.list.SelectedItem().(articleItem); ok {
-			if err := m.rdClient.SaveArticle(&i.article); err != nil {
-				return m, func() tea.Msg { return errorMsg{err} }
-			}
-			return m, func() tea.Msg { return statusMsg("Saved to Raindrop.io") }
+			return m.readInBrowser(i.article)
 		}
 
-	case "?":
-		m.view = ViewHelp
+	case key.Matches(msg, keys.Detail.Save):
+		return m.startSave()
+
+	case key.Matches(msg, keys.Detail.Star):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.toggleStar(i.article)
+		}
+
+	case key.Matches(msg, keys.Detail.Queue):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.toggleQueued(i.article)
+		}
+
+	case key.Matches(msg, keys.Detail.MuteDomain):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.muteDomain(i.article)
+		}
+
+	case key.Matches(msg, keys.Detail.Related):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.showRelated(i.article)
+		}
+
+	case key.Matches(msg, keys.Detail.Timeline):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.showTimeline(i.article)
+		}
+
+	case key.Matches(msg, keys.Detail.Archive):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.loadArchived(i.article)
+		}
+
+	case key.Matches(msg, keys.Detail.PDF):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.downloadPDF(i.article)
+		}
+
+	case key.Matches(msg, keys.Detail.Ask):
+		if i, ok := m.list.SelectedItem().(articleItem); ok {
+			return m.openChat(i.article)
+		}
+
+	case key.Matches(msg, keys.Detail.Share):
+		return m.startShare()
+
+	case key.Matches(msg, keys.Detail.Metadata):
+		return m.toggleMetadata()
+
+	case key.Matches(msg, keys.Detail.Snooze):
+		m.snoozePending = true
+		m.statusMsg = snoozePrompt
+		return m, nil
+
+	case key.Matches(msg, helpKey):
+		m = m.openHelp()
 		return m, nil
 	
 	// Scroll controls
-	case "up", "k":
+	case key.Matches(msg, keys.Detail.LineUp):
 		m.viewport.LineUp(1)
 		return m, nil
-	case "down", "j":
+	case key.Matches(msg, keys.Detail.LineDown):
 		m.viewport.LineDown(1)
 		return m, nil
-	case "pgup", "b":
+	case key.Matches(msg, keys.Detail.PageUp):
 		m.viewport.ViewUp()
 		return m, nil
-	case "pgdown", "f", " ":
+	case key.Matches(msg, keys.Detail.PageDown):
 		m.viewport.ViewDown()
 		return m, nil
-	case "home", "g":
+	case key.Matches(msg, keys.Detail.Top):
 		m.viewport.GotoTop()
 		return m, nil
-	case "end", "G":
+	case key.Matches(msg, keys.Detail.Bottom):
 		m.viewport.GotoBottom()
 		return m, nil
 	}
@@ -342,20 +963,30 @@ func (m Model) handleDetailKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openHelp shows the help screen, returning to the current view when closed
+func (m Model) openHelp() Model {
+	m.helpReturn = m.view
+	m.view = ViewHelp
+	m.helpViewport = viewport.New(m.width, max(m.height-2, 5))
+	m.helpViewport.SetContent(renderKeyHelp())
+	return m
+}
+
 func (m Model) handleHelpKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc", "?", "q":
-		if m.articleContent != "" {
-			m.view = ViewArticleDetail
-		} else {
-			m.view = ViewArticleList
-		}
+	switch {
+	case key.Matches(msg, keys.Help.Close):
+		m.view = m.helpReturn
 		return m, nil
+	case key.Matches(msg, keys.Help.Scroll):
+		var cmd tea.Cmd
+		m.helpViewport, cmd = m.helpViewport.Update(msg)
+		return m, cmd
 	}
 	return m, nil
 }
 
 func (m Model) View() string {
+	defer m.crash.catch()
 	switch m.view {
 	case ViewArticleList:
 		return m.renderList()
@@ -363,6 +994,32 @@ func (m Model) View() string {
 		return m.renderDetail()
 	case ViewHelp:
 		return m.renderHelp()
+	case ViewStats:
+		return m.renderStats()
+	case ViewDrift:
+		return m.renderDrift()
+	case ViewHealth:
+		return m.renderHealth()
+	case ViewFetchSummary:
+		return m.renderFetchSummary()
+	case ViewInterests:
+		return m.renderInterests()
+	case ViewRelated:
+		return m.renderRelated()
+	case ViewChat:
+		return m.renderChat()
+	case ViewMessages:
+		return m.renderMessages()
+	case ViewCleanup:
+		return m.renderCleanup()
+	case ViewTrash:
+		return m.renderTrash()
+	case ViewBriefing:
+		return m.renderBriefing()
+	case ViewTimeline:
+		return m.renderTimeline()
+	case ViewEntities:
+		return m.renderEntities()
 	}
 	return ""
 }
@@ -370,6 +1027,14 @@ func (m Model) View() string {
 func (m Model) renderList() string {
 	var s strings.Builder
 
+	if m.isMuting {
+		s.WriteString(m.renderMuteInput())
+	}
+	if m.isSearching {
+		s.WriteString(m.renderSearchInput())
+	}
+	s.WriteString(m.renderBanner())
+
 	// Show filter input if active
 	if m.isFiltering {
 		s.WriteString(filterStyle.Render("Filter: "))
@@ -378,18 +1043,23 @@ func (m Model) renderList() string {
 		s.WriteString("\n\n")
 	}
 
-	s.WriteString(m.list.View())
+	if m.wide() {
+		s.WriteString(m.renderPanes())
+	} else {
+		s.WriteString(m.list.View())
+	}
 	s.WriteString("\n")
 
 	// Status bar
-	if m.err != nil {
-		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+	s.WriteString(m.connectionBadge())
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
 	} else if m.statusMsg != "" {
 		s.WriteString(statusStyle.Render(m.statusMsg))
 	}
 
 	s.WriteString("\n")
-	s.WriteString(helpStyle.Render("enter: read • o: open browser • /,f: filter • r: refresh • F: fetch new • d: delete old • ?: help • q: quit"))
+	s.WriteString(m.renderStatusBar("list", "enter: read • o: open browser • *: star • z: snooze • l/L: queue • /,f: filter • r: refresh • F: fetch new • d: delete old • t: stats • ?: help • q: quit"))
 
 	return s.String()
 }
@@ -404,197 +1074,314 @@ func (m Model) renderDetail() string {
 	scrollInfo := helpStyle.Render(fmt.Sprintf("%3.f%%", m.viewport.ScrollPercent()*100))
 	s.WriteString(scrollInfo)
 	s.WriteString(" ")
+	s.WriteString(m.connectionBadge())
 
-	if m.err != nil {
-		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+	if m.isNoting {
+		s.WriteString(m.renderNoteInput())
+	}
+	if m.toast.text != "" {
+		s.WriteString(m.renderToast())
 		s.WriteString("\n")
 	} else if m.statusMsg != "" {
 		s.WriteString(statusStyle.Render(m.statusMsg))
 		s.WriteString("\n")
 	}
 
-	s.WriteString(helpStyle.Render("↑/↓,j/k: scroll • pgup/pgdn,space: page • enter: mark read • o: browser • s: raindrop • esc: back"))
+	s.WriteString(m.renderStatusBar("article", "↑/↓,j/k: scroll • pgup/pgdn,space: page • enter: mark read • o: browser • s: save • *: star • z: snooze • r: related • a: ask • i: metadata • esc: back"))
 
 	return s.String()
 }
 
 func (m Model) renderHelp() string {
-	help := `
-NewsReadr - Keyboard Shortcuts
-
-Article List:
-  ↑/↓, j/k     Navigate articles
-  enter        Read article
-  o            Open article in browser
-  /,f          Quick filter by title
-  r            Refresh article list
-  F            Fetch new articles from feeds
-  d            Delete old articles (older than configured max age)
-  q, ctrl+c    Quit
-
-Filter Mode:
-  type         Filter articles by title
-  enter        Apply filter and exit filter mode
-  esc          Cancel filter and show all articles
-
-Article Detail:
-  ↑/↓, j/k     Scroll line by line
-  pgup/pgdn    Scroll page by page
-  space        Page down
-  home/g       Go to top
-  end/G        Go to bottom
-  enter        Mark as read and delete article
-  o            Open article in browser
-  s            Save article to Raindrop.io
-  esc          Back to list
-  q, ctrl+c    Quit
-
-General:
-  ?            Show/hide this help
-`
-	return help + "\n" + helpStyle.Render("Press ? or esc to close help")
+	var s strings.Builder
+	s.WriteString(m.helpViewport.View())
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(fmt.Sprintf("%3.f%% • %s: scroll • %s: close help",
+		m.helpViewport.ScrollPercent()*100, keys.Help.Scroll.Help().Key, keys.Help.Close.Help().Key)))
+	return s.String()
+}
+
+// withContent fills in the content of an article from the unread list,
+// which is loaded without it
+func withContent(db database.Store, article models.Article) (models.Article, error) {
+	if article.Content != "" {
+		return article, nil
+	}
+	content, err := db.GetArticleContent(article.ID)
+	article.Content = content
+	return article, err
 }
 
-func loadArticles(db *database.DB, cfg *config.Config) tea.Cmd {
+func loadArticles(db database.Store, cfg *config.Config) tea.Cmd {
 	return func() tea.Msg {
 		maxAge := time.Duration(cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
-		articles, err := db.GetUnreadArticles(maxAge)
+		articles, err := db.GetUnreadArticleList(maxAge)
 		if err != nil {
 			return errorMsg{err}
 		}
-		return articlesLoadedMsg{articles}
+		return articlesLoadedMsg{articles: articles}
 	}
 }
 
-func fetchFeeds(fetcher *feed.Fetcher, db *database.DB, aiClient *ai.Client, cfg *config.Config) tea.Cmd {
+// fetchFeeds fetches, scores and cleans up articles. Scoring is skipped when
+// aiClient is nil. Progress is sent through send as each feed is done and
+// each batch of scores written. When manual is set the fetch summary screen
+// is shown once it completes.
+func fetchFeeds(send func(tea.Msg), fetcher *feed.Fetcher, db database.Store, aiClient *ai.Client, notifier *notify.Dispatcher, cfg *config.Config, manual bool) tea.Cmd {
 	return func() tea.Msg {
-		count, err := fetcher.FetchAllFeeds()
+		started := time.Now()
+		maxAge := time.Duration(cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
+
+		onScored := func(batch map[int64]float64, scored int, err error) {
+			send(articleScoredMsg{scores: batch, status: fmt.Sprintf("Fetching: %d new articles scored", scored), err: err})
+		}
+
+		// Score new articles unless Ollama is unavailable
+		var scorer *ai.Scorer
+		if aiClient != nil {
+			var err error
+			scorer, err = aiClient.NewScorer(onScored)
+			if err != nil {
+				return errorMsg{err}
+			}
+		}
+
+		// Articles are collected per feed and sent along with its result
+		var added []models.Article
+		onNew := func(article *models.Article) {
+			added = append(added, *article)
+			if scorer != nil {
+				scorer.Submit(article)
+			}
+		}
+		onFeedDone := func(result feed.FeedResult, done, total int) {
+			send(perFeedDoneMsg{result: result, articles: added, done: done, total: total})
+			added = nil
+		}
+
+		summary, err := fetcher.FetchAllFeeds(onNew, onFeedDone)
+		if scorer != nil {
+			if closeErr := scorer.Close(); err == nil {
+				err = closeErr
+			}
+		}
 		if err != nil {
 			return errorMsg{err}
 		}
 
-		// Score new articles
-		if err := aiClient.ScoreAllUnscored(cfg.UI.ArticleMaxAgeDays); err != nil {
-			return errorMsg{err}
+		// Catch up on articles left unscored by earlier fetches
+		if aiClient != nil {
+			if err := aiClient.ScoreAllUnscored(cfg.UI.ArticleMaxAgeDays, onScored); err != nil {
+				return errorMsg{err}
+			}
 		}
 
 		// Clean up old articles
-		maxAge := time.Duration(cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
+		send(statusMsg("Cleaning up old articles..."))
 		if err := db.DeleteOldArticles(maxAge); err != nil {
 			return errorMsg{err}
 		}
+		if _, err := db.PruneEmbeddings(cfg.Database.EmbeddingCacheMaxAge()); err != nil {
+			return errorMsg{err}
+		}
+		if _, err := db.VacuumIfNeeded(cfg.Database.AutoVacuumThreshold()); err != nil {
+			return errorMsg{err}
+		}
+
+		// Make queued and starred articles readable offline; their content
+		// is read from the database when they are shown
+		if _, err := fetcher.CacheOffline(); err != nil {
+			send(errorMsg{err})
+		}
 
-		return statusMsg(fmt.Sprintf("Fetched %d new articles", count))
+		unread, err := db.GetUnreadArticleList(maxAge)
+		if err != nil {
+			return errorMsg{err}
+		}
+		done := fetchDoneMsg{summary: summary, articles: unread, manual: manual}
+		if notifier != nil {
+			done.notifyErr = notifier.FetchDone(summary.TotalNew(), summary.Failed(), unread, started)
+		}
+		return done
 	}
 }
 
-func deleteOldArticles(db *database.DB, cfg *config.Config) tea.Cmd {
+// fireHook runs the hooks for an event in the background, reporting only
+// failures
+func fireHook(runner *hooks.Runner, db database.Store, event string, article models.Article) tea.Cmd {
+	if !runner.Has(event) {
+		return nil
+	}
 	return func() tea.Msg {
-		maxAge := time.Duration(cfg.UI.ArticleMaxAgeDays) * 24 * time.Hour
-		
-		// Get count before deletion for reporting
-		articles, _ := db.GetUnreadArticles(maxAge * 10) // Get articles older than max age
-		oldCount := 0
-		cutoff := time.Now().Add(-maxAge)
-		for _, article := range articles {
-			if article.PublishedAt.Before(cutoff) {
-				oldCount++
-			}
-		}
-		
-		// Delete old articles
-		if err := db.DeleteOldArticles(maxAge); err != nil {
-			return errorMsg{err}
-		}
-		
-		// Also delete read articles
-		if err := db.DeleteReadArticles(); err != nil {
+		// Hooks get the whole article, content included
+		article, err := withContent(db, article)
+		if err != nil {
 			return errorMsg{err}
 		}
-		
-		// Reload articles after deletion
-		articles, err := db.GetUnreadArticles(maxAge)
-		if err != nil {
+		if err := runner.Fire(event, &article); err != nil {
 			return errorMsg{err}
 		}
-		
-		return articlesLoadedMsg{articles}
+		return nil
 	}
 }
 
+// bodyMarkdown converts the HTML content of an article to Markdown, using
+// the description if there is no content
+func (m Model) bodyMarkdown(article models.Article) string {
+	var content string
+	if article.Content != "" {
+		images, _ := m.db.GetOfflineImages(article.ID)
+		content = m.articleMarkdown(localImages(article.Content, images))
+	}
+	if content == "" && article.Description != "" {
+		content = m.articleMarkdown(article.Description)
+	}
+	return content
+}
+
 func (m Model) formatArticleForView(article models.Article) string {
 	var s strings.Builder
 
-	// Convert HTML content to Markdown
-	content := article.Content
-	if content != "" {
-		// Try to convert HTML to markdown
-		markdown, err := m.mdConverter.ConvertString(content)
+	// Render the markdown with glamour, unless it was rendered recently or
+	// prefetched
+	key := m.renderKey(article)
+	rendered, ok := m.rendered.get(key)
+	if !ok {
+		content := m.bodyMarkdown(article)
+		renderer, err := m.renderers.get(key.rendererKey)
 		if err == nil {
-			content = markdown
+			rendered, err = renderer.Render(content)
 		}
-	}
-
-	// If no content, use description
-	if content == "" {
-		content = article.Description
-		if content != "" {
-			markdown, err := m.mdConverter.ConvertString(content)
-			if err == nil {
-				content = markdown
-			}
+		if err != nil {
+			// Fallback to plain text if rendering fails
+			s.WriteString(articleTitleStyle.Render(article.Title))
+			s.WriteString("\n")
+			s.WriteString(helpStyle.Render(fmt.Sprintf("Published: %s | Score: %s", article.PublishedAt.Format("Jan 2, 2006"), m.calibration.detail(article))))
+			s.WriteString("\n\n")
+			s.WriteString(content)
+			return s.String()
 		}
+		m.rendered.put(key, rendered)
 	}
 
-	// Render the markdown with glamour
-	rendered, err := m.renderer.Render(content)
-	if err != nil {
-		// Fallback to plain text if rendering fails
-		s.WriteString(articleTitleStyle.Render(article.Title))
-		s.WriteString("\n")
-		s.WriteString(helpStyle.Render(fmt.Sprintf("Published: %s | Score: %.2f", article.PublishedAt.Format("Jan 2, 2006"), article.RelevanceScore)))
-		s.WriteString("\n\n")
-		s.WriteString(content)
-		return s.String()
-	}
-
-	// Build the article view with rendered content
-	s.WriteString(articleTitleStyle.Render(article.Title))
+	// Build the article view with rendered content. Long titles wrap, the
+	// details line is cut to the viewport.
+	s.WriteString(articleTitleStyle.Width(m.viewport.Width).Render(article.Title))
 	s.WriteString("\n")
-	s.WriteString(helpStyle.Render(fmt.Sprintf("Published: %s | Score: %.2f | URL: %s", 
-		article.PublishedAt.Format("Jan 2, 2006"), 
-		article.RelevanceScore,
-		article.URL)))
+	s.WriteString(helpStyle.Render(truncate(fmt.Sprintf("Published: %s | Score: %s | URL: %s",
+		article.PublishedAt.Format("Jan 2, 2006"),
+		m.calibration.detail(article),
+		article.URL), max(m.viewport.Width, 20))))
 	s.WriteString("\n\n")
+	if m.showMetadata {
+		s.WriteString(m.renderMetadata(article))
+		s.WriteString("\n")
+	}
+	if notice := m.paywallNotice(article); notice != "" {
+		s.WriteString(notice)
+		s.WriteString("\n")
+	}
 	s.WriteString(rendered)
 
 	return s.String()
 }
 
-// applyFilter filters articles based on the filter input
-func (m *Model) applyFilter() {
-	filterText := strings.ToLower(strings.TrimSpace(m.filterInput.Value()))
+// applyFilter filters articles based on the query in the filter input. With
+// narrow, as while typing, a query narrowing the one the list was filtered
+// with only filters the articles listed; the selection stays on its article
+// when that is still listed.
+func (m *Model) applyFilter(narrow bool) {
+	expr, err := query.Parse(m.filterInput.Value())
+	if err != nil {
+		// Keep the previous results while the query is incomplete
+		m.statusMsg = fmt.Sprintf("Invalid filter: %v", err)
+		return
+	}
+	candidates := m.allArticles
+	if narrow {
+		candidates = m.filterCandidates(expr)
+	}
+	m.filterExpr = expr
 	
-	if filterText == "" {
+	if expr == nil {
 		// No filter, show all articles
 		m.articles = m.allArticles
 	} else {
-		// Filter articles by title
 		filtered := []models.Article{}
-		for _, article := range m.allArticles {
-			if strings.Contains(strings.ToLower(article.Title), filterText) {
-				filtered = append(filtered, article)
+		for i := range candidates {
+			if expr.Match(&candidates[i]) {
+				filtered = append(filtered, candidates[i])
 			}
 		}
+		// Best fuzzy matches first
+		query.Sort(expr, filtered)
 		m.articles = filtered
 	}
+	m.statusMsg = ""
 	
 	// Update list items
-	items := make([]list.Item, len(m.articles))
-	for i, article := range m.articles {
-		items[i] = articleItem{article}
+	selected := m.list.SelectedItem()
+	m.list.SetItems(m.listItems())
+	m.list.SetSize(m.listWidth(), m.height-4) // Force layout recalculation
+	if !m.restoreSelection(selected) {
+		m.list.ResetSelected()
+	}
+}
+
+// readInBrowser opens an article in the browser and marks it read, like
+// pressing enter after reading it, and returns to the list
+func (m Model) readInBrowser(article models.Article) (tea.Model, tea.Cmd) {
+	browse, err := m.openInBrowser(article)
+	if err != nil {
+		return m, func() tea.Msg { return errorMsg{err} }
+	}
+	// Recorded before marking read, which deletes the article
+	if err := m.db.RecordArticleOpen(article.ID); err != nil {
+		return m, func() tea.Msg { return errorMsg{err} }
+	}
+	if err := m.db.MarkArticleRead(article.ID); err != nil {
+		return m, func() tea.Msg { return errorMsg{err} }
+	}
+	m.db.DeleteReadArticles()
+	m.view = ViewArticleList
+	return m, tea.Batch(
+		browse,
+		m.reloadArticles(),
+		func() tea.Msg { return statusMsg("Opened in browser and marked as read") },
+		fireHook(m.hooks, m.db, hooks.ArticleRead, article),
+	)
+}
+
+// toggleStar stars or unstars an article and updates it in place
+func (m Model) toggleStar(article models.Article) (tea.Model, tea.Cmd) {
+	var err error
+	if article.Starred {
+		err = m.db.UnstarArticle(article.URL)
+	} else {
+		err = m.db.StarArticle(article.ID)
+	}
+	if err != nil {
+		return m, func() tea.Msg { return errorMsg{err} }
 	}
-	m.list.SetItems(items)
-	m.list.SetSize(m.width, m.height-4) // Force layout recalculation
-	m.list.ResetSelected()
+
+	starred := !article.Starred
+	for i := range m.allArticles {
+		if m.allArticles[i].ID == article.ID {
+			m.allArticles[i].Starred = starred
+		}
+	}
+	for i := range m.articles {
+		if m.articles[i].ID == article.ID {
+			m.articles[i].Starred = starred
+			m.updateArticleItem(m.articles[i])
+		}
+	}
+
+	if starred {
+		m.statusMsg = "Starred article"
+		article.Starred = true
+		return m, tea.Batch(fireHook(m.hooks, m.db, hooks.ArticleSaved, article), m.cacheOfflineNow())
+	}
+	m.statusMsg = "Unstarred article"
+	return m, nil
 }
@@ -0,0 +1,244 @@
+package vector
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Index is an in-memory HNSW (hierarchical navigable small world) graph for
+// approximate nearest neighbor search by cosine similarity. It is not safe
+// for concurrent use while vectors are being added.
+type Index struct {
+	m        int // links per node on upper layers; layer 0 allows 2*m
+	efBuild  int // candidates considered while linking a new node
+	levelMul float64
+	rng      *rand.Rand
+
+	nodes    []node
+	entry    int
+	maxLevel int
+
+	// ids maps each ID to its current node. Nodes of replaced vectors stay
+	// in the graph to route searches but are never returned.
+	ids      map[int64]int
+	replaced int
+}
+
+type node struct {
+	id       int64
+	vec      []float64
+	links    [][]int
+	replaced bool
+}
+
+// Result is a neighbor found by Search
+type Result struct {
+	ID         int64
+	Similarity float64
+}
+
+func New() *Index {
+	const m = 16
+	return &Index{
+		m:        m,
+		efBuild:  100,
+		levelMul: 1 / math.Log(m),
+		rng:      rand.New(rand.NewSource(1)),
+		entry:    -1,
+		ids:      map[int64]int{},
+	}
+}
+
+// Len returns the number of IDs in the index
+func (ix *Index) Len() int {
+	return len(ix.ids)
+}
+
+// Add inserts a vector under the given ID, replacing the ID's previous
+// vector. Zero vectors are ignored.
+func (ix *Index) Add(id int64, vec []float64) {
+	v := normalize(vec)
+	if v == nil {
+		return
+	}
+
+	level := int(-math.Log(1-ix.rng.Float64()) * ix.levelMul)
+	idx := len(ix.nodes)
+	ix.nodes = append(ix.nodes, node{id: id, vec: v, links: make([][]int, level+1)})
+	if old, ok := ix.ids[id]; ok {
+		ix.nodes[old].replaced = true
+		ix.replaced++
+	}
+	ix.ids[id] = idx
+
+	if ix.entry < 0 {
+		ix.entry, ix.maxLevel = idx, level
+		return
+	}
+
+	cur := ix.entry
+	for l := ix.maxLevel; l > level; l-- {
+		cur = ix.searchLayer(v, cur, 1, l)[0].idx
+	}
+
+	for l := min(level, ix.maxLevel); l >= 0; l-- {
+		candidates := ix.searchLayer(v, cur, ix.efBuild, l)
+		neighbors := candidates[:min(ix.m, len(candidates))]
+
+		for _, nb := range neighbors {
+			ix.nodes[idx].links[l] = append(ix.nodes[idx].links[l], nb.idx)
+			ix.nodes[nb.idx].links[l] = append(ix.nodes[nb.idx].links[l], idx)
+			ix.prune(nb.idx, l)
+		}
+		cur = candidates[0].idx
+	}
+
+	if level > ix.maxLevel {
+		ix.entry, ix.maxLevel = idx, level
+	}
+}
+
+// Search returns up to k stored vectors most similar to vec, best first
+func (ix *Index) Search(vec []float64, k int) []Result {
+	q := normalize(vec)
+	if q == nil || ix.entry < 0 || k <= 0 {
+		return nil
+	}
+
+	cur := ix.entry
+	for l := ix.maxLevel; l > 0; l-- {
+		cur = ix.searchLayer(q, cur, 1, l)[0].idx
+	}
+
+	// Replaced nodes are skipped, so as many more are gathered
+	found := ix.searchLayer(q, cur, max(k, 50)+ix.replaced, 0)
+	results := make([]Result, 0, min(k, len(found)))
+	for _, c := range found {
+		if len(results) == k {
+			break
+		}
+		if !ix.nodes[c.idx].replaced {
+			results = append(results, Result{ID: ix.nodes[c.idx].id, Similarity: c.sim})
+		}
+	}
+	return results
+}
+
+// prune trims a node's links on a layer to the most similar neighbors
+func (ix *Index) prune(idx, level int) {
+	maxLinks := ix.m
+	if level == 0 {
+		maxLinks = 2 * ix.m
+	}
+	links := ix.nodes[idx].links[level]
+	if len(links) <= maxLinks {
+		return
+	}
+
+	v := ix.nodes[idx].vec
+	sort.Slice(links, func(a, b int) bool {
+		return dot(v, ix.nodes[links[a]].vec) > dot(v, ix.nodes[links[b]].vec)
+	})
+	ix.nodes[idx].links[level] = links[:maxLinks]
+}
+
+// candidate is a node and its similarity to the query
+type candidate struct {
+	idx int
+	sim float64
+}
+
+// searchLayer runs a best-first search on one layer from entry, returning
+// up to ef candidates sorted by descending similarity
+func (ix *Index) searchLayer(q []float64, entry, ef, level int) []candidate {
+	visited := map[int]bool{entry: true}
+	start := candidate{entry, dot(q, ix.nodes[entry].vec)}
+
+	frontier := &maxHeap{start}
+	best := &minHeap{start}
+
+	for frontier.Len() > 0 {
+		c := heap.Pop(frontier).(candidate)
+		if best.Len() >= ef && c.sim < (*best)[0].sim {
+			break
+		}
+		for _, nb := range ix.nodes[c.idx].links[level] {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+
+			sim := dot(q, ix.nodes[nb].vec)
+			if best.Len() < ef || sim > (*best)[0].sim {
+				heap.Push(frontier, candidate{nb, sim})
+				heap.Push(best, candidate{nb, sim})
+				if best.Len() > ef {
+					heap.Pop(best)
+				}
+			}
+		}
+	}
+
+	out := append([]candidate(nil), *best...)
+	sort.Slice(out, func(a, b int) bool { return out[a].sim > out[b].sim })
+	return out
+}
+
+// normalize returns vec scaled to unit length, or nil for a zero vector
+func normalize(vec []float64) []float64 {
+	var norm float64
+	for _, x := range vec {
+		norm += x * x
+	}
+	if norm == 0 {
+		return nil
+	}
+	norm = math.Sqrt(norm)
+
+	v := make([]float64, len(vec))
+	for i, x := range vec {
+		v[i] = x / norm
+	}
+	return v
+}
+
+// dot returns the dot product of two unit vectors, i.e. their cosine
+// similarity. Vectors of different dimensions are treated as unrelated.
+func dot(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return -1
+	}
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].sim > h[j].sim }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].sim < h[j].sim }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
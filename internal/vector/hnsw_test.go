@@ -0,0 +1,155 @@
+package vector
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// randomVectors returns n vectors of dim components in [-1, 1)
+func randomVectors(rng *rand.Rand, n, dim int) [][]float64 {
+	vecs := make([][]float64, n)
+	for i := range vecs {
+		vecs[i] = make([]float64, dim)
+		for j := range vecs[i] {
+			vecs[i][j] = 2*rng.Float64() - 1
+		}
+	}
+	return vecs
+}
+
+// cosine is the cosine similarity of two vectors, computed directly
+func cosine(a, b []float64) float64 {
+	var ab, aa, bb float64
+	for i := range a {
+		ab += a[i] * b[i]
+		aa += a[i] * a[i]
+		bb += b[i] * b[i]
+	}
+	return ab / math.Sqrt(aa*bb)
+}
+
+// bruteForce returns the IDs (indexes into vecs) of the k vectors most
+// similar to q
+func bruteForce(vecs [][]float64, q []float64, k int) []int64 {
+	ids := make([]int64, len(vecs))
+	for i := range ids {
+		ids[i] = int64(i)
+	}
+	sort.Slice(ids, func(a, b int) bool { return cosine(q, vecs[ids[a]]) > cosine(q, vecs[ids[b]]) })
+	return ids[:k]
+}
+
+func TestSearchRecall(t *testing.T) {
+	const n, dim, queries = 2000, 32, 100
+	rng := rand.New(rand.NewSource(42))
+	vecs := randomVectors(rng, n, dim)
+	ix := New()
+	for i, v := range vecs {
+		ix.Add(int64(i), v)
+	}
+
+	for _, k := range []int{1, 10, 50} {
+		var hits int
+		for _, q := range randomVectors(rng, queries, dim) {
+			want := map[int64]bool{}
+			for _, id := range bruteForce(vecs, q, k) {
+				want[id] = true
+			}
+			results := ix.Search(q, k)
+			if len(results) != k {
+				t.Fatalf("Search(k=%d) returned %d results", k, len(results))
+			}
+			for i, r := range results {
+				if i > 0 && r.Similarity > results[i-1].Similarity {
+					t.Fatalf("Search(k=%d) results not sorted by similarity", k)
+				}
+				if got := cosine(q, vecs[r.ID]); math.Abs(got-r.Similarity) > 1e-9 {
+					t.Fatalf("result %d has similarity %f, want %f", r.ID, r.Similarity, got)
+				}
+				if want[r.ID] {
+					hits++
+				}
+			}
+		}
+		if recall := float64(hits) / float64(k*queries); recall < 0.95 {
+			t.Errorf("recall@%d = %.3f, want at least 0.95", k, recall)
+		}
+	}
+}
+
+func TestSearchEdgeCases(t *testing.T) {
+	empty := New()
+	if got := empty.Search([]float64{1, 0}, 5); got != nil {
+		t.Errorf("searching an empty index returned %v", got)
+	}
+
+	single := New()
+	single.Add(7, []float64{3, 4})
+	got := single.Search([]float64{3, 4}, 5)
+	if len(got) != 1 || got[0].ID != 7 || math.Abs(got[0].Similarity-1) > 1e-9 {
+		t.Errorf("searching a single node returned %v, want ID 7 with similarity 1", got)
+	}
+	if got := single.Search([]float64{3, 4}, 0); got != nil {
+		t.Errorf("searching for k=0 returned %v", got)
+	}
+	if got := single.Search([]float64{0, 0}, 5); got != nil {
+		t.Errorf("searching for a zero vector returned %v", got)
+	}
+
+	small := New()
+	small.Add(1, []float64{0, 0})
+	for i, v := range randomVectors(rand.New(rand.NewSource(1)), 5, 8) {
+		small.Add(int64(i), v)
+	}
+	if small.Len() != 5 {
+		t.Errorf("Len = %d after adding 5 vectors and a zero vector, want 5", small.Len())
+	}
+	got = small.Search(randomVectors(rand.New(rand.NewSource(2)), 1, 8)[0], 10)
+	seen := map[int64]bool{}
+	for _, r := range got {
+		seen[r.ID] = true
+	}
+	if len(got) != 5 || len(seen) != 5 {
+		t.Errorf("searching 5 nodes for 10 returned %v, want each node once", got)
+	}
+}
+
+func TestAddReplaces(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	vecs := randomVectors(rng, 200, 16)
+	ix := New()
+	for i, v := range vecs {
+		ix.Add(int64(i), v)
+	}
+	// Move every vector, some of them twice
+	for i := range vecs {
+		vecs[i] = randomVectors(rng, 1, 16)[0]
+		ix.Add(int64(i), vecs[i])
+	}
+	for i := 0; i < len(vecs); i += 3 {
+		vecs[i] = randomVectors(rng, 1, 16)[0]
+		ix.Add(int64(i), vecs[i])
+	}
+	if ix.Len() != len(vecs) {
+		t.Fatalf("Len = %d after re-adding the same IDs, want %d", ix.Len(), len(vecs))
+	}
+
+	for i, v := range vecs {
+		got := ix.Search(v, 10)
+		if len(got) == 0 || got[0].ID != int64(i) || math.Abs(got[0].Similarity-1) > 1e-9 {
+			t.Fatalf("searching for the new vector of %d returned %v", i, got)
+		}
+		seen := map[int64]bool{}
+		for _, r := range got {
+			if seen[r.ID] {
+				t.Fatalf("searching returned %d twice: %v", r.ID, got)
+			}
+			seen[r.ID] = true
+			if want := cosine(v, vecs[r.ID]); math.Abs(r.Similarity-want) > 1e-9 {
+				t.Fatalf("result %d has the similarity of a replaced vector", r.ID)
+			}
+		}
+	}
+}
@@ -8,6 +8,13 @@ type Feed struct {
 	Name      string    `json:"name"`
 	Enabled   bool      `json:"enabled"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// Health of the most recent fetches
+	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastItemAt    *time.Time `json:"last_item_at,omitempty"`
+	LastStatus    int        `json:"last_status,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
 }
 
 type Article struct {
@@ -20,6 +27,20 @@ type Article struct {
 	PublishedAt    time.Time `json:"published_at"`
 	FetchedAt      time.Time `json:"fetched_at"`
 	RelevanceScore float64   `json:"relevance_score"`
+	Tags           []string  `json:"tags,omitempty"`
+	// Author, GUID and CommentsURL are taken from the feed item when present
+	Author      string `json:"author,omitempty"`
+	GUID        string `json:"guid,omitempty"`
+	CommentsURL string `json:"comments_url,omitempty"`
+	// RemoteID is the item's ID in a synced service like Nextcloud News
+	RemoteID int64  `json:"remote_id,omitempty"`
+	FeedName string `json:"feed_name,omitempty"`
+	FeedURL  string `json:"feed_url,omitempty"`
+	Starred  bool   `json:"starred"`
+	Queued   bool   `json:"queued"`
+	// Scored is false until the article has been scored, or after it was
+	// marked for re-scoring
+	Scored bool `json:"scored"`
 }
 
 type UserInterest struct {
@@ -27,9 +48,55 @@ type UserInterest struct {
 	Description string  `json:"description"`
 	Weight      float64 `json:"weight"`
 	Embedding   []byte  `json:"embedding,omitempty"`
+	// Group scores interests together; an article's score is its best group
+	Group string `json:"group,omitempty"`
+	// Avoid marks a topic whose similarity is subtracted from the score
+	Avoid bool `json:"avoid,omitempty"`
 }
 
 type ReadArticle struct {
 	ArticleID int64     `json:"article_id"`
 	ReadAt    time.Time `json:"read_at"`
 }
+
+// HistoryEntry is a snapshot of a read or starred article that outlives
+// the article row itself
+type HistoryEntry struct {
+	ArticleID      int64      `json:"article_id"`
+	FeedName       string     `json:"feed"`
+	Title          string     `json:"title"`
+	URL            string     `json:"url"`
+	RelevanceScore float64    `json:"score"`
+	PublishedAt    time.Time  `json:"published_at"`
+	ReadAt         *time.Time `json:"read_at,omitempty"`
+	StarredAt      *time.Time `json:"starred_at,omitempty"`
+	OpenedAt       *time.Time `json:"opened_at,omitempty"` // last opened in the browser
+}
+
+// Mute kinds
+const (
+	MuteKeyword = "keyword"
+	MuteRegex   = "regex"
+	MuteDomain  = "domain"
+)
+
+// Mute hides articles whose title or description contains a keyword or
+// matches a regex, or whose URL is on a blocked domain
+type Mute struct {
+	ID    int64  `json:"id"`
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Entity kinds
+const (
+	EntityPerson  = "person"
+	EntityCompany = "company"
+	EntityProject = "project"
+)
+
+// Entity is a person, company or project an article names
+type Entity struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}